@@ -3,84 +3,158 @@ package main
 import (
 	"apigateway/internal/routes"
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"shared/config"
+	"shared/pkg/grpcutil"
+	"shared/pkg/tracing"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
-func setupGRPC() map[string]*grpc.ClientConn {
+const (
+	backendReadyTimeout  = 10 * time.Second
+	reconnectCheckPeriod = 5 * time.Second
+)
+
+func setupGRPC() (map[string]*grpc.ClientConn, error) {
 	godotenv.Load(".env")
 	services := map[string]string{
-		"collection": os.Getenv("COLLECTION_SERVICE_PORT"),
-		"book":       os.Getenv("BOOK_SERVICE_PORT"),
-		"borrow":     os.Getenv("BORROW_SERVICE_PORT"),
+		"collection": config.LoadServerConfig("collection").DialAddress(),
+		"book":       config.LoadServerConfig("book").DialAddress(),
+		"borrow":     config.LoadServerConfig("borrow").DialAddress(),
 	}
 
-	connections := make(map[string]*grpc.ClientConn)
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	connections, err := grpcutil.DialServices(services)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial backend services: %w", err)
+	}
 
-	for service, port := range services {
-		conn, err := grpc.NewClient("localhost:"+port, opts...)
-		if err != nil {
-			log.Fatalf("%s grpc server connection failed: %s", service, err)
-		}
-		connections[service] = conn
+	// Clients connect lazily, wait for the backends so the first requests
+	// don't fail. A backend that isn't up yet only logs, the gateway still starts.
+	ctx, cancel := context.WithTimeout(context.Background(), backendReadyTimeout)
+	defer cancel()
+	for service, err := range grpcutil.WaitForConnections(ctx, connections) {
+		log.Printf("%s service not ready: %v", service, err)
 	}
 
-	return connections
+	return connections, nil
 }
 
-func closeConnections(connections map[string]*grpc.ClientConn) {
-	for _, conn := range connections {
-		conn.Close()
+func setupRedis() *redis.Client {
+	cfg := config.LoadRedisConfig()
+	rdb := redis.NewClient(&redis.Options{
+		Addr:         cfg.Addr,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+		MaxRetries:   cfg.MaxRetries,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		PoolTimeout:  cfg.PoolTimeout,
+	})
+
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		log.Printf("Redis unavailable, using in-memory rate limiting: %v", err)
+		rdb.Close()
+		return nil
 	}
+
+	return rdb
 }
 
 func main() {
+	if err := run(); err != nil {
+		log.Fatalf("api gateway failed: %v", err)
+	}
+}
+
+// Runs the gateway until it is told to stop, startup failures are returned
+// after releasing whatever was already set up
+func run() error {
 	// Create a channel to listen for interrupt signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	godotenv.Load(".env")
+	gatewayConfig, err := config.LoadGatewayConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load gateway config: %w", err)
+	}
+
+	// Export traces when a collector endpoint is configured
+	shutdownTracing, err := tracing.Init(context.Background(), "api-gateway")
+	if err != nil {
+		return fmt.Errorf("failed to start tracing: %w", err)
+	}
+
 	// Setup gRPC
-	connections := setupGRPC()
-	defer closeConnections(connections)
+	connections, err := setupGRPC()
+	if err != nil {
+		return err
+	}
+	defer grpcutil.CloseConnections(connections)
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go grpcutil.WatchConnections(watchCtx, connections, reconnectCheckPeriod)
+
+	// Share rate limits across replicas through Redis when it's reachable
+	batchingConfig := routes.DefaultBatchingConfig()
+	batchingConfig.AdminToken = gatewayConfig.AdminToken
+	batchingConfig.MaxBodyBytes = gatewayConfig.MaxBodyBytes
+	batchingConfig.MaxBulkBodyBytes = gatewayConfig.MaxBulkBytes
+	rdb := setupRedis()
+	if rdb != nil {
+		defer rdb.Close()
+		batchingConfig.RateLimitRedis = rdb
+	}
 
 	// Setup Gin routes
-	router := routes.SetupRoutes(connections, routes.DefaultBatchingConfig())
+	router := routes.SetupRoutes(connections, batchingConfig)
 
 	// Start server in a goroutine
 	srv := &http.Server{
-		Addr:    "localhost:8080",
+		Addr:    gatewayConfig.Addr,
 		Handler: router,
 	}
+	serveErr := make(chan error, 1)
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+			serveErr <- err
 		}
 	}()
 
-	log.Println("Server started on localhost:8080")
+	log.Printf("Server started on %s", gatewayConfig.Addr)
 
-	// Wait for interrupt signal
-	<-quit
-	log.Println("Shutting down server...")
+	// Wait for interrupt signal, or for the server to fail
+	select {
+	case <-quit:
+		log.Println("Shutting down server...")
+	case err = <-serveErr:
+		log.Printf("Server failed: %v, shutting down...", err)
+	}
 
 	// Create a deadline for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), gatewayConfig.ShutdownTimeout)
 	defer cancel()
 
 	// Shutdown HTTP server
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Error flushing traces: %v", err)
+	}
 
 	log.Println("Server exited")
+	return err
 }
@@ -2,6 +2,11 @@ package handler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
 	"sync"
 	"time"
 )
@@ -33,3 +38,96 @@ func NewReqBatcher[K any, V any](client K, batchWindow time.Duration) *ReqBatche
 		pending:     []*BatchRequest[V]{},
 	}
 }
+
+// Adds a request to the current batch window and waits for its response.
+// The first request of a window starts the timer that calls flush.
+func (b *ReqBatcher[K, V]) enqueue(ctx context.Context, params QueryParams, flush func()) (*V, error) {
+	req := &BatchRequest[V]{
+		ctx:    ctx,
+		params: params,
+		resp:   make(chan *V, 1),
+		err:    make(chan error, 1),
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, req)
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.batchWindow, flush)
+	}
+	b.mu.Unlock()
+
+	select {
+	case r := <-req.resp:
+		return r, nil
+	case e := <-req.err:
+		return nil, e
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Takes every pending request, groups them by query params and makes one
+// backend call per group. Each response only goes to the members of its group.
+func (b *ReqBatcher[K, V]) dispatch(call func(ctx context.Context, params QueryParams) (*V, error)) {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.timer = nil
+	b.mu.Unlock()
+
+	var keys []string
+	groups := make(map[string][]*BatchRequest[V])
+	for _, req := range pending {
+		key := req.params.batchKey()
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], req)
+	}
+
+	log.Printf("Flushing batch with %d requests in %d groups", len(pending), len(groups))
+	for _, key := range keys {
+		group := groups[key]
+		ctx, cancel := groupContext(group)
+		resp, err := call(ctx, group[0].params)
+		cancel()
+		for _, req := range group {
+			if err != nil {
+				req.err <- err
+			} else {
+				req.resp <- resp
+			}
+		}
+	}
+}
+
+// Bounds a group's backend call by the latest deadline among its members, so
+// the call runs as long as someone is still waiting for it. Metadata and trace
+// of the first member are carried over, its cancellation isn't.
+func groupContext[V any](group []*BatchRequest[V]) (context.Context, context.CancelFunc) {
+	parent := context.WithoutCancel(group[0].ctx)
+	var latest time.Time
+	for _, req := range group {
+		deadline, ok := req.ctx.Deadline()
+		if !ok {
+			return context.WithTimeout(parent, DefaultRequestTimeout)
+		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+
+	return context.WithDeadline(parent, latest)
+}
+
+// Identifies requests that can share a single backend call
+func (p QueryParams) batchKey() string {
+	data, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("Error hashing query params: %v", err)
+		return fmt.Sprintf("%+v", p)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -2,35 +2,81 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"shared/pkg/model"
 	pb "shared/proto/buffer"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// Largest number of books accepted by a single bulk insert when no limit is configured
+const DefaultMaxBulkBooks = 500
+
 type BookHandler struct {
-	client  pb.BookServiceClient
-	batcher ReqBatcherInterface[pb.BookServiceClient, pb.BookResponse]
+	client       pb.BookServiceClient
+	batcher      ReqBatcherInterface[pb.BookServiceClient, pb.BookResponse]
+	timeout      time.Duration
+	retry        RetryPolicy
+	maxPageLimit int
+	maxBulkSize  int
 }
 
 func NewBookHandler(conn *grpc.ClientConn) *BookHandler {
+	return NewBookHandlerWithClient(pb.NewBookServiceClient(conn))
+}
+
+func NewBookHandlerWithClient(client pb.BookServiceClient) *BookHandler {
 	return &BookHandler{
-		client: pb.NewBookServiceClient(conn),
+		client:       client,
+		timeout:      DefaultRequestTimeout,
+		retry:        DefaultRetryPolicy(),
+		maxPageLimit: DefaultMaxPageLimit,
+		maxBulkSize:  DefaultMaxBulkBooks,
 	}
 }
 
 func NewBookHandlerWithBatching(conn *grpc.ClientConn, batchWindow time.Duration) *BookHandler {
 	client := pb.NewBookServiceClient(conn)
 	return &BookHandler{
-		client:  client,
-		batcher: NewBookReqBatcher(client, batchWindow),
+		client:       client,
+		timeout:      DefaultRequestTimeout,
+		retry:        DefaultRetryPolicy(),
+		maxPageLimit: DefaultMaxPageLimit,
+		maxBulkSize:  DefaultMaxBulkBooks,
+		batcher:      NewBookReqBatcher(client, batchWindow),
 	}
 }
 
+// Sets how long each backend call may take before the request fails with 504
+func (h *BookHandler) WithTimeout(timeout time.Duration) *BookHandler {
+	h.timeout = timeout
+	return h
+}
+
+// Sets how reads are retried when the book service fails transiently
+func (h *BookHandler) WithRetry(policy RetryPolicy) *BookHandler {
+	h.retry = policy
+	return h
+}
+
+// Sets the largest page a list request may ask for, larger limits are rejected
+func (h *BookHandler) WithMaxPageLimit(limit int) *BookHandler {
+	h.maxPageLimit = limit
+	return h
+}
+
+// Sets how many books a single bulk insert may contain
+func (h *BookHandler) WithMaxBulkSize(size int) *BookHandler {
+	h.maxBulkSize = size
+	return h
+}
+
 // GrpcBatcher handles batching for gRPC calls
 type BookReqBatcher struct {
 	baseBatcher *ReqBatcher[pb.BookServiceClient, pb.BookResponse]
@@ -53,128 +99,219 @@ func (h *BookHandler) BatchingMiddleware() gin.HandlerFunc {
 }
 
 func (h *BookHandler) GetBook(c *gin.Context) {
-	params := ParseQueryParams(c)
-	filter, sort := BuildFilterAndSort(params)
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	filter, sort, err := BuildFilterAndSort(params)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
 	request := pb.GetBookRequest{
-		Filter: filter,
-		Sort:   sort,
-		Skip:   int32(params.Skip),
-		Limit:  int32(params.Limit),
+		Filter:         filter,
+		Sort:           sort,
+		Skip:           int32(params.Skip),
+		Limit:          int32(params.Limit),
+		After:          params.After,
+		UseCursor:      params.UseCursor,
+		Fields:         params.Fields,
+		IncludeDeleted: params.IncludeDeleted,
 	}
 
-	response, err := h.client.GetBook(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BookResponse, error) {
+		return h.client.GetBook(ctx, &request)
+	})
 	if err != nil {
-		c.JSON(500, BuildHttpResponse(false, 500, ExtractErrorMessage(err), []interface{}{}))
+		RespondWithError(c, err)
 		return
 	}
 
 	books := model.FromPbBooks(response.Book)
-	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{books}))
+	httpResponse := BuildHttpResponse(true, 200, response.Message, []interface{}{books})
+	httpResponse.NextCursor = response.NextCursor
+	httpResponse.Meta = BuildMeta(params, response.Total)
+	c.JSON(200, httpResponse)
 }
 
 func (h *BookHandler) GetBookBatch(c *gin.Context) {
-	params := ParseQueryParams(c)
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
 
 	if h.batcher != nil {
 		// Use batcher for multiple requests
-		response, err := h.batcher.GetBatch(c.Request.Context(), params)
+		ctx, cancel := callContext(c, h.timeout)
+		defer cancel()
+		response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BookResponse, error) {
+			return h.batcher.GetBatch(ctx, params)
+		})
 		if err != nil {
-			message := ExtractErrorMessage(err)
-			c.JSON(500, BuildHttpResponse(false, 500, message, []interface{}{}))
+			RespondWithError(c, err)
 			return
 		}
-		c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbBooks(response.Book)}))
+		httpResponse := BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbBooks(response.Book)})
+		httpResponse.NextCursor = response.NextCursor
+		httpResponse.Meta = BuildMeta(params, response.Total)
+		c.JSON(200, httpResponse)
 	} else {
 		h.GetBook(c)
 	}
 }
 
 func (b *BookReqBatcher) GetBatch(ctx context.Context, params QueryParams) (*pb.BookResponse, error) {
-	req := &BatchRequest[pb.BookResponse]{
-		ctx:  ctx,
-		resp: make(chan *pb.BookResponse, 1),
-		err:  make(chan error, 1),
-	}
+	return b.baseBatcher.enqueue(ctx, params, b.flush)
+}
 
-	b.baseBatcher.mu.Lock()
-	b.baseBatcher.pending = append(b.baseBatcher.pending, req)
-	if b.baseBatcher.timer == nil {
-		b.baseBatcher.timer = time.AfterFunc(b.baseBatcher.batchWindow, b.flush)
-	}
-	b.baseBatcher.mu.Unlock()
+func (b *BookReqBatcher) flush() {
+	b.baseBatcher.dispatch(func(ctx context.Context, params QueryParams) (*pb.BookResponse, error) {
+		filter, sort, err := BuildFilterAndSort(params)
+		if err != nil {
+			return nil, err
+		}
+		request := pb.GetBookRequest{
+			Filter:         filter,
+			Sort:           sort,
+			Skip:           int32(params.Skip),
+			Limit:          int32(params.Limit),
+			After:          params.After,
+			UseCursor:      params.UseCursor,
+			Fields:         params.Fields,
+			IncludeDeleted: params.IncludeDeleted,
+		}
 
-	select {
-	case r := <-req.resp:
-		return r, nil
-	case e := <-req.err:
-		return nil, e
-	}
+		// Make a single backend call for every request in the group
+		return b.baseBatcher.client.GetBook(ctx, &request)
+	})
 }
 
-func (b *BookReqBatcher) flush() {
-	b.baseBatcher.mu.Lock()
-	pending := b.baseBatcher.pending
-	b.baseBatcher.pending = nil
-	b.baseBatcher.timer = nil
-	b.baseBatcher.mu.Unlock()
+func (h *BookHandler) GetBookById(c *gin.Context) {
+	id, ok := c.Params.Get("id")
 
-	var params QueryParams
-	if len(pending) > 0 {
-		params = pending[0].params
+	if !ok {
+		log.Println("Id not specified in request params")
+		c.JSON(500, BuildHttpResponse(false, 500, "ID Not Specified", []interface{}{}))
+		return
 	}
-	filter, sort := BuildFilterAndSort(params)
-	request := pb.GetBookRequest{
-		Filter: filter,
-		Sort:   sort,
-		Skip:   int32(params.Skip),
-		Limit:  int32(params.Limit),
+	request := pb.FindBookRequest{Id: id}
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BookResponse, error) {
+		return h.client.FindBookById(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if IsNotFoundResponse(response.Success, response.Message) {
+		c.JSON(404, BuildHttpResponse(false, 404, response.Message, []interface{}{}))
+		return
 	}
 
-	// Make a single backend call for all pending requests
-	resp, err := b.baseBatcher.client.GetBook(context.Background(), &request)
-	for _, req := range pending {
-		log.Printf("Flushing batch with %d requests", len(pending))
-		if err != nil {
-			req.err <- err
-		} else {
-			req.resp <- resp
-		}
+	if len(response.Book) == 1 {
+		setETag(c, response.Book[0].Etag)
 	}
+	books := model.FromPbBooks(response.Book)
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{books}))
 }
 
-func (h *BookHandler) GetBookById(c *gin.Context) {
+// Lists the books of the collection in the id param that can still be borrowed
+func (h *BookHandler) GetAvailableBooks(c *gin.Context) {
 	id, ok := c.Params.Get("id")
+	if !ok {
+		log.Println("Id not specified in request params")
+		c.JSON(500, BuildHttpResponse(false, 500, "ID Not Specified", []interface{}{}))
+		return
+	}
+
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	request := pb.GetAvailableBookRequest{
+		CollectionId: id,
+		Skip:         int32(params.Skip),
+		Limit:        int32(params.Limit),
+	}
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BookResponse, error) {
+		return h.client.GetAvailableBooks(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	books := model.FromPbBooks(response.Book)
+	httpResponse := BuildHttpResponse(true, 200, response.Message, []interface{}{books})
+	httpResponse.Meta = BuildMeta(params, response.Total)
+	c.JSON(200, httpResponse)
+}
 
+// Lists the books of the collection in the id param, available_only=true
+// leaves out the borrowed ones
+func (h *BookHandler) GetBooksByCollection(c *gin.Context) {
+	id, ok := c.Params.Get("id")
 	if !ok {
 		log.Println("Id not specified in request params")
 		c.JSON(500, BuildHttpResponse(false, 500, "ID Not Specified", []interface{}{}))
 		return
 	}
-	request := pb.FindBookRequest{Id: id}
-	response, err := h.client.FindBookById(c, &request)
+
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	availableOnly, _ := strconv.ParseBool(c.Query("available_only"))
+	request := pb.GetBooksByCollectionRequest{
+		CollectionId:  id,
+		AvailableOnly: availableOnly,
+		Skip:          int32(params.Skip),
+		Limit:         int32(params.Limit),
+	}
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BookResponse, error) {
+		return h.client.GetBooksByCollection(ctx, &request)
+	})
 	if err != nil {
-		c.JSON(500, BuildHttpResponse(false, 500, ExtractErrorMessage(err), []interface{}{}))
+		RespondWithError(c, err)
 		return
 	}
 
 	books := model.FromPbBooks(response.Book)
-	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{books}))
+	httpResponse := BuildHttpResponse(true, 200, response.Message, []interface{}{books})
+	httpResponse.Meta = BuildMeta(params, response.Total)
+	c.JSON(200, httpResponse)
 }
 
 func (h *BookHandler) CreateBook(c *gin.Context) {
 	var book model.Book
-	if err := c.BindJSON(&book); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request body"})
+	if err := c.ShouldBindJSON(&book); err != nil {
+		RespondWithBindError(c, err)
+		return
+	}
+	if !validateCreateBody(c, bookValidator.ValidateExcept(book, serverAssignedFields...)) {
 		return
 	}
 
 	pbBook := model.ToPbBook(&book)
 	pbBook.CollectionId = book.CollectionId.Hex()
 	request := pb.AddBookRequest{Book: pbBook}
-	response, err := h.client.AddBook(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.AddBook(ctx, &request)
 
 	if err != nil {
-		c.JSON(500, BuildHttpResponse(false, 500, ExtractErrorMessage(err), []interface{}{}))
+		RespondWithError(c, err)
 		return
 	}
 
@@ -191,9 +328,9 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 	}
 
 	var book map[string]interface{}
-	if err := c.BindJSON(&book); err != nil {
+	if err := c.ShouldBindJSON(&book); err != nil {
 		log.Printf("Error binding json: %s", err)
-		c.JSON(400, gin.H{"error": "Invalid request body"})
+		RespondWithBindError(c, err)
 		return
 	}
 
@@ -208,12 +345,23 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 	request := pb.UpdateBookRequest{
 		Payload: structPayload,
 		Id:      id,
+		IfMatch: ifMatch(c),
 	}
-	response, err := h.client.UpdateBook(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.UpdateBook(ctx, &request)
 	if err != nil {
-		c.JSON(500, BuildHttpResponse(false, 500, ExtractErrorMessage(err), []interface{}{}))
+		RespondWithError(c, err)
+		return
+	}
+	if IsNotFoundResponse(response.Success, response.Message) {
+		c.JSON(404, BuildHttpResponse(false, 404, response.Message, []interface{}{}))
+		return
 	}
 
+	if len(response.Book) == 1 {
+		setETag(c, response.Book[0].Etag)
+	}
 	books := model.FromPbBooks(response.Book)
 	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{books}))
 }
@@ -231,12 +379,111 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 		return
 	}
 	request := pb.DeleteBookRequest{Id: id}
-	response, err := h.client.DeleteBook(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.DeleteBook(ctx, &request)
 	if err != nil {
-		c.JSON(500, BuildHttpResponse(false, 500, ExtractErrorMessage(err), []interface{}{}))
+		RespondWithError(c, err)
+		return
+	}
+	if IsNotFoundResponse(response.Success, response.Message) {
+		c.JSON(404, BuildHttpResponse(false, 404, response.Message, []interface{}{}))
 		return
 	}
 
 	books := model.FromPbBooks(response.Book)
 	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{books}))
 }
+
+func (h *BookHandler) BulkInsertBooks(c *gin.Context) {
+	var books []model.Book
+	if err := c.ShouldBindJSON(&books); err != nil {
+		RespondWithBindError(c, err)
+		return
+	}
+
+	if len(books) == 0 {
+		c.JSON(400, BuildHttpResponse(false, 400, "No books to insert", []interface{}{}))
+		return
+	}
+	if h.maxBulkSize > 0 && len(books) > h.maxBulkSize {
+		message := fmt.Sprintf("Cannot insert more than %d books at once", h.maxBulkSize)
+		c.JSON(400, BuildHttpResponse(false, 400, message, []interface{}{}))
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range books {
+		books[i].Id = primitive.NewObjectID()
+		books[i].CreatedAt = now
+		books[i].UpdatedAt = now
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	request := pb.BulkInsertBookRequest{Books: model.ToPbBooks(books)}
+	response, err := h.client.BulkInsert(ctx, &request)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbBooks(response.Book)}))
+}
+
+// Reruns the background stock updates parked in the book service's DLQ. Not
+// retried, a replayed operation isn't safe to run twice.
+func (h *BookHandler) ReplayStockDLQ(c *gin.Context) {
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.ReplayStockDLQ(ctx, &pb.ReplayStockDLQRequest{})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{map[string]interface{}{
+		"replayed":  response.Replayed,
+		"failed":    response.Failed,
+		"remaining": response.Remaining,
+	}}))
+}
+
+// Sets the same fields on every book matching the filter, the body is
+// {"filter": {...}, "update": {...}}
+func (h *BookHandler) UpdateBooks(c *gin.Context) {
+	var body struct {
+		Filter map[string]interface{} `json:"filter"`
+		Update map[string]interface{} `json:"update"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.Printf("Error binding json: %s", err)
+		RespondWithBindError(c, err)
+		return
+	}
+
+	filter, err := structpb.NewStruct(body.Filter)
+	if err != nil {
+		log.Printf("Error creating struct: %s", err)
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+	update, err := structpb.NewStruct(body.Update)
+	if err != nil {
+		log.Printf("Error creating struct: %s", err)
+		c.JSON(400, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.UpdateBooks(ctx, &pb.UpdateBooksRequest{Filter: filter, Payload: update})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{map[string]interface{}{
+		"modified": response.Modified,
+	}}))
+}
@@ -1,32 +1,185 @@
 package handler
 
 import (
+	"context"
+	"log"
+	"shared/pkg/grpcutil"
+	"shared/pkg/model"
 	pb "shared/proto/buffer"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
 )
 
+// Lets clients retry a borrow without borrowing twice
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 type BorrowHandler struct {
-	client pb.BorrowServiceClient
+	client       pb.BorrowServiceClient
+	batcher      ReqBatcherInterface[pb.BorrowServiceClient, pb.BorrowListResponse]
+	timeout      time.Duration
+	retry        RetryPolicy
+	maxPageLimit int
 }
 
 func NewBorrowHandler(conn *grpc.ClientConn) *BorrowHandler {
+	return NewBorrowHandlerWithClient(pb.NewBorrowServiceClient(conn))
+}
+
+func NewBorrowHandlerWithClient(client pb.BorrowServiceClient) *BorrowHandler {
+	return &BorrowHandler{
+		client:       client,
+		timeout:      DefaultRequestTimeout,
+		retry:        DefaultRetryPolicy(),
+		maxPageLimit: DefaultMaxPageLimit,
+	}
+}
+
+func NewBorrowHandlerWithBatching(conn *grpc.ClientConn, batchWindow time.Duration) *BorrowHandler {
+	client := pb.NewBorrowServiceClient(conn)
 	return &BorrowHandler{
-		client: pb.NewBorrowServiceClient(conn),
+		client:       client,
+		timeout:      DefaultRequestTimeout,
+		retry:        DefaultRetryPolicy(),
+		maxPageLimit: DefaultMaxPageLimit,
+		batcher:      NewBorrowReqBatcher(client, batchWindow),
+	}
+}
+
+// Sets how long each backend call may take before the request fails with 504
+func (h *BorrowHandler) WithTimeout(timeout time.Duration) *BorrowHandler {
+	h.timeout = timeout
+	return h
+}
+
+// Sets how reads are retried when the borrow service fails transiently
+func (h *BorrowHandler) WithRetry(policy RetryPolicy) *BorrowHandler {
+	h.retry = policy
+	return h
+}
+
+// Sets the largest page a list request may ask for, larger limits are rejected
+func (h *BorrowHandler) WithMaxPageLimit(limit int) *BorrowHandler {
+	h.maxPageLimit = limit
+	return h
+}
+
+// BorrowReqBatcher handles batching for borrow list calls
+type BorrowReqBatcher struct {
+	baseBatcher *ReqBatcher[pb.BorrowServiceClient, pb.BorrowListResponse]
+}
+
+func NewBorrowReqBatcher(client pb.BorrowServiceClient, batchWindow time.Duration) *BorrowReqBatcher {
+	return &BorrowReqBatcher{
+		baseBatcher: NewReqBatcher[pb.BorrowServiceClient, pb.BorrowListResponse](client, batchWindow),
+	}
+}
+
+// BatchingMiddleware returns middleware function for this handler
+func (h *BorrowHandler) BatchingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.batcher != nil {
+			c.Set("borrow_batcher", h.batcher)
+		}
+		c.Next()
+	}
+}
+
+func (h *BorrowHandler) GetBorrows(c *gin.Context) {
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	filter, sort, err := BuildFilterAndSort(params)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	request := pb.GetBorrowsRequest{
+		Filter: filter,
+		Sort:   sort,
+		Skip:   int32(params.Skip),
+		Limit:  int32(params.Limit),
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BorrowListResponse, error) {
+		return h.client.GetBorrows(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	borrows := model.FromPbBorrows(response.Borrow)
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{borrows}))
+}
+
+func (h *BorrowHandler) GetBorrowBatch(c *gin.Context) {
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
 	}
+
+	if h.batcher != nil {
+		// Use batcher for multiple requests
+		ctx, cancel := callContext(c, h.timeout)
+		defer cancel()
+		response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BorrowListResponse, error) {
+			return h.batcher.GetBatch(ctx, params)
+		})
+		if err != nil {
+			RespondWithError(c, err)
+			return
+		}
+		c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbBorrows(response.Borrow)}))
+	} else {
+		h.GetBorrows(c)
+	}
+}
+
+func (b *BorrowReqBatcher) GetBatch(ctx context.Context, params QueryParams) (*pb.BorrowListResponse, error) {
+	return b.baseBatcher.enqueue(ctx, params, b.flush)
+}
+
+func (b *BorrowReqBatcher) flush() {
+	b.baseBatcher.dispatch(func(ctx context.Context, params QueryParams) (*pb.BorrowListResponse, error) {
+		filter, sort, err := BuildFilterAndSort(params)
+		if err != nil {
+			return nil, err
+		}
+		request := pb.GetBorrowsRequest{
+			Filter: filter,
+			Sort:   sort,
+			Skip:   int32(params.Skip),
+			Limit:  int32(params.Limit),
+		}
+
+		// Make a single backend call for every request in the group
+		return b.baseBatcher.client.GetBorrows(ctx, &request)
+	})
 }
 
 func (h *BorrowHandler) BorrowBook(c *gin.Context) {
 	var borrowRequest pb.BorrowRequest
-	if err := c.BindJSON(&borrowRequest); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request body"})
+	if err := c.ShouldBindJSON(&borrowRequest); err != nil {
+		RespondWithBindError(c, err)
 		return
 	}
+	if key := c.GetHeader(IdempotencyKeyHeader); key != "" {
+		borrowRequest.IdempotencyKey = key
+	}
 
-	response, err := h.client.BorrowBook(c, &borrowRequest)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.BorrowBook(ctx, &borrowRequest)
 	if err != nil {
-		c.JSON(500, BuildHttpResponse(false, 500, ExtractErrorMessage(err), []interface{}{}))
+		RespondWithError(c, err)
 		return
 	}
 
@@ -35,16 +188,223 @@ func (h *BorrowHandler) BorrowBook(c *gin.Context) {
 
 func (h *BorrowHandler) ReturnBook(c *gin.Context) {
 	var returnRequest pb.ReturnRequest
-	if err := c.BindJSON(&returnRequest); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request body"})
+	if err := c.ShouldBindJSON(&returnRequest); err != nil {
+		RespondWithBindError(c, err)
+		return
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.ReturnBook(ctx, &returnRequest)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{map[string]interface{}{
+		"id":           response.Id,
+		"book_id":      response.BookId,
+		"days_overdue": response.DaysOverdue,
+		"fine":         response.Fine,
+	}}))
+}
+
+func (h *BorrowHandler) RenewBook(c *gin.Context) {
+	var renewRequest pb.RenewRequest
+	if err := c.ShouldBindJSON(&renewRequest); err != nil {
+		RespondWithBindError(c, err)
+		return
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.RenewBook(ctx, &renewRequest)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{map[string]interface{}{"id": response.Id, "book_id": response.BookId}}))
+}
+
+func (h *BorrowHandler) FindBorrowById(c *gin.Context) {
+	id, ok := c.Params.Get("id")
+	if !ok {
+		log.Println("Id not specified in request params")
+		c.JSON(500, BuildHttpResponse(false, 500, "ID Not Specified", []interface{}{}))
+		return
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BorrowServiceResponse, error) {
+		return h.client.FindBorrowById(ctx, &pb.FindBorrowRequest{Id: id})
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	borrow := struct {
+		*model.Borrow
+		IsOverdue bool `json:"is_overdue"`
+	}{model.FromPbBorrow(response.Borrow), response.IsOverdue}
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{borrow}))
+}
+
+func (h *BorrowHandler) CalculateFine(c *gin.Context) {
+	id, ok := c.Params.Get("id")
+	if !ok {
+		log.Println("Id not specified in request params")
+		c.JSON(500, BuildHttpResponse(false, 500, "ID Not Specified", []interface{}{}))
+		return
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.FineResponse, error) {
+		return h.client.CalculateFine(ctx, &pb.FineRequest{BorrowId: id})
+	})
+	if err != nil {
+		RespondWithError(c, err)
 		return
 	}
 
-	response, err := h.client.ReturnBook(c, &returnRequest)
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{map[string]interface{}{
+		"borrow_id":    response.BorrowId,
+		"days_overdue": response.DaysOverdue,
+		"fine":         response.Fine,
+		"fine_per_day": response.FinePerDay,
+	}}))
+}
+
+func (h *BorrowHandler) DeleteBorrow(c *gin.Context) {
+	id, ok := c.Params.Get("id")
+	if !ok {
+		log.Println("Id not specified in request params")
+		c.JSON(500, BuildHttpResponse(false, 500, "ID Not Specified", []interface{}{}))
+		return
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.DeleteBorrow(ctx, &pb.DeleteBorrowRequest{Id: id})
 	if err != nil {
-		c.JSON(500, BuildHttpResponse(false, 500, ExtractErrorMessage(err), []interface{}{}))
+		RespondWithError(c, err)
 		return
 	}
 
 	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{map[string]interface{}{"id": response.Id, "book_id": response.BookId}}))
 }
+
+func (h *BorrowHandler) GetOverdueBorrows(c *gin.Context) {
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BorrowListResponse, error) {
+		return h.client.GetOverdueBorrows(ctx, &pb.OverdueRequest{
+			Skip:  int32(params.Skip),
+			Limit: int32(params.Limit),
+		})
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	borrows := model.FromPbBorrows(response.Borrow)
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{borrows}))
+}
+
+// Lists active borrows due within the optional days query param, the borrow
+// service's default window applies when it's missing
+func (h *BorrowHandler) GetDueSoon(c *gin.Context) {
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	request := pb.DueSoonRequest{Skip: int32(params.Skip), Limit: int32(params.Limit)}
+	if daysStr := c.Query("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			RespondWithError(c, grpcutil.InvalidArgumentStatus("Invalid days", map[string]string{"days": "days must be a positive number"}))
+			return
+		}
+		request.Days = int32(days)
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BorrowListResponse, error) {
+		return h.client.GetDueSoon(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	borrows := model.FromPbBorrows(response.Borrow)
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{borrows}))
+}
+
+// Counts borrows that haven't been returned, filtered by the optional
+// collection_id and user_id query params
+func (h *BorrowHandler) CountActiveBorrows(c *gin.Context) {
+	request := pb.CountBorrowRequest{
+		CollectionId: c.Query("collection_id"),
+		UserId:       c.Query("user_id"),
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BorrowCountResponse, error) {
+		return h.client.CountActiveBorrows(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{map[string]interface{}{"count": response.Count}}))
+}
+
+func (h *BorrowHandler) GetBorrowsByUser(c *gin.Context) {
+	id, ok := c.Params.Get("id")
+	if !ok {
+		log.Println("Id not specified in request params")
+		c.JSON(500, BuildHttpResponse(false, 500, "ID Not Specified", []interface{}{}))
+		return
+	}
+
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	activeOnly, _ := strconv.ParseBool(c.Query("active_only"))
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.BorrowListResponse, error) {
+		return h.client.GetBorrowsByUser(ctx, &pb.UserBorrowsRequest{
+			UserId:     id,
+			ActiveOnly: activeOnly,
+			Skip:       int32(params.Skip),
+			Limit:      int32(params.Limit),
+		})
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	borrows := model.FromPbBorrows(response.Borrow)
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{borrows}))
+}
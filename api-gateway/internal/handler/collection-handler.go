@@ -2,34 +2,68 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"shared/pkg/model"
 	pb "shared/proto/buffer"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // CollectionHandler with batching support
 type CollectionHandler struct {
-	client  pb.CollectionServiceClient
-	batcher ReqBatcherInterface[pb.CollectionServiceClient, pb.Response]
+	client       pb.CollectionServiceClient
+	batcher      ReqBatcherInterface[pb.CollectionServiceClient, pb.Response]
+	timeout      time.Duration
+	retry        RetryPolicy
+	maxPageLimit int
 }
 
 func NewCollectionHandler(conn *grpc.ClientConn) *CollectionHandler {
+	return NewCollectionHandlerWithClient(pb.NewCollectionServiceClient(conn))
+}
+
+func NewCollectionHandlerWithClient(client pb.CollectionServiceClient) *CollectionHandler {
 	return &CollectionHandler{
-		client: pb.NewCollectionServiceClient(conn),
+		client:       client,
+		timeout:      DefaultRequestTimeout,
+		retry:        DefaultRetryPolicy(),
+		maxPageLimit: DefaultMaxPageLimit,
 	}
 }
 
 func NewCollectionHandlerWithBatching(conn *grpc.ClientConn, batchWindow time.Duration) *CollectionHandler {
-	client := pb.NewCollectionServiceClient(conn)
-	return &CollectionHandler{
-		client:  client,
-		batcher: NewGrpcBatcher(client, batchWindow),
-	}
+	return NewCollectionHandler(conn).WithBatchWindow(batchWindow)
+}
+
+// Sets how long each backend call may take before the request fails with 504
+func (h *CollectionHandler) WithTimeout(timeout time.Duration) *CollectionHandler {
+	h.timeout = timeout
+	return h
+}
+
+// Sets how reads are retried when the collection service fails transiently
+func (h *CollectionHandler) WithRetry(policy RetryPolicy) *CollectionHandler {
+	h.retry = policy
+	return h
+}
+
+// Sets the largest page a list request may ask for, larger limits are rejected
+func (h *CollectionHandler) WithMaxPageLimit(limit int) *CollectionHandler {
+	h.maxPageLimit = limit
+	return h
+}
+
+// Batches list requests that arrive within batchWindow of each other
+func (h *CollectionHandler) WithBatchWindow(batchWindow time.Duration) *CollectionHandler {
+	h.batcher = NewGrpcBatcher(h.client, batchWindow)
+	return h
 }
 
 // GrpcBatcher handles batching for gRPC calls
@@ -48,56 +82,29 @@ func NewGrpcBatcher(client pb.CollectionServiceClient, batchWindow time.Duration
 }
 
 func (b *CollectionReqBatcher) GetBatch(ctx context.Context, params QueryParams) (*pb.Response, error) {
-	req := &BatchRequest[pb.Response]{
-		ctx:    ctx,
-		params: params,
-		resp:   make(chan *pb.Response, 1),
-		err:    make(chan error, 1),
-	}
-
-	b.baseBatcher.mu.Lock()
-	b.baseBatcher.pending = append(b.baseBatcher.pending, req)
-	if b.baseBatcher.timer == nil {
-		b.baseBatcher.timer = time.AfterFunc(b.baseBatcher.batchWindow, b.flush)
-	}
-	b.baseBatcher.mu.Unlock()
-
-	select {
-	case r := <-req.resp:
-		return r, nil
-	case e := <-req.err:
-		return nil, e
-	}
+	return b.baseBatcher.enqueue(ctx, params, b.flush)
 }
 
 func (b *CollectionReqBatcher) flush() {
-	b.baseBatcher.mu.Lock()
-	pending := b.baseBatcher.pending
-	b.baseBatcher.pending = nil
-	b.baseBatcher.timer = nil
-	b.baseBatcher.mu.Unlock()
-
-	var params QueryParams
-	if len(pending) > 0 {
-		params = pending[0].params
-	}
-	filter, sort := BuildFilterAndSort(params)
-	request := pb.GetCollectionRequest{
-		Filter: filter,
-		Sort:   sort,
-		Skip:   int32(params.Skip),
-		Limit:  int32(params.Limit),
-	}
-
-	// Make a single backend call for all pending requests
-	resp, err := b.baseBatcher.client.GetCollection(context.Background(), &request)
-	for _, req := range pending {
+	b.baseBatcher.dispatch(func(ctx context.Context, params QueryParams) (*pb.Response, error) {
+		filter, sort, err := BuildFilterAndSort(params)
 		if err != nil {
-			req.err <- err
-		} else {
-			req.resp <- resp
+			return nil, err
 		}
-	}
+		request := pb.GetCollectionRequest{
+			Filter:         filter,
+			Sort:           sort,
+			Skip:           int32(params.Skip),
+			Limit:          int32(params.Limit),
+			After:          params.After,
+			UseCursor:      params.UseCursor,
+			Fields:         params.Fields,
+			IncludeDeleted: params.IncludeDeleted,
+		}
+
+		// Make a single backend call for every request in the group
+		return b.baseBatcher.client.GetCollection(ctx, &request)
+	})
 }
 
 // BatchingMiddleware returns middleware function for this handler
@@ -110,45 +117,196 @@ func (h *CollectionHandler) BatchingMiddleware() gin.HandlerFunc {
 	}
 }
 
+// Narrows a collection list to the categories in ?filter[categories]=a or
+// ?category=a&category=b. The values are kept as strings, ParseQueryParams
+// would turn a category like "1984" into a number. One category matches
+// collections whose categories contain it, several match any of them.
+func applyCategoryFilter(c *gin.Context, params QueryParams) {
+	categories := []interface{}{}
+	for _, key := range []string{"filter[categories]", "category"} {
+		for _, category := range c.QueryArray(key) {
+			if category = strings.TrimSpace(category); category != "" {
+				categories = append(categories, category)
+			}
+		}
+	}
+
+	switch len(categories) {
+	case 0:
+		return
+	case 1:
+		params.Filter["categories"] = categories[0]
+	default:
+		params.Filter["categories"] = map[string]interface{}{"$in": categories}
+	}
+}
+
 // GetCollection gets all collections with pagination and caching
 func (h *CollectionHandler) GetCollection(c *gin.Context) {
-	params := ParseQueryParams(c)
-	filter, sort := BuildFilterAndSort(params)
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	applyCategoryFilter(c, params)
+	filter, sort, err := BuildFilterAndSort(params)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
 	request := pb.GetCollectionRequest{
-		Filter: filter,
-		Sort:   sort,
-		Skip:   int32(params.Skip),
-		Limit:  int32(params.Limit),
+		Filter:         filter,
+		Sort:           sort,
+		Skip:           int32(params.Skip),
+		Limit:          int32(params.Limit),
+		After:          params.After,
+		UseCursor:      params.UseCursor,
+		Fields:         params.Fields,
+		IncludeDeleted: params.IncludeDeleted,
 	}
 
-	response, err := h.client.GetCollection(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.Response, error) {
+		return h.client.GetCollection(ctx, &request)
+	})
 	if err != nil {
-		message := ExtractErrorMessage(err)
-		c.JSON(500, BuildHttpResponse(false, 500, message, []interface{}{}))
+		RespondWithError(c, err)
 		return
 	}
 
 	collections := model.FromPbCollections(response.Collection)
-	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{collections}))
+	httpResponse := BuildHttpResponse(true, 200, response.Message, []interface{}{collections})
+	httpResponse.NextCursor = response.NextCursor
+	httpResponse.Meta = BuildMeta(params, response.Total)
+	c.JSON(200, httpResponse)
 }
 
 func (h *CollectionHandler) GetCollectionBatch(c *gin.Context) {
-	params := ParseQueryParams(c)
+	if _, ok := c.GetQuery("ids"); ok {
+		h.GetCollectionsByIds(c)
+		return
+	}
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	applyCategoryFilter(c, params)
 
 	if h.batcher != nil {
 		// Use batcher for multiple requests
-		response, err := h.batcher.GetBatch(c.Request.Context(), params)
+		ctx, cancel := callContext(c, h.timeout)
+		defer cancel()
+		response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.Response, error) {
+			return h.batcher.GetBatch(ctx, params)
+		})
 		if err != nil {
-			message := ExtractErrorMessage(err)
-			c.JSON(500, BuildHttpResponse(false, 500, message, []interface{}{}))
+			RespondWithError(c, err)
 			return
 		}
-		c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbCollections(response.Collection)}))
+		httpResponse := BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbCollections(response.Collection)})
+		httpResponse.NextCursor = response.NextCursor
+		httpResponse.Meta = BuildMeta(params, response.Total)
+		c.JSON(200, httpResponse)
 	} else {
 		h.GetCollection(c)
 	}
 }
 
+// Fetches every collection in ?ids=a,b,c with one backend call. Invalid ids
+// are skipped and reported in the message rather than failing the request.
+func (h *CollectionHandler) GetCollectionsByIds(c *gin.Context) {
+	ids := []string{}
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(400, BuildHttpResponse(false, 400, "At least one id is required", []interface{}{}))
+		return
+	}
+
+	request := pb.FindCollectionsByIdsRequest{Ids: ids}
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.Response, error) {
+		return h.client.FindCollectionsByIds(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	message := response.Message
+	if response.Skipped > 0 {
+		message = fmt.Sprintf("%s, skipped %d invalid ids", message, response.Skipped)
+	}
+	c.JSON(200, BuildHttpResponse(true, 200, message, []interface{}{model.FromPbCollections(response.Collection)}))
+}
+
+func (h *CollectionHandler) SearchCollections(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(400, BuildHttpResponse(false, 400, "Search query is required", []interface{}{}))
+		return
+	}
+
+	// ?regex=true matches the query as a pattern, which a crafted pattern can
+	// make slow, so only requests through the admin routes may ask for it
+	regex, _ := strconv.ParseBool(c.Query("regex"))
+	if regex && !c.GetBool(AdminKey) {
+		c.JSON(403, BuildHttpResponse(false, 403, "Regex search requires the admin token", []interface{}{}))
+		return
+	}
+
+	params, err := ParseQueryParams(c, h.maxPageLimit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	request := pb.SearchRequest{
+		Query: query,
+		Skip:  int32(params.Skip),
+		Limit: int32(params.Limit),
+		Regex: regex,
+	}
+
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.Response, error) {
+		return h.client.SearchCollections(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	httpResponse := BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbCollections(response.Collection)})
+	httpResponse.Meta = BuildMeta(params, response.Total)
+	c.JSON(200, httpResponse)
+}
+
+// Lists every category used by a collection, sorted
+func (h *CollectionHandler) GetCategories(c *gin.Context) {
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.CategoriesResponse, error) {
+		return h.client.GetCategories(ctx, &emptypb.Empty{})
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	categories := response.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	c.JSON(200, BuildHttpResponse(true, 200, "Categories retrieved successfully", []interface{}{categories}))
+}
+
 func (h *CollectionHandler) GetCollectionById(c *gin.Context) {
 	id, ok := c.Params.Get("id")
 
@@ -158,29 +316,68 @@ func (h *CollectionHandler) GetCollectionById(c *gin.Context) {
 		return
 	}
 	request := pb.FindCollectionRequest{Id: id}
-	response, err := h.client.FindCollectionById(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.Response, error) {
+		return h.client.FindCollectionById(ctx, &request)
+	})
 
 	if err != nil {
-		message := ExtractErrorMessage(err)
-		c.JSON(500, BuildHttpResponse(false, 500, message, []interface{}{}))
+		RespondWithError(c, err)
 		return
 	}
+	if IsNotFoundResponse(response.Success, response.Message) {
+		c.JSON(404, BuildHttpResponse(false, 404, response.Message, []interface{}{}))
+		return
+	}
+	if len(response.Collection) == 1 {
+		setETag(c, response.Collection[0].Etag)
+	}
 	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{response.Collection}))
 }
 
+// Answers a collection with its stock counted from the books themselves, for
+// screens that must not show a drifted counter
+func (h *CollectionHandler) GetCollectionWithCounts(c *gin.Context) {
+	request := pb.FindCollectionRequest{Id: c.Param("id")}
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := withRetry(ctx, h.retry, func(ctx context.Context) (*pb.Response, error) {
+		return h.client.GetCollectionWithCounts(ctx, &request)
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{model.FromPbCollections(response.Collection)}))
+}
+
 func (h *CollectionHandler) CreateCollection(c *gin.Context) {
-	var collection pb.Collection
-	if err := c.BindJSON(&collection); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request body"})
+	var collection model.Collection
+	if err := c.ShouldBindJSON(&collection); err != nil {
+		RespondWithBindError(c, err)
+		return
+	}
+	if !validateCreateBody(c, collectionValidator.ValidateExcept(collection, serverAssignedFields...)) {
 		return
 	}
 
-	request := pb.AddCollectionRequest{Collection: &collection}
-	response, err := h.client.AddCollection(c, &request)
+	request := pb.AddCollectionRequest{Collection: model.ToPbCollection(&collection)}
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.AddCollection(ctx, &request)
 
 	if err != nil {
-		message := ExtractErrorMessage(err)
-		c.JSON(500, BuildHttpResponse(false, 500, message, []interface{}{}))
+		RespondWithError(c, err)
+		return
+	}
+	if !response.Success {
+		code := 500
+		if IsConflictResponse(response.Success, response.Message) {
+			code = 409
+		}
+		c.JSON(code, BuildHttpResponse(false, code, response.Message, []interface{}{}))
 		return
 	}
 	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{response.Collection}))
@@ -194,8 +391,8 @@ func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
 		return
 	}
 	var collection map[string]interface{}
-	if err := c.BindJSON(&collection); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request body"})
+	if err := c.ShouldBindJSON(&collection); err != nil {
+		RespondWithBindError(c, err)
 		return
 	}
 
@@ -209,13 +406,22 @@ func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
 	request := pb.UpdateCollectionRequest{
 		Payload: structPayload,
 		Id:      id,
+		IfMatch: ifMatch(c),
 	}
-	response, err := h.client.UpdateCollection(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.UpdateCollection(ctx, &request)
 	if err != nil {
-		message := ExtractErrorMessage(err)
-		c.JSON(500, BuildHttpResponse(false, 500, message, []interface{}{}))
+		RespondWithError(c, err)
+		return
+	}
+	if IsNotFoundResponse(response.Success, response.Message) {
+		c.JSON(404, BuildHttpResponse(false, 404, response.Message, []interface{}{}))
 		return
 	}
+	if len(response.Collection) == 1 {
+		setETag(c, response.Collection[0].Etag)
+	}
 	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{response.Collection}))
 }
 
@@ -232,11 +438,16 @@ func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
 		return
 	}
 	request := pb.DeleteCollectionRequest{Id: id}
-	response, err := h.client.DeleteCollection(c, &request)
+	ctx, cancel := callContext(c, h.timeout)
+	defer cancel()
+	response, err := h.client.DeleteCollection(ctx, &request)
 
 	if err != nil {
-		message := ExtractErrorMessage(err)
-		c.JSON(500, BuildHttpResponse(false, 500, message, []interface{}{}))
+		RespondWithError(c, err)
+		return
+	}
+	if IsNotFoundResponse(response.Success, response.Message) {
+		c.JSON(404, BuildHttpResponse(false, 404, response.Message, []interface{}{}))
 		return
 	}
 	c.JSON(200, BuildHttpResponse(true, 200, response.Message, []interface{}{response.Collection}))
@@ -0,0 +1,21 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+// Sets the ETag header clients send back in If-Match to update only the
+// version they read, left out when the backend didn't provide one
+func setETag(c *gin.Context, etag string) {
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+}
+
+// The If-Match header of the request, "*" matches any version so it's
+// treated like no condition at all
+func ifMatch(c *gin.Context) string {
+	etag := c.GetHeader("If-Match")
+	if etag == "*" {
+		return ""
+	}
+	return etag
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"shared/pkg/grpcutil"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+// How long a readiness result is reused before the backends are asked again
+const DefaultReadyCacheTTL = 2 * time.Second
+
+// HealthHandler reports the health of every backend service
+type HealthHandler struct {
+	connections map[string]*grpc.ClientConn
+	clients     map[string]healthpb.HealthClient
+
+	readyTTL      time.Duration
+	readyMu       sync.Mutex
+	readyAt       time.Time
+	readyOk       bool
+	readyServices map[string]string
+}
+
+func NewHealthHandler(connections map[string]*grpc.ClientConn) *HealthHandler {
+	clients := make(map[string]healthpb.HealthClient)
+	for service, conn := range connections {
+		clients[service] = healthpb.NewHealthClient(conn)
+	}
+
+	return &HealthHandler{connections: connections, clients: clients, readyTTL: DefaultReadyCacheTTL}
+}
+
+// Sets how long Readyz reuses its last result, zero checks the backends on
+// every request
+func (h *HealthHandler) WithReadyCacheTTL(ttl time.Duration) *HealthHandler {
+	h.readyTTL = ttl
+	return h
+}
+
+// Reports the gateway as alive along with whether every backend connection is
+// Ready. It never calls the backends, DeepHealth does.
+func (h *HealthHandler) Health(c *gin.Context) {
+	states, ready := grpcutil.ConnectionStates(h.connections)
+	c.JSON(http.StatusOK, gin.H{"status": "healthy", "backends_ready": ready, "backends": states})
+}
+
+// Liveness probe, answers as long as the process can serve requests and never
+// depends on the backends so a backend outage doesn't get the gateway restarted
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// Readiness probe, the gateway is ready when every backend reports SERVING.
+// The result is cached for the ready TTL so frequent probes don't turn into a
+// health RPC per backend each time.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	h.readyMu.Lock()
+	if h.readyServices == nil || time.Since(h.readyAt) >= h.readyTTL {
+		h.readyServices, h.readyOk = h.checkBackends(c)
+		h.readyAt = time.Now()
+	}
+	services, ready := h.readyServices, h.readyOk
+	h.readyMu.Unlock()
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "services": services})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "services": services})
+}
+
+// Checks every backend concurrently, the gateway is only healthy when all of
+// them report SERVING
+func (h *HealthHandler) DeepHealth(c *gin.Context) {
+	services, healthy := h.checkBackends(c)
+	if !healthy {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unhealthy", "services": services})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "healthy", "services": services})
+}
+
+// Calls the health RPC of every backend within healthCheckTimeout and returns
+// the status of each along with whether all of them are SERVING
+func (h *HealthHandler) checkBackends(ctx context.Context) (map[string]string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		services = make(map[string]string)
+		healthy  = true
+	)
+
+	for service, client := range h.clients {
+		wg.Add(1)
+		go func(service string, client healthpb.HealthClient) {
+			defer wg.Done()
+
+			var state string
+			resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+			if err != nil {
+				state = ExtractErrorMessage(err)
+			} else {
+				state = resp.GetStatus().String()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			services[service] = state
+			if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+				healthy = false
+			}
+		}(service, client)
+	}
+	wg.Wait()
+
+	return services, healthy
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = 50 * time.Millisecond
+)
+
+// How idempotent reads are retried when a backend fails transiently
+type RetryPolicy struct {
+	Attempts  int           // Total tries including the first, 1 or less disables retries
+	BaseDelay time.Duration // Wait before the first retry, doubled on each one after
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:  DefaultRetryAttempts,
+		BaseDelay: DefaultRetryBaseDelay,
+	}
+}
+
+// Errors worth another try, the backend may be restarting or briefly overloaded
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// Runs call until it succeeds, fails with a non retryable error or runs out of
+// attempts. Retries wait an exponential backoff with jitter and stop early when
+// the wait would outlive ctx, so the request deadline is never extended.
+// Only use it for calls that are safe to repeat.
+func withRetry[V any](ctx context.Context, policy RetryPolicy, call func(ctx context.Context) (*V, error)) (*V, error) {
+	delay := policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		response, err := call(ctx)
+		if err == nil || attempt >= policy.Attempts || !isRetryable(err) || ctx.Err() != nil {
+			return response, err
+		}
+
+		// Jitter keeps replicas from retrying in lockstep
+		wait := delay
+		if delay > 0 {
+			wait = time.Duration(rand.Int63n(int64(delay))) + delay/2
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return response, err
+		}
+
+		log.Printf("Retrying after %v (attempt %d of %d): %v", wait, attempt+1, policy.Attempts, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return response, err
+		}
+		delay *= 2
+	}
+}
@@ -1,59 +1,141 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"shared/pkg/grpcutil"
 	"shared/pkg/model"
 	pb "shared/proto/buffer"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// How long a single backend call may take when no timeout is configured
+const DefaultRequestTimeout = 5 * time.Second
+
+// Context key the admin auth middleware sets once the admin token checked out
+const AdminKey = "admin"
+
+const (
+	DefaultPageLimit    = 10
+	DefaultMaxPageLimit = 100
+)
+
 type QueryParams struct {
-	Filter bson.M
-	Sort   *bson.D
-	Skip   int
-	Limit  int
+	Filter         bson.M
+	Sort           *bson.D
+	Skip           int
+	Limit          int
+	After          string
+	UseCursor      bool
+	Fields         []string
+	IncludeDeleted bool
 }
 
-// Extracts and validates query parameters from the request
-func ParseQueryParams(c *gin.Context) QueryParams {
+// Extracts and validates query parameters from the request. Pagination values
+// that are out of range are rejected with InvalidArgument instead of replaced,
+// a maxLimit of 0 or less leaves the page size unbounded.
+func ParseQueryParams(c *gin.Context, maxLimit int) (QueryParams, error) {
 	params := QueryParams{
 		Filter: bson.M{},
 		Skip:   0,
-		Limit:  10,
+		Limit:  DefaultPageLimit,
+	}
+	violations := map[string]string{}
+
+	// Parse pagination, the limit first since pages are measured in it
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		switch {
+		case err != nil || limit <= 0:
+			violations["limit"] = "limit must be a positive number"
+		case maxLimit > 0 && limit > maxLimit:
+			violations["limit"] = fmt.Sprintf("limit must not exceed %d", maxLimit)
+		default:
+			params.Limit = limit
+		}
 	}
 
-	// Parse pagination
 	if pageStr := c.Query("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+		if page, err := strconv.Atoi(pageStr); err != nil || page <= 0 {
+			violations["page"] = "page must be a positive number"
+		} else {
 			params.Skip = (page - 1) * params.Limit
 		}
 	}
 
 	if skipStr := c.Query("skip"); skipStr != "" {
-		if skip, err := strconv.Atoi(skipStr); err == nil && skip >= 0 {
+		if skip, err := strconv.Atoi(skipStr); err != nil || skip < 0 {
+			violations["skip"] = "skip must not be negative"
+		} else {
 			params.Skip = skip
 		}
 	}
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
-			params.Limit = limit
+	if len(violations) > 0 {
+		return params, grpcutil.InvalidArgumentStatus("Invalid pagination parameters", violations)
+	}
+
+	// Parse cursor - ?after=<cursor> switches to cursor pagination, an empty value requests the first page
+	if after, ok := c.GetQuery("after"); ok {
+		params.After = after
+		params.UseCursor = true
+	}
+
+	// Parse soft-delete escape hatch - ?include_deleted=true also returns deleted documents,
+	// honored only on requests authenticated by AdminAuthMiddleware
+	if includeDeleted, err := strconv.ParseBool(c.Query("include_deleted")); err == nil && c.GetBool(AdminKey) {
+		params.IncludeDeleted = includeDeleted
+	}
+
+	// Parse field selection - expecting format: ?fields=name,author
+	if fieldsStr := c.Query("fields"); fieldsStr != "" {
+		for _, field := range strings.Split(fieldsStr, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				params.Fields = append(params.Fields, field)
+			}
 		}
 	}
 
-	// Parse filters - expecting format: ?filter[field]=value&filter[status]=active
+	// Parse filters - expecting format: ?filter[field]=value&filter[status]=active,
+	// or ?filter[field][gte]=5 for comparisons
 	for key, values := range c.Request.URL.Query() {
 		if strings.HasPrefix(key, "filter[") && strings.HasSuffix(key, "]") {
+			if len(values) == 0 || values[0] == "" {
+				continue
+			}
+
 			fieldName := strings.TrimSuffix(strings.TrimPrefix(key, "filter["), "]")
-			if len(values) > 0 && values[0] != "" {
-				params.Filter[fieldName] = values[0]
+			value := parseFilterValue(values[0])
+
+			fieldName, op, hasOp := strings.Cut(fieldName, "][")
+			if !hasOp {
+				params.Filter[fieldName] = value
+				continue
 			}
+
+			mongoOp, ok := filterOperators[op]
+			if !ok {
+				log.Printf("Ignoring unsupported filter operator %q on %s", op, fieldName)
+				continue
+			}
+
+			// Several operators on one field combine, e.g. a gte/lte range
+			ops, ok := params.Filter[fieldName].(map[string]interface{})
+			if !ok {
+				ops = map[string]interface{}{}
+				params.Filter[fieldName] = ops
+			}
+			ops[mongoOp] = value
 		}
 	}
 
@@ -78,23 +160,58 @@ func ParseQueryParams(c *gin.Context) QueryParams {
 		}
 	}
 
-	return params
+	return params, nil
 }
 
-func BuildFilterAndSort(params QueryParams) (*structpb.Struct, []*pb.Sort) {
+// Comparison operators accepted in ?filter[field][op]=value
+var filterOperators = map[string]string{
+	"eq":  "$eq",
+	"ne":  "$ne",
+	"gt":  "$gt",
+	"gte": "$gte",
+	"lt":  "$lt",
+	"lte": "$lte",
+}
+
+// Turns a filter value that looks like a boolean, integer or decimal into
+// that type so it matches what is stored in Mongo, anything else stays a string
+func parseFilterValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+	// Only plain decimals, so ids or names like "1e5" and "Inf" stay strings
+	if strings.Contains(value, ".") {
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !strings.ContainsAny(value, "eEnN") {
+			return f
+		}
+	}
+
+	return value
+}
+
+// Converts query params into the filter and sort of a list request. Filter
+// values protobuf can't represent are reported as an InvalidArgument error.
+func BuildFilterAndSort(params QueryParams) (*structpb.Struct, []*pb.Sort, error) {
 	filter, err := structpb.NewStruct(params.Filter)
 	if err != nil {
 		log.Printf("Error parsing filter params: %v", err)
-		return nil, nil
+		return nil, nil, status.Errorf(codes.InvalidArgument, "Invalid filter: %v", err)
 	}
 	
-	var sorts []*pb.Sort
+	sorts := []*pb.Sort{}
 	if params.Sort != nil {
 		for _, sort := range *params.Sort {
 			direction, ok := sort.Value.(int)
 			if !ok {
 				log.Printf("Can't convert element to int: %v", direction)
-				return filter, nil
+				return filter, []*pb.Sort{}, nil
 			}
 
 			sorts = append(sorts, &pb.Sort{
@@ -104,7 +221,7 @@ func BuildFilterAndSort(params QueryParams) (*structpb.Struct, []*pb.Sort) {
 		}
 	}
 
-	return filter, sorts
+	return filter, sorts, nil
 }
 
 func BuildHttpResponse(success bool, code int, message string, data []interface{}) model.HttpResponse {
@@ -116,12 +233,66 @@ func BuildHttpResponse(success bool, code int, message string, data []interface{
 	}
 }
 
+// Builds offset pagination metadata, cursor pages have no page number so they get none
+func BuildMeta(params QueryParams, total int64) *model.Meta {
+	if params.UseCursor || params.Limit <= 0 {
+		return nil
+	}
+
+	return &model.Meta{
+		Total:      total,
+		Page:       params.Skip/params.Limit + 1,
+		Limit:      params.Limit,
+		TotalPages: int((total + int64(params.Limit) - 1) / int64(params.Limit)),
+		HasNext:    int64(params.Skip+params.Limit) < total,
+	}
+}
+
+// The client-safe message for a failed backend call, see grpcutil.HTTPError
 func ExtractErrorMessage(err error) string {
-	st, ok := status.FromError(err)
+	_, message := grpcutil.HTTPError(err)
+	return message
+}
+
+// Bounds a backend call made for the request so a hung service can't hang
+// the HTTP request with it
+func callContext(c *gin.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return context.WithTimeout(c.Request.Context(), timeout)
+}
+
+func IsDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
+
+// Maps the gRPC status of a failed backend call to the HTTP status returned to clients
+func HttpStatusFromGrpc(err error) int {
+	code, _ := grpcutil.HTTPError(err)
+	return code
+}
+
+// Services report a missing resource as an unsuccessful response rather than an error
+func IsNotFoundResponse(success bool, message string) bool {
+	return !success && strings.Contains(strings.ToLower(message), "not found")
+}
+
+// Services report a duplicate the same way, as an unsuccessful response
+func IsConflictResponse(success bool, message string) bool {
+	return !success && strings.Contains(strings.ToLower(message), "already exists")
+}
+
+// Writes the response for a failed backend call using the HTTP status matching
+// its gRPC code, calls that ran out of time are reported as a gateway timeout
+// and unreachable backends as unavailable
+func RespondWithError(c *gin.Context, err error) {
+	code, message := grpcutil.HTTPError(err)
 
-	if !ok {
-		return "Internal Server Error"
+	data := []interface{}{}
+	if fields := grpcutil.FieldViolations(err); fields != nil {
+		data = append(data, fields)
 	}
 
-	return st.Message()
+	c.JSON(code, BuildHttpResponse(false, code, message, data))
 }
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"shared/pkg/model"
+	"shared/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	collectionValidator = service.NewValidationService[model.Collection, model.CollectionUpdateRequest]()
+	bookValidator       = service.NewValidationService[model.Book, model.BookUpdateRequest]()
+)
+
+// Fields the services set themselves on create, so request bodies never carry them
+var serverAssignedFields = []string{"Id", "CreatedAt", "UpdatedAt", "DeletedAt"}
+
+// Responds with a 400 listing every failing field when the create request body
+// is invalid. Returns false when a response was written and the handler should stop.
+func validateCreateBody(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	details := service.ValidationDetails(err)
+	if details == nil {
+		log.Printf("Error validating request body: %v", err)
+		c.JSON(400, BuildHttpResponse(false, 400, "Invalid request body", []interface{}{}))
+		return false
+	}
+
+	c.JSON(400, BuildHttpResponse(false, 400, "Invalid request body", []interface{}{details}))
+	return false
+}
+
+// Responds to a request body that couldn't be bound, with 413 when it was cut
+// off by the body limit and 400 otherwise
+func RespondWithBindError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondBodyTooLarge(c)
+		return
+	}
+	c.JSON(400, gin.H{"error": "Invalid request body"})
+}
+
+func respondBodyTooLarge(c *gin.Context) {
+	c.JSON(http.StatusRequestEntityTooLarge, BuildHttpResponse(
+		false, http.StatusRequestEntityTooLarge, "Request body too large", []interface{}{},
+	))
+}
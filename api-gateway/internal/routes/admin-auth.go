@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"apigateway/internal/handler"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Guards the admin endpoints with a shared bearer token. Without a configured
+// token the endpoints are switched off rather than left open.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, handler.BuildHttpResponse(
+				false, http.StatusForbidden, "Admin endpoints are disabled", []interface{}{},
+			))
+			return
+		}
+
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handler.BuildHttpResponse(
+				false, http.StatusUnauthorized, "Invalid admin token", []interface{}{},
+			))
+			return
+		}
+
+		c.Set(handler.AdminKey, true)
+		c.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context key holding the request body as it arrived, before any limit
+const rawBodyKey = "raw_body"
+
+// Caps the request body at limit bytes so an oversized payload is refused
+// before it is read into memory. Reading past the limit fails and the
+// handler answers 413, see handler.RespondWithBindError. Applying it again on
+// a route replaces the earlier limit, so a global default can be raised or
+// lowered per route.
+func BodyLimitMiddleware(limit int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := c.Request.Body
+		if raw, ok := c.Get(rawBodyKey); ok {
+			body = raw.(io.ReadCloser)
+		} else {
+			c.Set(rawBodyKey, body)
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, body, int64(limit))
+
+		c.Next()
+	}
+}
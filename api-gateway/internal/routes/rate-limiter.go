@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// Trims entries older than the window, then records the request if the
+// client is still under the limit. Returns {allowed, oldest entry score,
+// requests in the window}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local allowed = 0
+if redis.call('ZCARD', key) < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	allowed = 1
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local count = redis.call('ZCARD', key)
+if oldest[2] == nil then
+	return {allowed, now, count}
+end
+return {allowed, tonumber(oldest[2]), count}
+`)
+
+// Picks the Redis-backed limiter when a client is configured, so limits are
+// shared across gateway replicas, and the in-memory one otherwise
+func NewRateLimitingMiddleware(config *BatchingConfig) gin.HandlerFunc {
+	if config.RateLimitRedis != nil {
+		return RedisRateLimitingMiddleware(config.RateLimitRedis, config.RateLimit, config.RateLimitWindow)
+	}
+	return RateLimitingMiddleware(config.RateLimit, config.RateLimitWindow)
+}
+
+// Sliding window rate limiting backed by a Redis sorted set per client
+func RedisRateLimitingMiddleware(client *redis.Client, maxRequests int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now().UnixMilli()
+		key := "rate_limit:" + rateLimitIdentity(c)
+		member := fmt.Sprintf("%d-%d", now, rand.Int64())
+
+		result, err := slidingWindowScript.Run(
+			c.Request.Context(),
+			client,
+			[]string{key},
+			now, window.Milliseconds(), maxRequests, member,
+		).Int64Slice()
+		if err != nil {
+			// Fail open, a Redis outage shouldn't take the gateway down
+			log.Printf("Error checking rate limit: %v", err)
+			c.Next()
+			return
+		}
+
+		oldest := time.UnixMilli(result[1])
+		respondRateLimited(c, maxRequests, int(result[2]), oldest.Add(window), result[0] == 1)
+	}
+}
+
+// Sliding window rate limiting kept in process memory, each client's recent
+// request times are kept so its remaining budget is exact at any moment
+func RateLimitingMiddleware(maxRequests int, window time.Duration) gin.HandlerFunc {
+	var (
+		hits      = make(map[string][]time.Time)
+		lastSweep = time.Now()
+		mu        sync.Mutex
+	)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		key := rateLimitIdentity(c)
+
+		mu.Lock()
+		// Forget clients that went quiet so the map doesn't grow without bound
+		if now.Sub(lastSweep) > window {
+			for client, times := range hits {
+				if now.Sub(times[len(times)-1]) > window {
+					delete(hits, client)
+				}
+			}
+			lastSweep = now
+		}
+
+		recent := hits[key]
+		for len(recent) > 0 && now.Sub(recent[0]) > window {
+			recent = recent[1:]
+		}
+		allowed := len(recent) < maxRequests
+		if allowed {
+			recent = append(recent, now)
+		}
+
+		reset := now.Add(window)
+		if len(recent) > 0 {
+			hits[key] = recent
+			reset = recent[0].Add(window)
+		} else {
+			delete(hits, key)
+		}
+		count := len(recent)
+		mu.Unlock()
+
+		respondRateLimited(c, maxRequests, count, reset, allowed)
+	}
+}
+
+// Reports the client's budget on every response and rejects the request when
+// it is used up. count is the number of requests in the current window and
+// reset is when the oldest of them leaves it.
+func respondRateLimited(c *gin.Context, limit, count int, reset time.Time, allowed bool) {
+	untilReset := time.Until(reset).Milliseconds()
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(max(0, limit-count)))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix()+int64(retryAfterSeconds(untilReset)), 10))
+
+	if !allowed {
+		retryAfter := retryAfterSeconds(untilReset)
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(429, gin.H{
+			"error":       "Rate limit exceeded",
+			"retry_after": retryAfter,
+		})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// Limits authenticated users by id and everyone else by IP
+func rateLimitIdentity(c *gin.Context) string {
+	if userId := c.GetString("user_id"); userId != "" {
+		return "user:" + userId
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func retryAfterSeconds(millis int64) int {
+	return max(1, int(math.Ceil(float64(millis)/1000)))
+}
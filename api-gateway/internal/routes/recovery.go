@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"apigateway/internal/handler"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Turns a panic in a later handler into a JSON 500 instead of gin's plain
+// text one, logging the stack with the request id so the failing request can
+// be found. Registered first so it also covers the other middleware.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			requestLogger.Error("panic recovered",
+				slog.String("request_id", c.GetString("request_id")),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("error", fmt.Sprint(recovered)),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			// Part of the response may already be out, nothing sensible to add
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, handler.BuildHttpResponse(
+				false, http.StatusInternalServerError, "Internal server error", []interface{}{},
+			))
+		}()
+
+		c.Next()
+	}
+}
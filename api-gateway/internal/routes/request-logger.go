@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"shared/pkg/grpcutil"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	RequestIdHeader   = "X-Request-ID"
+	RequestIdMetadata = grpcutil.RequestIdMetadata
+)
+
+var requestLogger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+// Tags every request with an id and logs it as JSON once it completes. The id
+// is echoed in the response header and sent to downstream gRPC calls made with
+// the request context.
+func RequestLoggingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestId := c.GetHeader(RequestIdHeader)
+		if requestId == "" {
+			requestId = newRequestId()
+		}
+		c.Set("request_id", requestId)
+		c.Header(RequestIdHeader, requestId)
+		c.Request = c.Request.WithContext(
+			metadata.AppendToOutgoingContext(c.Request.Context(), RequestIdMetadata, requestId),
+		)
+
+		c.Next()
+
+		requestLogger.Info("request",
+			slog.String("request_id", requestId),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("status", c.Writer.Status()),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.Int("bytes", c.Writer.Size()),
+		)
+	}
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -2,10 +2,12 @@ package routes
 
 import (
 	"apigateway/internal/handler"
-	"sync"
+	"shared/config"
+	"shared/pkg/metrics"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 )
 
@@ -13,16 +15,33 @@ type BatchingConfig struct {
 	CollectionBatchWindow time.Duration
 	BookBatchWindow       time.Duration
 	BorrowBatchWindow     time.Duration
+	RequestTimeout        time.Duration // Deadline for each backend call
+	RetryAttempts         int           // Tries per idempotent read, 1 disables retries
+	RetryBaseDelay        time.Duration // Backoff before the first retry, doubled after each
+	MaxPageLimit          int           // Largest page size a list request may ask for
+	MaxBulkBooks          int
 	RateLimit             int
 	RateLimitWindow       time.Duration
+	RateLimitRedis        *redis.Client // Optional, the in-memory limiter is used when nil
+	AdminToken            string        // Bearer token for /api/v1/admin, admin routes are disabled when empty
+	MaxBodyBytes          int           // Largest request body accepted
+	MaxBulkBodyBytes      int           // Largest request body accepted by bulk endpoints
 }
 
 func DefaultBatchingConfig() *BatchingConfig {
 	return &BatchingConfig{
 		CollectionBatchWindow: 20 * time.Millisecond,
 		BookBatchWindow:       20 * time.Millisecond,
+		BorrowBatchWindow:     20 * time.Millisecond,
+		RequestTimeout:        handler.DefaultRequestTimeout,
+		RetryAttempts:         handler.DefaultRetryAttempts,
+		RetryBaseDelay:        handler.DefaultRetryBaseDelay,
+		MaxPageLimit:          handler.DefaultMaxPageLimit,
+		MaxBulkBooks:          handler.DefaultMaxBulkBooks,
 		RateLimit:             100,
 		RateLimitWindow:       1 * time.Minute,
+		MaxBodyBytes:          config.DefaultGatewayMaxBodyBytes,
+		MaxBulkBodyBytes:      config.DefaultGatewayMaxBulkBytes,
 	}
 }
 
@@ -34,30 +53,47 @@ func SetupRoutes(
 		config = DefaultBatchingConfig()
 	}
 
+	retry := handler.RetryPolicy{Attempts: config.RetryAttempts, BaseDelay: config.RetryBaseDelay}
+
 	collectionHandler := handler.NewCollectionHandlerWithBatching(
 		connections["collection"],
 		config.CollectionBatchWindow,
-	)
+	).WithTimeout(config.RequestTimeout).WithRetry(retry).WithMaxPageLimit(config.MaxPageLimit)
 
 	bookHandler := handler.NewBookHandlerWithBatching(
 		connections["book"],
 		config.BookBatchWindow,
-	)
+	).WithTimeout(config.RequestTimeout).WithRetry(retry).WithMaxPageLimit(config.MaxPageLimit).WithMaxBulkSize(config.MaxBulkBooks)
 
-	borrowHandler := handler.NewBorrowHandler(
+	borrowHandler := handler.NewBorrowHandlerWithBatching(
 		connections["borrow"],
-	)
+		config.BorrowBatchWindow,
+	).WithTimeout(config.RequestTimeout).WithRetry(retry).WithMaxPageLimit(config.MaxPageLimit)
+
+	healthHandler := handler.NewHealthHandler(connections)
 
-	router := gin.Default()
+	router := gin.New()
+	// Handlers pass the gin context to gRPC calls, fall back to the request
+	// context so the request id metadata reaches downstream services
+	router.ContextWithFallback = true
 
 	// Global middleware
-	router.Use(RateLimitingMiddleware(config.RateLimit, config.RateLimitWindow))
+	router.Use(RecoveryMiddleware())
+	router.Use(RequestLoggingMiddleware())
+	router.Use(TracingMiddleware())
+	router.Use(MetricsMiddleware())
+	router.Use(NewRateLimitingMiddleware(config))
 	router.Use(CorsMiddleware())
+	router.Use(BodyLimitMiddleware(config.MaxBodyBytes))
 
 	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
-	})
+	router.GET("/health", healthHandler.Health)
+	router.GET("/health/deep", healthHandler.DeepHealth)
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+
+	// Prometheus scrape endpoint
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	v1 := router.Group("/api/v1")
 	{
@@ -65,7 +101,12 @@ func SetupRoutes(
 		collections.Use(collectionHandler.BatchingMiddleware())
 		{
 			collections.GET("", collectionHandler.GetCollectionBatch)
+			collections.GET("/search", collectionHandler.SearchCollections)
+			collections.GET("/categories", collectionHandler.GetCategories)
 			collections.GET("/:id", collectionHandler.GetCollectionById)
+			collections.GET("/:id/counts", collectionHandler.GetCollectionWithCounts)
+			collections.GET("/:id/available", bookHandler.GetAvailableBooks)
+			collections.GET("/:id/books", bookHandler.GetBooksByCollection)
 			collections.POST("", collectionHandler.CreateCollection)
 			collections.PUT("/:id", collectionHandler.UpdateCollection)
 			collections.DELETE("/:id", collectionHandler.DeleteCollection)
@@ -77,14 +118,39 @@ func SetupRoutes(
 			books.GET("", bookHandler.GetBookBatch)
 			books.GET("/:id", bookHandler.GetBookById)
 			books.POST("", bookHandler.CreateBook)
+			books.POST("/bulk", BodyLimitMiddleware(config.MaxBulkBodyBytes), bookHandler.BulkInsertBooks)
 			books.PUT("/:id", bookHandler.UpdateBook)
 			books.DELETE("/:id", bookHandler.DeleteBook)
 		}
 
 		borrows := v1.Group("/borrow")
+		borrows.Use(borrowHandler.BatchingMiddleware())
 		{
+			borrows.GET("", borrowHandler.GetBorrowBatch)
 			borrows.POST("", borrowHandler.BorrowBook)
 			borrows.POST("/return", borrowHandler.ReturnBook)
+			borrows.POST("/renew", borrowHandler.RenewBook)
+			borrows.GET("/overdue", borrowHandler.GetOverdueBorrows)
+			borrows.GET("/due-soon", borrowHandler.GetDueSoon)
+			borrows.GET("/count", borrowHandler.CountActiveBorrows)
+			borrows.GET("/:id", borrowHandler.FindBorrowById)
+			borrows.GET("/:id/fine", borrowHandler.CalculateFine)
+		}
+
+		users := v1.Group("/users")
+		{
+			users.GET("/:id/borrows", borrowHandler.GetBorrowsByUser)
+		}
+
+		admin := v1.Group("/admin")
+		admin.Use(AdminAuthMiddleware(config.AdminToken))
+		{
+			admin.POST("/stock-dlq/replay", bookHandler.ReplayStockDLQ)
+			admin.GET("/books", bookHandler.GetBook)
+			admin.PATCH("/books", bookHandler.UpdateBooks)
+			admin.GET("/collections", collectionHandler.GetCollection)
+			admin.GET("/collections/search", collectionHandler.SearchCollections)
+			admin.DELETE("/borrow/:id", borrowHandler.DeleteBorrow)
 		}
 	}
 
@@ -103,8 +169,9 @@ func SetupRoutes(
 func CorsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Idempotency-Key, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag, X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -114,38 +181,3 @@ func CorsMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-// Rate limiting middleware (from your original code)
-func RateLimitingMiddleware(maxRequests int, window time.Duration) gin.HandlerFunc {
-	var (
-		requestCounts = make(map[string]int)
-		lastReset     = time.Now()
-		mu            sync.RWMutex
-	)
-
-	return func(c *gin.Context) {
-		now := time.Now()
-
-		mu.Lock()
-		if now.Sub(lastReset) > window {
-			requestCounts = make(map[string]int)
-			lastReset = now
-		}
-
-		clientIP := c.ClientIP()
-		if requestCounts[clientIP] >= maxRequests {
-			mu.Unlock()
-			c.JSON(429, gin.H{
-				"error":       "Rate limit exceeded",
-				"retry_after": window.Seconds(),
-			})
-			c.Abort()
-			return
-		}
-
-		requestCounts[clientIP]++
-		mu.Unlock()
-
-		c.Next()
-	}
-}
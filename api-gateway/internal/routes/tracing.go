@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"shared/pkg/tracing"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Starts the root span of every request, continuing a trace the client sent
+// in its headers. Backend calls made with the request context become its children.
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracing.StartSpan(ctx, c.Request.Method+" "+route, trace.SpanKindServer,
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("http.route", route),
+		)
+		defer span.End()
+
+		if requestId, ok := c.Get("request_id"); ok {
+			span.SetAttributes(attribute.String("request_id", requestId.(string)))
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"apigateway/internal/routes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func adminRequest(token, authorization string) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(routes.AdminAuthMiddleware(token))
+	router.PATCH("/admin/books", func(c *gin.Context) {
+		c.String(200, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/admin/books", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAdminAuth(t *testing.T) {
+	assert.Equal(t, http.StatusOK, adminRequest("secret", "Bearer secret"))
+	assert.Equal(t, http.StatusUnauthorized, adminRequest("secret", "Bearer wrong"))
+	assert.Equal(t, http.StatusUnauthorized, adminRequest("secret", "secret"))
+	assert.Equal(t, http.StatusUnauthorized, adminRequest("secret", ""))
+
+	// No configured token switches the admin routes off instead of opening them
+	assert.Equal(t, http.StatusForbidden, adminRequest("", ""))
+	assert.Equal(t, http.StatusForbidden, adminRequest("", "Bearer "))
+}
+
+func TestDeleteBorrow_RequiresAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := routes.DefaultBatchingConfig()
+	config.AdminToken = "secret"
+	router := routes.SetupRoutes(map[string]*grpc.ClientConn{}, config)
+
+	code, _ := serve(router, http.MethodDelete, "/api/v1/borrow/b1")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = serve(router, http.MethodDelete, "/api/v1/admin/borrow/b1")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"shared/pkg/grpcutil"
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/metadata"
+)
+
+func filterOn(author string) interface{} {
+	return mock.MatchedBy(func(req *pb.GetCollectionRequest) bool {
+		return req.Filter.Fields["author"].GetStringValue() == author
+	})
+}
+
+func TestCollectionBatcher_GroupsByParams(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCollection", mock.Anything, filterOn("Tolkien")).Return(&pb.Response{Message: "Tolkien"}, nil).Once()
+	client.On("GetCollection", mock.Anything, filterOn("Rowling")).Return(&pb.Response{Message: "Rowling"}, nil).Once()
+
+	batcher := handler.NewGrpcBatcher(client, 20*time.Millisecond)
+
+	authors := []string{"Tolkien", "Rowling", "Tolkien"}
+	responses := make([]*pb.Response, len(authors))
+	errs := make([]error, len(authors))
+
+	var wg sync.WaitGroup
+	for i, author := range authors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			params := handler.QueryParams{Filter: bson.M{"author": author}, Limit: 10}
+			responses[i], errs[i] = batcher.GetBatch(context.Background(), params)
+		}()
+	}
+	wg.Wait()
+
+	for i, author := range authors {
+		require.NoError(t, errs[i])
+		assert.Equal(t, author, responses[i].Message)
+	}
+
+	// One backend call per distinct filter
+	client.AssertExpectations(t)
+	client.AssertNumberOfCalls(t, "GetCollection", 2)
+}
+
+func TestCollectionHandler_BatchedListForwardsFilter(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCollection", mock.Anything, mock.MatchedBy(func(req *pb.GetCollectionRequest) bool {
+		return req.Filter.Fields["author"].GetStringValue() == "Tolkien" && req.Limit == 5
+	})).Return(&pb.Response{Success: true, Message: "Collections retrieved successfully"}, nil).Once()
+
+	h := handler.NewCollectionHandlerWithClient(client).WithBatchWindow(10 * time.Millisecond)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(h.BatchingMiddleware())
+	router.GET("/collections", h.GetCollectionBatch)
+
+	code, resp := serve(router, http.MethodGet, "/collections?filter[author]=Tolkien&limit=5")
+
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Collections retrieved successfully", resp.Message)
+	client.AssertExpectations(t)
+}
+
+func TestCollectionBatcher_ContextCancelled(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCollection", mock.Anything, mock.Anything).Return(&pb.Response{}, nil)
+
+	batcher := handler.NewGrpcBatcher(client, 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := batcher.GetBatch(ctx, handler.QueryParams{Filter: bson.M{}, Limit: 10})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBorrowBatcher_CollapsesConcurrentCalls(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("GetBorrows", mock.Anything, mock.Anything).Return(&pb.BorrowListResponse{Success: true, Message: "ok"}, nil).Once()
+
+	batcher := handler.NewBorrowReqBatcher(client, 20*time.Millisecond)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = batcher.GetBatch(context.Background(), handler.QueryParams{Filter: bson.M{}, Limit: 10})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	client.AssertNumberOfCalls(t, "GetBorrows", 1)
+}
+
+func TestCollectionBatcher_ForwardsRequestId(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCollection", mock.MatchedBy(func(ctx context.Context) bool {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		_, hasDeadline := ctx.Deadline()
+		return slices.Equal(md.Get(grpcutil.RequestIdMetadata), []string{"req-1"}) && hasDeadline
+	}), mock.Anything).Return(&pb.Response{Success: true}, nil).Once()
+
+	batcher := handler.NewGrpcBatcher(client, 10*time.Millisecond)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), grpcutil.RequestIdMetadata, "req-1")
+	_, err := batcher.GetBatch(ctx, handler.QueryParams{Filter: bson.M{}, Limit: 10})
+
+	require.NoError(t, err)
+	client.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/internal/routes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// Routes binding a JSON body like the handlers do, /bulk raises the global limit
+func newBodyLimitedRouter(limit, bulkLimit int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(routes.BodyLimitMiddleware(limit))
+
+	bind := func(c *gin.Context) {
+		var body map[string]interface{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			handler.RespondWithBindError(c, err)
+			return
+		}
+		c.JSON(200, body)
+	}
+	router.POST("/items", bind)
+	router.POST("/bulk", routes.BodyLimitMiddleware(bulkLimit), bind)
+	return router
+}
+
+func postBody(router *gin.Engine, path string, body string, chunked bool) int {
+	var reader io.Reader = strings.NewReader(body)
+	if chunked {
+		// Hides the length so the limit is only hit while reading
+		reader = io.MultiReader(reader)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, reader)
+	if chunked {
+		req.ContentLength = -1
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestBodyLimit(t *testing.T) {
+	router := newBodyLimitedRouter(32, 256)
+	small := `{"title":"Dune"}`
+	large := `{"title":"` + strings.Repeat("x", 64) + `"}`
+
+	assert.Equal(t, http.StatusOK, postBody(router, "/items", small, false))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBody(router, "/items", large, false))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBody(router, "/items", large, true))
+
+	// Malformed bodies within the limit are still a bad request
+	assert.Equal(t, http.StatusBadRequest, postBody(router, "/items", `{"title":`, false))
+}
+
+func TestBodyLimit_RouteOverride(t *testing.T) {
+	router := newBodyLimitedRouter(32, 256)
+	large := `{"title":"` + strings.Repeat("x", 64) + `"}`
+	huge := `{"title":"` + strings.Repeat("x", 512) + `"}`
+
+	assert.Equal(t, http.StatusOK, postBody(router, "/bulk", large, false))
+	assert.Equal(t, http.StatusOK, postBody(router, "/bulk", large, true))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBody(router, "/bulk", huge, true))
+}
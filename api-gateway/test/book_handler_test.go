@@ -0,0 +1,236 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/internal/routes"
+	"apigateway/test/mocks"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shared/pkg/model"
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func bulkPayload(collectionId primitive.ObjectID, count int) string {
+	books := make([]string, count)
+	for i := range books {
+		books[i] = fmt.Sprintf(`{"collection_id":%q,"is_borrowed":false}`, collectionId.Hex())
+	}
+	return "[" + strings.Join(books, ",") + "]"
+}
+
+func postBulk(h *handler.BookHandler, body string) (int, model.HttpResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/books/bulk", h.BulkInsertBooks)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/books/bulk", strings.NewReader(body)))
+
+	var resp model.HttpResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestBulkInsertBooks_Success(t *testing.T) {
+	collectionId := primitive.NewObjectID()
+	client := &mocks.MockBookServiceClient{}
+	client.On("BulkInsert", mock.Anything, mock.MatchedBy(func(req *pb.BulkInsertBookRequest) bool {
+		for _, book := range req.Books {
+			if book.CollectionId != collectionId.Hex() || book.Id == primitive.NilObjectID.Hex() || book.CreatedAt == "" {
+				return false
+			}
+		}
+		return len(req.Books) == 3 && req.Books[0].Id != req.Books[1].Id
+	})).Return(&pb.BookResponse{Success: true, Message: "Book added!"}, nil)
+
+	code, resp := postBulk(handler.NewBookHandlerWithClient(client), bulkPayload(collectionId, 3))
+
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "Book added!", resp.Message)
+	client.AssertExpectations(t)
+}
+
+func TestBulkInsertBooks_RejectsOversizedPayload(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+	h := handler.NewBookHandlerWithClient(client).WithMaxBulkSize(2)
+
+	code, resp := postBulk(h, bulkPayload(primitive.NewObjectID(), 3))
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "Cannot insert more than 2 books at once", resp.Message)
+	client.AssertNotCalled(t, "BulkInsert", mock.Anything, mock.Anything)
+}
+
+func TestBulkInsertBooks_RejectsEmptyPayload(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+
+	code, _ := postBulk(handler.NewBookHandlerWithClient(client), "[]")
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	client.AssertNotCalled(t, "BulkInsert", mock.Anything, mock.Anything)
+}
+
+func TestGetAvailableBooks_ForwardsPagination(t *testing.T) {
+	collectionId := primitive.NewObjectID().Hex()
+	books := []*pb.Book{
+		{Id: primitive.NewObjectID().Hex(), CollectionId: collectionId},
+		{Id: primitive.NewObjectID().Hex(), CollectionId: collectionId},
+	}
+	client := &mocks.MockBookServiceClient{}
+	client.On("GetAvailableBooks", mock.Anything, mock.MatchedBy(func(req *pb.GetAvailableBookRequest) bool {
+		return req.CollectionId == collectionId && req.Skip == 2 && req.Limit == 2
+	})).Return(&pb.BookResponse{Success: true, Message: "Books retrieved successfully", Book: books, Total: 5}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/:id/available", handler.NewBookHandlerWithClient(client).GetAvailableBooks)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionId+"/available?skip=2&limit=2", nil))
+
+	var resp model.HttpResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, resp.Success)
+	require.NotNil(t, resp.Meta)
+	assert.Equal(t, int64(5), resp.Meta.Total)
+	assert.Equal(t, 3, resp.Meta.TotalPages)
+	assert.True(t, resp.Meta.HasNext)
+	client.AssertExpectations(t)
+}
+
+func TestGetBooksByCollection_ForwardsFilterAndPagination(t *testing.T) {
+	collectionId := primitive.NewObjectID().Hex()
+	books := []*pb.Book{{Id: primitive.NewObjectID().Hex(), CollectionId: collectionId}}
+	client := &mocks.MockBookServiceClient{}
+	client.On("GetBooksByCollection", mock.Anything, mock.MatchedBy(func(req *pb.GetBooksByCollectionRequest) bool {
+		return req.CollectionId == collectionId && req.AvailableOnly && req.Skip == 2 && req.Limit == 2
+	})).Return(&pb.BookResponse{Success: true, Message: "Books retrieved successfully", Book: books, Total: 3}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/:id/books", handler.NewBookHandlerWithClient(client).GetBooksByCollection)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionId+"/books?available_only=true&skip=2&limit=2", nil))
+
+	var resp model.HttpResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, resp.Meta)
+	assert.Equal(t, int64(3), resp.Meta.Total)
+	assert.False(t, resp.Meta.HasNext)
+	client.AssertExpectations(t)
+}
+
+func TestGetBooksByCollection_InvalidIdIsBadRequest(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+	client.On("GetBooksByCollection", mock.Anything, mock.Anything).
+		Return(nil, status.Error(codes.InvalidArgument, "Invalid collection id"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/:id/books", handler.NewBookHandlerWithClient(client).GetBooksByCollection)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/nope/books", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestReplayStockDLQ_ReportsCounts(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+	client.On("ReplayStockDLQ", mock.Anything, mock.Anything).Return(&pb.ReplayStockDLQResponse{
+		Success:   true,
+		Message:   "2 operations replayed, 1 failed",
+		Replayed:  2,
+		Failed:    1,
+		Remaining: 1,
+	}, nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/admin/stock-dlq/replay", handler.NewBookHandlerWithClient(client).ReplayStockDLQ)
+
+	code, resp := serve(router, http.MethodPost, "/admin/stock-dlq/replay")
+
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "2 operations replayed, 1 failed", resp.Message)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, map[string]interface{}{"replayed": 2.0, "failed": 1.0, "remaining": 1.0}, resp.Data[0])
+	client.AssertExpectations(t)
+}
+
+func TestUpdateBooks_ForwardsFilterAndUpdate(t *testing.T) {
+	collectionId := primitive.NewObjectID().Hex()
+	client := &mocks.MockBookServiceClient{}
+	client.On("UpdateBooks", mock.Anything, mock.MatchedBy(func(in *pb.UpdateBooksRequest) bool {
+		return in.GetFilter().AsMap()["collection_id"] == collectionId &&
+			in.GetPayload().AsMap()["is_borrowed"] == false
+	})).Return(&pb.UpdateBooksResponse{Success: true, Message: "3 books updated", Modified: 3}, nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/admin/books", handler.NewBookHandlerWithClient(client).UpdateBooks)
+
+	body := fmt.Sprintf(`{"filter":{"collection_id":%q},"update":{"is_borrowed":false}}`, collectionId)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/admin/books", strings.NewReader(body)))
+
+	var resp model.HttpResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3 books updated", resp.Message)
+	assert.Equal(t, map[string]interface{}{"modified": 3.0}, resp.Data[0])
+	client.AssertExpectations(t)
+}
+
+func newListBooksRouter(client *mocks.MockBookServiceClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	bookHandler := handler.NewBookHandlerWithClient(client)
+	router.GET("/books", bookHandler.GetBook)
+	router.GET("/admin/books", routes.AdminAuthMiddleware("secret"), bookHandler.GetBook)
+	return router
+}
+
+func TestGetBook_IncludeDeletedIgnoredWithoutAdmin(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+	client.On("GetBook", mock.Anything, mock.MatchedBy(func(req *pb.GetBookRequest) bool {
+		return !req.IncludeDeleted
+	})).Return(&pb.BookResponse{Success: true, Message: "Books retrieved successfully"}, nil).Once()
+
+	code, _ := serve(newListBooksRouter(client), http.MethodGet, "/books?include_deleted=true")
+
+	assert.Equal(t, http.StatusOK, code)
+	client.AssertExpectations(t)
+}
+
+func TestGetBook_AdminIncludeDeletedIsForwarded(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+	client.On("GetBook", mock.Anything, mock.MatchedBy(func(req *pb.GetBookRequest) bool {
+		return req.IncludeDeleted
+	})).Return(&pb.BookResponse{Success: true, Message: "Books retrieved successfully"}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/books?include_deleted=true", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	newListBooksRouter(client).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	client.AssertExpectations(t)
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shared/pkg/model"
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func postBorrow(client *mocks.MockBorrowServiceClient, idempotencyKey string) (int, model.HttpResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/borrow", handler.NewBorrowHandlerWithClient(client).BorrowBook)
+
+	req := httptest.NewRequest(http.MethodPost, "/borrow", strings.NewReader(`{"collection_id":"c1","user_id":"u1"}`))
+	if idempotencyKey != "" {
+		req.Header.Set(handler.IdempotencyKeyHeader, idempotencyKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp model.HttpResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestBorrowBook_ForwardsIdempotencyKey(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("BorrowBook", mock.Anything, mock.MatchedBy(func(req *pb.BorrowRequest) bool {
+		return req.IdempotencyKey == "retry-1" && req.CollectionId == "c1" && req.UserId == "u1"
+	})).Return(&pb.BorrowServiceResponse{Success: true, Message: "Book borrowed!", Id: "b1", BookId: "k1"}, nil)
+
+	code, resp := postBorrow(client, "retry-1")
+
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Success)
+	client.AssertExpectations(t)
+}
+
+func TestBorrowBook_InProgressIsConflict(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("BorrowBook", mock.Anything, mock.Anything).
+		Return(nil, status.Error(codes.Aborted, "A borrow with this idempotency key is already in progress"))
+
+	code, _ := postBorrow(client, "retry-1")
+
+	assert.Equal(t, http.StatusConflict, code)
+}
+
+func TestDeleteBorrow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("deleted", func(t *testing.T) {
+		client := &mocks.MockBorrowServiceClient{}
+		client.On("DeleteBorrow", mock.Anything, &pb.DeleteBorrowRequest{Id: "b1"}).
+			Return(&pb.BorrowServiceResponse{Success: true, Message: "Borrow deleted!", Id: "b1", BookId: "k1"}, nil)
+		router := gin.New()
+		router.DELETE("/borrow/:id", handler.NewBorrowHandlerWithClient(client).DeleteBorrow)
+
+		code, resp := serve(router, http.MethodDelete, "/borrow/b1")
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "Borrow deleted!", resp.Message)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client := &mocks.MockBorrowServiceClient{}
+		client.On("DeleteBorrow", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.NotFound, "Borrow record not found"))
+		router := gin.New()
+		router.DELETE("/borrow/:id", handler.NewBorrowHandlerWithClient(client).DeleteBorrow)
+
+		code, _ := serve(router, http.MethodDelete, "/borrow/missing")
+
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
+func TestGetBorrows_ForwardsUserFilter(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("GetBorrows", mock.Anything, mock.MatchedBy(func(req *pb.GetBorrowsRequest) bool {
+		filter := req.GetFilter().AsMap()
+		return len(filter) == 1 && filter["user_id"] == "6650a1b2c3d4e5f6a7b8c9d0"
+	})).Return(&pb.BorrowListResponse{Success: true, Message: "Borrows retrieved successfully", Borrow: []*pb.Borrow{{
+		Id:         "6650a1b2c3d4e5f6a7b8c9d1",
+		UserId:     "6650a1b2c3d4e5f6a7b8c9d0",
+		BorrowDate: "2026-10-01T00:00:00Z",
+		CreatedAt:  "2026-10-01T00:00:00Z",
+		UpdatedAt:  "2026-10-01T00:00:00Z",
+	}}}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/borrow", handler.NewBorrowHandlerWithClient(client).GetBorrows)
+
+	code, resp := serve(router, http.MethodGet, "/borrow?filter[user_id]=6650a1b2c3d4e5f6a7b8c9d0")
+
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Data, 1)
+	assert.Len(t, resp.Data[0], 1)
+	client.AssertExpectations(t)
+}
+
+func TestCountActiveBorrows_ForwardsFilters(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("CountActiveBorrows", mock.Anything, mock.MatchedBy(func(req *pb.CountBorrowRequest) bool {
+		return req.CollectionId == "c1" && req.UserId == ""
+	})).Return(&pb.BorrowCountResponse{Success: true, Message: "Borrows counted successfully!", Count: 4}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/borrow/count", handler.NewBorrowHandlerWithClient(client).CountActiveBorrows)
+
+	code, resp := serve(router, http.MethodGet, "/borrow/count?collection_id=c1")
+
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, map[string]interface{}{"count": float64(4)}, resp.Data[0])
+	client.AssertExpectations(t)
+}
+
+func TestFindBorrowById(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("found", func(t *testing.T) {
+		borrow := &pb.Borrow{
+			Id:           "65f1c0a1b2c3d4e5f6a7b8c9",
+			BookId:       "65f1c0a1b2c3d4e5f6a7b8ca",
+			UserId:       "65f1c0a1b2c3d4e5f6a7b8cb",
+			CollectionId: "65f1c0a1b2c3d4e5f6a7b8cc",
+			BorrowDate:   "2026-01-01T00:00:00Z",
+			DueDate:      "2026-01-08T00:00:00Z",
+			CreatedAt:    "2026-01-01T00:00:00Z",
+			UpdatedAt:    "2026-01-01T00:00:00Z",
+		}
+		client := &mocks.MockBorrowServiceClient{}
+		client.On("FindBorrowById", mock.Anything, &pb.FindBorrowRequest{Id: borrow.Id}).
+			Return(&pb.BorrowServiceResponse{Success: true, Message: "Borrow record found", Id: borrow.Id, Borrow: borrow, IsOverdue: true}, nil)
+		router := gin.New()
+		router.GET("/borrow/:id", handler.NewBorrowHandlerWithClient(client).FindBorrowById)
+
+		code, resp := serve(router, http.MethodGet, "/borrow/"+borrow.Id)
+
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Data, 1)
+		data := resp.Data[0].(map[string]interface{})
+		assert.Equal(t, borrow.Id, data["id"])
+		assert.Equal(t, "2026-01-08T00:00:00Z", data["due_date"])
+		assert.Equal(t, true, data["is_overdue"])
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client := &mocks.MockBorrowServiceClient{}
+		client.On("FindBorrowById", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.NotFound, "Borrow record not found"))
+		router := gin.New()
+		router.GET("/borrow/:id", handler.NewBorrowHandlerWithClient(client).FindBorrowById)
+
+		code, _ := serve(router, http.MethodGet, "/borrow/missing")
+
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
+func TestGetDueSoon_ForwardsDays(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("GetDueSoon", mock.Anything, mock.MatchedBy(func(req *pb.DueSoonRequest) bool {
+		return req.Days == 5
+	})).Return(&pb.BorrowListResponse{Success: true, Message: "Due soon borrows retrieved successfully"}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/borrow/due-soon", handler.NewBorrowHandlerWithClient(client).GetDueSoon)
+
+	code, _ := serve(router, http.MethodGet, "/borrow/due-soon?days=5")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = serve(router, http.MethodGet, "/borrow/due-soon?days=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+	client.AssertNumberOfCalls(t, "GetDueSoon", 1)
+}
+
+func TestCalculateFine(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("CalculateFine", mock.Anything, &pb.FineRequest{BorrowId: "b1"}).
+		Return(&pb.FineResponse{Success: true, Message: "Fine calculated successfully", BorrowId: "b1", DaysOverdue: 3, Fine: 3000, FinePerDay: 1000}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/borrow/:id/fine", handler.NewBorrowHandlerWithClient(client).CalculateFine)
+
+	code, resp := serve(router, http.MethodGet, "/borrow/b1/fine")
+
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, map[string]interface{}{
+		"borrow_id":    "b1",
+		"days_overdue": float64(3),
+		"fine":         float64(3000),
+		"fine_per_day": float64(1000),
+	}, resp.Data[0])
+}
@@ -0,0 +1,190 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/internal/routes"
+	"apigateway/test/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newCollectionListRouter(client *mocks.MockCollectionServiceClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections", handler.NewCollectionHandlerWithClient(client).GetCollectionBatch)
+	return router
+}
+
+func TestGetCollectionsByIds_SingleBackendCall(t *testing.T) {
+	first, second := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionsByIds", mock.Anything, &pb.FindCollectionsByIdsRequest{Ids: []string{first, "bad", second}}).
+		Return(&pb.Response{
+			Success:    true,
+			Message:    "Found 2 collections",
+			Collection: []*pb.Collection{{Id: first, Name: "Dune"}, {Id: second, Name: "Emma"}},
+			Skipped:    1,
+		}, nil).Once()
+
+	code, body := serve(newCollectionListRouter(client), http.MethodGet, "/collections?ids="+first+",bad,,"+second)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, body.Success)
+	assert.Equal(t, "Found 2 collections, skipped 1 invalid ids", body.Message)
+	require.Len(t, body.Data, 1)
+	assert.Len(t, body.Data[0], 2)
+	client.AssertExpectations(t)
+	client.AssertNotCalled(t, "GetCollection", mock.Anything, mock.Anything)
+}
+
+func TestGetCollectionsByIds_EmptyList(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+
+	code, body := serve(newCollectionListRouter(client), http.MethodGet, "/collections?ids=,")
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.False(t, body.Success)
+	client.AssertNotCalled(t, "FindCollectionsByIds", mock.Anything, mock.Anything)
+}
+
+func TestGetCategories_ListsCategories(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCategories", mock.Anything, mock.Anything).
+		Return(&pb.CategoriesResponse{Categories: []string{"classic", "fantasy"}}, nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/categories", handler.NewCollectionHandlerWithClient(client).GetCategories)
+
+	code, resp := serve(router, http.MethodGet, "/collections/categories")
+
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, []interface{}{"classic", "fantasy"}, resp.Data[0])
+	client.AssertExpectations(t)
+}
+
+func TestGetCollection_CategoryFilter(t *testing.T) {
+	cases := map[string]struct {
+		query string
+		want  interface{}
+	}{
+		"single category matches membership": {"filter[categories]=fantasy", "fantasy"},
+		"numeric category stays a string":    {"filter[categories]=1984", "1984"},
+		"several categories match any":       {"category=fantasy&category=scifi", map[string]interface{}{"$in": []interface{}{"fantasy", "scifi"}}},
+		"both forms combine":                 {"filter[categories]=fantasy&category=scifi", map[string]interface{}{"$in": []interface{}{"fantasy", "scifi"}}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := &mocks.MockCollectionServiceClient{}
+			client.On("GetCollection", mock.Anything, mock.MatchedBy(func(in *pb.GetCollectionRequest) bool {
+				return assert.ObjectsAreEqual(tc.want, in.Filter.AsMap()["categories"])
+			})).Return(&pb.Response{Success: true}, nil).Once()
+
+			code, _ := serve(newCollectionListRouter(client), http.MethodGet, "/collections?"+tc.query)
+
+			assert.Equal(t, http.StatusOK, code)
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func newSearchRouter(client *mocks.MockCollectionServiceClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	collectionHandler := handler.NewCollectionHandlerWithClient(client)
+	router.GET("/collections/search", collectionHandler.SearchCollections)
+	router.GET("/admin/collections/search", routes.AdminAuthMiddleware("secret"), collectionHandler.SearchCollections)
+	return router
+}
+
+func TestSearchCollections_RegexNeedsAdmin(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+
+	code, body := serve(newSearchRouter(client), http.MethodGet, "/collections/search?q=^Harry&regex=true")
+
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.False(t, body.Success)
+	client.AssertNotCalled(t, "SearchCollections", mock.Anything, mock.Anything)
+}
+
+func TestSearchCollections_AdminRegexIsForwarded(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("SearchCollections", mock.Anything, mock.MatchedBy(func(req *pb.SearchRequest) bool {
+		return req.Query == "^Harry" && req.Regex
+	})).Return(&pb.Response{Success: true, Message: "Collections retrieved successfully"}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/collections/search?q=%5EHarry&regex=true", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	newSearchRouter(client).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	client.AssertExpectations(t)
+}
+
+func TestSearchCollections_LiteralByDefault(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("SearchCollections", mock.Anything, mock.MatchedBy(func(req *pb.SearchRequest) bool {
+		return req.Query == "C++" && !req.Regex
+	})).Return(&pb.Response{Success: true, Message: "Collections retrieved successfully"}, nil).Once()
+
+	code, _ := serve(newSearchRouter(client), http.MethodGet, "/collections/search?q=C%2B%2B")
+
+	assert.Equal(t, http.StatusOK, code)
+	client.AssertExpectations(t)
+}
+
+func TestGetCollectionWithCounts_ForwardsId(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCollectionWithCounts", mock.Anything, &pb.FindCollectionRequest{Id: id}).
+		Return(&pb.Response{Success: true, Message: "Collection found", Collection: []*pb.Collection{{
+			Id:             id,
+			Name:           "Dune",
+			TotalBooks:     3,
+			AvailableBooks: 2,
+			CreatedAt:      "2026-10-01T00:00:00Z",
+			UpdatedAt:      "2026-10-01T00:00:00Z",
+		}}}, nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/:id/counts", handler.NewCollectionHandlerWithClient(client).GetCollectionWithCounts)
+
+	code, body := serve(router, http.MethodGet, "/collections/"+id+"/counts")
+
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, body.Data, 1)
+	collections := body.Data[0].([]interface{})
+	require.Len(t, collections, 1)
+	assert.Equal(t, float64(3), collections[0].(map[string]interface{})["total_books"])
+	assert.Equal(t, float64(2), collections[0].(map[string]interface{})["available_books"])
+	client.AssertExpectations(t)
+}
+
+func TestGetCollectionWithCounts_NotFound(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCollectionWithCounts", mock.Anything, mock.Anything).
+		Return(nil, status.Error(codes.NotFound, "Collection not found")).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/:id/counts", handler.NewCollectionHandlerWithClient(client).GetCollectionWithCounts)
+
+	code, _ := serve(router, http.MethodGet, "/collections/"+primitive.NewObjectID().Hex()+"/counts")
+
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"shared/pkg/model"
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func conditionalRequest(router *gin.Engine, method, path, ifMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{"is_borrowed":true}`))
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBookETag(t *testing.T) {
+	book := model.NewBook()
+	current := model.ToPbBook(&book)
+	readEtag := current.Etag
+	book.UpdatedAt = book.UpdatedAt.Add(time.Second)
+	updated := model.ToPbBook(&book)
+
+	client := &mocks.MockBookServiceClient{}
+	client.On("FindBookById", mock.Anything, mock.Anything).Return(&pb.BookResponse{Success: true, Book: []*pb.Book{current}}, nil)
+	client.On("UpdateBook", mock.Anything, mock.MatchedBy(func(in *pb.UpdateBookRequest) bool { return in.IfMatch == readEtag })).
+		Return(&pb.BookResponse{Success: true, Book: []*pb.Book{updated}}, nil).Once()
+	client.On("UpdateBook", mock.Anything, mock.MatchedBy(func(in *pb.UpdateBookRequest) bool { return in.IfMatch != readEtag })).
+		Return(nil, status.Error(codes.FailedPrecondition, "Book was changed since it was read"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handler.NewBookHandlerWithClient(client)
+	router.GET("/books/:id", h.GetBookById)
+	router.PUT("/books/:id", h.UpdateBook)
+	path := "/books/" + book.Id.Hex()
+
+	w := conditionalRequest(router, http.MethodGet, path, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, readEtag, w.Header().Get("ETag"))
+
+	// The tag that was read updates and hands out the new one
+	w = conditionalRequest(router, http.MethodPut, path, readEtag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, updated.Etag, w.Header().Get("ETag"))
+
+	// Replaying the now stale tag is refused
+	w = conditionalRequest(router, http.MethodPut, path, model.ETag(time.Now().Add(-time.Hour)))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestCollectionETag(t *testing.T) {
+	id := primitive.NewObjectID()
+	current := model.ToPbCollection(&model.Collection{Id: id, Name: "C", Author: "A", UpdatedAt: time.Now()})
+
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionById", mock.Anything, mock.Anything).Return(&pb.Response{Success: true, Collection: []*pb.Collection{current}}, nil)
+	client.On("UpdateCollection", mock.Anything, mock.MatchedBy(func(in *pb.UpdateCollectionRequest) bool { return in.IfMatch == current.Etag })).
+		Return(&pb.Response{Success: true, Collection: []*pb.Collection{current}}, nil).Once()
+	client.On("UpdateCollection", mock.Anything, mock.MatchedBy(func(in *pb.UpdateCollectionRequest) bool { return in.IfMatch != current.Etag })).
+		Return(nil, status.Error(codes.FailedPrecondition, "Collection was changed since it was read"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handler.NewCollectionHandlerWithClient(client)
+	router.GET("/collections/:id", h.GetCollectionById)
+	router.PUT("/collections/:id", h.UpdateCollection)
+	path := "/collections/" + id.Hex()
+
+	w := conditionalRequest(router, http.MethodGet, path, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, current.Etag, w.Header().Get("ETag"))
+
+	assert.Equal(t, http.StatusOK, conditionalRequest(router, http.MethodPut, path, current.Etag).Code)
+	assert.Equal(t, http.StatusPreconditionFailed, conditionalRequest(router, http.MethodPut, path, `"stale"`).Code)
+}
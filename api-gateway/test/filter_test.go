@@ -0,0 +1,142 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"net/http"
+	"net/http/httptest"
+	"shared/pkg/grpcutil"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBuildFilterAndSort_NoSort(t *testing.T) {
+	filter, sort, err := handler.BuildFilterAndSort(handler.QueryParams{Filter: bson.M{"author": "Tolkien"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Tolkien", filter.Fields["author"].GetStringValue())
+	assert.NotNil(t, sort)
+	assert.Empty(t, sort)
+}
+
+func TestBuildFilterAndSort_Sort(t *testing.T) {
+	sortDoc := bson.D{{Key: "name", Value: 1}, {Key: "created_at", Value: -1}}
+
+	_, sort, err := handler.BuildFilterAndSort(handler.QueryParams{Filter: bson.M{}, Sort: &sortDoc})
+
+	require.NoError(t, err)
+	require.Len(t, sort, 2)
+	assert.Equal(t, "name", sort[0].Key)
+	assert.Equal(t, int32(1), sort[0].Direction)
+	assert.Equal(t, "created_at", sort[1].Key)
+	assert.Equal(t, int32(-1), sort[1].Direction)
+}
+
+func TestBuildFilterAndSort_UnrepresentableFilter(t *testing.T) {
+	filter, sort, err := handler.BuildFilterAndSort(handler.QueryParams{
+		Filter: bson.M{"collection_id": primitive.NewObjectID()},
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Nil(t, filter)
+	assert.Nil(t, sort)
+}
+
+func parseQueryWithError(query string) (handler.QueryParams, error) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/books?"+query, nil)
+	return handler.ParseQueryParams(c, handler.DefaultMaxPageLimit)
+}
+
+func parseQuery(query string) handler.QueryParams {
+	params, _ := parseQueryWithError(query)
+	return params
+}
+
+func TestParseQueryParams_Pagination(t *testing.T) {
+	params, err := parseQueryWithError("page=3&limit=20")
+	require.NoError(t, err)
+	assert.Equal(t, 20, params.Limit)
+	assert.Equal(t, 40, params.Skip)
+
+	params, err = parseQueryWithError("")
+	require.NoError(t, err)
+	assert.Equal(t, handler.DefaultPageLimit, params.Limit)
+	assert.Equal(t, 0, params.Skip)
+}
+
+func TestParseQueryParams_RejectsOutOfRangePagination(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+	}{
+		{query: "limit=5000", field: "limit"},
+		{query: "limit=0", field: "limit"},
+		{query: "limit=ten", field: "limit"},
+		{query: "skip=-5", field: "skip"},
+		{query: "page=-1", field: "page"},
+		{query: "page=0", field: "page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseQueryWithError(tt.query)
+
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, grpcutil.FieldViolations(err), tt.field)
+		})
+	}
+}
+
+func TestGetBook_OverLimitIs400(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/books", handler.NewBookHandlerWithClient(client).WithMaxPageLimit(50).GetBook)
+
+	code, body := serve(router, http.MethodGet, "/books?limit=51")
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.False(t, body.Success)
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, map[string]interface{}{"limit": "limit must not exceed 50"}, body.Data[0])
+	client.AssertNotCalled(t, "GetBook", mock.Anything, mock.Anything)
+}
+
+func TestParseQueryParams_TypedFilterValues(t *testing.T) {
+	params := parseQuery("filter[is_borrowed]=false&filter[year]=1954&filter[rating]=4.5&filter[author]=Tolkien")
+
+	assert.Equal(t, false, params.Filter["is_borrowed"])
+	assert.Equal(t, int64(1954), params.Filter["year"])
+	assert.Equal(t, 4.5, params.Filter["rating"])
+	assert.Equal(t, "Tolkien", params.Filter["author"])
+}
+
+func TestParseQueryParams_IdLikeValuesStayStrings(t *testing.T) {
+	params := parseQuery("filter[collection_id]=123456789012345678901234&filter[code]=1e5")
+
+	assert.Equal(t, "123456789012345678901234", params.Filter["collection_id"])
+	assert.Equal(t, "1e5", params.Filter["code"])
+}
+
+func TestParseQueryParams_RangeOperators(t *testing.T) {
+	params := parseQuery("filter[year][gte]=1950&filter[year][lt]=1960&filter[year][like]=19")
+
+	assert.Equal(t, map[string]interface{}{"$gte": int64(1950), "$lt": int64(1960)}, params.Filter["year"])
+
+	filter, _, err := handler.BuildFilterAndSort(params)
+	require.NoError(t, err)
+	year := filter.Fields["year"].GetStructValue()
+	require.NotNil(t, year)
+	assert.Equal(t, 1950.0, year.Fields["$gte"].GetNumberValue())
+	assert.Equal(t, 1960.0, year.Fields["$lt"].GetNumberValue())
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"shared/pkg/grpcutil"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Starts an in-memory backend reporting the given status and returns a
+// connection to it
+func newHealthConn(t *testing.T, status healthpb.HealthCheckResponse_ServingStatus) *grpc.ClientConn {
+	conn, _ := newHealthBackend(t, status)
+	return conn
+}
+
+// Like newHealthConn but also returns the health server so a test can change
+// the reported status
+func newHealthBackend(t *testing.T, status healthpb.HealthCheckResponse_ServingStatus) (*grpc.ClientConn, *health.Server) {
+	lis := bufconn.Listen(1024 * 1024)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", status)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, healthServer
+}
+
+type deepHealthResponse struct {
+	Status   string            `json:"status"`
+	Services map[string]string `json:"services"`
+}
+
+func getDeepHealth(t *testing.T, connections map[string]*grpc.ClientConn) (int, deepHealthResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health/deep", handler.NewHealthHandler(connections).DeepHealth)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/deep", nil))
+
+	var body deepHealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestDeepHealth_AllServing(t *testing.T) {
+	code, body := getDeepHealth(t, map[string]*grpc.ClientConn{
+		"book":   newHealthConn(t, healthpb.HealthCheckResponse_SERVING),
+		"borrow": newHealthConn(t, healthpb.HealthCheckResponse_SERVING),
+	})
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "healthy", body.Status)
+	assert.Equal(t, "SERVING", body.Services["book"])
+	assert.Equal(t, "SERVING", body.Services["borrow"])
+}
+
+func TestDeepHealth_OneNotServing(t *testing.T) {
+	code, body := getDeepHealth(t, map[string]*grpc.ClientConn{
+		"book":       newHealthConn(t, healthpb.HealthCheckResponse_SERVING),
+		"collection": newHealthConn(t, healthpb.HealthCheckResponse_NOT_SERVING),
+	})
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unhealthy", body.Status)
+	assert.Equal(t, "SERVING", body.Services["book"])
+	assert.Equal(t, "NOT_SERVING", body.Services["collection"])
+}
+
+func TestHealth_ReportsBackendReadiness(t *testing.T) {
+	conn := newHealthConn(t, healthpb.HealthCheckResponse_SERVING)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, grpcutil.WaitForReady(ctx, conn))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health", handler.NewHealthHandler(map[string]*grpc.ClientConn{"book": conn}).Health)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var body struct {
+		Status        string            `json:"status"`
+		BackendsReady bool              `json:"backends_ready"`
+		Backends      map[string]string `json:"backends"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, body.BackendsReady)
+	assert.Equal(t, "READY", body.Backends["book"])
+}
+
+func getJSON(t *testing.T, router *gin.Engine, path string, body interface{}) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), body))
+	return w.Code
+}
+
+func TestLivez_AliveWhenBackendNotServing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/livez", handler.NewHealthHandler(map[string]*grpc.ClientConn{
+		"book": newHealthConn(t, healthpb.HealthCheckResponse_NOT_SERVING),
+	}).Livez)
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	code := getJSON(t, router, "/livez", &body)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "alive", body.Status)
+}
+
+func TestReadyz_FlipsWhenBackendNotServing(t *testing.T) {
+	bookConn, bookHealth := newHealthBackend(t, healthpb.HealthCheckResponse_SERVING)
+	borrowConn := newHealthConn(t, healthpb.HealthCheckResponse_SERVING)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", handler.NewHealthHandler(map[string]*grpc.ClientConn{
+		"book":   bookConn,
+		"borrow": borrowConn,
+	}).WithReadyCacheTTL(0).Readyz)
+
+	var body deepHealthResponse
+	code := getJSON(t, router, "/readyz", &body)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body.Status)
+	assert.Equal(t, "SERVING", body.Services["book"])
+	assert.Equal(t, "SERVING", body.Services["borrow"])
+
+	bookHealth.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	body = deepHealthResponse{}
+	code = getJSON(t, router, "/readyz", &body)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", body.Status)
+	assert.Equal(t, "NOT_SERVING", body.Services["book"])
+	assert.Equal(t, "SERVING", body.Services["borrow"])
+
+	bookHealth.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	body = deepHealthResponse{}
+	code = getJSON(t, router, "/readyz", &body)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body.Status)
+}
+
+func TestReadyz_CachesResultWithinTTL(t *testing.T) {
+	conn, healthServer := newHealthBackend(t, healthpb.HealthCheckResponse_SERVING)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", handler.NewHealthHandler(map[string]*grpc.ClientConn{"book": conn}).
+		WithReadyCacheTTL(time.Minute).Readyz)
+
+	var body deepHealthResponse
+	require.Equal(t, http.StatusOK, getJSON(t, router, "/readyz", &body))
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	body = deepHealthResponse{}
+	code := getJSON(t, router, "/readyz", &body)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "SERVING", body.Services["book"])
+}
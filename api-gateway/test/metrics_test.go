@@ -0,0 +1,33 @@
+package test
+
+import (
+	"apigateway/internal/routes"
+	"net/http"
+	"net/http/httptest"
+	"shared/pkg/metrics"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware_CountsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(routes.MetricsMiddleware())
+	router.GET("/items/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	counter := metrics.HTTPRequests.WithLabelValues("GET", "/items/:id", "200")
+	before := testutil.ToFloat64(counter)
+
+	serve(router, http.MethodGet, "/items/1")
+	serve(router, http.MethodGet, "/items/2")
+	assert.Equal(t, before+2, testutil.ToFloat64(counter))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="GET",route="/items/:id",status="200"}`)
+}
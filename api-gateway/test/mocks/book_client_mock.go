@@ -0,0 +1,117 @@
+package mocks
+
+import (
+	"context"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+type MockBookServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockBookServiceClient) GetBook(ctx context.Context, in *pb.GetBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) FindBookById(ctx context.Context, in *pb.FindBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) AddBook(ctx context.Context, in *pb.AddBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) UpdateBook(ctx context.Context, in *pb.UpdateBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) DeleteBook(ctx context.Context, in *pb.DeleteBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) GetAvailableBook(ctx context.Context, in *pb.GetAvailableBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) GetAvailableBooks(ctx context.Context, in *pb.GetAvailableBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) GetBooksByCollection(ctx context.Context, in *pb.GetBooksByCollectionRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) CountBook(ctx context.Context, in *pb.CountBookRequest, opts ...grpc.CallOption) (*pb.BookCountResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookCountResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) BulkInsert(ctx context.Context, in *pb.BulkInsertBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) BulkDelete(ctx context.Context, in *pb.BulkDeleteBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) ReplayStockDLQ(ctx context.Context, in *pb.ReplayStockDLQRequest, opts ...grpc.CallOption) (*pb.ReplayStockDLQResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.ReplayStockDLQResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookServiceClient) UpdateBooks(ctx context.Context, in *pb.UpdateBooksRequest, opts ...grpc.CallOption) (*pb.UpdateBooksResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.UpdateBooksResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
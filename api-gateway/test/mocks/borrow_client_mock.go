@@ -0,0 +1,101 @@
+package mocks
+
+import (
+	"context"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+type MockBorrowServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockBorrowServiceClient) GetBorrows(ctx context.Context, in *pb.GetBorrowsRequest, opts ...grpc.CallOption) (*pb.BorrowListResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowListResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) BorrowBook(ctx context.Context, in *pb.BorrowRequest, opts ...grpc.CallOption) (*pb.BorrowServiceResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowServiceResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) ReturnBook(ctx context.Context, in *pb.ReturnRequest, opts ...grpc.CallOption) (*pb.BorrowServiceResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowServiceResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) GetOverdueBorrows(ctx context.Context, in *pb.OverdueRequest, opts ...grpc.CallOption) (*pb.BorrowListResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowListResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) GetDueSoon(ctx context.Context, in *pb.DueSoonRequest, opts ...grpc.CallOption) (*pb.BorrowListResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowListResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) CalculateFine(ctx context.Context, in *pb.FineRequest, opts ...grpc.CallOption) (*pb.FineResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.FineResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) RenewBook(ctx context.Context, in *pb.RenewRequest, opts ...grpc.CallOption) (*pb.BorrowServiceResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowServiceResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) GetBorrowsByUser(ctx context.Context, in *pb.UserBorrowsRequest, opts ...grpc.CallOption) (*pb.BorrowListResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowListResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) DeleteBorrow(ctx context.Context, in *pb.DeleteBorrowRequest, opts ...grpc.CallOption) (*pb.BorrowServiceResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowServiceResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) FindBorrowById(ctx context.Context, in *pb.FindBorrowRequest, opts ...grpc.CallOption) (*pb.BorrowServiceResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowServiceResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBorrowServiceClient) CountActiveBorrows(ctx context.Context, in *pb.CountBorrowRequest, opts ...grpc.CallOption) (*pb.BorrowCountResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BorrowCountResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
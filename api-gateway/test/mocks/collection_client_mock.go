@@ -0,0 +1,94 @@
+package mocks
+
+import (
+	"context"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type MockCollectionServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockCollectionServiceClient) GetCollection(ctx context.Context, in *pb.GetCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) FindCollectionById(ctx context.Context, in *pb.FindCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) GetCollectionWithCounts(ctx context.Context, in *pb.FindCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) FindCollectionsByIds(ctx context.Context, in *pb.FindCollectionsByIdsRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) AddCollection(ctx context.Context, in *pb.AddCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) UpdateCollection(ctx context.Context, in *pb.UpdateCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) DeleteCollection(ctx context.Context, in *pb.DeleteCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	return nil, nil
+}
+
+func (m *MockCollectionServiceClient) AdjustBookStock(ctx context.Context, in *pb.AdjustBookStockRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	return nil, nil
+}
+
+func (m *MockCollectionServiceClient) SearchCollections(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) SetSeedStatus(ctx context.Context, in *pb.SetSeedStatusRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.Response); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionServiceClient) GetCategories(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.CategoriesResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.CategoriesResponse); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMeta(t *testing.T) {
+	tests := []struct {
+		name       string
+		skip       int
+		limit      int
+		total      int64
+		page       int
+		totalPages int
+		hasNext    bool
+	}{
+		{name: "exact fit first page", skip: 0, limit: 10, total: 20, page: 1, totalPages: 2, hasNext: true},
+		{name: "exact fit last page", skip: 10, limit: 10, total: 20, page: 2, totalPages: 2, hasNext: false},
+		{name: "partial last page", skip: 20, limit: 10, total: 25, page: 3, totalPages: 3, hasNext: false},
+		{name: "before partial last page", skip: 10, limit: 10, total: 25, page: 2, totalPages: 3, hasNext: true},
+		{name: "single partial page", skip: 0, limit: 10, total: 3, page: 1, totalPages: 1, hasNext: false},
+		{name: "no results", skip: 0, limit: 10, total: 0, page: 1, totalPages: 0, hasNext: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := handler.BuildMeta(handler.QueryParams{Skip: tt.skip, Limit: tt.limit}, tt.total)
+
+			require.NotNil(t, meta)
+			assert.Equal(t, tt.total, meta.Total)
+			assert.Equal(t, tt.limit, meta.Limit)
+			assert.Equal(t, tt.page, meta.Page)
+			assert.Equal(t, tt.totalPages, meta.TotalPages)
+			assert.Equal(t, tt.hasNext, meta.HasNext)
+		})
+	}
+}
+
+func TestBuildMeta_CursorPagesHaveNone(t *testing.T) {
+	assert.Nil(t, handler.BuildMeta(handler.QueryParams{Limit: 10, UseCursor: true}, 50))
+}
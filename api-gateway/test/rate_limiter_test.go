@@ -0,0 +1,150 @@
+package test
+
+import (
+	"apigateway/internal/routes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T) *redis.Client {
+	mr := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func newLimitedRouter(middleware gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware)
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(200, "pong")
+	})
+	return router
+}
+
+func requestFrom(router *gin.Engine, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.RemoteAddr = ip + ":1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRedisRateLimit_RejectsOverLimit(t *testing.T) {
+	router := newLimitedRouter(routes.RedisRateLimitingMiddleware(newRedis(t), 2, time.Minute))
+
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+
+	w := requestFrom(router, "10.0.0.1")
+	assert.Equal(t, 429, w.Code)
+
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, 60, retryAfter, 1)
+
+	// Other clients have their own window
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.2").Code)
+}
+
+func TestRedisRateLimit_SlidingWindow(t *testing.T) {
+	window := 200 * time.Millisecond
+	router := newLimitedRouter(routes.RedisRateLimitingMiddleware(newRedis(t), 1, window))
+
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+	assert.Equal(t, 429, requestFrom(router, "10.0.0.1").Code)
+
+	time.Sleep(window + 50*time.Millisecond)
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+}
+
+func TestRedisRateLimit_SharedAcrossInstances(t *testing.T) {
+	client := newRedis(t)
+	first := newLimitedRouter(routes.RedisRateLimitingMiddleware(client, 1, time.Minute))
+	second := newLimitedRouter(routes.RedisRateLimitingMiddleware(client, 1, time.Minute))
+
+	assert.Equal(t, 200, requestFrom(first, "10.0.0.1").Code)
+	assert.Equal(t, 429, requestFrom(second, "10.0.0.1").Code)
+}
+
+func TestRateLimit_FallsBackToMemory(t *testing.T) {
+	config := routes.DefaultBatchingConfig()
+	config.RateLimit = 1
+	router := newLimitedRouter(routes.NewRateLimitingMiddleware(config))
+
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+	assert.Equal(t, 429, requestFrom(router, "10.0.0.1").Code)
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	limiters := map[string]func(t *testing.T, limit int, window time.Duration) gin.HandlerFunc{
+		"redis": func(t *testing.T, limit int, window time.Duration) gin.HandlerFunc {
+			return routes.RedisRateLimitingMiddleware(newRedis(t), limit, window)
+		},
+		"memory": func(t *testing.T, limit int, window time.Duration) gin.HandlerFunc {
+			return routes.RateLimitingMiddleware(limit, window)
+		},
+	}
+
+	for name, newLimiter := range limiters {
+		t.Run(name+" decrements across requests", func(t *testing.T) {
+			router := newLimitedRouter(newLimiter(t, 3, time.Minute))
+
+			for _, remaining := range []string{"2", "1", "0"} {
+				w := requestFrom(router, "10.0.0.1")
+				assert.Equal(t, 200, w.Code)
+				assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+				assert.Equal(t, remaining, w.Header().Get("X-RateLimit-Remaining"))
+
+				reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+				require.NoError(t, err)
+				assert.InDelta(t, time.Now().Add(time.Minute).Unix(), reset, 1)
+			}
+
+			w := requestFrom(router, "10.0.0.1")
+			assert.Equal(t, 429, w.Code)
+			assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+			// Other clients have their own budget
+			assert.Equal(t, "2", requestFrom(router, "10.0.0.2").Header().Get("X-RateLimit-Remaining"))
+		})
+
+		t.Run(name+" resets after the window", func(t *testing.T) {
+			window := 200 * time.Millisecond
+			router := newLimitedRouter(newLimiter(t, 2, window))
+
+			assert.Equal(t, "1", requestFrom(router, "10.0.0.1").Header().Get("X-RateLimit-Remaining"))
+			assert.Equal(t, "0", requestFrom(router, "10.0.0.1").Header().Get("X-RateLimit-Remaining"))
+
+			time.Sleep(window + 50*time.Millisecond)
+			w := requestFrom(router, "10.0.0.1")
+			assert.Equal(t, 200, w.Code)
+			assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+		})
+	}
+}
+
+func TestRateLimit_MemorySlidingWindow(t *testing.T) {
+	window := 300 * time.Millisecond
+	router := newLimitedRouter(routes.RateLimitingMiddleware(2, window))
+
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+	time.Sleep(window / 2)
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+	assert.Equal(t, 429, requestFrom(router, "10.0.0.1").Code)
+
+	// Only the first request has left the window, a fixed window reset would
+	// have freed both slots
+	time.Sleep(window/2 + 50*time.Millisecond)
+	assert.Equal(t, 200, requestFrom(router, "10.0.0.1").Code)
+	assert.Equal(t, 429, requestFrom(router, "10.0.0.1").Code)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"apigateway/internal/routes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPanickingRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(routes.RecoveryMiddleware())
+	router.Use(routes.RequestLoggingMiddleware())
+	router.GET("/panic", func(c *gin.Context) {
+		var sort *struct{ Key string }
+		c.String(http.StatusOK, sort.Key)
+	})
+	router.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return router
+}
+
+func TestRecovery_PanicReturnsJson500(t *testing.T) {
+	router := newPanickingRouter()
+
+	code, body := serve(router, http.MethodGet, "/panic")
+
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.False(t, body.Success)
+	assert.Equal(t, http.StatusInternalServerError, body.Code)
+	assert.NotEmpty(t, body.Message)
+}
+
+func TestRecovery_KeepsServingAfterPanic(t *testing.T) {
+	router := newPanickingRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(routes.RequestIdHeader, "req-panic")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "req-panic", w.Header().Get(routes.RequestIdHeader))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/internal/routes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type collectionServer struct {
+	pb.UnimplementedCollectionServiceServer
+}
+
+func (s *collectionServer) FindCollectionById(ctx context.Context, in *pb.FindCollectionRequest) (*pb.Response, error) {
+	return &pb.Response{Success: true}, nil
+}
+
+// Starts an in-memory collection service and returns a connection to it along
+// with the request ids its interceptor received
+func newCollectionConn(t *testing.T) (*grpc.ClientConn, <-chan string) {
+	lis := bufconn.Listen(1024 * 1024)
+	received := make(chan string, 1)
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if ids := md.Get(routes.RequestIdMetadata); len(ids) > 0 {
+			received <- ids[0]
+		}
+		return next(ctx, req)
+	}))
+	pb.RegisterCollectionServiceServer(server, &collectionServer{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, received
+}
+
+func receive(t *testing.T, received <-chan string) string {
+	select {
+	case id := <-received:
+		return id
+	case <-time.After(time.Second):
+		t.Fatal("downstream service received no request id")
+		return ""
+	}
+}
+
+func newLoggedRouter(conn *grpc.ClientConn) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(routes.RequestLoggingMiddleware())
+	router.GET("/collections/:id", handler.NewCollectionHandler(conn).GetCollectionById)
+	return router
+}
+
+func TestRequestLogging_GeneratesRequestId(t *testing.T) {
+	conn, received := newCollectionConn(t)
+	router := newLoggedRouter(conn)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/1", nil))
+
+	requestId := w.Header().Get(routes.RequestIdHeader)
+	require.NotEmpty(t, requestId)
+	assert.Equal(t, requestId, receive(t, received))
+}
+
+func TestRequestLogging_KeepsIncomingRequestId(t *testing.T) {
+	conn, received := newCollectionConn(t)
+	router := newLoggedRouter(conn)
+
+	req := httptest.NewRequest(http.MethodGet, "/collections/1", nil)
+	req.Header.Set(routes.RequestIdHeader, "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-123", w.Header().Get(routes.RequestIdHeader))
+	assert.Equal(t, "req-123", receive(t, received))
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"net/http"
+	"testing"
+	"time"
+
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUnavailable = status.Error(codes.Unavailable, "connection refused")
+
+func collectionByIdRouter(h *handler.CollectionHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/:id", h.GetCollectionById)
+	return router
+}
+
+func TestRetry_ReadSucceedsAfterTransientFailures(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionById", mock.Anything, mock.Anything).Return(nil, errUnavailable).Twice()
+	client.On("FindCollectionById", mock.Anything, mock.Anything).
+		Return(&pb.Response{Success: true, Message: "found", Collection: []*pb.Collection{{Id: "c1"}}}, nil).Once()
+
+	h := handler.NewCollectionHandlerWithClient(client).
+		WithRetry(handler.RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond})
+	code, body := serve(collectionByIdRouter(h), http.MethodGet, "/collections/c1")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, body.Success)
+	client.AssertNumberOfCalls(t, "FindCollectionById", 3)
+}
+
+func TestRetry_GivesUpAfterConfiguredAttempts(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionById", mock.Anything, mock.Anything).Return(nil, errUnavailable)
+
+	h := handler.NewCollectionHandlerWithClient(client).
+		WithRetry(handler.RetryPolicy{Attempts: 2, BaseDelay: time.Millisecond})
+	code, _ := serve(collectionByIdRouter(h), http.MethodGet, "/collections/c1")
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	client.AssertNumberOfCalls(t, "FindCollectionById", 2)
+}
+
+func TestRetry_NonRetryableCodeFailsImmediately(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionById", mock.Anything, mock.Anything).
+		Return(nil, status.Error(codes.NotFound, "Collection not found"))
+
+	h := handler.NewCollectionHandlerWithClient(client).
+		WithRetry(handler.RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond})
+	code, _ := serve(collectionByIdRouter(h), http.MethodGet, "/collections/c1")
+
+	assert.Equal(t, http.StatusNotFound, code)
+	client.AssertNumberOfCalls(t, "FindCollectionById", 1)
+}
+
+func TestRetry_StopsAtRequestDeadline(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionById", mock.Anything, mock.Anything).Return(nil, errUnavailable)
+
+	// The backoff is longer than the whole request may take
+	h := handler.NewCollectionHandlerWithClient(client).
+		WithTimeout(20 * time.Millisecond).
+		WithRetry(handler.RetryPolicy{Attempts: 5, BaseDelay: time.Second})
+
+	start := time.Now()
+	serve(collectionByIdRouter(h), http.MethodGet, "/collections/c1")
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	client.AssertNumberOfCalls(t, "FindCollectionById", 1)
+}
+
+func TestRetry_WritesAreNotRetried(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("BorrowBook", mock.Anything, mock.Anything).Return(nil, errUnavailable)
+
+	code, _ := postBorrow(client, "")
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	client.AssertNumberOfCalls(t, "BorrowBook", 1)
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHttpStatusFromGrpc(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusOK},
+		{status.Error(codes.NotFound, "missing"), http.StatusNotFound},
+		{status.Error(codes.InvalidArgument, "bad id"), http.StatusBadRequest},
+		{status.Error(codes.AlreadyExists, "duplicate"), http.StatusConflict},
+		{status.Error(codes.FailedPrecondition, "already returned"), http.StatusPreconditionFailed},
+		{status.Error(codes.ResourceExhausted, "Borrow limit reached"), http.StatusTooManyRequests},
+		{status.Error(codes.Internal, "boom"), http.StatusInternalServerError},
+		{status.Error(codes.DeadlineExceeded, "slow"), http.StatusGatewayTimeout},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{errors.New("not a grpc error"), http.StatusInternalServerError},
+		{status.Error(codes.Unavailable, "connection refused"), http.StatusServiceUnavailable},
+		{status.Error(codes.PermissionDenied, "admins only"), http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprint(tc.err), func(t *testing.T) {
+			assert.Equal(t, tc.want, handler.HttpStatusFromGrpc(tc.err))
+		})
+	}
+}
+
+func newCollectionRouter(client *mocks.MockCollectionServiceClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/collections/:id", handler.NewCollectionHandlerWithClient(client).GetCollectionById)
+	return router
+}
+
+func TestGetCollectionById_NotFoundResponseIs404(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionById", mock.Anything, &pb.FindCollectionRequest{Id: "missing"}).
+		Return(&pb.Response{Success: false, Message: "Collection not found"}, nil)
+
+	code, body := serve(newCollectionRouter(client), http.MethodGet, "/collections/missing")
+
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.False(t, body.Success)
+	assert.Equal(t, "Collection not found", body.Message)
+}
+
+func TestGetCollectionById_GrpcCodeMapsToHttp(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("FindCollectionById", mock.Anything, mock.Anything).
+		Return(nil, status.Error(codes.InvalidArgument, "Invalid collection ID"))
+
+	code, body := serve(newCollectionRouter(client), http.MethodGet, "/collections/bad")
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 400, body.Code)
+	assert.Equal(t, "Invalid collection ID", body.Message)
+}
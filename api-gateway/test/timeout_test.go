@@ -0,0 +1,114 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"shared/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Blocks like a hung backend until the caller's deadline passes
+func hangUntilDeadline(args mock.Arguments) {
+	<-args.Get(0).(context.Context).Done()
+}
+
+var errDeadline = status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+
+func serve(router *gin.Engine, method, path string) (int, model.HttpResponse) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+	var body model.HttpResponse
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body
+}
+
+func TestTimeout_HungBackendReturns504(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("GetOverdueBorrows", mock.Anything, mock.Anything).
+		Run(hangUntilDeadline).
+		Return(nil, errDeadline)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handler.NewBorrowHandlerWithClient(client).WithTimeout(20 * time.Millisecond)
+	router.GET("/borrow/overdue", h.GetOverdueBorrows)
+
+	start := time.Now()
+	code, body := serve(router, http.MethodGet, "/borrow/overdue")
+
+	assert.Equal(t, http.StatusGatewayTimeout, code)
+	assert.Equal(t, 504, body.Code)
+	assert.False(t, body.Success)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestTimeout_BatchedCallReturns504(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("GetCollection", mock.Anything, mock.Anything).
+		Run(hangUntilDeadline).
+		Return(nil, errDeadline)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handler.NewCollectionHandlerWithClient(client).WithTimeout(20 * time.Millisecond)
+	router.GET("/collections", h.GetCollection)
+
+	code, body := serve(router, http.MethodGet, "/collections")
+
+	assert.Equal(t, http.StatusGatewayTimeout, code)
+	assert.Equal(t, 504, body.Code)
+}
+
+func TestTimeout_OtherErrorsStay500(t *testing.T) {
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("GetOverdueBorrows", mock.Anything, mock.Anything).
+		Return(nil, status.Error(codes.Internal, "database unavailable"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/borrow/overdue", handler.NewBorrowHandlerWithClient(client).GetOverdueBorrows)
+
+	code, body := serve(router, http.MethodGet, "/borrow/overdue")
+
+	require.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, "database unavailable", body.Message)
+}
+
+func TestTimeout_BatcherCallGetsCallerDeadline(t *testing.T) {
+	cancelled := make(chan struct{})
+	client := &mocks.MockBorrowServiceClient{}
+	client.On("GetBorrows", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			hangUntilDeadline(args)
+			close(cancelled)
+		}).
+		Return(nil, errDeadline)
+
+	batcher := handler.NewBorrowReqBatcher(client, 5*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := batcher.GetBatch(ctx, handler.QueryParams{Limit: 10})
+
+	assert.True(t, handler.IsDeadlineExceeded(err))
+	// The backend call itself is cancelled instead of hanging forever
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("batched backend call was never cancelled")
+	}
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"apigateway/internal/handler"
+	"apigateway/test/mocks"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shared/pkg/grpcutil"
+	"shared/pkg/model"
+	pb "shared/proto/buffer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func postJSON(handlerFunc gin.HandlerFunc, body string) (int, model.HttpResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/create", handlerFunc)
+
+	req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp model.HttpResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestCreateCollection_MissingFieldsIsStructured400(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+
+	code, resp := postJSON(handler.NewCollectionHandlerWithClient(client).CreateCollection, `{"author":"Tolkien","total_books":-1}`)
+
+	require.Equal(t, http.StatusBadRequest, code)
+	assert.False(t, resp.Success)
+	require.Len(t, resp.Data, 1)
+	details := resp.Data[0].(map[string]interface{})
+	assert.Contains(t, details, "name")
+	assert.Contains(t, details, "categories")
+	assert.Contains(t, details, "total_books")
+	assert.NotContains(t, details, "author")
+	assert.NotContains(t, details, "created_at")
+	client.AssertNotCalled(t, "AddCollection", mock.Anything, mock.Anything)
+}
+
+func TestCreateCollection_ValidBodyPassesThrough(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("AddCollection", mock.Anything, mock.MatchedBy(func(req *pb.AddCollectionRequest) bool {
+		return req.Collection.Name == "The Hobbit" && req.Collection.TotalBooks == 2
+	})).Return(&pb.Response{Success: true, Message: "Collection added!"}, nil)
+
+	code, resp := postJSON(handler.NewCollectionHandlerWithClient(client).CreateCollection,
+		`{"name":"The Hobbit","author":"Tolkien","categories":["fantasy"],"total_books":2}`)
+
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Success)
+	client.AssertExpectations(t)
+}
+
+func TestCreateBook_MissingCollectionIdIsStructured400(t *testing.T) {
+	client := &mocks.MockBookServiceClient{}
+
+	code, resp := postJSON(handler.NewBookHandlerWithClient(client).CreateBook, `{}`)
+
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, map[string]interface{}{"collection_id": "collection_id is required"}, resp.Data[0])
+	client.AssertNotCalled(t, "AddBook", mock.Anything, mock.Anything)
+}
+
+func TestCreateCollection_ServiceFieldViolationsAreSurfaced(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("AddCollection", mock.Anything, mock.Anything).
+		Return(nil, grpcutil.InvalidArgumentStatus("Invalid collection", map[string]string{"name": "name must be at most 200 characters"}))
+
+	code, resp := postJSON(handler.NewCollectionHandlerWithClient(client).CreateCollection,
+		`{"name":"The Hobbit","author":"Tolkien","categories":["fantasy"]}`)
+
+	require.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "Invalid collection", resp.Message)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, map[string]interface{}{"name": "name must be at most 200 characters"}, resp.Data[0])
+}
+
+func TestCreateCollection_DuplicateIsConflict(t *testing.T) {
+	body := `{"name":"The Hobbit","author":"Tolkien","categories":["fantasy"]}`
+
+	t.Run("unsuccessful response", func(t *testing.T) {
+		client := &mocks.MockCollectionServiceClient{}
+		client.On("AddCollection", mock.Anything, mock.Anything).
+			Return(&pb.Response{Success: false, Message: "Collection already exists"}, nil)
+
+		code, resp := postJSON(handler.NewCollectionHandlerWithClient(client).CreateCollection, body)
+
+		require.Equal(t, http.StatusConflict, code)
+		assert.False(t, resp.Success)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Equal(t, "Collection already exists", resp.Message)
+	})
+
+	t.Run("already exists status", func(t *testing.T) {
+		client := &mocks.MockCollectionServiceClient{}
+		client.On("AddCollection", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.AlreadyExists, "Collection already exists"))
+
+		code, resp := postJSON(handler.NewCollectionHandlerWithClient(client).CreateCollection, body)
+
+		require.Equal(t, http.StatusConflict, code)
+		assert.False(t, resp.Success)
+		assert.Equal(t, "Collection already exists", resp.Message)
+	})
+}
+
+func TestCreateCollection_UnsuccessfulResponseIsNotOk(t *testing.T) {
+	client := &mocks.MockCollectionServiceClient{}
+	client.On("AddCollection", mock.Anything, mock.Anything).
+		Return(&pb.Response{Success: false, Message: "Something went wrong"}, nil)
+
+	code, resp := postJSON(handler.NewCollectionHandlerWithClient(client).CreateCollection,
+		`{"name":"The Hobbit","author":"Tolkien","categories":["fantasy"]}`)
+
+	require.Equal(t, http.StatusInternalServerError, code)
+	assert.False(t, resp.Success)
+}
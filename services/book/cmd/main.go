@@ -2,8 +2,11 @@ package main
 
 import (
 	"book/internal"
+	"log"
 )
 
 func main() {
-	internal.Setup()
+	if err := internal.Setup(); err != nil {
+		log.Fatalf("book service failed: %v", err)
+	}
 }
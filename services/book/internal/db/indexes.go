@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"shared/config"
+	"shared/pkg/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const CollectionName = config.BookCollectionName
+
+// Serves the available book lookups of a collection
+func IndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "collection_id", Value: 1}, {Key: "is_borrowed", Value: 1}},
+			Options: options.Index().SetName("collection_id_is_borrowed"),
+		},
+	}
+}
+
+func EnsureIndexes(ctx context.Context, database *mongo.Database) error {
+	return repository.EnsureIndexes(ctx, database.Collection(CollectionName), IndexModels())
+}
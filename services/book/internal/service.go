@@ -2,16 +2,20 @@ package internal
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math/rand/v2"
 	"time"
 
+	"shared/config"
+	"shared/pkg/grpcutil"
 	interfaces "shared/pkg/interface"
 	"shared/pkg/model"
 	"shared/pkg/repository"
 	"shared/pkg/service"
 	"shared/pkg/utils"
+	"shared/pkg/worker"
 	pb "shared/proto/buffer"
 
 	"github.com/redis/go-redis/v9"
@@ -21,28 +25,35 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type BookServiceServer struct {
 	pb.UnimplementedBookServiceServer
 	Service          interfaces.ServiceInterface[model.Book, model.BookUpdateRequest]
 	Cache            *redis.Client
+	CacheTTL         config.CacheTTLConfig
 	CollectionClient pb.CollectionServiceClient
+	Background       *worker.Pool // Runs the stock updates that follow a committed write
 }
 
-func NewBookService(database *mongo.Database, collection_name string, connections map[string]*grpc.ClientConn, cache *redis.Client) *BookServiceServer {
+func NewBookService(database *mongo.Database, collection_name string, connections map[string]*grpc.ClientConn, cache *redis.Client, cacheTTL *config.CacheTTLConfig) *BookServiceServer {
 	repository := repository.NewRepository[model.Book](database, collection_name)
 	return &BookServiceServer{
 		Service:          service.NewBaseService[model.Book, model.BookUpdateRequest](repository),
 		Cache:            cache,
+		CacheTTL:         *cacheTTL,
 		CollectionClient: pb.NewCollectionServiceClient(connections["collection"]),
+		Background:       worker.NewPool(worker.DefaultPoolSize),
 	}
 }
 
+// Fields the book indexes can sort on, see db.IndexModels
+var bookSortFields = []string{"_id", "collection_id"}
+
 func (s *BookServiceServer) GetBook(ctx context.Context, in *pb.GetBookRequest) (*pb.BookResponse, error) {
 	// Parse filter and sort from protobuf
 	var filter bson.M
-	var sort bson.D
 
 	if len(in.Filter.Fields) > 0 {
 		filterMap := in.Filter.AsMap()
@@ -54,22 +65,36 @@ func (s *BookServiceServer) GetBook(ctx context.Context, in *pb.GetBookRequest)
 		filter = bson.M{}
 	}
 
-	if len(in.Sort) > 0 {
-		sort = bson.D{}
-		for _, sortItem := range in.Sort {
-			sort = append(sort, bson.E{Key: sortItem.Key, Value: sortItem.Direction})
+	sort, err := grpcutil.BuildSort(in.Sort, bookSortFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.IncludeDeleted {
+		ctx = repository.WithDeleted(ctx)
+	}
+
+	cacheKey, cacheable := s.listCacheKey(ctx, in)
+	if cacheable {
+		if page, ok := utils.GetCachedData[utils.ListPage[model.Book]](ctx, s.Cache, cacheKey); ok {
+			return s.buildListResponse(page), nil
 		}
-	} else {
-		sort = bson.D{}
 	}
 
-	data, err := s.Service.List(ctx, filter, sort, int(in.Skip), int(in.Limit))
+	page := &utils.ListPage[model.Book]{}
+	if in.UseCursor {
+		page.Data, page.NextCursor, err = s.Service.ListCursor(ctx, filter, sort, in.After, int(in.Limit), in.Fields...)
+	} else {
+		page.Data, page.Total, err = s.Service.ListWithTotal(ctx, filter, sort, int(in.Skip), int(in.Limit), in.Fields...)
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	books := model.ToPbBooks(data)
-	return s.buildResponse(true, "Books retrieved successfully", books), nil
+	if cacheable {
+		utils.SetCachedData(ctx, s.Cache, cacheKey, page, s.CacheTTL.ListTTL)
+	}
+	return s.buildListResponse(page), nil
 }
 
 func (s *BookServiceServer) FindBookById(ctx context.Context, in *pb.FindBookRequest) (*pb.BookResponse, error) {
@@ -88,15 +113,7 @@ func (s *BookServiceServer) FindBookById(ctx context.Context, in *pb.FindBookReq
 		book = data
 
 		// Set cache
-		bytes, err := json.Marshal(book)
-		if err != nil {
-			log.Printf("Error packing JSON: %s", err)
-		} else {
-			err = s.Cache.Set(ctx, "book:"+in.Id, bytes, time.Hour).Err()
-			if err != nil {
-				log.Printf("Error setting cache: %v", err)
-			}
-		}
+		utils.SetCachedData(ctx, s.Cache, "book:"+in.Id, book, s.CacheTTL.BookTTL)
 	}
 
 	pbBook := model.ToPbBook(book)
@@ -111,54 +128,63 @@ func (s *BookServiceServer) AddBook(ctx context.Context, in *pb.AddBookRequest)
 
 	Book := model.FromPbBook(in.Book)
 	err := s.Service.Create(ctx, *Book)
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return nil, grpcutil.InvalidArgumentStatus("Invalid book", validationErr.Fields)
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	backgroundCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	go func() {
-		defer cancel()
+	s.invalidateLists(ctx)
+	s.invalidateCollectionBooks(ctx, in.Book.CollectionId)
 
-		retries := 0
-		for retries < 3 {
-			if _, err := s.CollectionClient.DecrementAvailableBooks(backgroundCtx, &pb.DecrementAvailableBooksRequest{
-				Id:     in.Book.CollectionId,
-				Amount: 1,
-			}); err != nil {
-				log.Printf("Failed to update collection stock: %v", err)
-				retries += 1
-			} else {
-				break
-			}
-		}
-	}()
+	// A new book adds to the collection's stock and is free to borrow
+	s.adjustCollectionStock(in.Book.CollectionId, 1, 1)
 
 	return s.buildResponse(true, "Book added!", []*pb.Book{in.Book}), nil
 }
 
 func (s *BookServiceServer) UpdateBook(ctx context.Context, in *pb.UpdateBookRequest) (*pb.BookResponse, error) {
 	update := in.Payload.AsMap()
-	update["updated_at"] = time.Now().UTC().Format(time.RFC3339)
 
+	// A book moving to another collection also leaves the cached books of
+	// the one it was in
+	var previousCollection string
 	if collectionId, ok := update["collection_id"]; ok {
 		collectionId, err := primitive.ObjectIDFromHex(collectionId.(string))
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 		update["collection_id"] = collectionId
+
+		if previous, err := s.Service.Find(ctx, bson.M{"_id": in.Id}); err == nil {
+			previousCollection = previous.CollectionId.Hex()
+		}
 	}
 	delete(update, "id")
 
-	data, err := s.Service.Update(ctx, update, in.Id)
+	var data model.Book
+	var err error
+	if in.IfMatch != "" {
+		data, err = service.UpdateIfMatch(ctx, s.Service, update, in.Id, in.IfMatch)
+	} else {
+		data, err = s.Service.Update(ctx, update, in.Id)
+	}
 
 	if err == mongo.ErrNoDocuments {
 		reply := s.buildResponse(false, "Book not found", nil)
 		return reply, nil
 	}
+	if errors.Is(err, service.ErrStaleETag) {
+		return nil, status.Error(codes.FailedPrecondition, "Book was changed since it was read")
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	s.invalidateCache(ctx, in.Id)
+	s.invalidateLists(ctx)
+	s.invalidateCollectionBooks(ctx, data.CollectionId.Hex(), previousCollection)
 
 	dataPb := model.ToPbBook(&data)
 	if dataPb == nil {
@@ -168,7 +194,7 @@ func (s *BookServiceServer) UpdateBook(ctx context.Context, in *pb.UpdateBookReq
 }
 
 func (s *BookServiceServer) DeleteBook(ctx context.Context, in *pb.DeleteBookRequest) (*pb.BookResponse, error) {
-	data, err := s.Service.Delete(ctx, in.Id)
+	data, err := s.Service.SoftDelete(ctx, in.Id)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return s.buildResponse(false, "Book not found", nil), nil
@@ -176,24 +202,20 @@ func (s *BookServiceServer) DeleteBook(ctx context.Context, in *pb.DeleteBookReq
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	s.invalidateCache(ctx, in.Id)
+	s.invalidateLists(ctx)
+	s.invalidateCollectionBooks(ctx, data.CollectionId.Hex())
 
-	backgroundCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	go func() {
-		defer cancel()
+	// Deleted books must no longer be handed out for borrowing
+	if err := s.Cache.SRem(ctx, "available_books:"+data.CollectionId.Hex(), in.Id).Err(); err != nil {
+		log.Printf("Error deleting cache: %v", err)
+	}
 
-		retries := 0
-		for retries < 3 {
-			if _, err := s.CollectionClient.DecrementAvailableBooks(backgroundCtx, &pb.DecrementAvailableBooksRequest{
-				Id:     data.CollectionId.Hex(),
-				Amount: -1,
-			}); err != nil {
-				log.Printf("Failed to update collection stock: %v", err)
-				retries += 1
-			} else {
-				break
-			}
-		}
-	}()
+	// A borrowed book was already taken out of the available count
+	availableDelta := int32(-1)
+	if data.IsBorrowed {
+		availableDelta = 0
+	}
+	s.adjustCollectionStock(data.CollectionId.Hex(), -1, availableDelta)
 
 	newBook := model.ToPbBook(&data)
 	return s.buildResponse(true, "Book deleted!", []*pb.Book{newBook}), nil
@@ -225,7 +247,7 @@ func (s *BookServiceServer) GetAvailableBook(ctx context.Context, in *pb.GetAvai
 		book = data
 
 		// Set cache
-		err = s.Cache.SAdd(ctx, "available_books:"+in.CollectionId, book.Id.Hex(), time.Hour).Err()
+		err = utils.AddToSet(ctx, s.Cache, "available_books:"+in.CollectionId, s.CacheTTL.AvailableBooksTTL, book.Id.Hex())
 		if err != nil {
 			log.Printf("Error setting cache: %v", err)
 		}
@@ -235,9 +257,78 @@ func (s *BookServiceServer) GetAvailableBook(ctx context.Context, in *pb.GetAvai
 	return s.buildResponse(true, "Books retrieved successfully", []*pb.Book{pbBook}), nil
 }
 
+// Lists every book of a collection that isn't borrowed, sorted by id so pages
+// are stable. Always read from the database, the available_books set only
+// holds the ids a borrow can be served from and is rarely the full list.
+func (s *BookServiceServer) GetAvailableBooks(ctx context.Context, in *pb.GetAvailableBookRequest) (*pb.BookResponse, error) {
+	collectionId, err := primitive.ObjectIDFromHex(in.CollectionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid collection id")
+	}
+
+	data, total, err := s.Service.ListWithTotal(ctx, bson.M{
+		"collection_id": collectionId,
+		"is_borrowed":   false,
+	}, bson.D{{Key: "_id", Value: 1}}, int(in.Skip), int(in.Limit), "_id")
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	books := make([]*pb.Book, 0, len(data))
+	for _, book := range data {
+		books = append(books, model.ToPbBook(&model.Book{
+			Id:           book.Id,
+			CollectionId: collectionId,
+			IsBorrowed:   false,
+		}))
+	}
+
+	response := s.buildResponse(true, "Books retrieved successfully", books)
+	response.Total = total
+	return response, nil
+}
+
+// Lists the books of a collection by id, optionally only the ones that can be
+// borrowed. The first page is cached per collection until a book of it is
+// written.
+func (s *BookServiceServer) GetBooksByCollection(ctx context.Context, in *pb.GetBooksByCollectionRequest) (*pb.BookResponse, error) {
+	collectionId, err := primitive.ObjectIDFromHex(in.CollectionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid collection id")
+	}
+	if in.Skip < 0 || in.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "skip and limit must not be negative")
+	}
+
+	cacheKey, cacheable := s.collectionBooksCacheKey(ctx, in)
+	if cacheable {
+		if page, ok := utils.GetCachedData[utils.ListPage[model.Book]](ctx, s.Cache, cacheKey); ok {
+			return s.buildListResponse(page), nil
+		}
+	}
+
+	filter := bson.M{"collection_id": collectionId}
+	if in.AvailableOnly {
+		filter["is_borrowed"] = false
+	}
+
+	page := &utils.ListPage[model.Book]{}
+	page.Data, page.Total, err = s.Service.ListWithTotal(ctx, filter, bson.D{{Key: "_id", Value: 1}}, int(in.Skip), int(in.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if cacheable {
+		utils.SetCachedData(ctx, s.Cache, cacheKey, page, s.CacheTTL.BookTTL)
+	}
+	return s.buildListResponse(page), nil
+}
+
 func (s *BookServiceServer) CountBook(ctx context.Context, in *pb.CountBookRequest) (*pb.BookCountResponse, error) {
+	cacheKey := "available_count:" + in.CollectionId
+
 	// Check cache first
-	if count, found := utils.GetCachedData[int64](ctx, s.Cache, in.CollectionId); found {
+	if count, found := utils.GetCachedData[int64](ctx, s.Cache, cacheKey); found {
 		return &pb.BookCountResponse{
 			Count:   *count,
 			Success: true,
@@ -245,18 +336,19 @@ func (s *BookServiceServer) CountBook(ctx context.Context, in *pb.CountBookReque
 		}, nil
 	}
 
-	// Compute from books
-	collectionObjId, _ := primitive.ObjectIDFromHex(in.CollectionId)
-	count, err := s.Service.Count(ctx, bson.M{
-		"collection_id": collectionObjId,
-	})
+	collectionObjId, err := primitive.ObjectIDFromHex(in.CollectionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid collection id")
+	}
 
+	// Compute from books
+	count, err := s.CountAvailableBooks(ctx, collectionObjId)
 	if err != nil {
 		return nil, err
 	}
 
 	// Cache result
-	s.Cache.Set(ctx, "available_count:"+in.CollectionId, int(count), time.Hour)
+	utils.SetCachedData(ctx, s.Cache, cacheKey, count, s.CacheTTL.AvailableBooksTTL)
 	return &pb.BookCountResponse{
 		Count:   count,
 		Success: true,
@@ -264,16 +356,39 @@ func (s *BookServiceServer) CountBook(ctx context.Context, in *pb.CountBookReque
 	}, nil
 }
 
-func (s *BookServiceServer) BulkInsert(ctx context.Context, in *pb.BulkInsertBookRequest) (*pb.BookResponse, error) {
-	// log.Println(in.Books[0])
-	// for _, book := range in.Books {
-	// 	currTime := time.Now().UTC().Format(time.RFC3339)
-	// 	book.Id = primitive.NewObjectID().Hex()
-	// 	book.CreatedAt = currTime
-	// 	book.UpdatedAt = currTime
-	// }
+// Counts the books of a collection that are not currently borrowed
+func (s *BookServiceServer) CountAvailableBooks(ctx context.Context, collectionId primitive.ObjectID) (int64, error) {
+	return s.Service.Count(ctx, bson.M{
+		"collection_id": collectionId,
+		"is_borrowed":   false,
+	})
+}
 
-	// log.Println(in.Books[0].CollectionId, in.Books[0].IsBorrowed)
+func (s *BookServiceServer) BulkInsert(ctx context.Context, in *pb.BulkInsertBookRequest) (*pb.BookResponse, error) {
+	// Fill in what the caller left out, a book that can't be converted would
+	// otherwise come back from FromPbBooks as nil
+	currTime := time.Now().UTC().Format(time.RFC3339)
+	violations := map[string]string{}
+	for i, book := range in.Books {
+		if _, err := primitive.ObjectIDFromHex(book.CollectionId); err != nil {
+			violations[fmt.Sprintf("books[%d].collection_id", i)] = "collection_id must be a valid id"
+		}
+		if _, err := primitive.ObjectIDFromHex(book.Id); err != nil {
+			book.Id = primitive.NewObjectID().Hex()
+		}
+		if _, err := time.Parse(time.RFC3339, book.CreatedAt); err != nil {
+			book.CreatedAt = currTime
+		}
+		if _, err := time.Parse(time.RFC3339, book.UpdatedAt); err != nil {
+			book.UpdatedAt = currTime
+		}
+		if book.IsBorrowed == nil {
+			book.IsBorrowed = wrapperspb.Bool(false)
+		}
+	}
+	if len(violations) > 0 {
+		return nil, grpcutil.InvalidArgumentStatus("Invalid books", violations)
+	}
 
 	booksPtr := model.FromPbBooks(in.Books)
 	books := make([]model.Book, len(booksPtr))
@@ -286,10 +401,360 @@ func (s *BookServiceServer) BulkInsert(ctx context.Context, in *pb.BulkInsertBoo
 		log.Printf("error bulk insert: %v", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	s.invalidateLists(ctx)
+	collectionIds := make([]string, 0, len(in.Books))
+	for _, book := range in.Books {
+		collectionIds = append(collectionIds, book.CollectionId)
+	}
+	s.invalidateCollectionBooks(ctx, collectionIds...)
 
 	return s.buildResponse(true, "Book added!", in.Books), nil
 }
 
+// Deletes the books matching the given ids and/or collection in one call.
+// Deleting by collection is used when the collection itself is deleted, so
+// only deletes by id adjust the stock counters of the books' collections.
+func (s *BookServiceServer) BulkDelete(ctx context.Context, in *pb.BulkDeleteBookRequest) (*pb.BookResponse, error) {
+	filter := bson.M{}
+	if in.CollectionId != "" {
+		collectionId, err := primitive.ObjectIDFromHex(in.CollectionId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid collection ID")
+		}
+		filter["collection_id"] = collectionId
+	}
+	if len(in.Ids) > 0 {
+		ids := make([]primitive.ObjectID, len(in.Ids))
+		for i, id := range in.Ids {
+			objId, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Invalid book ID %q", id)
+			}
+			ids[i] = objId
+		}
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	if len(filter) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Either ids or a collection ID is required")
+	}
+
+	// Read the matching books first to know which cache entries they have
+	books, err := s.Service.List(ctx, filter, nil, 0, 0, "_id", "collection_id", "is_borrowed")
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	count, err := s.Service.BulkDelete(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	keys := []string{}
+	if in.CollectionId != "" {
+		keys = append(keys, "available_books:"+in.CollectionId, "available_count:"+in.CollectionId)
+	}
+	type stockDelta struct{ total, available int32 }
+	deltas := map[string]*stockDelta{}
+	for _, book := range books {
+		keys = append(keys, "book:"+book.Id.Hex())
+
+		if in.CollectionId == "" {
+			collectionId := book.CollectionId.Hex()
+			if err := s.Cache.SRem(ctx, "available_books:"+collectionId, book.Id.Hex()).Err(); err != nil {
+				log.Printf("Error deleting cache: %v", err)
+			}
+			if deltas[collectionId] == nil {
+				deltas[collectionId] = &stockDelta{}
+				keys = append(keys, "available_count:"+collectionId)
+			}
+			deltas[collectionId].total--
+			// A borrowed book was already taken out of the available count
+			if !book.IsBorrowed {
+				deltas[collectionId].available--
+			}
+		}
+	}
+	utils.InvalidateCache(ctx, s.Cache, keys...)
+	s.invalidateLists(ctx)
+	collectionIds := []string{in.CollectionId}
+	for collectionId := range deltas {
+		collectionIds = append(collectionIds, collectionId)
+	}
+	s.invalidateCollectionBooks(ctx, collectionIds...)
+
+	for collectionId, delta := range deltas {
+		s.adjustCollectionStock(collectionId, delta.total, delta.available)
+	}
+
+	return s.buildResponse(true, fmt.Sprintf("%d books deleted", count), nil), nil
+}
+
+// Fields UpdateBooks may match on, anything else could scan the whole collection
+var updateBooksFilterFields = map[string]bool{"_id": true, "collection_id": true, "is_borrowed": true}
+
+// Sets the same fields on every book matching the filter, e.g. to reset the
+// borrow flags of a collection. Meant for admin clean-ups, the gateway only
+// exposes it behind the admin token. Books that change collection or borrow
+// state move the stock counters of their collections like single writes do.
+func (s *BookServiceServer) UpdateBooks(ctx context.Context, in *pb.UpdateBooksRequest) (*pb.UpdateBooksResponse, error) {
+	filter := bson.M{}
+	for key, value := range in.GetFilter().AsMap() {
+		if !updateBooksFilterFields[key] {
+			return nil, grpcutil.InvalidArgumentStatus("Invalid filter", map[string]string{
+				key: key + " can't be filtered on, use one of: _id collection_id is_borrowed",
+			})
+		}
+		filter[key] = value
+	}
+	if len(filter) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "A filter is required")
+	}
+	for _, key := range []string{"_id", "collection_id"} {
+		switch value := filter[key].(type) {
+		case string:
+			objectId, err := primitive.ObjectIDFromHex(value)
+			if err != nil {
+				return nil, grpcutil.InvalidArgumentStatus("Invalid filter", map[string]string{key: key + " must be a valid id"})
+			}
+			filter[key] = objectId
+		case []interface{}:
+			// A list matches any of its ids
+			objectIds := make([]primitive.ObjectID, 0, len(value))
+			for _, item := range value {
+				hex, _ := item.(string)
+				objectId, err := primitive.ObjectIDFromHex(hex)
+				if err != nil {
+					return nil, grpcutil.InvalidArgumentStatus("Invalid filter", map[string]string{key: key + " must be a list of valid ids"})
+				}
+				objectIds = append(objectIds, objectId)
+			}
+			filter[key] = bson.M{"$in": objectIds}
+		}
+	}
+
+	update := in.GetPayload().AsMap()
+	delete(update, "id")
+	delete(update, "_id")
+	if len(update) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Nothing to update")
+	}
+	if value, ok := update["collection_id"].(string); ok {
+		collectionId, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, grpcutil.InvalidArgumentStatus("Invalid update", map[string]string{"collection_id": "collection_id must be a valid id"})
+		}
+		update["collection_id"] = collectionId
+	}
+
+	// Read the matching books first to know which counters and cache entries
+	// the update touches
+	books, err := s.Service.List(ctx, filter, nil, 0, 0, "_id", "collection_id", "is_borrowed")
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	modified, err := s.Service.UpdateMany(ctx, filter, update)
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return nil, grpcutil.InvalidArgumentStatus("Invalid update", validationErr.Fields)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	type stockDelta struct{ total, available int32 }
+	deltas := map[string]*stockDelta{}
+	move := func(collectionId string, total, available int32) {
+		if deltas[collectionId] == nil {
+			deltas[collectionId] = &stockDelta{}
+		}
+		deltas[collectionId].total += total
+		deltas[collectionId].available += available
+	}
+	keys := []string{}
+	collectionIds := []string{}
+	for _, book := range books {
+		keys = append(keys, "book:"+book.Id.Hex())
+		collectionIds = append(collectionIds, book.CollectionId.Hex())
+
+		collectionId, isBorrowed := book.CollectionId, book.IsBorrowed
+		if value, ok := update["collection_id"].(primitive.ObjectID); ok {
+			collectionId = value
+		}
+		if value, ok := update["is_borrowed"].(bool); ok {
+			isBorrowed = value
+		}
+
+		if collectionId != book.CollectionId || isBorrowed != book.IsBorrowed {
+			move(book.CollectionId.Hex(), -1, availableCount(book.IsBorrowed)*-1)
+			move(collectionId.Hex(), 1, availableCount(isBorrowed))
+		}
+	}
+	for collectionId := range deltas {
+		keys = append(keys, "available_books:"+collectionId, "available_count:"+collectionId)
+	}
+	if value, ok := update["collection_id"].(primitive.ObjectID); ok && len(books) > 0 {
+		collectionIds = append(collectionIds, value.Hex())
+	}
+	utils.InvalidateCache(ctx, s.Cache, keys...)
+	s.invalidateLists(ctx)
+	s.invalidateCollectionBooks(ctx, collectionIds...)
+
+	for collectionId, delta := range deltas {
+		if delta.total != 0 || delta.available != 0 {
+			s.adjustCollectionStock(collectionId, delta.total, delta.available)
+		}
+	}
+
+	return &pb.UpdateBooksResponse{
+		Success:  true,
+		Message:  fmt.Sprintf("%d books updated", modified),
+		Modified: modified,
+	}, nil
+}
+
+// How much a book adds to its collection's available count
+func availableCount(isBorrowed bool) int32 {
+	if isBorrowed {
+		return 0
+	}
+	return 1
+}
+
+// Updates the collection's book counters in the background, retrying a few
+// times since the book write has already been committed. Updates that still
+// fail, or that arrive while the service is shutting down, are queued in the
+// stock DLQ for ReplayDLQ.
+func (s *BookServiceServer) adjustCollectionStock(collectionId string, totalDelta, availableDelta int32) {
+	deadLetter := func(err error) {
+		utils.PushDeadLetter(context.Background(), s.Cache, utils.StockDLQKey, utils.StockOperation{
+			Op:             utils.OpAdjustBookStock,
+			CollectionId:   collectionId,
+			TotalDelta:     totalDelta,
+			AvailableDelta: availableDelta,
+			Error:          err.Error(),
+			FailedAt:       time.Now().UTC(),
+		})
+	}
+
+	err := s.Background.Go(func() {
+		backgroundCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var err error
+		for range 3 {
+			if err = s.callAdjustBookStock(backgroundCtx, collectionId, totalDelta, availableDelta); err == nil {
+				return
+			}
+			log.Printf("Failed to update collection stock: %v", err)
+		}
+		deadLetter(err)
+	})
+	if err != nil {
+		deadLetter(err)
+	}
+}
+
+func (s *BookServiceServer) callAdjustBookStock(ctx context.Context, collectionId string, totalDelta, availableDelta int32) error {
+	res, err := s.CollectionClient.AdjustBookStock(ctx, &pb.AdjustBookStockRequest{
+		Id:             collectionId,
+		TotalDelta:     totalDelta,
+		AvailableDelta: availableDelta,
+	})
+	if err != nil {
+		return err
+	}
+	if !res.Success {
+		return errors.New(res.Message)
+	}
+	return nil
+}
+
+// Drains the entries queued in the stock DLQ when this call starts and runs
+// each one again. Entries that fail again go back to the end of the queue.
+func (s *BookServiceServer) ReplayDLQ(ctx context.Context) (replayed, failed int, err error) {
+	pending, err := s.Cache.LLen(ctx, utils.StockDLQKey).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for range pending {
+		op, ok, err := utils.PopDeadLetter[utils.StockOperation](ctx, s.Cache, utils.StockDLQKey)
+		if err != nil {
+			return replayed, failed, err
+		}
+		if !ok {
+			break
+		}
+		if op == nil {
+			continue
+		}
+
+		if err := s.replayStockOperation(ctx, op); err != nil {
+			log.Printf("Failed to replay %s for collection %s: %v", op.Op, op.CollectionId, err)
+			op.Error = err.Error()
+			op.FailedAt = time.Now().UTC()
+			if err := utils.PushDeadLetter(ctx, s.Cache, utils.StockDLQKey, *op); err != nil {
+				return replayed, failed, err
+			}
+			failed++
+			continue
+		}
+		replayed++
+	}
+	return replayed, failed, nil
+}
+
+func (s *BookServiceServer) replayStockOperation(ctx context.Context, op *utils.StockOperation) error {
+	switch op.Op {
+	case utils.OpAdjustBookStock:
+		return s.callAdjustBookStock(ctx, op.CollectionId, op.TotalDelta, op.AvailableDelta)
+	case utils.OpCreateCollectionBooks:
+		// BulkInsert fills in the ids and timestamps
+		books := make([]*pb.Book, op.BookCount)
+		for i := range books {
+			books[i] = &pb.Book{CollectionId: op.CollectionId}
+		}
+		if _, err := s.BulkInsert(ctx, &pb.BulkInsertBookRequest{Books: books}); err != nil {
+			return err
+		}
+		// The books exist now, a failure here only leaves the status stale and
+		// must not queue the create again
+		if _, err := s.CollectionClient.SetSeedStatus(ctx, &pb.SetSeedStatusRequest{
+			Id:         op.CollectionId,
+			SeedStatus: model.SeedStatusComplete,
+		}); err != nil {
+			log.Printf("Failed to mark collection %s as seeded: %v", op.CollectionId, err)
+		}
+		return nil
+	case utils.OpDeleteCollectionBooks:
+		_, err := s.BulkDelete(ctx, &pb.BulkDeleteBookRequest{CollectionId: op.CollectionId})
+		return err
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+func (s *BookServiceServer) ReplayStockDLQ(ctx context.Context, in *pb.ReplayStockDLQRequest) (*pb.ReplayStockDLQResponse, error) {
+	replayed, failed, err := s.ReplayDLQ(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	remaining, err := s.Cache.LLen(ctx, utils.StockDLQKey).Result()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ReplayStockDLQResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("%d operations replayed, %d failed", replayed, failed),
+		Replayed:  int32(replayed),
+		Failed:    int32(failed),
+		Remaining: remaining,
+	}, nil
+}
+
 func (s *BookServiceServer) buildResponse(success bool, message string, collections []*pb.Book) *pb.BookResponse {
 	return &pb.BookResponse{
 		Success: success,
@@ -298,6 +763,64 @@ func (s *BookServiceServer) buildResponse(success bool, message string, collecti
 	}
 }
 
+func (s *BookServiceServer) buildListResponse(page *utils.ListPage[model.Book]) *pb.BookResponse {
+	response := s.buildResponse(true, "Books retrieved successfully", model.ToPbBooks(page.Data))
+	response.Total = page.Total
+	response.NextCursor = page.NextCursor
+	return response
+}
+
+// Key of the cached page for a list request, false when list caching is off
+// or the key can't be built
+func (s *BookServiceServer) listCacheKey(ctx context.Context, in *pb.GetBookRequest) (string, bool) {
+	if s.CacheTTL.ListTTL <= 0 {
+		return "", false
+	}
+	key, err := utils.ListCacheKey(ctx, s.Cache, "books", in)
+	if err != nil {
+		log.Printf("Error building list cache key: %v", err)
+		return "", false
+	}
+	return key, true
+}
+
+// Drops every cached book list page, called after any write to books
+func (s *BookServiceServer) invalidateLists(ctx context.Context) {
+	if s.CacheTTL.ListTTL > 0 {
+		utils.InvalidateLists(ctx, s.Cache, "books")
+	}
+}
+
+// Prefix of the cached GetBooksByCollection pages of a collection
+const collectionBooksPrefix = "collection_books:"
+
+// Key of the cached page for a GetBooksByCollection request, only the first
+// page is cached
+func (s *BookServiceServer) collectionBooksCacheKey(ctx context.Context, in *pb.GetBooksByCollectionRequest) (string, bool) {
+	if in.Skip > 0 {
+		return "", false
+	}
+	key, err := utils.ListCacheKey(ctx, s.Cache, collectionBooksPrefix+in.CollectionId, in)
+	if err != nil {
+		log.Printf("Error building collection books cache key: %v", err)
+		return "", false
+	}
+	return key, true
+}
+
+// Drops the cached GetBooksByCollection pages of every given collection, called
+// after a write to any of their books
+func (s *BookServiceServer) invalidateCollectionBooks(ctx context.Context, collectionIds ...string) {
+	seen := map[string]bool{}
+	for _, collectionId := range collectionIds {
+		if collectionId == "" || seen[collectionId] {
+			continue
+		}
+		seen[collectionId] = true
+		utils.InvalidateLists(ctx, s.Cache, collectionBooksPrefix+collectionId)
+	}
+}
+
 func (s *BookServiceServer) getCachedAvailableBook(ctx context.Context, collectionId string) (*model.Book, bool) {
 	books, err := s.Cache.SMembers(ctx, "available_books:"+collectionId).Result()
 
@@ -339,9 +862,5 @@ func (s *BookServiceServer) getCachedBook(ctx context.Context, id string) (*mode
 }
 
 func (s *BookServiceServer) invalidateCache(ctx context.Context, id string) {
-	// Invalidate cache
-	err := s.Cache.Del(ctx, "book:"+id).Err()
-	if err != nil {
-		log.Printf("Error deleting cache: %v", err)
-	}
+	utils.InvalidateCache(ctx, s.Cache, "book:"+id)
 }
@@ -9,7 +9,13 @@ import (
 	"testing"
 	"time"
 
+	"shared/config"
+	"shared/pkg/grpcutil"
 	"shared/pkg/model"
+	"shared/pkg/repository"
+	"shared/pkg/service"
+	"shared/pkg/utils"
+	"shared/pkg/worker"
 	pb "shared/proto/buffer"
 
 	"github.com/alicebob/miniredis/v2"
@@ -37,7 +43,9 @@ func newServer(cache *redis.Client) (*mocks.MockService[model.Book, model.BookUp
 	svc := &internal.BookServiceServer{
 		Service:          mockService,
 		Cache:            cache,
+		CacheTTL:         *config.DefaultCacheTTLConfig(),
 		CollectionClient: mocks.NewMockCollectionService(cache),
+		Background:       worker.NewPool(worker.DefaultPoolSize),
 	}
 
 	return mockService, svc
@@ -50,7 +58,7 @@ func TestGetBook_Success(t *testing.T) {
 	// Arrange
 	ctx := context.Background()
 	mockData := []model.Book{{Id: primitive.NewObjectID(), CollectionId: primitive.NewObjectID(), IsBorrowed: false}}
-	mockBaseService.On("List", ctx).Return(mockData, nil)
+	mockBaseService.On("ListWithTotal", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockData, int64(25), nil)
 
 	filterMap := map[string]interface{}{}
 	filter, err := structpb.NewStruct(filterMap)
@@ -71,6 +79,87 @@ func TestGetBook_Success(t *testing.T) {
 	assert.True(t, resp.Success)
 	// assert.Equal(t, "Books retrieved successfully", resp.Message)
 	assert.NotEmpty(t, resp.Book)
+	assert.Equal(t, int64(25), resp.Total)
+}
+
+func TestGetBook_IndexedSortPassesThrough(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+	ctx := context.Background()
+
+	expectedSort := bson.D{{Key: "collection_id", Value: int32(1)}, {Key: "_id", Value: int32(-1)}}
+	mockBaseService.On("ListWithTotal", ctx, mock.Anything, expectedSort, mock.Anything, mock.Anything).Return([]model.Book{}, int64(0), nil).Once()
+
+	resp, err := mockService.GetBook(ctx, &pb.GetBookRequest{
+		Filter: &structpb.Struct{},
+		Sort:   []*pb.Sort{{Key: "collection_id", Direction: 1}, {Key: "_id", Direction: -1}},
+		Limit:  10,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	mockBaseService.AssertExpectations(t)
+}
+
+func TestGetBook_RejectsUnindexedSort(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+
+	resp, err := mockService.GetBook(context.Background(), &pb.GetBookRequest{
+		Filter: &structpb.Struct{},
+		Sort:   []*pb.Sort{{Key: "title", Direction: 1}},
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, grpcutil.FieldViolations(err), "title")
+	mockBaseService.AssertNotCalled(t, "ListWithTotal", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetBook_ListCachedUntilWrite(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	mockService.CacheTTL.ListTTL = time.Minute
+
+	ctx := context.Background()
+	mockData := []model.Book{{Id: primitive.NewObjectID(), CollectionId: primitive.NewObjectID()}}
+	mockBaseService.On("ListWithTotal", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockData, int64(1), nil).Twice()
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).
+		On("AdjustBookStock", mock.Anything, mock.Anything).Return(&pb.Response{Success: true}, nil)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{"is_borrowed": false})
+	require.NoError(t, err)
+	request := &pb.GetBookRequest{Filter: filter, Limit: 10}
+
+	// A second identical list is served from cache
+	first, err := mockService.GetBook(ctx, request)
+	require.NoError(t, err)
+	second, err := mockService.GetBook(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, first.Book[0].Id, second.Book[0].Id)
+	assert.Equal(t, int64(1), second.Total)
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 1)
+
+	// A create busts every cached page
+	_, err = mockService.AddBook(ctx, &pb.AddBookRequest{Book: &pb.Book{CollectionId: primitive.NewObjectID().Hex(), IsBorrowed: wrapperspb.Bool(false)}})
+	require.NoError(t, err)
+	_, err = mockService.GetBook(ctx, request)
+	require.NoError(t, err)
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 2)
+}
+
+func TestGetBook_ListCacheOffByDefault(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+
+	ctx := context.Background()
+	mockBaseService.On("ListWithTotal", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]model.Book{}, int64(0), nil)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := mockService.GetBook(ctx, &pb.GetBookRequest{Filter: filter, Limit: 10})
+		require.NoError(t, err)
+	}
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 2)
 }
 
 func TestGetBook_Error(t *testing.T) {
@@ -78,7 +167,7 @@ func TestGetBook_Error(t *testing.T) {
 	mockBaseService, mockService := newServer(cache)
 
 	ctx := context.Background()
-	mockBaseService.On("List", ctx).Return(nil, errors.New("db error"))
+	mockBaseService.On("ListWithTotal", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("db error"))
 
 	filterMap := map[string]interface{}{}
 	filter, err := structpb.NewStruct(filterMap)
@@ -99,6 +188,56 @@ func TestGetBook_Error(t *testing.T) {
 	assert.Equal(t, codes.Internal, status.Code(err))
 }
 
+func TestGetBook_CursorLastPage(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	ctx := context.Background()
+	mockData := []model.Book{{Id: primitive.NewObjectID(), CollectionId: primitive.NewObjectID(), IsBorrowed: false}}
+	mockBaseService.On("ListCursor", ctx, "", 10).Return(mockData, "", nil)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{})
+	require.NoError(t, err)
+
+	resp, err := mockService.GetBook(ctx, &pb.GetBookRequest{
+		Filter:    filter,
+		Limit:     10,
+		UseCursor: true,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Book, 1)
+	assert.Empty(t, resp.NextCursor)
+}
+
+func TestGetBook_IncludeDeleted(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	deletedAt := time.Now().UTC()
+	active := model.Book{Id: primitive.NewObjectID(), CollectionId: primitive.NewObjectID()}
+	deleted := model.Book{Id: primitive.NewObjectID(), CollectionId: active.CollectionId, DeletedAt: &deletedAt}
+
+	mockBaseService.On("ListWithTotal", mock.MatchedBy(func(ctx context.Context) bool {
+		return !repository.IncludesDeleted(ctx)
+	}), mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]model.Book{active}, int64(1), nil)
+	mockBaseService.On("ListWithTotal", mock.MatchedBy(repository.IncludesDeleted), mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]model.Book{active, deleted}, int64(2), nil)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{})
+	require.NoError(t, err)
+
+	resp, err := mockService.GetBook(context.Background(), &pb.GetBookRequest{Filter: filter, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, resp.Book, 1)
+	assert.Empty(t, resp.Book[0].DeletedAt)
+
+	resp, err = mockService.GetBook(context.Background(), &pb.GetBookRequest{Filter: filter, Limit: 10, IncludeDeleted: true})
+	require.NoError(t, err)
+	require.Len(t, resp.Book, 2)
+	assert.NotEmpty(t, resp.Book[1].DeletedAt)
+}
+
 func TestFindBookById_CacheMissThenSet(t *testing.T) {
 	cache := newRedis(t)
 	mockBaseService, mockService := newServer(cache)
@@ -130,7 +269,7 @@ func TestAddBook_Success(t *testing.T) {
 	collectionId := primitive.NewObjectID()
 
 	// seed cache with collection having AvailableBooks=5
-	seed := &model.Collection{Id: mustOID(collectionId.Hex()), TotalBooks: 5}
+	seed := &model.Collection{Id: mustOID(collectionId.Hex()), TotalBooks: 5, AvailableBooks: 5}
 	raw, _ := json.Marshal(seed)
 	require.NoError(t, cache.Set(context.Background(), "collection:"+collectionId.Hex(), raw, time.Hour).Err())
 
@@ -138,11 +277,12 @@ func TestAddBook_Success(t *testing.T) {
 
 	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
 	mockService.CollectionClient.(*mocks.MockCollectionService).On(
-		"DecrementAvailableBooks",
+		"AdjustBookStock",
 		mock.AnythingOfType("*context.timerCtx"),
-		&pb.DecrementAvailableBooksRequest{
-			Id:     collectionId.Hex(),
-			Amount: 1,
+		&pb.AdjustBookStockRequest{
+			Id:             collectionId.Hex(),
+			TotalDelta:     1,
+			AvailableDelta: 1,
 		},
 	).Return(&pb.Response{Success: true}, nil)
 
@@ -152,19 +292,233 @@ func TestAddBook_Success(t *testing.T) {
 	// assert.Equal(t, )
 	// assert.Equal(t, "Book added!", resp.Message)
 
-	// Wait a bit for the goroutine to complete
-	time.Sleep(100 * time.Millisecond)
+	// Wait for the background stock update to finish
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
 
 	out, err := cache.Get(context.Background(), "collection:"+collectionId.Hex()).Bytes()
 	require.NoError(t, err)
 	var cached model.Collection
 	require.NoError(t, json.Unmarshal(out, &cached))
 	assert.Equal(t, 6, cached.TotalBooks)
+	assert.Equal(t, 6, cached.AvailableBooks)
 
 	// Verify that the mock was called as expected
 	mockService.CollectionClient.(*mocks.MockCollectionService).AssertExpectations(t)
 }
 
+func TestAddBook_ExhaustedStockUpdateIsReplayed(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID().Hex()
+	seed, _ := json.Marshal(&model.Collection{Id: mustOID(collectionId), TotalBooks: 5, AvailableBooks: 5})
+	require.NoError(t, cache.Set(ctx, "collection:"+collectionId, seed, time.Hour).Err())
+
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+	collectionClient := mockService.CollectionClient.(*mocks.MockCollectionService)
+	request := &pb.AdjustBookStockRequest{Id: collectionId, TotalDelta: 1, AvailableDelta: 1}
+	collectionClient.On("AdjustBookStock", mock.Anything, request).
+		Return((*pb.Response)(nil), errors.New("collection service unavailable")).Times(3)
+
+	_, err := mockService.AddBook(ctx, &pb.AddBookRequest{Book: &pb.Book{CollectionId: collectionId, IsBorrowed: wrapperspb.Bool(false)}})
+	require.NoError(t, err)
+
+	// All three attempts fail, so the update is parked in the DLQ
+	require.Eventually(t, func() bool {
+		return cache.LLen(ctx, utils.StockDLQKey).Val() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	raw, err := cache.LIndex(ctx, utils.StockDLQKey, 0).Bytes()
+	require.NoError(t, err)
+	var op utils.StockOperation
+	require.NoError(t, json.Unmarshal(raw, &op))
+	assert.Equal(t, utils.OpAdjustBookStock, op.Op)
+	assert.Equal(t, collectionId, op.CollectionId)
+	assert.Equal(t, int32(1), op.TotalDelta)
+	assert.Equal(t, int32(1), op.AvailableDelta)
+	assert.Equal(t, "collection service unavailable", op.Error)
+
+	// A replay while the collection service is still down keeps the entry
+	collectionClient.On("AdjustBookStock", mock.Anything, request).
+		Return((*pb.Response)(nil), errors.New("collection service unavailable")).Once()
+	resp, err := mockService.ReplayStockDLQ(ctx, &pb.ReplayStockDLQRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), resp.Replayed)
+	assert.Equal(t, int32(1), resp.Failed)
+	assert.Equal(t, int64(1), resp.Remaining)
+
+	// Once it's back the replay re-invokes the collection client and drains the queue
+	collectionClient.On("AdjustBookStock", mock.Anything, request).
+		Return(&pb.Response{Success: true}, nil).Once()
+	replayed, failed, err := mockService.ReplayDLQ(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Zero(t, failed)
+	assert.Zero(t, cache.LLen(ctx, utils.StockDLQKey).Val())
+	collectionClient.AssertNumberOfCalls(t, "AdjustBookStock", 5)
+	collectionClient.AssertExpectations(t)
+}
+
+func TestBulkInsert_FillsIdsAndTimestamps(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+	collectionId := primitive.NewObjectID()
+	existingId := primitive.NewObjectID()
+
+	var inserted []model.Book
+	mockBaseService.On("BulkInsert", mockAnyCtx(), mock.Anything).
+		Run(func(args mock.Arguments) { inserted = args.Get(1).([]model.Book) }).
+		Return(nil).Once()
+
+	resp, err := mockService.BulkInsert(context.Background(), &pb.BulkInsertBookRequest{Books: []*pb.Book{
+		{CollectionId: collectionId.Hex()},
+		{CollectionId: collectionId.Hex(), IsBorrowed: wrapperspb.Bool(true)},
+		{Id: existingId.Hex(), CollectionId: collectionId.Hex()},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.Book, 3)
+	require.Len(t, inserted, 3)
+
+	for _, book := range inserted {
+		assert.False(t, book.Id.IsZero())
+		assert.Equal(t, collectionId, book.CollectionId)
+		assert.WithinDuration(t, time.Now(), book.CreatedAt, 2*time.Second)
+		assert.WithinDuration(t, time.Now(), book.UpdatedAt, 2*time.Second)
+	}
+	assert.NotEqual(t, inserted[0].Id, inserted[1].Id)
+	assert.False(t, inserted[0].IsBorrowed)
+	assert.True(t, inserted[1].IsBorrowed)
+	// Ids the caller picked are kept
+	assert.Equal(t, existingId, inserted[2].Id)
+	assert.Equal(t, inserted[0].Id.Hex(), resp.Book[0].Id)
+	assert.NotEmpty(t, resp.Book[0].CreatedAt)
+}
+
+func TestBulkInsert_RejectsInvalidCollectionId(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+
+	_, err := mockService.BulkInsert(context.Background(), &pb.BulkInsertBookRequest{Books: []*pb.Book{
+		{CollectionId: primitive.NewObjectID().Hex()},
+		{CollectionId: "not-an-id"},
+		{},
+	}})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{
+		"books[1].collection_id": "collection_id must be a valid id",
+		"books[2].collection_id": "collection_id must be a valid id",
+	}, grpcutil.FieldViolations(err))
+	mockBaseService.AssertNotCalled(t, "BulkInsert", mock.Anything, mock.Anything)
+}
+
+func TestReplayDLQ_CreatesBooksAndMarksCollectionSeeded(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID().Hex()
+	require.NoError(t, utils.PushDeadLetter(ctx, cache, utils.StockDLQKey, utils.StockOperation{
+		Op:           utils.OpCreateCollectionBooks,
+		CollectionId: collectionId,
+		BookCount:    3,
+	}))
+
+	mockBaseService.On("BulkInsert", mockAnyCtx(), mock.MatchedBy(func(books []model.Book) bool {
+		return len(books) == 3 && books[0].CollectionId.Hex() == collectionId
+	})).Return(nil).Once()
+	collectionClient := mockService.CollectionClient.(*mocks.MockCollectionService)
+	collectionClient.On("SetSeedStatus", mock.Anything, &pb.SetSeedStatusRequest{
+		Id:         collectionId,
+		SeedStatus: model.SeedStatusComplete,
+	}).Return(nil, errors.New("collection service unavailable")).Once()
+
+	// A stale status is logged, queueing the create again would duplicate the books
+	replayed, failed, err := mockService.ReplayDLQ(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Zero(t, failed)
+	assert.Zero(t, cache.LLen(ctx, utils.StockDLQKey).Val())
+	mockBaseService.AssertExpectations(t)
+	collectionClient.AssertExpectations(t)
+}
+
+func TestShutdown_WaitsForPendingStockUpdates(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID().Hex()
+	seed, _ := json.Marshal(&model.Collection{Id: mustOID(collectionId), TotalBooks: 5, AvailableBooks: 5})
+	require.NoError(t, cache.Set(ctx, "collection:"+collectionId, seed, time.Hour).Err())
+
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+	release := make(chan struct{})
+	collectionClient := mockService.CollectionClient.(*mocks.MockCollectionService)
+	collectionClient.On("AdjustBookStock", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { <-release }).
+		Return(&pb.Response{Success: true}, nil).Once()
+
+	book := &pb.AddBookRequest{Book: &pb.Book{CollectionId: collectionId, IsBorrowed: wrapperspb.Bool(false)}}
+	_, err := mockService.AddBook(ctx, book)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- mockService.Background.Shutdown(ctx) }()
+
+	select {
+	case <-done:
+		t.Fatal("shutdown returned while a stock update was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-done)
+	collectionClient.AssertExpectations(t)
+
+	// Writes that land after shutdown started park their update in the DLQ
+	_, err = mockService.AddBook(ctx, book)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cache.LLen(ctx, utils.StockDLQKey).Val())
+	collectionClient.AssertNumberOfCalls(t, "AdjustBookStock", 1)
+}
+
+func TestReplayDLQ_DeletesBooksOfDeletedCollection(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	require.NoError(t, utils.PushDeadLetter(ctx, cache, utils.StockDLQKey, utils.StockOperation{
+		Op:           utils.OpDeleteCollectionBooks,
+		CollectionId: collectionId.Hex(),
+	}))
+
+	filter := bson.M{"collection_id": collectionId}
+	mockBaseService.On("List", mockAnyCtx()).Return([]model.Book{}, nil)
+	mockBaseService.On("BulkDelete", mockAnyCtx(), filter).Return(int64(0), nil)
+
+	replayed, failed, err := mockService.ReplayDLQ(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Zero(t, failed)
+	mockBaseService.AssertExpectations(t)
+	// Deleting by collection never touches the stock counters
+	mockService.CollectionClient.(*mocks.MockCollectionService).AssertNotCalled(t, "AdjustBookStock", mock.Anything, mock.Anything)
+}
+
+func TestAddBook_ValidationFailureIsInvalidArgument(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(&service.ValidationError{
+		Fields: map[string]string{"collection_id": "collection_id is required"},
+	})
+
+	_, err := mockService.AddBook(context.Background(), &pb.AddBookRequest{Book: &pb.Book{CollectionId: primitive.NewObjectID().Hex(), IsBorrowed: wrapperspb.Bool(false)}})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{"collection_id": "collection_id is required"}, grpcutil.FieldViolations(err))
+	mockService.CollectionClient.(*mocks.MockCollectionService).AssertNotCalled(t, "AdjustBookStock", mock.Anything, mock.Anything)
+}
+
 func TestUpdateBook_Success(t *testing.T) {
 	cache := newRedis(t)
 	mockBaseService, mockService := newServer(cache)
@@ -173,7 +527,7 @@ func TestUpdateBook_Success(t *testing.T) {
 	collectionId := primitive.NewObjectID()
 
 	updated := model.Book{Id: id, CollectionId: collectionId, IsBorrowed: true}
-	mockBaseService.On("Update", mockAnyCtx(), mock.MatchedBy(func(m map[string]any) bool { return m["updated_at"] != nil }), id.Hex()).Return(updated, nil)
+	mockBaseService.On("Update", mockAnyCtx(), mock.MatchedBy(func(m map[string]any) bool { return m["updated_at"] == nil }), id.Hex()).Return(updated, nil)
 
 	resp, err := mockService.UpdateBook(context.Background(), &pb.UpdateBookRequest{Id: id.Hex(), Payload: &structpb.Struct{
 		Fields: map[string]*structpb.Value{
@@ -187,11 +541,46 @@ func TestUpdateBook_Success(t *testing.T) {
 	// assert.Equal(t, "Book updated!", resp.Message)
 }
 
+func TestUpdateBook_IfMatch(t *testing.T) {
+	id := primitive.NewObjectID()
+	readAt := time.UnixMilli(time.Now().Add(-time.Minute).UnixMilli())
+	payload := &structpb.Struct{Fields: map[string]*structpb.Value{"is_borrowed": structpb.NewBoolValue(true)}}
+	conditional := bson.M{"_id": id, "updated_at": readAt}
+
+	t.Run("matching etag", func(t *testing.T) {
+		mockBaseService, mockService := newServer(newRedis(t))
+		updated := &model.Book{Id: id, IsBorrowed: true, UpdatedAt: time.Now()}
+		mockBaseService.On("UpdateMany", mockAnyCtx(), conditional, mock.Anything).Return(int64(1), nil).Once()
+		mockBaseService.On("FindById", mockAnyCtx(), id.Hex()).Return(updated, nil).Once()
+
+		resp, err := mockService.UpdateBook(context.Background(), &pb.UpdateBookRequest{
+			Id: id.Hex(), Payload: payload, IfMatch: model.ETag(readAt),
+		})
+
+		require.NoError(t, err)
+		assert.True(t, resp.Success)
+		assert.Equal(t, model.ETag(updated.UpdatedAt), resp.Book[0].Etag)
+		mockBaseService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stale etag", func(t *testing.T) {
+		mockBaseService, mockService := newServer(newRedis(t))
+		mockBaseService.On("UpdateMany", mockAnyCtx(), conditional, mock.Anything).Return(int64(0), nil).Once()
+		mockBaseService.On("FindById", mockAnyCtx(), id.Hex()).Return(&model.Book{Id: id, UpdatedAt: time.Now()}, nil).Once()
+
+		_, err := mockService.UpdateBook(context.Background(), &pb.UpdateBookRequest{
+			Id: id.Hex(), Payload: payload, IfMatch: model.ETag(readAt),
+		})
+
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
 func TestDeleteBook_NotFound(t *testing.T) {
 	cache := newRedis(t)
 	mockBaseService, mockService := newServer(cache)
 
-	mockBaseService.On("Delete", mockAnyCtx(), "missing").Return(model.Book{}, mongo.ErrNoDocuments)
+	mockBaseService.On("SoftDelete", mockAnyCtx(), "missing").Return(model.Book{}, mongo.ErrNoDocuments)
 
 	resp, err := mockService.DeleteBook(context.Background(), &pb.DeleteBookRequest{Id: "missing"})
 	require.NoError(t, err)
@@ -205,20 +594,22 @@ func TestDeleteBook_Success(t *testing.T) {
 	mockBaseService, mockService := newServer(cache)
 
 	collectionId := primitive.NewObjectID()
-	seed := &model.Collection{Id: mustOID(collectionId.Hex()), TotalBooks: 5}
+	seed := &model.Collection{Id: mustOID(collectionId.Hex()), TotalBooks: 5, AvailableBooks: 5}
 	raw, _ := json.Marshal(seed)
 	require.NoError(t, cache.Set(context.Background(), "collection:"+collectionId.Hex(), raw, time.Hour).Err())
 
 	id := primitive.NewObjectID()
 	deleted := model.Book{Id: id, CollectionId: collectionId}
+	require.NoError(t, cache.SAdd(context.Background(), "available_books:"+collectionId.Hex(), id.Hex()).Err())
 
-	mockBaseService.On("Delete", mockAnyCtx(), mock.Anything).Return(deleted, nil)
+	mockBaseService.On("SoftDelete", mockAnyCtx(), mock.Anything).Return(deleted, nil)
 	mockService.CollectionClient.(*mocks.MockCollectionService).On(
-		"DecrementAvailableBooks",
+		"AdjustBookStock",
 		mock.AnythingOfType("*context.timerCtx"), // or mock.Anything for simplicity
-		&pb.DecrementAvailableBooksRequest{
-			Id:     collectionId.Hex(),
-			Amount: -1,
+		&pb.AdjustBookStockRequest{
+			Id:             collectionId.Hex(),
+			TotalDelta:     -1,
+			AvailableDelta: -1,
 		},
 	).Return(&pb.Response{Success: true}, nil)
 
@@ -227,14 +618,256 @@ func TestDeleteBook_Success(t *testing.T) {
 	assert.True(t, resp.Success)
 	assert.Equal(t, deleted.Id.Hex(), resp.Book[0].Id)
 
-	// Wait a bit for the goroutine to complete
-	time.Sleep(100 * time.Millisecond)
+	available, err := cache.SIsMember(context.Background(), "available_books:"+collectionId.Hex(), id.Hex()).Result()
+	require.NoError(t, err)
+	assert.False(t, available)
+
+	// Wait for the background stock update to finish
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
+
+	out, err := cache.Get(context.Background(), "collection:"+collectionId.Hex()).Bytes()
+	require.NoError(t, err)
+	var cached model.Collection
+	require.NoError(t, json.Unmarshal(out, &cached))
+	assert.Equal(t, 4, cached.TotalBooks)
+	assert.Equal(t, 4, cached.AvailableBooks)
+}
+
+func TestBulkDelete_ByCollection(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	books := []model.Book{
+		{Id: primitive.NewObjectID(), CollectionId: collectionId},
+		{Id: primitive.NewObjectID(), CollectionId: collectionId},
+	}
+	require.NoError(t, cache.SAdd(ctx, "available_books:"+collectionId.Hex(), books[0].Id.Hex()).Err())
+	require.NoError(t, cache.Set(ctx, "book:"+books[1].Id.Hex(), "{}", time.Hour).Err())
+
+	mockBaseService.On("List", mockAnyCtx()).Return(books, nil).Once()
+	mockBaseService.On("BulkDelete", mockAnyCtx(), bson.M{"collection_id": collectionId}).Return(int64(2), nil).Once()
+
+	resp, err := mockService.BulkDelete(ctx, &pb.BulkDeleteBookRequest{CollectionId: collectionId.Hex()})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "2 books deleted", resp.Message)
+	mockBaseService.AssertExpectations(t)
+	// A deleted collection's counters aren't adjusted
+	mockService.CollectionClient.(*mocks.MockCollectionService).AssertNotCalled(t, "AdjustBookStock", mock.Anything, mock.Anything)
+
+	exists, err := cache.Exists(ctx, "available_books:"+collectionId.Hex(), "book:"+books[1].Id.Hex()).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestBulkDelete_ByIds(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	kept := primitive.NewObjectID()
+	books := []model.Book{
+		{Id: primitive.NewObjectID(), CollectionId: collectionId},
+		{Id: primitive.NewObjectID(), CollectionId: collectionId, IsBorrowed: true},
+	}
+	require.NoError(t, cache.SAdd(ctx, "available_books:"+collectionId.Hex(), books[0].Id.Hex(), kept.Hex()).Err())
+	require.NoError(t, cache.Set(ctx, "book:"+books[0].Id.Hex(), "{}", time.Hour).Err())
+	seed := &model.Collection{Id: collectionId, TotalBooks: 5, AvailableBooks: 3}
+	raw, _ := json.Marshal(seed)
+	require.NoError(t, cache.Set(ctx, "collection:"+collectionId.Hex(), raw, time.Hour).Err())
+
+	mockBaseService.On("List", mockAnyCtx()).Return(books, nil).Once()
+	mockBaseService.On("BulkDelete", mockAnyCtx(), bson.M{
+		"_id": bson.M{"$in": []primitive.ObjectID{books[0].Id, books[1].Id}},
+	}).Return(int64(2), nil).Once()
+	mockService.CollectionClient.(*mocks.MockCollectionService).On(
+		"AdjustBookStock",
+		mock.Anything,
+		&pb.AdjustBookStockRequest{Id: collectionId.Hex(), TotalDelta: -2, AvailableDelta: -1},
+	).Return(&pb.Response{Success: true}, nil).Once()
+
+	resp, err := mockService.BulkDelete(ctx, &pb.BulkDeleteBookRequest{Ids: []string{books[0].Id.Hex(), books[1].Id.Hex()}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "2 books deleted", resp.Message)
+	mockBaseService.AssertExpectations(t)
+
+	members, err := cache.SMembers(ctx, "available_books:"+collectionId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{kept.Hex()}, members)
+	exists, err := cache.Exists(ctx, "book:"+books[0].Id.Hex()).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+
+	// Wait for the background stock update to finish
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
+
+	out, err := cache.Get(ctx, "collection:"+collectionId.Hex()).Bytes()
+	require.NoError(t, err)
+	var cached model.Collection
+	require.NoError(t, json.Unmarshal(out, &cached))
+	assert.Equal(t, 3, cached.TotalBooks)
+	assert.Equal(t, 2, cached.AvailableBooks)
+}
+
+func TestBulkDelete_RequiresFilter(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+
+	_, err := mockService.BulkDelete(context.Background(), &pb.BulkDeleteBookRequest{})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockBaseService.AssertNotCalled(t, "BulkDelete", mock.Anything, mock.Anything)
+}
+
+func TestUpdateBooks_MovesStockOfMatchedBooks(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+	ctx := context.Background()
+
+	from, to := primitive.NewObjectID(), primitive.NewObjectID()
+	books := []model.Book{
+		{Id: primitive.NewObjectID(), CollectionId: from},
+		{Id: primitive.NewObjectID(), CollectionId: from, IsBorrowed: true},
+		{Id: primitive.NewObjectID(), CollectionId: to, IsBorrowed: true},
+	}
+	require.NoError(t, cache.Set(ctx, "book:"+books[1].Id.Hex(), "{}", time.Hour).Err())
+	require.NoError(t, cache.SAdd(ctx, "available_books:"+from.Hex(), books[0].Id.Hex()).Err())
+	for _, seed := range []model.Collection{
+		{Id: from, TotalBooks: 2, AvailableBooks: 1},
+		{Id: to, TotalBooks: 1, AvailableBooks: 0},
+	} {
+		raw, _ := json.Marshal(seed)
+		require.NoError(t, cache.Set(ctx, "collection:"+seed.Id.Hex(), raw, time.Hour).Err())
+	}
+
+	mockBaseService.On("List", mockAnyCtx()).Return(books, nil).Once()
+	mockBaseService.On("UpdateMany", mockAnyCtx(), bson.M{
+		"_id": bson.M{"$in": []primitive.ObjectID{books[0].Id, books[1].Id, books[2].Id}},
+	}, map[string]interface{}{
+		"collection_id": to,
+		"is_borrowed":   false,
+	}).Return(int64(3), nil).Once()
+	collectionClient := mockService.CollectionClient.(*mocks.MockCollectionService)
+	collectionClient.On("AdjustBookStock", mock.Anything,
+		&pb.AdjustBookStockRequest{Id: from.Hex(), TotalDelta: -2, AvailableDelta: -1},
+	).Return(&pb.Response{Success: true}, nil).Once()
+	collectionClient.On("AdjustBookStock", mock.Anything,
+		&pb.AdjustBookStockRequest{Id: to.Hex(), TotalDelta: 2, AvailableDelta: 3},
+	).Return(&pb.Response{Success: true}, nil).Once()
+
+	filterStruct, err := structpb.NewStruct(map[string]interface{}{
+		"_id": []interface{}{books[0].Id.Hex(), books[1].Id.Hex(), books[2].Id.Hex()},
+	})
+	require.NoError(t, err)
+	payload, err := structpb.NewStruct(map[string]interface{}{"collection_id": to.Hex(), "is_borrowed": false})
+	require.NoError(t, err)
+
+	resp, err := mockService.UpdateBooks(ctx, &pb.UpdateBooksRequest{Filter: filterStruct, Payload: payload})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(3), resp.Modified)
+	assert.Equal(t, "3 books updated", resp.Message)
+
+	exists, err := cache.Exists(ctx, "book:"+books[1].Id.Hex(), "available_books:"+from.Hex()).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+
+	// Wait for the background stock updates to finish
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
+	mockBaseService.AssertExpectations(t)
+	collectionClient.AssertExpectations(t)
+
+	// Every book now sits available in the target collection
+	for id, want := range map[primitive.ObjectID][2]int{from: {0, 0}, to: {3, 3}} {
+		out, err := cache.Get(ctx, "collection:"+id.Hex()).Bytes()
+		require.NoError(t, err)
+		var cached model.Collection
+		require.NoError(t, json.Unmarshal(out, &cached))
+		assert.Equal(t, want, [2]int{cached.TotalBooks, cached.AvailableBooks})
+	}
+}
+
+func TestUpdateBooks_NoMatches(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	mockBaseService.On("List", mockAnyCtx()).Return([]model.Book{}, nil).Once()
+	mockBaseService.On("UpdateMany", mockAnyCtx(), bson.M{"collection_id": collectionId}, map[string]interface{}{
+		"is_borrowed": false,
+	}).Return(int64(0), nil).Once()
+
+	filter, err := structpb.NewStruct(map[string]interface{}{"collection_id": collectionId.Hex()})
+	require.NoError(t, err)
+	payload, err := structpb.NewStruct(map[string]interface{}{"is_borrowed": false})
+	require.NoError(t, err)
+
+	resp, err := mockService.UpdateBooks(ctx, &pb.UpdateBooksRequest{Filter: filter, Payload: payload})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), resp.Modified)
+	assert.Equal(t, "0 books updated", resp.Message)
+
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
+	mockBaseService.AssertExpectations(t)
+	mockService.CollectionClient.(*mocks.MockCollectionService).AssertNotCalled(t, "AdjustBookStock", mock.Anything, mock.Anything)
+}
+
+func TestUpdateBooks_RejectsUnsupportedFilter(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+
+	filter, err := structpb.NewStruct(map[string]interface{}{"title": "Dune"})
+	require.NoError(t, err)
+	payload, err := structpb.NewStruct(map[string]interface{}{"is_borrowed": false})
+	require.NoError(t, err)
+
+	_, err = mockService.UpdateBooks(context.Background(), &pb.UpdateBooksRequest{Filter: filter, Payload: payload})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, grpcutil.FieldViolations(err), "title")
+	mockBaseService.AssertNotCalled(t, "UpdateMany", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = mockService.UpdateBooks(context.Background(), &pb.UpdateBooksRequest{Payload: payload})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDeleteBook_BorrowedKeepsAvailableCount(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	collectionId := primitive.NewObjectID()
+	seed := &model.Collection{Id: mustOID(collectionId.Hex()), TotalBooks: 5, AvailableBooks: 2}
+	raw, _ := json.Marshal(seed)
+	require.NoError(t, cache.Set(context.Background(), "collection:"+collectionId.Hex(), raw, time.Hour).Err())
+
+	id := primitive.NewObjectID()
+	deleted := model.Book{Id: id, CollectionId: collectionId, IsBorrowed: true}
+
+	mockBaseService.On("SoftDelete", mockAnyCtx(), id.Hex()).Return(deleted, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On(
+		"AdjustBookStock",
+		mock.Anything,
+		&pb.AdjustBookStockRequest{Id: collectionId.Hex(), TotalDelta: -1, AvailableDelta: 0},
+	).Return(&pb.Response{Success: true}, nil)
+
+	resp, err := mockService.DeleteBook(context.Background(), &pb.DeleteBookRequest{Id: id.Hex()})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	// Wait for the background stock update to finish
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
 
 	out, err := cache.Get(context.Background(), "collection:"+collectionId.Hex()).Bytes()
 	require.NoError(t, err)
 	var cached model.Collection
 	require.NoError(t, json.Unmarshal(out, &cached))
 	assert.Equal(t, 4, cached.TotalBooks)
+	assert.Equal(t, 2, cached.AvailableBooks)
 }
 
 func TestGetAvailableBook_Success(t *testing.T) {
@@ -256,14 +889,342 @@ func TestGetAvailableBook_Success(t *testing.T) {
 	assert.True(t, resp.Success)
 	assert.Equal(t, id1.Hex(), resp.Book[0].Id)
 
-	// Wait a bit for the goroutine to complete
-	time.Sleep(100 * time.Millisecond)
+	// Wait for the background stock update to finish
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
 
 	assert.True(t, cache.SIsMember(context.Background(), "available_books:"+collectionId.Hex(), id1.Hex()).Val())
 }
 
+func TestGetAvailableBook_SetHasOnlyIdsAndExpires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockBaseService, mockService := newServer(cache)
+	mockService.CacheTTL.AvailableBooksTTL = 20 * time.Minute
+
+	collectionId := primitive.NewObjectID()
+	id := primitive.NewObjectID()
+	mockBaseService.On("Find", mockAnyCtx(), bson.M{
+		"collection_id": collectionId,
+		"is_borrowed":   false,
+	}).Return(&model.Book{Id: id, CollectionId: collectionId}, nil)
+
+	_, err := mockService.GetAvailableBook(context.Background(), &pb.GetAvailableBookRequest{
+		CollectionId: collectionId.Hex(),
+	})
+	require.NoError(t, err)
+
+	key := "available_books:" + collectionId.Hex()
+	members, err := mr.Members(key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{id.Hex()}, members)
+	assert.Equal(t, 20*time.Minute, mr.TTL(key))
+
+	mr.FastForward(20 * time.Minute)
+	assert.False(t, mr.Exists(key))
+}
+
 func mockAnyCtx() interface{} { return mock.MatchedBy(func(ctx context.Context) bool { return true }) }
 func mustOID(hex string) primitive.ObjectID {
 	id, _ := primitive.ObjectIDFromHex(hex)
 	return id
 }
+
+func TestFindBookById_RedisDownFallsThrough(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	guard := utils.GuardCache(cache, time.Minute)
+	mr.Close()
+	mockBaseService, mockService := newServer(cache)
+
+	id := primitive.NewObjectID()
+	mc := &model.Book{Id: id, CollectionId: primitive.NewObjectID()}
+	mockBaseService.On("Find", mockAnyCtx(), bson.M{"_id": id.Hex()}).Return(mc, nil).Twice()
+
+	for range 2 {
+		resp, err := mockService.FindBookById(context.Background(), &pb.FindBookRequest{Id: id.Hex()})
+		require.NoError(t, err)
+		assert.True(t, resp.Success)
+		require.Len(t, resp.Book, 1)
+		assert.Equal(t, id.Hex(), resp.Book[0].Id)
+	}
+
+	// Only the first lookup waited on Redis, the second skipped it
+	assert.False(t, guard.Available())
+	assert.Equal(t, int64(1), guard.Failures())
+	mockBaseService.AssertExpectations(t)
+}
+
+func TestFindBookById_UsesConfiguredTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockBaseService, mockService := newServer(cache)
+	mockService.CacheTTL.BookTTL = 10 * time.Minute
+
+	id := primitive.NewObjectID()
+	mc := &model.Book{Id: id, CollectionId: primitive.NewObjectID()}
+	mockBaseService.On("Find", mockAnyCtx(), bson.M{"_id": id.Hex()}).Return(mc, nil)
+
+	_, err := mockService.FindBookById(context.Background(), &pb.FindBookRequest{Id: id.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, mr.TTL("book:"+id.Hex()))
+
+	mr.FastForward(9 * time.Minute)
+	assert.True(t, mr.Exists("book:"+id.Hex()))
+	mr.FastForward(time.Minute)
+	assert.False(t, mr.Exists("book:"+id.Hex()))
+}
+
+func TestCountBook_UsesAvailableBooksTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockBaseService, mockService := newServer(cache)
+	mockService.CacheTTL.AvailableBooksTTL = 5 * time.Minute
+
+	collectionId := primitive.NewObjectID().Hex()
+	mockBaseService.On("Count", mockAnyCtx(), mock.Anything).Return(int64(3), nil)
+
+	_, err := mockService.CountBook(context.Background(), &pb.CountBookRequest{CollectionId: collectionId})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, mr.TTL("available_count:"+collectionId))
+
+	mr.FastForward(5 * time.Minute)
+	assert.False(t, mr.Exists("available_count:"+collectionId))
+}
+
+func TestCountBook_CountsOnlyAvailableBooks(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	collectionId := primitive.NewObjectID()
+	mockBaseService.On("Count", mockAnyCtx(), bson.M{
+		"collection_id": collectionId,
+		"is_borrowed":   false,
+	}).Return(int64(2), nil).Once()
+
+	resp, err := mockService.CountBook(context.Background(), &pb.CountBookRequest{CollectionId: collectionId.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Count)
+	mockBaseService.AssertExpectations(t)
+}
+
+func TestCountBook_SecondCallHitsCache(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	collectionId := primitive.NewObjectID().Hex()
+	mockBaseService.On("Count", mockAnyCtx(), mock.Anything).Return(int64(4), nil).Once()
+
+	first, err := mockService.CountBook(context.Background(), &pb.CountBookRequest{CollectionId: collectionId})
+	require.NoError(t, err)
+	second, err := mockService.CountBook(context.Background(), &pb.CountBookRequest{CollectionId: collectionId})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), first.Count)
+	assert.Equal(t, int64(4), second.Count)
+	mockBaseService.AssertNumberOfCalls(t, "Count", 1)
+}
+
+func TestCountBook_ServedFromCacheWithoutService(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	collectionId := primitive.NewObjectID().Hex()
+	require.NoError(t, utils.SetCachedData(context.Background(), cache, "available_count:"+collectionId, int64(7), time.Hour))
+
+	resp, err := mockService.CountBook(context.Background(), &pb.CountBookRequest{CollectionId: collectionId})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), resp.Count)
+	mockBaseService.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+}
+
+func TestCountBook_InvalidCollectionId(t *testing.T) {
+	mockBaseService, mockService := newServer(newRedis(t))
+
+	_, err := mockService.CountBook(context.Background(), &pb.CountBookRequest{CollectionId: "not-an-id"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockBaseService.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+}
+
+func TestGetAvailableBooks_ListsFromDatabase(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	collectionId := primitive.NewObjectID()
+	books := []model.Book{{Id: primitive.NewObjectID()}, {Id: primitive.NewObjectID()}}
+	mockBaseService.On("ListWithTotal", mockAnyCtx(), bson.M{
+		"collection_id": collectionId,
+		"is_borrowed":   false,
+	}, bson.D{{Key: "_id", Value: 1}}, 1, 2).Return(books, int64(3), nil).Once()
+
+	resp, err := mockService.GetAvailableBooks(context.Background(), &pb.GetAvailableBookRequest{
+		CollectionId: collectionId.Hex(),
+		Skip:         1,
+		Limit:        2,
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(3), resp.Total)
+	require.Len(t, resp.Book, 2)
+	for i, book := range resp.Book {
+		assert.Equal(t, books[i].Id.Hex(), book.Id)
+		assert.Equal(t, collectionId.Hex(), book.CollectionId)
+		assert.False(t, book.IsBorrowed.GetValue())
+	}
+	mockBaseService.AssertExpectations(t)
+}
+
+func TestGetAvailableBooks_IgnoresPartialSet(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService := newServer(cache)
+
+	collectionId := primitive.NewObjectID()
+	filter := bson.M{"collection_id": collectionId, "is_borrowed": false}
+	books := []model.Book{{Id: primitive.NewObjectID()}, {Id: primitive.NewObjectID()}, {Id: primitive.NewObjectID()}}
+
+	// A cache miss of GetAvailableBook leaves a single id in the set
+	mockBaseService.On("Find", mockAnyCtx(), filter).Return(&model.Book{Id: books[1].Id, CollectionId: collectionId}, nil).Once()
+	_, err := mockService.GetAvailableBook(context.Background(), &pb.GetAvailableBookRequest{CollectionId: collectionId.Hex()})
+	require.NoError(t, err)
+	members, err := cache.SMembers(context.Background(), "available_books:"+collectionId.Hex()).Result()
+	require.NoError(t, err)
+	require.Equal(t, []string{books[1].Id.Hex()}, members)
+
+	mockBaseService.On("ListWithTotal", mockAnyCtx(), filter, bson.D{{Key: "_id", Value: 1}}, 0, 0).
+		Return(books, int64(len(books)), nil).Once()
+
+	resp, err := mockService.GetAvailableBooks(context.Background(), &pb.GetAvailableBookRequest{CollectionId: collectionId.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.Total)
+	require.Len(t, resp.Book, 3)
+	for i, book := range resp.Book {
+		assert.Equal(t, books[i].Id.Hex(), book.Id)
+	}
+	mockBaseService.AssertExpectations(t)
+}
+
+func TestGetAvailableBooks_InvalidCollectionId(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, err := mockService.GetAvailableBooks(context.Background(), &pb.GetAvailableBookRequest{CollectionId: "nope"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// Matches a GetBooksByCollection query, availableOnly adds the is_borrowed
+// condition and pages are sorted by id so they stay stable
+func collectionBooksFilter(collectionId primitive.ObjectID, availableOnly bool) interface{} {
+	return mock.MatchedBy(func(filter bson.M) bool {
+		isBorrowed, hasIsBorrowed := filter["is_borrowed"]
+		if availableOnly != hasIsBorrowed || (hasIsBorrowed && isBorrowed != false) {
+			return false
+		}
+		return filter["collection_id"] == collectionId
+	})
+}
+
+var collectionBooksSort = bson.D{{Key: "_id", Value: 1}}
+
+func TestGetBooksByCollection_AllBooks(t *testing.T) {
+	mockBaseService, svc := newServer(newRedis(t))
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	books := []model.Book{
+		{Id: primitive.NewObjectID(), CollectionId: collectionId, IsBorrowed: true},
+		{Id: primitive.NewObjectID(), CollectionId: collectionId},
+	}
+	mockBaseService.On("ListWithTotal", ctx, collectionBooksFilter(collectionId, false), collectionBooksSort, 0, 10).
+		Return(books, int64(2), nil).Once()
+
+	resp, err := svc.GetBooksByCollection(ctx, &pb.GetBooksByCollectionRequest{CollectionId: collectionId.Hex(), Limit: 10})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(2), resp.Total)
+	require.Len(t, resp.Book, 2)
+	assert.True(t, resp.Book[0].IsBorrowed.GetValue())
+	mockBaseService.AssertExpectations(t)
+}
+
+func TestGetBooksByCollection_AvailableOnly(t *testing.T) {
+	mockBaseService, svc := newServer(newRedis(t))
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	books := []model.Book{{Id: primitive.NewObjectID(), CollectionId: collectionId}}
+	mockBaseService.On("ListWithTotal", ctx, collectionBooksFilter(collectionId, true), collectionBooksSort, 0, 10).
+		Return(books, int64(1), nil).Once()
+
+	resp, err := svc.GetBooksByCollection(ctx, &pb.GetBooksByCollectionRequest{
+		CollectionId:  collectionId.Hex(),
+		AvailableOnly: true,
+		Limit:         10,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.Total)
+	require.Len(t, resp.Book, 1)
+	assert.False(t, resp.Book[0].IsBorrowed.GetValue())
+	mockBaseService.AssertExpectations(t)
+}
+
+func TestGetBooksByCollection_Pagination(t *testing.T) {
+	mockBaseService, svc := newServer(newRedis(t))
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	books := []model.Book{{Id: primitive.NewObjectID(), CollectionId: collectionId}}
+	mockBaseService.On("ListWithTotal", ctx, collectionBooksFilter(collectionId, false), collectionBooksSort, 4, 2).
+		Return(books, int64(5), nil).Twice()
+
+	in := &pb.GetBooksByCollectionRequest{CollectionId: collectionId.Hex(), Skip: 4, Limit: 2}
+	for range 2 {
+		resp, err := svc.GetBooksByCollection(ctx, in)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), resp.Total)
+		assert.Len(t, resp.Book, 1)
+	}
+
+	// Only the first page is cached, later pages always hit the database
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 2)
+}
+
+func TestGetBooksByCollection_FirstPageCachedUntilBookAdded(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, svc := newServer(cache)
+	ctx := context.Background()
+
+	collectionId := primitive.NewObjectID()
+	books := []model.Book{{Id: primitive.NewObjectID(), CollectionId: collectionId}}
+	mockBaseService.On("ListWithTotal", ctx, collectionBooksFilter(collectionId, false), collectionBooksSort, 0, 10).
+		Return(books, int64(1), nil).Twice()
+
+	in := &pb.GetBooksByCollectionRequest{CollectionId: collectionId.Hex(), Limit: 10}
+	_, err := svc.GetBooksByCollection(ctx, in)
+	require.NoError(t, err)
+	resp, err := svc.GetBooksByCollection(ctx, in)
+	require.NoError(t, err)
+	assert.Len(t, resp.Book, 1)
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 1)
+
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+	svc.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", mock.Anything, mock.Anything).
+		Return(&pb.Response{Success: true}, nil)
+	_, err = svc.AddBook(ctx, &pb.AddBookRequest{Book: &pb.Book{CollectionId: collectionId.Hex(), IsBorrowed: wrapperspb.Bool(false)}})
+	require.NoError(t, err)
+	require.NoError(t, svc.Background.Shutdown(ctx))
+
+	_, err = svc.GetBooksByCollection(ctx, in)
+	require.NoError(t, err)
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 2)
+}
+
+func TestGetBooksByCollection_InvalidCollectionId(t *testing.T) {
+	_, svc := newServer(newRedis(t))
+
+	resp, err := svc.GetBooksByCollection(context.Background(), &pb.GetBooksByCollectionRequest{CollectionId: "nope"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
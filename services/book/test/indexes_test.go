@@ -0,0 +1,19 @@
+package test
+
+import (
+	"book/internal/db"
+	"shared/pkg/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestIndexModels_WellFormed(t *testing.T) {
+	models := db.IndexModels()
+	require.NoError(t, repository.ValidateIndexModels(models))
+	require.Len(t, models, 1)
+
+	assert.Equal(t, bson.D{{Key: "collection_id", Value: 1}, {Key: "is_borrowed", Value: 1}}, models[0].Keys)
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"book/internal"
+	"net"
+	"testing"
+
+	"shared/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialClients_ReturnsErrorInsteadOfExiting(t *testing.T) {
+	t.Setenv("GRPC_INSECURE", "")
+	t.Setenv("GRPC_TLS_CA", "/nonexistent/ca.pem")
+
+	connections, err := internal.DialClients()
+
+	require.Error(t, err)
+	assert.Nil(t, connections)
+	assert.Contains(t, err.Error(), "CA file")
+}
+
+func TestStartServer_ReturnsErrorWhenPortTaken(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+
+	host, port, err := net.SplitHostPort(taken.Addr().String())
+	require.NoError(t, err)
+	t.Setenv("BOOK_SERVICE_HOST", host)
+	t.Setenv("BOOK_SERVICE_PORT", port)
+
+	server, svc, serveErr, err := internal.StartServer(nil, nil, nil, config.DefaultCacheTTLConfig(), nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen")
+	assert.Nil(t, server)
+	assert.Nil(t, svc)
+	assert.Nil(t, serveErr)
+}
+
+func TestStartServer_ReleasesListenerWithoutCredentials(t *testing.T) {
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(free.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, free.Close())
+
+	t.Setenv("BOOK_SERVICE_HOST", host)
+	t.Setenv("BOOK_SERVICE_PORT", port)
+	t.Setenv("GRPC_INSECURE", "")
+	t.Setenv("GRPC_TLS_CERT", "")
+	t.Setenv("GRPC_TLS_KEY", "")
+
+	_, _, _, err = internal.StartServer(nil, nil, nil, config.DefaultCacheTTLConfig(), nil)
+	require.Error(t, err)
+
+	// The port is free again, a failed start doesn't hold on to it
+	lis, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	require.NoError(t, err)
+	lis.Close()
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"borrow/internal"
+	"log"
 )
 
 func main() {
-	internal.Setup()
+	if err := internal.Setup(); err != nil {
+		log.Fatalf("borrow service failed: %v", err)
+	}
 }
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"shared/pkg/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const CollectionName = "borrow_history"
+
+// Serves per-user borrow history and the overdue scan
+func IndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "due_date", Value: 1}},
+			Options: options.Index().SetName("due_date"),
+		},
+	}
+}
+
+func EnsureIndexes(ctx context.Context, database *mongo.Database) error {
+	return repository.EnsureIndexes(ctx, database.Collection(CollectionName), IndexModels())
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"shared/config"
+	"shared/pkg/model"
+	"time"
+)
+
+// Upper bound for a single webhook delivery
+const DueSoonWebhookTimeout = 10 * time.Second
+
+// Body POSTed to the due-soon webhook
+type DueSoonPayload struct {
+	Days        int            `json:"days"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Borrows     []model.Borrow `json:"borrows"`
+}
+
+// Posts every active borrow due within days to url, a non-2xx response is
+// reported as an error
+func (s *BorrowServiceServer) DispatchDueSoon(ctx context.Context, client *http.Client, url string, days int) error {
+	now := time.Now().UTC()
+	borrows, err := s.listDueSoon(ctx, now, days, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to list due soon borrows: %w", err)
+	}
+	for i := range borrows {
+		borrows[i].Status = borrows[i].StatusAt(now)
+	}
+
+	body, err := json.Marshal(DueSoonPayload{Days: days, GeneratedAt: now, Borrows: borrows})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to deliver due soon webhook: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("due soon webhook responded with %s", response.Status)
+	}
+	return nil
+}
+
+// Dispatches the due-soon list every interval until ctx is cancelled, a
+// failed dispatch is logged and retried on the next tick
+func (s *BorrowServiceServer) StartDueSoonNotifier(ctx context.Context, cfg *config.BorrowConfig) {
+	client := &http.Client{Timeout: DueSoonWebhookTimeout}
+	ticker := time.NewTicker(cfg.DueSoonInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.DispatchDueSoon(ctx, client, cfg.DueSoonWebhook, cfg.DueSoonDays); err != nil {
+					log.Printf("Error dispatching due soon borrows: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"shared/pkg/utils"
+	pb "shared/proto/buffer"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// How long a borrow can be replayed with the same idempotency key
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// Stored under an idempotency key while its borrow is still running
+const idempotencyPending = "pending"
+
+// Outcome of a completed borrow, stored under its idempotency key
+type idempotentBorrow struct {
+	BorrowId string `json:"borrow_id"`
+	BookId   string `json:"book_id"`
+}
+
+// Runs borrow at most once per user and idempotency key. The key is claimed
+// with SETNX so only one of several simultaneous requests borrows, the others
+// are told it is in progress, and later retries get the original response.
+// A failed borrow releases the key so the client can retry it.
+func (s *BorrowServiceServer) withIdempotency(ctx context.Context, userId string, key string, borrow func() (*pb.BorrowServiceResponse, error)) (*pb.BorrowServiceResponse, error) {
+	if key == "" {
+		return borrow()
+	}
+
+	cacheKey := "idempotency:borrow:" + userId + ":" + key
+	claimed, err := s.Cache.SetNX(ctx, cacheKey, idempotencyPending, s.idempotencyTTL()).Result()
+	if err != nil {
+		// Without Redis the request can't be deduplicated, borrow as usual
+		log.Printf("Error claiming idempotency key: %v", err)
+		return borrow()
+	}
+	if !claimed {
+		return s.replayBorrow(ctx, cacheKey)
+	}
+
+	// Finish bookkeeping even if the client goes away mid-request
+	cleanupCtx := context.WithoutCancel(ctx)
+
+	response, err := borrow()
+	if err != nil {
+		if delErr := s.Cache.Del(cleanupCtx, cacheKey).Err(); delErr != nil {
+			log.Printf("Error releasing idempotency key: %v", delErr)
+		}
+		return nil, err
+	}
+
+	stored := idempotentBorrow{BorrowId: response.Id, BookId: response.BookId}
+	utils.SetCachedData(cleanupCtx, s.Cache, cacheKey, stored, s.idempotencyTTL())
+
+	return response, nil
+}
+
+func (s *BorrowServiceServer) replayBorrow(ctx context.Context, cacheKey string) (*pb.BorrowServiceResponse, error) {
+	data, err := s.Cache.Get(ctx, cacheKey).Result()
+	if err == redis.Nil || data == idempotencyPending {
+		return nil, status.Error(codes.Aborted, "A borrow with this idempotency key is already in progress")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read idempotency key: %v", err)
+	}
+
+	var previous idempotentBorrow
+	if err := json.Unmarshal([]byte(data), &previous); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read idempotency key: %v", err)
+	}
+
+	return s.buildResponse(true, "Book borrowed!", previous.BorrowId, previous.BookId), nil
+}
+
+func (s *BorrowServiceServer) idempotencyTTL() time.Duration {
+	if s.IdempotencyTTL > 0 {
+		return s.IdempotencyTTL
+	}
+	return DefaultIdempotencyTTL
+}
@@ -3,16 +3,21 @@ package internal
 import (
 	"context"
 	"log"
+	"shared/config"
+	"shared/pkg/grpcutil"
 	interfaces "shared/pkg/interface"
 	"shared/pkg/model"
 	"shared/pkg/repository"
 	"shared/pkg/service"
+	"shared/pkg/utils"
 	pb "shared/proto/buffer"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -20,77 +25,444 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+const (
+	// Active borrow counts are for dashboards, a short TTL bounds staleness
+	// from changes made outside this service
+	ActiveBorrowsCountTTL = time.Minute
+)
+
 type BorrowServiceServer struct {
 	pb.UnimplementedBorrowServiceServer
 	Service          interfaces.ServiceInterface[model.Borrow, model.BorrowUpdateRequest]
 	Cache            *redis.Client
+	CacheTTL         config.CacheTTLConfig
 	CollectionClient pb.CollectionServiceClient
 	BookClient       pb.BookServiceClient
+	UserClient       pb.UserServiceClient // Nil when no user service is configured
+	RenewalDays      int
+	MaxRenewals      int
+	LoanDays         int
+	MaxLoanDays      int
+	MaxActive        int
+	FinePerDay       int64
+	DueSoonDays      int
+	IdempotencyTTL   time.Duration
 }
 
-func NewBorrowService(database *mongo.Database, collection_name string, connections map[string]*grpc.ClientConn, redis *redis.Client) *BorrowServiceServer {
+func NewBorrowService(database *mongo.Database, collection_name string, connections map[string]*grpc.ClientConn, redis *redis.Client, cacheTTL *config.CacheTTLConfig, borrowConfig *config.BorrowConfig) *BorrowServiceServer {
 	repository := repository.NewRepository[model.Borrow](database, collection_name)
 	return &BorrowServiceServer{
 		Service:          service.NewBaseService[model.Borrow, model.BorrowUpdateRequest](repository),
 		Cache:            redis,
+		CacheTTL:         *cacheTTL,
 		CollectionClient: pb.NewCollectionServiceClient(connections["collection"]),
 		BookClient:       pb.NewBookServiceClient(connections["book"]),
+		UserClient:       newUserClient(connections["user"]),
+		RenewalDays:      borrowConfig.RenewalDays,
+		MaxRenewals:      borrowConfig.MaxRenewals,
+		LoanDays:         borrowConfig.LoanDays,
+		MaxLoanDays:      borrowConfig.MaxLoanDays,
+		MaxActive:        borrowConfig.MaxActive,
+		FinePerDay:       borrowConfig.FinePerDay,
+		DueSoonDays:      borrowConfig.DueSoonDays,
+		IdempotencyTTL:   DefaultIdempotencyTTL,
 	}
 }
 
-func (s *BorrowServiceServer) BorrowBook(ctx context.Context, in *pb.BorrowRequest) (*pb.BorrowServiceResponse, error) {
-	// Fetch book and collection info
-	book, err := s.fetchBookAndCollection(ctx, in.CollectionId)
+// Fields the borrow indexes can sort on, see db.IndexModels
+var borrowSortFields = []string{"_id", "user_id", "due_date"}
+
+func (s *BorrowServiceServer) GetBorrows(ctx context.Context, in *pb.GetBorrowsRequest) (*pb.BorrowListResponse, error) {
+	// Parse filter and sort from protobuf
+	filter := bson.M{}
+	for k, v := range in.GetFilter().AsMap() {
+		filter[k] = v
+	}
+
+	// Reference fields are stored as object IDs
+	for _, key := range []string{"book_id", "user_id", "collection_id"} {
+		if hex, ok := filter[key].(string); ok {
+			id, err := primitive.ObjectIDFromHex(hex)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Invalid %s", key)
+			}
+			filter[key] = id
+		}
+	}
+
+	sort, err := grpcutil.BuildSort(in.Sort, borrowSortFields...)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create borrow record with compensation pattern
-	borrow, err := s.createBorrowWithCompensation(ctx, book, in.CollectionId)
+	data, err := s.Service.List(ctx, filter, sort, int(in.Skip), int(in.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.buildListResponse(true, "Borrows retrieved successfully", data), nil
+}
+
+func (s *BorrowServiceServer) BorrowBook(ctx context.Context, in *pb.BorrowRequest) (*pb.BorrowServiceResponse, error) {
+	// Validate user before touching any book state
+	userId, err := primitive.ObjectIDFromHex(in.UserId)
 	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid user ID")
+	}
+
+	loanDays, err := s.resolveLoanDays(in.LoanDays)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUserExists(ctx, in.UserId); err != nil {
 		return nil, err
 	}
 
-	// Update cache
-	s.updateCache(ctx, book.Id.Hex(), in.CollectionId, "remove")
+	return s.withIdempotency(ctx, in.UserId, in.IdempotencyKey, func() (*pb.BorrowServiceResponse, error) {
+		// Check the user's limit before reserving a book
+		if err := s.checkActiveLimit(ctx, in.UserId); err != nil {
+			return nil, err
+		}
+
+		// Fetch book and collection info
+		book, err := s.fetchBookAndCollection(ctx, in.CollectionId)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create borrow record with compensation pattern
+		borrow, err := s.createBorrowWithCompensation(ctx, book, in.CollectionId, userId, loanDays)
+		if err != nil {
+			return nil, err
+		}
 
-	return s.buildResponse(true, "Book borrowed!", borrow.Id.Hex(), borrow.BookId.Hex()), nil
+		// Update cache
+		s.updateCache(ctx, book.Id.Hex(), in.CollectionId, "remove")
+		s.invalidateActiveBorrowCounts(ctx, in.CollectionId, in.UserId)
+
+		return s.buildResponse(true, "Book borrowed!", borrow.Id.Hex(), borrow.BookId.Hex()), nil
+	})
 }
 
 func (s *BorrowServiceServer) ReturnBook(ctx context.Context, in *pb.ReturnRequest) (*pb.BorrowServiceResponse, error) {
 	now := time.Now().UTC()
+	borrowId, err := primitive.ObjectIDFromHex(in.BorrowId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid borrow ID")
+	}
 
-	// Check if book already returned
-	borrowRecord, err := s.Service.FindById(ctx, in.BorrowId)
+	// Check if book already returned, the record may come from the cache so
+	// the update below is what actually guards against a second return
+	borrowRecord, err := s.findBorrow(ctx, in.BorrowId)
 	if err == mongo.ErrNoDocuments {
 		log.Printf("error checking book status when returning: %v", err)
 		return nil, status.Error(codes.NotFound, "Borrow record not found")
-	} else if borrowRecord != nil {
-		if borrowRecord.ReturnDate != nil && !borrowRecord.ReturnDate.IsZero() {
-			log.Printf("Borrow already returned: %v", borrowRecord.Id.Hex())
-			return nil, status.Error(codes.FailedPrecondition, "Book already returned")
-		}
+	} else if err != nil {
+		log.Printf("error retrieving borrow record when returning: %v", err)
+		return nil, status.Error(codes.Internal, "Error retrieving borrow record")
+	}
+	if borrowRecord.IsReturned() {
+		log.Printf("Borrow already returned: %v", borrowRecord.Id.Hex())
+		return nil, status.Error(codes.FailedPrecondition, "Book already returned")
 	}
 
-	if err := s.markBookBorrowedStatus(ctx, borrowRecord.BookId.Hex(), false, now); err != nil {
+	// Update borrow record, returned_at keeps the exact time for audit. Only a
+	// record without a return date matches, so of several concurrent returns
+	// exactly one modifies it
+	update := map[string]interface{}{
+		"return_date": now,
+		"returned_at": now,
+	}
+	if returnedBy := strings.TrimSpace(in.ReturnedBy); returnedBy != "" {
+		update["returned_by"] = returnedBy
+	}
+	modified, err := s.Service.UpdateMany(ctx, bson.M{"_id": borrowId, "return_date": nil}, update)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update borrow record: %v", err)
+	}
+	s.invalidateBorrowCache(ctx, in.BorrowId)
+	if modified == 0 {
+		log.Printf("Borrow already returned: %v", borrowRecord.Id.Hex())
+		return nil, status.Error(codes.FailedPrecondition, "Book already returned")
+	}
+
+	if err := s.markBookBorrowedStatus(ctx, borrowRecord.BookId.Hex(), false); err != nil {
+		s.undoReturn(ctx, borrowId)
 		return nil, status.Errorf(codes.Aborted, "failed to mark book as returned: %v", err)
 	}
 
-	// Update borrow record
+	if err := s.adjustAvailableBooks(ctx, borrowRecord.CollectionId.Hex(), 1); err != nil {
+		log.Printf("Error releasing book %s: %v", borrowRecord.BookId.Hex(), err)
+	}
+
+	// Update cache
+	s.updateCache(ctx, borrowRecord.BookId.Hex(), borrowRecord.CollectionId.Hex(), "put")
+	s.invalidateActiveBorrowCounts(ctx, borrowRecord.CollectionId.Hex(), borrowRecord.UserId.Hex())
+
+	response := s.buildResponse(true, "Book returned successfully", borrowRecord.Id.Hex(), borrowRecord.BookId.Hex())
+	borrowRecord.ReturnDate = &now
+	response.DaysOverdue = int32(borrowRecord.DaysOverdue(now))
+	response.Fine = s.fine(borrowRecord.DaysOverdue(now))
+	return response, nil
+}
+
+func (s *BorrowServiceServer) RenewBook(ctx context.Context, in *pb.RenewRequest) (*pb.BorrowServiceResponse, error) {
+	now := time.Now().UTC()
+
+	borrowRecord, err := s.findBorrow(ctx, in.BorrowId)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.NotFound, "Borrow record not found")
+	} else if err != nil {
+		log.Printf("error retrieving borrow record when renewing: %v", err)
+		return nil, status.Error(codes.Internal, "Error retrieving borrow record")
+	}
+
+	// Only active, not yet overdue borrows can be renewed
+	if borrowRecord.IsReturned() {
+		return nil, status.Error(codes.FailedPrecondition, "Book already returned")
+	}
+	if borrowRecord.DueDate == nil || borrowRecord.DueDate.Before(now) {
+		return nil, status.Error(codes.FailedPrecondition, "Borrow is overdue and cannot be renewed")
+	}
+	if borrowRecord.RenewalCount >= s.maxRenewals() {
+		return nil, status.Errorf(codes.FailedPrecondition, "Renewal limit of %d reached", s.maxRenewals())
+	}
+
+	due := borrowRecord.DueDate.AddDate(0, 0, s.renewalDays())
+
+	// borrow_date is resent unchanged so the due date can be validated against it
 	_, err = s.Service.Update(ctx, map[string]interface{}{
-		"return_date": now.Format(time.RFC3339),
-		"updated_at":  now.Format(time.RFC3339),
+		"borrow_date":   borrowRecord.BorrowDate,
+		"due_date":      due,
+		"renewal_count": borrowRecord.RenewalCount + 1,
 	}, in.BorrowId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to renew borrow record: %v", err)
+	}
+	s.invalidateBorrowCache(ctx, in.BorrowId)
 
+	return s.buildResponse(true, "Book renewed until "+due.Format(time.RFC3339), borrowRecord.Id.Hex(), borrowRecord.BookId.Hex()), nil
+}
+
+func (s *BorrowServiceServer) FindBorrowById(ctx context.Context, in *pb.FindBorrowRequest) (*pb.BorrowServiceResponse, error) {
+	if _, err := primitive.ObjectIDFromHex(in.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid borrow ID")
+	}
+
+	borrowRecord, err := s.findBorrow(ctx, in.Id)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.NotFound, "Borrow record not found")
+	} else if err != nil {
+		log.Printf("error retrieving borrow record: %v", err)
+		return nil, status.Error(codes.Internal, "Error retrieving borrow record")
+	}
+
+	return &pb.BorrowServiceResponse{
+		Id:        in.Id,
+		BookId:    borrowRecord.BookId.Hex(),
+		Message:   "Borrow record found",
+		Success:   true,
+		Borrow:    model.ToPbBorrow(borrowRecord),
+		IsOverdue: borrowRecord.IsOverdue(time.Now().UTC()),
+	}, nil
+}
+
+// Fine owed for a borrow, its days overdue up to the return, or now while the
+// book is still out, times the daily rate
+func (s *BorrowServiceServer) CalculateFine(ctx context.Context, in *pb.FineRequest) (*pb.FineResponse, error) {
+	if _, err := primitive.ObjectIDFromHex(in.BorrowId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid borrow ID")
+	}
+
+	borrowRecord, err := s.findBorrow(ctx, in.BorrowId)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.NotFound, "Borrow record not found")
+	} else if err != nil {
+		log.Printf("error retrieving borrow record: %v", err)
+		return nil, status.Error(codes.Internal, "Error retrieving borrow record")
+	}
+
+	days := borrowRecord.DaysOverdue(time.Now().UTC())
+	return &pb.FineResponse{
+		BorrowId:    in.BorrowId,
+		DaysOverdue: int32(days),
+		Fine:        s.fine(days),
+		FinePerDay:  s.FinePerDay,
+		Message:     "Fine calculated successfully",
+		Success:     true,
+	}, nil
+}
+
+// Removes a borrow record entered by mistake. A borrow that was never returned
+// still holds its book, so the book is made available again.
+func (s *BorrowServiceServer) DeleteBorrow(ctx context.Context, in *pb.DeleteBorrowRequest) (*pb.BorrowServiceResponse, error) {
+	if _, err := primitive.ObjectIDFromHex(in.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid borrow ID")
+	}
+
+	borrowRecord, err := s.findBorrow(ctx, in.Id)
+	if err == mongo.ErrNoDocuments {
+		return nil, status.Error(codes.NotFound, "Borrow record not found")
+	} else if err != nil {
+		log.Printf("error retrieving borrow record when deleting: %v", err)
+		return nil, status.Error(codes.Internal, "Error retrieving borrow record")
+	}
+
+	active := !borrowRecord.IsReturned()
+	if active {
+		if err := s.markBookBorrowedStatus(ctx, borrowRecord.BookId.Hex(), false); err != nil {
+			return nil, status.Errorf(codes.Aborted, "failed to free borrowed book: %v", err)
+		}
+	}
+
+	if _, err := s.Service.Delete(ctx, in.Id); err != nil {
+		if active {
+			s.markBookBorrowedStatus(ctx, borrowRecord.BookId.Hex(), true)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete borrow record: %v", err)
+	}
+	s.invalidateBorrowCache(ctx, in.Id)
+
+	if active {
+		if err := s.adjustAvailableBooks(ctx, borrowRecord.CollectionId.Hex(), 1); err != nil {
+			log.Printf("Error releasing book %s: %v", borrowRecord.BookId.Hex(), err)
+		}
+
+		// Update cache
+		s.updateCache(ctx, borrowRecord.BookId.Hex(), borrowRecord.CollectionId.Hex(), "put")
+		s.invalidateActiveBorrowCounts(ctx, borrowRecord.CollectionId.Hex(), borrowRecord.UserId.Hex())
+	}
+
+	return s.buildResponse(true, "Borrow deleted!", borrowRecord.Id.Hex(), borrowRecord.BookId.Hex()), nil
+}
+
+func (s *BorrowServiceServer) GetOverdueBorrows(ctx context.Context, in *pb.OverdueRequest) (*pb.BorrowListResponse, error) {
+	filter := bson.M{
+		"return_date": nil,
+		"due_date":    bson.M{"$lt": time.Now().UTC()},
+	}
+	sort := bson.D{{Key: "due_date", Value: 1}}
+
+	data, err := s.Service.List(ctx, filter, sort, int(in.Skip), int(in.Limit))
 	if err != nil {
-		s.markBookBorrowedStatus(ctx, borrowRecord.BookId.Hex(), true, now)
-		return nil, status.Errorf(codes.Internal, "failed to update borrow record: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Update cache
-	s.updateCache(ctx, borrowRecord.BookId.Hex(), borrowRecord.CollectionId.Hex(), "put")
+	return s.buildListResponse(true, "Overdue borrows retrieved successfully", data), nil
+}
+
+// Lists active borrows due within the requested number of days, soonest first
+func (s *BorrowServiceServer) GetDueSoon(ctx context.Context, in *pb.DueSoonRequest) (*pb.BorrowListResponse, error) {
+	if in.Days < 0 {
+		return nil, status.Error(codes.InvalidArgument, "Days must not be negative")
+	}
+	days := int(in.Days)
+	if days == 0 {
+		days = s.dueSoonDays()
+	}
 
-	return s.buildResponse(true, "Book returned successfully", borrowRecord.Id.Hex(), borrowRecord.BookId.Hex()), nil
+	data, err := s.listDueSoon(ctx, time.Now().UTC(), days, int(in.Skip), int(in.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.buildListResponse(true, "Due soon borrows retrieved successfully", data), nil
+}
+
+// Active borrows that aren't overdue yet and are due at most days after now,
+// a due date exactly days away is included
+func (s *BorrowServiceServer) listDueSoon(ctx context.Context, now time.Time, days int, skip int, limit int) ([]model.Borrow, error) {
+	filter := bson.M{
+		"return_date": nil,
+		"due_date": bson.M{
+			"$gte": now,
+			"$lte": now.AddDate(0, 0, days),
+		},
+	}
+	sort := bson.D{{Key: "due_date", Value: 1}}
+
+	return s.Service.List(ctx, filter, sort, skip, limit)
+}
+
+func (s *BorrowServiceServer) GetBorrowsByUser(ctx context.Context, in *pb.UserBorrowsRequest) (*pb.BorrowListResponse, error) {
+	userId, err := primitive.ObjectIDFromHex(in.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid user ID")
+	}
+
+	filter := bson.M{"user_id": userId}
+	if in.ActiveOnly {
+		filter["return_date"] = nil
+	}
+	sort := bson.D{{Key: "borrow_date", Value: -1}}
+
+	data, err := s.Service.List(ctx, filter, sort, int(in.Skip), int(in.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.buildListResponse(true, "Borrows retrieved successfully", data), nil
+}
+
+// Rejects a borrow once the user holds the maximum number of unreturned
+// books. The count is read through the active borrows cache, which borrow,
+// return and delete invalidate, so simultaneous borrows by one user may
+// briefly exceed the limit.
+func (s *BorrowServiceServer) checkActiveLimit(ctx context.Context, userId string) error {
+	response, err := s.CountActiveBorrows(ctx, &pb.CountBorrowRequest{UserId: userId})
+	if err != nil {
+		return err
+	}
+
+	limit := s.maxActive()
+	if response.Count >= int64(limit) {
+		return status.Errorf(codes.ResourceExhausted, "Borrow limit reached, return a book first (max %d active borrows)", limit)
+	}
+	return nil
+}
+
+// Counts borrows that haven't been returned, optionally only those of a
+// collection and/or user
+func (s *BorrowServiceServer) CountActiveBorrows(ctx context.Context, in *pb.CountBorrowRequest) (*pb.BorrowCountResponse, error) {
+	filter := bson.M{"return_date": nil}
+	if in.CollectionId != "" {
+		collectionId, err := primitive.ObjectIDFromHex(in.CollectionId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid collection ID")
+		}
+		filter["collection_id"] = collectionId
+	}
+	if in.UserId != "" {
+		userId, err := primitive.ObjectIDFromHex(in.UserId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid user ID")
+		}
+		filter["user_id"] = userId
+	}
+
+	// Check cache first
+	cacheKey := activeBorrowsKey(in.CollectionId, in.UserId)
+	if count, found := utils.GetCachedData[int64](ctx, s.Cache, cacheKey); found {
+		return &pb.BorrowCountResponse{
+			Count:   *count,
+			Success: true,
+			Message: "Borrows counted successfully!",
+		}, nil
+	}
+
+	count, err := s.Service.Count(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Cache result
+	utils.SetCachedData(ctx, s.Cache, cacheKey, count, ActiveBorrowsCountTTL)
+
+	return &pb.BorrowCountResponse{
+		Count:   count,
+		Success: true,
+		Message: "Borrows counted successfully!",
+	}, nil
 }
 
 func (s *BorrowServiceServer) fetchBookAndCollection(ctx context.Context, collectionId string) (*model.Book, error) {
@@ -131,6 +503,32 @@ func (s *BorrowServiceServer) fetchBookAndCollection(ctx context.Context, collec
 	return book, nil
 }
 
+// Only a dialed user service gets a client, so deployments without one keep
+// accepting any well-formed user ID
+func newUserClient(conn *grpc.ClientConn) pb.UserServiceClient {
+	if conn == nil {
+		return nil
+	}
+	return pb.NewUserServiceClient(conn)
+}
+
+// Asks the user service whether the user exists, a no-op without one
+func (s *BorrowServiceServer) checkUserExists(ctx context.Context, userId string) error {
+	if s.UserClient == nil {
+		return nil
+	}
+
+	_, err := s.UserClient.FindUserById(ctx, &pb.FindUserRequest{Id: userId})
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.NotFound, "User not found")
+	}
+	if err != nil {
+		log.Printf("Error retrieving user: %v", err)
+		return status.Error(codes.Internal, "Error retrieving user info")
+	}
+	return nil
+}
+
 func (s *BorrowServiceServer) getCollection(ctx context.Context, collectionId string) (*model.Collection, error) {
 	response, err := s.CollectionClient.FindCollectionById(ctx, &pb.FindCollectionRequest{Id: collectionId})
 	if status.Code(err) == codes.NotFound {
@@ -150,7 +548,12 @@ func (s *BorrowServiceServer) getCollection(ctx context.Context, collectionId st
 }
 
 func (s *BorrowServiceServer) getBook(ctx context.Context, collectionId string) (*model.Book, error) {
-	// Try to get an available book first
+	// Take a cached available book first
+	if book, ok := s.popAvailableBook(ctx, collectionId); ok {
+		return book, nil
+	}
+
+	// Nothing cached, let the book service look one up
 	bookResponse, err := s.BookClient.GetAvailableBook(ctx, &pb.GetAvailableBookRequest{CollectionId: collectionId})
 	if err != nil {
 		return nil, err
@@ -166,27 +569,49 @@ func (s *BorrowServiceServer) getBook(ctx context.Context, collectionId string)
 	return nil, status.Error(codes.Internal, "Unknown error")
 }
 
-func (s *BorrowServiceServer) createBorrowWithCompensation(ctx context.Context, book *model.Book, collectionId string) (*model.Borrow, error) {
-	now := time.Now()
-	due := now.AddDate(0, 0, 7)
+// Removes a random book from the collection's available_books set. SPOP is
+// atomic, so concurrent borrows never reserve the same book.
+func (s *BorrowServiceServer) popAvailableBook(ctx context.Context, collectionId string) (*model.Book, bool) {
+	collectionIdObj, err := primitive.ObjectIDFromHex(collectionId)
+	if err != nil {
+		log.Printf("Error converting collection id to object id: %v", err)
+		return nil, false
+	}
 
-	collection_id, err := primitive.ObjectIDFromHex(collectionId)
+	member, err := s.Cache.SPop(ctx, "available_books:"+collectionId).Result()
 	if err != nil {
-		return nil, err
+		if err != redis.Nil {
+			log.Printf("Error popping available book: %v", err)
+		}
+		return nil, false
 	}
 
-	needsBookUpdate := !book.IsBorrowed // If book wasn't already borrowed, we need to mark it
+	bookId, err := primitive.ObjectIDFromHex(member)
+	if err != nil {
+		log.Printf("Error converting book id to object id: %v", err)
+		return nil, false
+	}
 
-	if needsBookUpdate {
-		if err := s.markBookBorrowedStatus(ctx, book.Id.Hex(), true, now); err != nil {
-			return nil, err
-		}
+	return &model.Book{
+		Id:           bookId,
+		CollectionId: collectionIdObj,
+		IsBorrowed:   false,
+	}, true
+}
+
+func (s *BorrowServiceServer) createBorrowWithCompensation(ctx context.Context, book *model.Book, collectionId string, userId primitive.ObjectID, loanDays int) (*model.Borrow, error) {
+	now := time.Now()
+	due := now.AddDate(0, 0, loanDays)
+
+	collection_id, err := primitive.ObjectIDFromHex(collectionId)
+	if err != nil {
+		return nil, err
 	}
 
 	newBorrow := &model.Borrow{
 		Id:           primitive.NewObjectID(),
 		BookId:       book.Id,
-		UserId:       primitive.NewObjectID(), // TODO: use real user ID
+		UserId:       userId,
 		CollectionId: collection_id,
 		BorrowDate:   now,
 		DueDate:      &due,
@@ -194,23 +619,81 @@ func (s *BorrowServiceServer) createBorrowWithCompensation(ctx context.Context,
 		UpdatedAt:    now,
 	}
 
+	// Reserve the book in the collection's available count first, the
+	// collection service refuses to take it below zero
+	if err := s.adjustAvailableBooks(ctx, collectionId, -1); err != nil {
+		s.updateCache(ctx, book.Id.Hex(), collectionId, "put")
+		return nil, err
+	}
+
+	// A single insert is already atomic, so no transaction is needed (which
+	// would also require a replica set); the book service update below is only
+	// made once it succeeds and is compensated manually if it fails.
 	if err := s.Service.Create(ctx, *newBorrow); err != nil {
-		// Mark book as not borrowed on failure
-		s.markBookBorrowedStatus(ctx, book.Id.Hex(), false, now)
+		s.releaseAvailableBook(ctx, collectionId)
 		s.updateCache(ctx, book.Id.Hex(), collectionId, "put")
 		return nil, status.Errorf(codes.Internal, "failed to create borrow record: %v", err)
 	}
 
+	needsBookUpdate := !book.IsBorrowed // If book wasn't already borrowed, we need to mark it
+
+	if needsBookUpdate {
+		if err := s.markBookBorrowedStatus(ctx, book.Id.Hex(), true); err != nil {
+			// Remove the borrow record on failure
+			if _, delErr := s.Service.Delete(ctx, newBorrow.Id.Hex()); delErr != nil {
+				log.Printf("Error removing borrow record %s: %v", newBorrow.Id.Hex(), delErr)
+			}
+			s.releaseAvailableBook(ctx, collectionId)
+			s.updateCache(ctx, book.Id.Hex(), collectionId, "put")
+			return nil, err
+		}
+	}
+
 	return newBorrow, nil
 }
 
-func (s *BorrowServiceServer) markBookBorrowedStatus(ctx context.Context, bookId string, borrowed bool, timestamp time.Time) error {
+// Moves the collection's available_books counter, borrowing only changes how
+// many books are available, never the total
+func (s *BorrowServiceServer) adjustAvailableBooks(ctx context.Context, collectionId string, delta int32) error {
+	response, err := s.CollectionClient.AdjustBookStock(ctx, &pb.AdjustBookStockRequest{
+		Id:             collectionId,
+		AvailableDelta: delta,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to update available books: %v", err)
+	}
+	if !response.Success {
+		return status.Error(codes.FailedPrecondition, "No books available in this collection")
+	}
+	return nil
+}
+
+// Gives back a book reserved by a borrow that could not be completed
+func (s *BorrowServiceServer) releaseAvailableBook(ctx context.Context, collectionId string) {
+	if err := s.adjustAvailableBooks(ctx, collectionId, 1); err != nil {
+		log.Printf("Error releasing reserved book of collection %s: %v", collectionId, err)
+	}
+}
+
+// Reopens a borrow whose return couldn't be completed
+func (s *BorrowServiceServer) undoReturn(ctx context.Context, borrowId primitive.ObjectID) {
+	_, err := s.Service.UpdateMany(ctx, bson.M{"_id": borrowId}, map[string]interface{}{
+		"return_date": nil,
+		"returned_at": nil,
+		"returned_by": nil,
+	})
+	if err != nil {
+		log.Printf("Error reopening borrow %s: %v", borrowId.Hex(), err)
+	}
+	s.invalidateBorrowCache(ctx, borrowId.Hex())
+}
+
+func (s *BorrowServiceServer) markBookBorrowedStatus(ctx context.Context, bookId string, borrowed bool) error {
 	_, err := s.BookClient.UpdateBook(ctx, &pb.UpdateBookRequest{
 		Id: bookId,
 		Payload: &structpb.Struct{
 			Fields: map[string]*structpb.Value{
 				"is_borrowed": structpb.NewBoolValue(borrowed),
-				"updated_at":  structpb.NewStringValue(timestamp.UTC().Format(time.RFC3339Nano)),
 			},
 		},
 	})
@@ -220,6 +703,69 @@ func (s *BorrowServiceServer) markBookBorrowedStatus(ctx context.Context, bookId
 	return nil
 }
 
+func (s *BorrowServiceServer) renewalDays() int {
+	if s.RenewalDays > 0 {
+		return s.RenewalDays
+	}
+	return config.DefaultRenewalDays
+}
+
+func (s *BorrowServiceServer) maxRenewals() int {
+	if s.MaxRenewals > 0 {
+		return s.MaxRenewals
+	}
+	return config.DefaultMaxRenewals
+}
+
+// Picks the loan period for a borrow, requested must be positive and within
+// the maximum, zero means the service default
+func (s *BorrowServiceServer) resolveLoanDays(requested int32) (int, error) {
+	if requested == 0 {
+		return min(s.loanDays(), s.maxLoanDays()), nil
+	}
+	if requested < 0 {
+		return 0, status.Error(codes.InvalidArgument, "Loan days must be positive")
+	}
+	if int(requested) > s.maxLoanDays() {
+		return 0, status.Errorf(codes.InvalidArgument, "Loan days cannot exceed %d", s.maxLoanDays())
+	}
+	return int(requested), nil
+}
+
+func (s *BorrowServiceServer) loanDays() int {
+	if s.LoanDays > 0 {
+		return s.LoanDays
+	}
+	return config.DefaultLoanDays
+}
+
+func (s *BorrowServiceServer) maxLoanDays() int {
+	if s.MaxLoanDays > 0 {
+		return s.MaxLoanDays
+	}
+	return config.DefaultMaxLoanDays
+}
+
+func (s *BorrowServiceServer) maxActive() int {
+	if s.MaxActive > 0 {
+		return s.MaxActive
+	}
+	return config.DefaultMaxActive
+}
+
+// Zero FinePerDay means fines are off, so unlike the other settings it has
+// no fallback
+func (s *BorrowServiceServer) fine(daysOverdue int) int64 {
+	return int64(daysOverdue) * s.FinePerDay
+}
+
+func (s *BorrowServiceServer) dueSoonDays() int {
+	if s.DueSoonDays > 0 {
+		return s.DueSoonDays
+	}
+	return config.DefaultDueSoonDays
+}
+
 func (s *BorrowServiceServer) buildResponse(success bool, message string, borrowId string, bookId string) *pb.BorrowServiceResponse {
 	return &pb.BorrowServiceResponse{
 		Id:      borrowId,
@@ -229,6 +775,44 @@ func (s *BorrowServiceServer) buildResponse(success bool, message string, borrow
 	}
 }
 
+func (s *BorrowServiceServer) buildListResponse(success bool, message string, borrows []model.Borrow) *pb.BorrowListResponse {
+	records := make([]*model.Borrow, len(borrows))
+	for i := range borrows {
+		records[i] = &borrows[i]
+	}
+
+	return &pb.BorrowListResponse{
+		Success: success,
+		Borrow:  model.ToPbBorrows(records),
+		Message: message,
+	}
+}
+
+// Reads a borrow record through the borrow:<id> cache, filling it on a miss
+func (s *BorrowServiceServer) findBorrow(ctx context.Context, id string) (*model.Borrow, error) {
+	if borrowRecord, found := s.getCachedBorrow(ctx, id); found {
+		return borrowRecord, nil
+	}
+
+	borrowRecord, err := s.Service.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set cache
+	utils.SetCachedData(ctx, s.Cache, "borrow:"+id, borrowRecord, s.CacheTTL.BorrowTTL)
+
+	return borrowRecord, nil
+}
+
+func (s *BorrowServiceServer) getCachedBorrow(ctx context.Context, id string) (*model.Borrow, bool) {
+	return utils.GetCachedData[model.Borrow](ctx, s.Cache, "borrow:"+id)
+}
+
+func (s *BorrowServiceServer) invalidateBorrowCache(ctx context.Context, id string) {
+	utils.InvalidateCache(ctx, s.Cache, "borrow:"+id)
+}
+
 func (s *BorrowServiceServer) updateCache(ctx context.Context, bookId string, collectionId string, action string) {
 	cacheKey := "available_books:" + collectionId
 
@@ -242,7 +826,7 @@ func (s *BorrowServiceServer) updateCache(ctx context.Context, bookId string, co
 	if existInCache > 0 {
 		switch action {
 		case "put":
-			err = s.Cache.SAdd(ctx, cacheKey, bookId, time.Hour).Err()
+			err = utils.AddToSet(ctx, s.Cache, cacheKey, s.CacheTTL.AvailableBooksTTL, bookId)
 			if err != nil {
 				s.Cache.Del(ctx, cacheKey)
 			}
@@ -253,9 +837,31 @@ func (s *BorrowServiceServer) updateCache(ctx context.Context, bookId string, co
 			}
 		}
 	} else if action == "put" {
-		err = s.Cache.SAdd(ctx, cacheKey, bookId, time.Hour).Err()
+		err = utils.AddToSet(ctx, s.Cache, cacheKey, s.CacheTTL.AvailableBooksTTL, bookId)
 		if err != nil {
 			s.Cache.Del(ctx, cacheKey)
 		}
 	}
 }
+
+// Cache key of an active borrow count, empty ids are left out of the filter
+func activeBorrowsKey(collectionId string, userId string) string {
+	key := "active_borrows"
+	if collectionId != "" {
+		key += ":collection:" + collectionId
+	}
+	if userId != "" {
+		key += ":user:" + userId
+	}
+	return key
+}
+
+// Drops every cached count a borrow of this collection and user is part of
+func (s *BorrowServiceServer) invalidateActiveBorrowCounts(ctx context.Context, collectionId string, userId string) {
+	utils.InvalidateCache(ctx, s.Cache,
+		activeBorrowsKey("", ""),
+		activeBorrowsKey(collectionId, ""),
+		activeBorrowsKey("", userId),
+		activeBorrowsKey(collectionId, userId),
+	)
+}
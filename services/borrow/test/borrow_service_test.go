@@ -4,9 +4,12 @@ import (
 	"borrow/internal"
 	"borrow/test/mocks"
 	"context"
+	"encoding/json"
+	"sync"
 	"testing"
 	"time"
 
+	"shared/config"
 	"shared/pkg/model"
 	pb "shared/proto/buffer"
 
@@ -16,9 +19,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -29,10 +34,14 @@ func newRedis(t *testing.T) *redis.Client {
 
 func newServer(cache *redis.Client) (*mocks.MockService[model.Borrow, model.BorrowUpdateRequest], *internal.BorrowServiceServer) {
 	mockService := &mocks.MockService[model.Borrow, model.BorrowUpdateRequest]{}
+	// Users start without active borrows so they pass the borrow limit, tests
+	// of the limit seed the cached count instead
+	mockService.On("Count", mock.Anything, mock.MatchedBy(isUserActiveFilter)).Return(int64(0), nil).Maybe()
 
 	svc := &internal.BorrowServiceServer{
 		Service:          mockService,
 		Cache:            cache,
+		CacheTTL:         *config.DefaultCacheTTLConfig(),
 		CollectionClient: mocks.NewMockCollectionService(cache),
 		BookClient:       mocks.NewMockBookService(cache),
 	}
@@ -40,6 +49,45 @@ func newServer(cache *redis.Client) (*mocks.MockService[model.Borrow, model.Borr
 	return mockService, svc
 }
 
+// The filter BorrowBook counts a user's active borrows with
+func isUserActiveFilter(filter bson.M) bool {
+	_, hasUser := filter["user_id"]
+	return len(filter) == 2 && hasUser
+}
+
+// Stores a user's active borrow count where the borrow limit reads it from
+func seedActiveBorrows(t *testing.T, cache *redis.Client, userId primitive.ObjectID, count int64) {
+	raw, err := json.Marshal(count)
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(context.Background(), "active_borrows:user:"+userId.Hex(), raw, time.Minute).Err())
+}
+
+// Matches the filter ReturnBook claims a borrow with, only records without a
+// return date may be returned
+func returnGuard(borrowId string) interface{} {
+	return mock.MatchedBy(func(filter bson.M) bool {
+		id, ok := filter["_id"].(primitive.ObjectID)
+		returnDate, guarded := filter["return_date"]
+		return ok && id.Hex() == borrowId && guarded && returnDate == nil
+	})
+}
+
+// Caches a collection so the collection client mock can apply stock changes to it
+func seedCollection(t *testing.T, cache *redis.Client, id primitive.ObjectID, total, available int) {
+	raw, err := json.Marshal(model.Collection{Id: id, TotalBooks: total, AvailableBooks: available})
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(context.Background(), "collection:"+id.Hex(), raw, time.Hour).Err())
+}
+
+func cachedCollection(t *testing.T, cache *redis.Client, id primitive.ObjectID) model.Collection {
+	raw, err := cache.Get(context.Background(), "collection:"+id.Hex()).Bytes()
+	require.NoError(t, err)
+
+	var collection model.Collection
+	require.NoError(t, json.Unmarshal(raw, &collection))
+	return collection
+}
+
 func ArrangeBorrowData() (primitive.ObjectID, primitive.ObjectID, *pb.Collection, *pb.Book, time.Time) {
 	collectionId := primitive.NewObjectID()
 	bookId := primitive.NewObjectID()
@@ -103,15 +151,18 @@ func TestBorrow_Success(t *testing.T) {
 	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
 		return req.Id == book.Id &&
 			req.Payload.Fields["is_borrowed"].GetBoolValue() == true &&
-			req.Payload.Fields["updated_at"].GetStringValue() != ""
+			req.Payload.Fields["updated_at"] == nil
 	})).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
 
 	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.MatchedBy(func(req model.Borrow) bool {
 		return req.BookId.Hex() == book.Id && req.CollectionId.Hex() == collection.Id
 	})).Return(nil)
 
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, &pb.AdjustBookStockRequest{Id: collectionId.Hex(), AvailableDelta: -1}).Return(&pb.Response{Success: true}, nil)
+	seedCollection(t, cache, collectionId, 5, 5)
+
 	// Act
-	cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex(), time.Hour)
+	cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex())
 	resp, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
 		CollectionId: collectionId.Hex(),
 		UserId:       primitive.NewObjectID().Hex(),
@@ -125,6 +176,108 @@ func TestBorrow_Success(t *testing.T) {
 
 	require.NoError(t, err2)
 	assert.False(t, exist)
+
+	cached := cachedCollection(t, cache, collectionId)
+	assert.Equal(t, 5, cached.TotalBooks)
+	assert.Equal(t, 4, cached.AvailableBooks)
+}
+
+func TestBorrow_StoresUserId(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, _, collection, book, _ := ArrangeBorrowData()
+	userId := primitive.NewObjectID()
+	ctx := context.Background()
+
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, &pb.FindCollectionRequest{Id: collectionId.Hex()}).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("GetAvailableBook", ctx, &pb.GetAvailableBookRequest{CollectionId: collectionId.Hex()}).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.MatchedBy(func(req model.Borrow) bool {
+		return req.UserId == userId
+	})).Return(nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+
+	resp, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       userId.Hex(),
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertExpectations(t)
+}
+
+func TestBorrow_InvalidUserId(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, bookId, _, _, _ := ArrangeBorrowData()
+	ctx := context.Background()
+
+	cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex())
+	_, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       "not-a-user",
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Rejected before any book state changes
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "GetAvailableBook", mock.Anything, mock.Anything)
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+	exist, err := cache.SIsMember(ctx, "available_books:"+collectionId.Hex(), bookId.Hex()).Result()
+	require.NoError(t, err)
+	assert.True(t, exist)
+}
+
+func TestBorrow_ExistingUserWithUserService(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, bookId, collection, book, _ := ArrangeBorrowData()
+	userId := primitive.NewObjectID().Hex()
+	ctx := context.Background()
+
+	userClient := &mocks.MockUserServiceClient{}
+	userClient.On("FindUserById", ctx, &pb.FindUserRequest{Id: userId}).
+		Return(&pb.UserResponse{Success: true, User: []*pb.User{{Id: userId}}}, nil).Once()
+	mockService.UserClient = userClient
+
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, mock.Anything).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.Anything).Return(nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex())
+
+	resp, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{CollectionId: collectionId.Hex(), UserId: userId})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	userClient.AssertExpectations(t)
+}
+
+func TestBorrow_UnknownUserWithUserService(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, bookId, _, _, _ := ArrangeBorrowData()
+	ctx := context.Background()
+
+	userClient := &mocks.MockUserServiceClient{}
+	userClient.On("FindUserById", ctx, mock.Anything).Return(nil, status.Error(codes.NotFound, "User not found")).Once()
+	mockService.UserClient = userClient
+
+	cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex())
+	_, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       primitive.NewObjectID().Hex(),
+	})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Rejected before any book state changes
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+	exist, err := cache.SIsMember(ctx, "available_books:"+collectionId.Hex(), bookId.Hex()).Result()
+	require.NoError(t, err)
+	assert.True(t, exist)
 }
 
 func TestBorrow_FailedCollectionFetch(t *testing.T) {
@@ -175,13 +328,33 @@ func TestBorrow_UpdateBookFailure(t *testing.T) {
 	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
 		return req.Id == book.Id &&
 			req.Payload.Fields["is_borrowed"].GetBoolValue() == true &&
-			req.Payload.Fields["updated_at"].GetStringValue() != ""
+			req.Payload.Fields["updated_at"] == nil
 	})).Return(nil, status.Error(codes.Internal, "Error updating book status"))
 
+	var createdId string
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.MatchedBy(func(req model.Borrow) bool {
+		createdId = req.Id.Hex()
+		return req.BookId.Hex() == book.Id
+	})).Return(nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Delete", ctx, mock.MatchedBy(func(id string) bool {
+		return id == createdId
+	})).Return(model.Borrow{}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	seedCollection(t, cache, collectionId, 5, 5)
+
 	_, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
 		CollectionId: collection.Id,
+		UserId:       primitive.NewObjectID().Hex(),
 	})
 	require.Error(t, err)
+
+	// Committed borrow record is compensated and the book is available again
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertExpectations(t)
+	members, err := cache.SMembers(ctx, "available_books:"+collectionId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{book.Id}, members)
+	assert.Equal(t, config.DefaultCacheTTL, cache.TTL(ctx, "available_books:"+collectionId.Hex()).Val())
+	assert.Equal(t, 5, cachedCollection(t, cache, collectionId).AvailableBooks)
 }
 
 func TestBorrow_CreateBorrowFailure(t *testing.T) {
@@ -196,23 +369,87 @@ func TestBorrow_CreateBorrowFailure(t *testing.T) {
 	mockService.BookClient.(*mocks.MockBookServiceClient).On("GetAvailableBook", ctx, &pb.GetAvailableBookRequest{CollectionId: collectionId.Hex()}).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
 
 	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
-		return req.Id == book.Id && req.Payload.Fields["updated_at"].GetStringValue() != ""
+		return req.Id == book.Id && req.Payload.Fields["updated_at"] == nil
 	})).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
 
 	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.MatchedBy(func(req model.Borrow) bool {
 		return req.BookId.Hex() == book.Id && req.CollectionId.Hex() == collection.Id
 	})).Return(status.Error(codes.Internal, "Error creating borrow record"))
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	seedCollection(t, cache, collectionId, 5, 5)
 
-	cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex(), time.Hour)
+	cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex())
 	_, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
 		CollectionId: collectionId.Hex(),
 		UserId:       primitive.NewObjectID().Hex(),
 	})
 	require.Error(t, err)
 
+	// Book status is only updated once the borrow record is committed
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+
 	exist, err := cache.SIsMember(ctx, "available_books:"+collectionId.Hex(), book.Id).Result()
 	require.NoError(t, err)
 	assert.True(t, exist)
+	assert.Equal(t, 5, cachedCollection(t, cache, collectionId).AvailableBooks)
+}
+
+func TestBorrow_NoStockLeft(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	collectionId, _, collection, book, _ := ArrangeBorrowData()
+	ctx := context.Background()
+
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, &pb.FindCollectionRequest{Id: collectionId.Hex()}).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("GetAvailableBook", ctx, &pb.GetAvailableBookRequest{CollectionId: collectionId.Hex()}).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	// The collection service refuses to take available_books below zero
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, &pb.AdjustBookStockRequest{Id: collectionId.Hex(), AvailableDelta: -1}).Return(&pb.Response{Success: false, Message: "No book updated"}, nil)
+
+	_, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       primitive.NewObjectID().Hex(),
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestBorrowThenReturn_RestoresAvailableBooks(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	collectionId, _, collection, book, _ := ArrangeBorrowData()
+	ctx := context.Background()
+	seedCollection(t, cache, collectionId, 5, 3)
+
+	var created model.Borrow
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, mock.Anything).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("GetAvailableBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.MatchedBy(func(req model.Borrow) bool {
+		created = req
+		return true
+	})).Return(nil)
+
+	borrowResp, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       primitive.NewObjectID().Hex(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cachedCollection(t, cache, collectionId).AvailableBooks)
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowResp.Id).Return(&created, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowResp.Id), mock.Anything).Return(int64(1), nil)
+
+	_, err = mockService.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowResp.Id})
+	require.NoError(t, err)
+
+	cached := cachedCollection(t, cache, collectionId)
+	assert.Equal(t, 3, cached.AvailableBooks)
+	assert.Equal(t, 5, cached.TotalBooks)
 }
 
 func TestReturn_Success(t *testing.T) {
@@ -223,16 +460,19 @@ func TestReturn_Success(t *testing.T) {
 	ctx := context.Background()
 
 	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
-		return req.Id == book.Id && req.Payload.Fields["updated_at"].GetStringValue() != ""
+		return req.Id == book.Id && req.Payload.Fields["updated_at"] == nil
 	})).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
 
 	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
 
-	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Update", ctx, mock.MatchedBy(func(req map[string]interface{}) bool {
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.MatchedBy(func(req map[string]interface{}) bool {
 		_, ok1 := req["return_date"]
 		_, ok2 := req["updated_at"]
-		return ok1 && ok2
-	}), borrowId.Hex()).Return(borrowRecord, nil)
+		return ok1 && !ok2
+	})).Return(int64(1), nil)
+
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, &pb.AdjustBookStockRequest{Id: collectionId.Hex(), AvailableDelta: 1}).Return(&pb.Response{Success: true}, nil)
+	seedCollection(t, cache, collectionId, 5, 4)
 
 	resp, err := mockService.ReturnBook(ctx, &pb.ReturnRequest{
 		BorrowId: borrowId.Hex(),
@@ -245,6 +485,51 @@ func TestReturn_Success(t *testing.T) {
 
 	require.NoError(t, err2)
 	assert.True(t, exist)
+
+	cached := cachedCollection(t, cache, collectionId)
+	assert.Equal(t, 5, cached.TotalBooks)
+	assert.Equal(t, 5, cached.AvailableBooks)
+}
+
+func TestReturn_RecordsWhoReturned(t *testing.T) {
+	for name, tc := range map[string]struct {
+		returnedBy string
+		want       interface{}
+	}{
+		"supplied": {" librarian-7 ", "librarian-7"},
+		"absent":   {"", nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := newRedis(t)
+			_, svc := newServer(cache)
+			collectionId, _, borrowId, book, borrowRecord, _ := ArrangeReturnData()
+			ctx := context.Background()
+			before := time.Now().UTC()
+
+			var update map[string]interface{}
+			svc.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+			svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+			svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.MatchedBy(func(req map[string]interface{}) bool {
+				update = req
+				return true
+			})).Return(int64(1), nil)
+			svc.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+			seedCollection(t, cache, collectionId, 5, 4)
+
+			_, err := svc.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowId.Hex(), ReturnedBy: tc.returnedBy})
+			require.NoError(t, err)
+
+			returnedAt, ok := update["returned_at"].(time.Time)
+			require.True(t, ok, "returned_at is stored as a time")
+			assert.False(t, returnedAt.Before(before))
+			returnedBy, stored := update["returned_by"]
+			if tc.want == nil {
+				assert.False(t, stored)
+			} else {
+				assert.Equal(t, tc.want, returnedBy)
+			}
+		})
+	}
 }
 
 func TestReturn_NotFound(t *testing.T) {
@@ -286,15 +571,69 @@ func TestReturn_BookUpdateFailure(t *testing.T) {
 	ctx := context.Background()
 
 	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
-		return req.Id == book.Id && req.Payload.Fields["updated_at"].GetStringValue() != ""
+		return req.Id == book.Id && req.Payload.Fields["updated_at"] == nil
 	})).Return(nil, status.Error(codes.Aborted, "failed to mark book as returned"))
 
 	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.Anything).Return(int64(1), nil).Once()
+
+	// The claimed return is reopened since the book is still marked borrowed
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, bson.M{"_id": borrowRecord.Id}, mock.MatchedBy(func(req map[string]interface{}) bool {
+		returnDate, ok := req["return_date"]
+		return ok && returnDate == nil
+	})).Return(int64(1), nil).Once()
 
 	_, err := mockService.ReturnBook(ctx, &pb.ReturnRequest{
 		BorrowId: borrowId.Hex(),
 	})
 	require.Error(t, err)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertExpectations(t)
+}
+
+func TestReturn_ConcurrentReturnsOnlyOneWins(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, _, borrowId, book, borrowRecord, _ := ArrangeReturnData()
+	ctx := context.Background()
+	seedCollection(t, cache, collectionId, 5, 4)
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+	// Both returns read the record before either writes, the database guard
+	// lets the first update through and matches nothing for the second
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.Anything).Return(int64(1), nil).Once()
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.Anything).Return(int64(0), nil).Once()
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+
+	start := make(chan struct{})
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, errs[i] = mockService.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowId.Hex()})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	var succeeded, rejected int
+	for _, err := range errs {
+		switch status.Code(err) {
+		case codes.OK:
+			succeeded++
+		case codes.FailedPrecondition:
+			rejected++
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, rejected)
+
+	// Only the winning return freed the book
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNumberOfCalls(t, "UpdateBook", 1)
+	assert.Equal(t, 5, cachedCollection(t, cache, collectionId).AvailableBooks)
 }
 
 func TestReturn_BorrowUpdateFailure(t *testing.T) {
@@ -305,19 +644,916 @@ func TestReturn_BorrowUpdateFailure(t *testing.T) {
 	ctx := context.Background()
 
 	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
-		return req.Id == book.Id && req.Payload.Fields["updated_at"].GetStringValue() != ""
+		return req.Id == book.Id && req.Payload.Fields["updated_at"] == nil
 	})).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
 
 	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
 
-	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Update", ctx, mock.MatchedBy(func(req map[string]interface{}) bool {
-		_, ok1 := req["return_date"]
-		_, ok2 := req["updated_at"]
-		return ok1 && ok2
-	}), borrowId.Hex()).Return(nil, status.Error(codes.Internal, "failed to update borrow record"))
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.Anything).Return(int64(0), status.Error(codes.Internal, "failed to update borrow record"))
 
 	_, err := mockService.ReturnBook(ctx, &pb.ReturnRequest{
 		BorrowId: borrowId.Hex(),
 	})
 	require.Error(t, err)
 }
+
+// Answers List with the records the filter selects, so tests can assert which
+// borrows a query leaves out rather than the filter it was built from
+func listMatching(m *mocks.MockService[model.Borrow, model.BorrowUpdateRequest], ctx context.Context, records ...model.Borrow) {
+	call := m.On("List", ctx, mock.Anything)
+	call.Run(func(args mock.Arguments) {
+		matched := []model.Borrow{}
+		for _, record := range records {
+			if borrowMatches(args.Get(1).(bson.M), record) {
+				matched = append(matched, record)
+			}
+		}
+		call.ReturnArguments = mock.Arguments{matched, nil}
+	})
+}
+
+// Evaluates the return_date and due_date conditions the borrow queries use,
+// anything else doesn't match
+func borrowMatches(filter bson.M, record model.Borrow) bool {
+	for key, cond := range filter {
+		switch key {
+		case "return_date":
+			if cond != nil || record.ReturnDate != nil {
+				return false
+			}
+		case "due_date":
+			ops, ok := cond.(bson.M)
+			if !ok || record.DueDate == nil {
+				return false
+			}
+			for op, value := range ops {
+				bound, ok := value.(time.Time)
+				if !ok {
+					return false
+				}
+				switch op {
+				case "$lt":
+					if !record.DueDate.Before(bound) {
+						return false
+					}
+				case "$lte":
+					if record.DueDate.After(bound) {
+						return false
+					}
+				case "$gte":
+					if record.DueDate.Before(bound) {
+						return false
+					}
+				default:
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetOverdueBorrows_OnlyUnreturnedPastDue(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	ctx := context.Background()
+	_, _, _, _, borrowRecord, now := ArrangeReturnData()
+
+	overdue := *borrowRecord
+	overdueDue := now.Add(-24 * time.Hour)
+	overdue.DueDate = &overdueDue
+
+	notDue := *borrowRecord
+	notDue.Id = primitive.NewObjectID()
+	notDueDue := now.Add(24 * time.Hour)
+	notDue.DueDate = &notDueDue
+
+	returned := overdue
+	returned.Id = primitive.NewObjectID()
+	returnDate := now.Add(-time.Hour)
+	returned.ReturnDate = &returnDate
+
+	listMatching(mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]), ctx, overdue, notDue, returned)
+
+	resp, err := mockService.GetOverdueBorrows(ctx, &pb.OverdueRequest{Limit: 10})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	ids := make([]string, len(resp.Borrow))
+	for i, borrow := range resp.Borrow {
+		ids[i] = borrow.Id
+	}
+	assert.Equal(t, []string{overdue.Id.Hex()}, ids)
+	assert.NotContains(t, ids, notDue.Id.Hex(), "a borrow due in the future isn't overdue")
+	assert.NotContains(t, ids, returned.Id.Hex(), "a returned borrow isn't overdue")
+}
+
+func TestGetOverdueBorrows_ListFailure(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	ctx := context.Background()
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("List", ctx, mock.Anything).Return([]model.Borrow{}, mongo.ErrClientDisconnected)
+
+	resp, err := mockService.GetOverdueBorrows(ctx, &pb.OverdueRequest{})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRenew_Success(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, _, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 3)
+	borrowRecord.DueDate = &due
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Update", ctx, mock.MatchedBy(func(req map[string]interface{}) bool {
+		newDue, ok := req["due_date"].(time.Time)
+		return ok && newDue.Equal(due.AddDate(0, 0, config.DefaultRenewalDays)) && req["renewal_count"] == 1
+	}), borrowId.Hex()).Return(borrowRecord, nil)
+
+	resp, err := mockService.RenewBook(ctx, &pb.RenewRequest{BorrowId: borrowId.Hex()})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, borrowId.Hex(), resp.Id)
+}
+
+func TestRenew_LimitReached(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, _, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 3)
+	borrowRecord.DueDate = &due
+	borrowRecord.RenewalCount = config.DefaultMaxRenewals
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+
+	_, err := mockService.RenewBook(ctx, &pb.RenewRequest{BorrowId: borrowId.Hex()})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRenew_ConfiguredDaysAndLimit(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	mockService.RenewalDays = 14
+	mockService.MaxRenewals = 3
+
+	_, _, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 3)
+	borrowRecord.DueDate = &due
+	borrowRecord.RenewalCount = config.DefaultMaxRenewals
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Update", ctx, mock.MatchedBy(func(req map[string]interface{}) bool {
+		newDue, ok := req["due_date"].(time.Time)
+		return ok && newDue.Equal(due.AddDate(0, 0, 14)) && req["renewal_count"] == config.DefaultMaxRenewals+1
+	}), borrowId.Hex()).Return(borrowRecord, nil)
+
+	resp, err := mockService.RenewBook(ctx, &pb.RenewRequest{BorrowId: borrowId.Hex()})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+}
+
+func TestRenew_Overdue(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, _, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, -1)
+	borrowRecord.DueDate = &due
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+
+	_, err := mockService.RenewBook(ctx, &pb.RenewRequest{BorrowId: borrowId.Hex()})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetBorrowsByUser_ActiveOnly(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, _, _, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 7)
+	borrowRecord.UserId = primitive.NewObjectID()
+	borrowRecord.DueDate = &due
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("List", ctx, mock.MatchedBy(func(filter bson.M) bool {
+		returnDate, hasReturnDate := filter["return_date"]
+		return filter["user_id"] == borrowRecord.UserId && hasReturnDate && returnDate == nil
+	})).Return([]model.Borrow{*borrowRecord}, nil)
+
+	resp, err := mockService.GetBorrowsByUser(ctx, &pb.UserBorrowsRequest{UserId: borrowRecord.UserId.Hex(), ActiveOnly: true})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Borrow, 1)
+	assert.Equal(t, borrowRecord.UserId.Hex(), resp.Borrow[0].UserId)
+}
+
+func TestGetBorrowsByUser_FullHistory(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, _, _, _, active, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 7)
+	active.UserId = primitive.NewObjectID()
+	active.DueDate = &due
+
+	returned := *active
+	returned.Id = primitive.NewObjectID()
+	returned.ReturnDate = &now
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("List", ctx, mock.MatchedBy(func(filter bson.M) bool {
+		_, hasReturnDate := filter["return_date"]
+		return filter["user_id"] == active.UserId && !hasReturnDate
+	})).Return([]model.Borrow{*active, returned}, nil)
+
+	resp, err := mockService.GetBorrowsByUser(ctx, &pb.UserBorrowsRequest{UserId: active.UserId.Hex()})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Borrow, 2)
+	assert.Empty(t, resp.Borrow[0].ReturnDate)
+	assert.NotEmpty(t, resp.Borrow[1].ReturnDate)
+}
+
+func TestGetBorrowsByUser_InvalidUserId(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, err := mockService.GetBorrowsByUser(context.Background(), &pb.UserBorrowsRequest{UserId: "not-an-id"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetBorrows_EmptyFilterListsAll(t *testing.T) {
+	_, mockService := newServer(newRedis(t))
+	ctx := context.Background()
+
+	_, _, _, _, first, _ := ArrangeReturnData()
+	_, _, _, _, second, _ := ArrangeReturnData()
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).
+		On("List", ctx, bson.M{}).Return([]model.Borrow{*first, *second}, nil).Once()
+
+	resp, err := mockService.GetBorrows(ctx, &pb.GetBorrowsRequest{})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	require.Len(t, resp.Borrow, 2)
+	assert.Equal(t, first.Id.Hex(), resp.Borrow[0].Id)
+	assert.Equal(t, second.Id.Hex(), resp.Borrow[1].Id)
+}
+
+func TestGetBorrows_ConvertsReferenceIds(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, _, _, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 7)
+	borrowRecord.UserId = primitive.NewObjectID()
+	borrowRecord.DueDate = &due
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("List", ctx, bson.M{"user_id": borrowRecord.UserId}).Return([]model.Borrow{*borrowRecord}, nil)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{"user_id": borrowRecord.UserId.Hex()})
+	require.NoError(t, err)
+
+	resp, err := mockService.GetBorrows(ctx, &pb.GetBorrowsRequest{Filter: filter, Limit: 10})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Borrow, 1)
+	assert.Equal(t, borrowRecord.Id.Hex(), resp.Borrow[0].Id)
+}
+
+func TestGetBorrows_InvalidReferenceId(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{"book_id": "not-an-id"})
+	require.NoError(t, err)
+
+	_, err = mockService.GetBorrows(context.Background(), &pb.GetBorrowsRequest{Filter: filter})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestBorrow_ConcurrentBorrowsGetDistinctBooks(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, _, collection, book, _ := ArrangeBorrowData()
+	ctx := context.Background()
+
+	const n = 20
+	for range n {
+		require.NoError(t, cache.SAdd(ctx, "available_books:"+collectionId.Hex(), primitive.NewObjectID().Hex()).Err())
+	}
+
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, &pb.FindCollectionRequest{Id: collectionId.Hex()}).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.Anything).Return(nil)
+
+	var wg sync.WaitGroup
+	bookIds := make(chan string, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+				CollectionId: collectionId.Hex(),
+				UserId:       primitive.NewObjectID().Hex(),
+			})
+			if assert.NoError(t, err) {
+				bookIds <- resp.BookId
+			}
+		}()
+	}
+	wg.Wait()
+	close(bookIds)
+
+	seen := map[string]bool{}
+	for id := range bookIds {
+		assert.False(t, seen[id], "book %s borrowed twice", id)
+		seen[id] = true
+	}
+	assert.Len(t, seen, n)
+
+	// Every borrow was served from the set
+	size, err := cache.SCard(ctx, "available_books:"+collectionId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Zero(t, size)
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "GetAvailableBook", mock.Anything, mock.Anything)
+}
+
+func TestBorrow_CreateFailurePutsPoppedBookBack(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, bookId, collection, _, _ := ArrangeBorrowData()
+	ctx := context.Background()
+
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, &pb.FindCollectionRequest{Id: collectionId.Hex()}).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.Anything).Return(status.Error(codes.Internal, "Error creating borrow record"))
+
+	require.NoError(t, cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex()).Err())
+	_, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       primitive.NewObjectID().Hex(),
+	})
+	require.Error(t, err)
+
+	members, err := cache.SMembers(ctx, "available_books:"+collectionId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{bookId.Hex()}, members)
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "GetAvailableBook", mock.Anything, mock.Anything)
+}
+
+// Borrows a book and returns the record handed to Create
+func borrowWithLoanDays(t *testing.T, mockService *internal.BorrowServiceServer, cache *redis.Client, loanDays int32) model.Borrow {
+	collectionId, bookId, collection, book, _ := ArrangeBorrowData()
+	ctx := context.Background()
+
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, &pb.FindCollectionRequest{Id: collectionId.Hex()}).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+
+	var created model.Borrow
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.MatchedBy(func(req model.Borrow) bool {
+		created = req
+		return true
+	})).Return(nil)
+
+	require.NoError(t, cache.SAdd(ctx, "available_books:"+collectionId.Hex(), bookId.Hex()).Err())
+	_, err := mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       primitive.NewObjectID().Hex(),
+		LoanDays:     loanDays,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, created.DueDate)
+	return created
+}
+
+func TestBorrow_DefaultLoanPeriod(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	created := borrowWithLoanDays(t, mockService, cache, 0)
+
+	assert.Equal(t, created.BorrowDate.AddDate(0, 0, config.DefaultLoanDays), *created.DueDate)
+}
+
+func TestBorrow_ConfiguredLoanPeriod(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	mockService.LoanDays = 21
+
+	created := borrowWithLoanDays(t, mockService, cache, 0)
+
+	assert.Equal(t, created.BorrowDate.AddDate(0, 0, 21), *created.DueDate)
+}
+
+func TestBorrow_LoanDaysOverride(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	created := borrowWithLoanDays(t, mockService, cache, 14)
+
+	assert.Equal(t, created.BorrowDate.AddDate(0, 0, 14), *created.DueDate)
+}
+
+func TestBorrow_RejectsInvalidLoanDays(t *testing.T) {
+	for name, loanDays := range map[string]int32{
+		"negative": -3,
+		"over max": config.DefaultMaxLoanDays + 1,
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := newRedis(t)
+			_, mockService := newServer(cache)
+
+			_, err := mockService.BorrowBook(context.Background(), &pb.BorrowRequest{
+				CollectionId: primitive.NewObjectID().Hex(),
+				UserId:       primitive.NewObjectID().Hex(),
+				LoanDays:     loanDays,
+			})
+
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			mockService.CollectionClient.(*mocks.MockCollectionService).AssertNotCalled(t, "FindCollectionById", mock.Anything, mock.Anything)
+			mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// Mocks a collection with n cached available books that can be borrowed
+func arrangeIdempotentBorrow(t *testing.T, mockService *internal.BorrowServiceServer, cache *redis.Client, n int) (primitive.ObjectID, *mock.Call) {
+	collectionId, _, collection, book, _ := ArrangeBorrowData()
+	ctx := context.Background()
+
+	for range n {
+		require.NoError(t, cache.SAdd(ctx, "available_books:"+collectionId.Hex(), primitive.NewObjectID().Hex()).Err())
+	}
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("FindCollectionById", ctx, mock.Anything).Return(&pb.Response{Collection: []*pb.Collection{collection}}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	create := mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", ctx, mock.Anything).Return(nil)
+
+	return collectionId, create
+}
+
+func TestBorrow_IdempotencyKeyReplaysResponse(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, _ := arrangeIdempotentBorrow(t, mockService, cache, 2)
+	ctx := context.Background()
+	request := &pb.BorrowRequest{
+		CollectionId:   collectionId.Hex(),
+		UserId:         primitive.NewObjectID().Hex(),
+		IdempotencyKey: "retry-1",
+	}
+
+	first, err := mockService.BorrowBook(ctx, request)
+	require.NoError(t, err)
+	second, err := mockService.BorrowBook(ctx, request)
+	require.NoError(t, err)
+
+	assert.True(t, second.Success)
+	assert.Equal(t, first.Id, second.Id)
+	assert.Equal(t, first.BookId, second.BookId)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNumberOfCalls(t, "Create", 1)
+
+	// The other book is still available
+	size, err := cache.SCard(ctx, "available_books:"+collectionId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+	assert.Greater(t, cache.TTL(ctx, "idempotency:borrow:"+request.UserId+":retry-1").Val(), time.Duration(0))
+}
+
+func TestBorrow_IdempotencyKeyReleasedOnFailure(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, create := arrangeIdempotentBorrow(t, mockService, cache, 1)
+	create.Return(status.Error(codes.Internal, "Error creating borrow record")).Once()
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Create", context.Background(), mock.Anything).Return(nil)
+	request := &pb.BorrowRequest{
+		CollectionId:   collectionId.Hex(),
+		UserId:         primitive.NewObjectID().Hex(),
+		IdempotencyKey: "retry-1",
+	}
+
+	_, err := mockService.BorrowBook(context.Background(), request)
+	require.Error(t, err)
+
+	// The retry borrows for real
+	resp, err := mockService.BorrowBook(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestBorrow_IdempotencyKeyConcurrentRequests(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, create := arrangeIdempotentBorrow(t, mockService, cache, 10)
+	// Keep the first borrow running while the others arrive
+	create.Run(func(mock.Arguments) { time.Sleep(50 * time.Millisecond) })
+	request := &pb.BorrowRequest{
+		CollectionId:   collectionId.Hex(),
+		UserId:         primitive.NewObjectID().Hex(),
+		IdempotencyKey: "retry-1",
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	borrowIds := make(chan string, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := mockService.BorrowBook(context.Background(), request)
+			if err != nil {
+				assert.Equal(t, codes.Aborted, status.Code(err))
+				return
+			}
+			borrowIds <- resp.Id
+		}()
+	}
+	wg.Wait()
+	close(borrowIds)
+
+	var ids []string
+	for id := range borrowIds {
+		ids = append(ids, id)
+	}
+	require.Len(t, ids, 1)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNumberOfCalls(t, "Create", 1)
+
+	// Once finished, retries replay the single borrow
+	resp, err := mockService.BorrowBook(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, ids[0], resp.Id)
+}
+
+func TestBorrow_RejectedAtUserLimit(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	mockService.MaxActive = 2
+	collectionId, _ := arrangeIdempotentBorrow(t, mockService, cache, 1)
+	userId := primitive.NewObjectID()
+	seedActiveBorrows(t, cache, userId, 2)
+
+	resp, err := mockService.BorrowBook(context.Background(), &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       userId.Hex(),
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "max 2 active borrows")
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// No book was reserved for the rejected borrow
+	available, err := cache.SCard(context.Background(), "available_books:"+collectionId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), available)
+}
+
+func TestBorrow_AllowedAfterReturnFreesSlot(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	mockService.MaxActive = 1
+	ctx := context.Background()
+	collectionId, _ := arrangeIdempotentBorrow(t, mockService, cache, 1)
+
+	_, _, borrowId, _, borrowRecord, _ := ArrangeReturnData()
+	userId := primitive.NewObjectID()
+	borrowRecord.UserId = userId
+	seedActiveBorrows(t, cache, userId, 1)
+	request := &pb.BorrowRequest{CollectionId: collectionId.Hex(), UserId: userId.Hex()}
+
+	_, err := mockService.BorrowBook(ctx, request)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{}, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.Anything).Return(int64(1), nil)
+	_, err = mockService.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowId.Hex()})
+	require.NoError(t, err)
+
+	resp, err := mockService.BorrowBook(ctx, request)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+}
+
+func TestDeleteBorrow_ActiveBorrowFreesBook(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, bookId, borrowId, book, borrowRecord, _ := ArrangeReturnData()
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Delete", ctx, borrowId.Hex()).Return(*borrowRecord, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
+		return req.Id == book.Id && !req.Payload.Fields["is_borrowed"].GetBoolValue()
+	})).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, &pb.AdjustBookStockRequest{Id: collectionId.Hex(), AvailableDelta: 1}).Return(&pb.Response{Success: true}, nil)
+	seedCollection(t, cache, collectionId, 5, 4)
+
+	resp, err := mockService.DeleteBorrow(ctx, &pb.DeleteBorrowRequest{Id: borrowId.Hex()})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, bookId.Hex(), resp.BookId)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertExpectations(t)
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertExpectations(t)
+
+	exist, err := cache.SIsMember(ctx, "available_books:"+collectionId.Hex(), bookId.Hex()).Result()
+	require.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, 5, cachedCollection(t, cache, collectionId).AvailableBooks)
+}
+
+func TestDeleteBorrow_ReturnedBorrowLeavesBook(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, bookId, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	borrowRecord.ReturnDate = &now
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Delete", ctx, borrowId.Hex()).Return(*borrowRecord, nil)
+
+	resp, err := mockService.DeleteBorrow(ctx, &pb.DeleteBorrowRequest{Id: borrowId.Hex()})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+	mockService.CollectionClient.(*mocks.MockCollectionService).AssertNotCalled(t, "AdjustBookStock", mock.Anything, mock.Anything)
+	exist, err := cache.SIsMember(ctx, "available_books:"+collectionId.Hex(), bookId.Hex()).Result()
+	require.NoError(t, err)
+	assert.False(t, exist)
+}
+
+func TestDeleteBorrow_NotFound(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	borrowId := primitive.NewObjectID().Hex()
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId).Return(nil, mongo.ErrNoDocuments)
+
+	_, err := mockService.DeleteBorrow(ctx, &pb.DeleteBorrowRequest{Id: borrowId})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestFindBorrowById_Found(t *testing.T) {
+	_, mockService := newServer(newRedis(t))
+	_, bookId, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	dueDate := now.AddDate(0, 0, 7)
+	borrowRecord.DueDate = &dueDate
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+
+	resp, err := mockService.FindBorrowById(ctx, &pb.FindBorrowRequest{Id: borrowId.Hex()})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, bookId.Hex(), resp.BookId)
+	assert.Equal(t, borrowId.Hex(), resp.Borrow.Id)
+	assert.Equal(t, dueDate.Format(time.RFC3339), resp.Borrow.DueDate)
+	assert.False(t, resp.IsOverdue)
+}
+
+func TestFindBorrowById_NotFound(t *testing.T) {
+	_, mockService := newServer(newRedis(t))
+	borrowId := primitive.NewObjectID().Hex()
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId).Return(nil, mongo.ErrNoDocuments)
+
+	_, err := mockService.FindBorrowById(ctx, &pb.FindBorrowRequest{Id: borrowId})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestFindBorrowById_OverdueFlag(t *testing.T) {
+	_, mockService := newServer(newRedis(t))
+	_, _, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	dueDate := now.AddDate(0, 0, -1)
+	borrowRecord.DueDate = &dueDate
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+
+	resp, err := mockService.FindBorrowById(ctx, &pb.FindBorrowRequest{Id: borrowId.Hex()})
+
+	require.NoError(t, err)
+	assert.True(t, resp.IsOverdue)
+}
+
+func TestCalculateFine(t *testing.T) {
+	_, _, _, _, base, now := ArrangeReturnData()
+	// Just under three days ago, so a book still out is in its third started day
+	due := now.AddDate(0, 0, -3).Add(time.Hour)
+	onTime := due.Add(-time.Hour)
+	lateByPartialDay := due.Add(49 * time.Hour)
+
+	cases := map[string]struct {
+		returnDate *time.Time
+		wantDays   int32
+	}{
+		"returned on time":           {&onTime, 0},
+		"returned overdue":           {&lateByPartialDay, 3},
+		"still overdue and not back": {nil, 3},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, svc := newServer(newRedis(t))
+			svc.FinePerDay = 500
+			ctx := context.Background()
+
+			borrowRecord := *base
+			borrowRecord.DueDate = &due
+			borrowRecord.ReturnDate = tc.returnDate
+			svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowRecord.Id.Hex()).Return(&borrowRecord, nil)
+
+			resp, err := svc.CalculateFine(ctx, &pb.FineRequest{BorrowId: borrowRecord.Id.Hex()})
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDays, resp.DaysOverdue)
+			assert.Equal(t, int64(tc.wantDays)*500, resp.Fine)
+			assert.Equal(t, int64(500), resp.FinePerDay)
+		})
+	}
+}
+
+func TestCalculateFine_NotFound(t *testing.T) {
+	_, svc := newServer(newRedis(t))
+	ctx := context.Background()
+	borrowId := primitive.NewObjectID().Hex()
+	svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId).Return(nil, mongo.ErrNoDocuments)
+
+	_, err := svc.CalculateFine(ctx, &pb.FineRequest{BorrowId: borrowId})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestReturn_ReportsFine(t *testing.T) {
+	cache := newRedis(t)
+	_, svc := newServer(cache)
+	svc.FinePerDay = 500
+	collectionId, _, borrowId, book, borrowRecord, now := ArrangeReturnData()
+	due := now.Add(-30 * time.Hour)
+	borrowRecord.DueDate = &due
+	ctx := context.Background()
+
+	svc.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil)
+	svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.Anything).Return(int64(1), nil)
+	svc.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	seedCollection(t, cache, collectionId, 5, 4)
+
+	resp, err := svc.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowId.Hex()})
+
+	require.NoError(t, err)
+	// 30 hours late is a second started day
+	assert.Equal(t, int32(2), resp.DaysOverdue)
+	assert.Equal(t, int64(1000), resp.Fine)
+}
+
+func TestFindBorrowById_CachesOnMiss(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	_, _, borrowId, _, borrowRecord, now := ArrangeReturnData()
+	dueDate := now.AddDate(0, 0, 7)
+	borrowRecord.DueDate = &dueDate
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("FindById", ctx, borrowId.Hex()).Return(borrowRecord, nil).Once()
+
+	_, err := mockService.FindBorrowById(ctx, &pb.FindBorrowRequest{Id: borrowId.Hex()})
+	require.NoError(t, err)
+
+	ttl, err := cache.TTL(ctx, "borrow:"+borrowId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultCacheTTL, ttl)
+
+	// The second read is served from the cache
+	resp, err := mockService.FindBorrowById(ctx, &pb.FindBorrowRequest{Id: borrowId.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, borrowId.Hex(), resp.Borrow.Id)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNumberOfCalls(t, "FindById", 1)
+}
+
+func TestReturn_InvalidatesBorrowCache(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, _, borrowId, book, borrowRecord, _ := ArrangeReturnData()
+	ctx := context.Background()
+
+	raw, err := json.Marshal(borrowRecord)
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(ctx, "borrow:"+borrowId.Hex(), raw, time.Hour).Err())
+
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", ctx, mock.Anything).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("UpdateMany", ctx, returnGuard(borrowId.Hex()), mock.Anything).Return(int64(1), nil)
+	mockService.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", ctx, mock.Anything).Return(&pb.Response{Success: true}, nil)
+	seedCollection(t, cache, collectionId, 5, 4)
+
+	_, err = mockService.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowId.Hex()})
+	require.NoError(t, err)
+
+	// The cached record was used instead of the database
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNotCalled(t, "FindById", mock.Anything, mock.Anything)
+	exists, err := cache.Exists(ctx, "borrow:"+borrowId.Hex()).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestCountActiveBorrows_ExcludesReturned(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId := primitive.NewObjectID()
+	userId := primitive.NewObjectID()
+	ctx := context.Background()
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Count", ctx, bson.M{
+		"return_date":   nil,
+		"collection_id": collectionId,
+		"user_id":       userId,
+	}).Return(int64(3), nil).Once()
+
+	resp, err := mockService.CountActiveBorrows(ctx, &pb.CountBorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       userId.Hex(),
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(3), resp.Count)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertExpectations(t)
+}
+
+func TestCountActiveBorrows_InvalidId(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+
+	_, err := mockService.CountActiveBorrows(context.Background(), &pb.CountBorrowRequest{UserId: "nope"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCountActiveBorrows_CacheBustedByBorrow(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService := newServer(cache)
+	collectionId, _ := arrangeIdempotentBorrow(t, mockService, cache, 1)
+	ctx := context.Background()
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Count", ctx, bson.M{
+		"return_date":   nil,
+		"collection_id": collectionId,
+	}).Return(int64(2), nil).Once()
+	request := &pb.CountBorrowRequest{CollectionId: collectionId.Hex()}
+
+	first, err := mockService.CountActiveBorrows(ctx, request)
+	require.NoError(t, err)
+	cached, err := mockService.CountActiveBorrows(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, first.Count, cached.Count)
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNumberOfCalls(t, "Count", 1)
+
+	_, err = mockService.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       primitive.NewObjectID().Hex(),
+	})
+	require.NoError(t, err)
+
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).On("Count", ctx, mock.Anything).Return(int64(3), nil).Once()
+	after, err := mockService.CountActiveBorrows(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), after.Count)
+	// The borrow counted its user's active borrows for the limit as well
+	mockService.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).AssertNumberOfCalls(t, "Count", 3)
+}
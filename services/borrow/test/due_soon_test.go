@@ -0,0 +1,134 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"borrow/internal"
+	"borrow/test/mocks"
+	"shared/pkg/model"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Matches the due-soon filter for a window of days starting no earlier than
+// after, the upper bound must be inclusive so a borrow due exactly days from
+// now is listed
+func dueSoonFilter(after time.Time, days int) interface{} {
+	return mock.MatchedBy(func(filter bson.M) bool {
+		dueDate, ok := filter["due_date"].(bson.M)
+		if !ok {
+			return false
+		}
+		from, ok := dueDate["$gte"].(time.Time)
+		if !ok {
+			return false
+		}
+		until, ok := dueDate["$lte"].(time.Time)
+		returnDate, hasReturnDate := filter["return_date"]
+		return ok && !from.Before(after) && until.Equal(from.AddDate(0, 0, days)) &&
+			hasReturnDate && returnDate == nil
+	})
+}
+
+func TestGetDueSoon_WindowIncludesBoundary(t *testing.T) {
+	_, svc := newServer(newRedis(t))
+	ctx := context.Background()
+
+	_, _, _, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 5)
+	borrowRecord.DueDate = &due
+
+	svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).
+		On("List", ctx, dueSoonFilter(now, 5)).Return([]model.Borrow{*borrowRecord}, nil).Once()
+
+	resp, err := svc.GetDueSoon(ctx, &pb.DueSoonRequest{Days: 5})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Borrow, 1)
+	assert.Equal(t, borrowRecord.Id.Hex(), resp.Borrow[0].Id)
+	assert.Equal(t, model.BorrowStatusActive, resp.Borrow[0].Status)
+}
+
+func TestGetDueSoon_DefaultWindow(t *testing.T) {
+	_, svc := newServer(newRedis(t))
+	svc.DueSoonDays = 2
+	ctx := context.Background()
+
+	svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).
+		On("List", ctx, dueSoonFilter(time.Now().UTC(), 2)).Return([]model.Borrow{}, nil).Once()
+
+	resp, err := svc.GetDueSoon(ctx, &pb.DueSoonRequest{})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Empty(t, resp.Borrow)
+}
+
+func TestGetDueSoon_RejectsNegativeDays(t *testing.T) {
+	_, svc := newServer(newRedis(t))
+
+	resp, err := svc.GetDueSoon(context.Background(), &pb.DueSoonRequest{Days: -1})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDispatchDueSoon_PostsBorrows(t *testing.T) {
+	_, svc := newServer(newRedis(t))
+	ctx := context.Background()
+
+	_, _, _, _, borrowRecord, now := ArrangeReturnData()
+	due := now.AddDate(0, 0, 3)
+	borrowRecord.DueDate = &due
+	svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).
+		On("List", ctx, dueSoonFilter(now, 3)).Return([]model.Borrow{*borrowRecord}, nil).Once()
+
+	received := make(chan internal.DueSoonPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload internal.DueSoonPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	require.NoError(t, svc.DispatchDueSoon(ctx, server.Client(), server.URL, 3))
+
+	payload := <-received
+	assert.Equal(t, 3, payload.Days)
+	require.Len(t, payload.Borrows, 1)
+	assert.Equal(t, borrowRecord.Id, payload.Borrows[0].Id)
+	assert.Equal(t, model.BorrowStatusActive, payload.Borrows[0].Status)
+}
+
+func TestDispatchDueSoon_ReportsFailedDelivery(t *testing.T) {
+	_, svc := newServer(newRedis(t))
+	ctx := context.Background()
+
+	svc.Service.(*mocks.MockService[model.Borrow, model.BorrowUpdateRequest]).
+		On("List", ctx, mock.Anything).Return([]model.Borrow{}, nil).Once()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := svc.DispatchDueSoon(ctx, server.Client(), server.URL, 3)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
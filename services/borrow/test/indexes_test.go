@@ -0,0 +1,24 @@
+package test
+
+import (
+	"borrow/internal/db"
+	"shared/pkg/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestIndexModels_WellFormed(t *testing.T) {
+	models := db.IndexModels()
+	require.NoError(t, repository.ValidateIndexModels(models))
+
+	var keys []bson.D
+	for _, model := range models {
+		keys = append(keys, model.Keys.(bson.D))
+	}
+
+	assert.Contains(t, keys, bson.D{{Key: "user_id", Value: 1}})
+	assert.Contains(t, keys, bson.D{{Key: "due_date", Value: 1}})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/mock"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type MockCollectionService struct {
@@ -35,6 +36,14 @@ func (m *MockCollectionService) FindCollectionById(ctx context.Context, in *pb.F
 	return &pb.Response{}, args.Error(1)
 }
 
+func (m *MockCollectionService) GetCollectionWithCounts(ctx context.Context, in *pb.FindCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	return nil, nil
+}
+
+func (m *MockCollectionService) FindCollectionsByIds(ctx context.Context, in *pb.FindCollectionsByIdsRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	return nil, nil
+}
+
 func (m *MockCollectionService) AddCollection(ctx context.Context, in *pb.AddCollectionRequest, opts ...grpc.CallOption) (*pb.Response, error) {
 	return nil, nil
 }
@@ -47,10 +56,17 @@ func (m *MockCollectionService) DeleteCollection(ctx context.Context, in *pb.Del
 	return nil, nil
 }
 
-func (m *MockCollectionService) DecrementAvailableBooks(ctx context.Context, in *pb.DecrementAvailableBooksRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+func (m *MockCollectionService) AdjustBookStock(ctx context.Context, in *pb.AdjustBookStockRequest, opts ...grpc.CallOption) (*pb.Response, error) {
 	args := m.Called(ctx, in)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
 
+	// Tests that don't seed the collection only care about the call itself
 	out, err := m.cache.Get(ctx, "collection:"+in.Id).Bytes()
+	if err == redis.Nil {
+		return args.Get(0).(*pb.Response), nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +75,8 @@ func (m *MockCollectionService) DecrementAvailableBooks(ctx context.Context, in
 	if err != nil {
 		return nil, err
 	}
-	cached.TotalBooks += int(in.Amount)
+	cached.TotalBooks += int(in.TotalDelta)
+	cached.AvailableBooks += int(in.AvailableDelta)
 
 	bytes, err := json.Marshal(cached)
 	if err != nil {
@@ -70,3 +87,15 @@ func (m *MockCollectionService) DecrementAvailableBooks(ctx context.Context, in
 
 	return args.Get(0).(*pb.Response), args.Error(1)
 }
+
+func (m *MockCollectionService) SearchCollections(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	return nil, nil
+}
+
+func (m *MockCollectionService) SetSeedStatus(ctx context.Context, in *pb.SetSeedStatusRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	return nil, nil
+}
+
+func (m *MockCollectionService) GetCategories(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.CategoriesResponse, error) {
+	return nil, nil
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+type MockUserServiceClient struct {
+	mock.Mock
+}
+
+func (m *MockUserServiceClient) FindUserById(ctx context.Context, in *pb.FindUserRequest, opts ...grpc.CallOption) (*pb.UserResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.UserResponse); ok {
+		return v, args.Error(1)
+	}
+	return &pb.UserResponse{}, args.Error(1)
+}
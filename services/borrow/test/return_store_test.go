@@ -0,0 +1,71 @@
+package test
+
+import (
+	"borrow/internal"
+	"borrow/internal/db"
+	"borrow/test/mocks"
+	"context"
+	"testing"
+	"time"
+
+	"shared/config"
+	"shared/pkg/model"
+	"shared/pkg/mongotest"
+	"shared/pkg/repository"
+	"shared/pkg/service"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server whose borrows are stored in store instead of a mock service
+func newStoreServer(t *testing.T, store mongotest.Store) *internal.BorrowServiceServer {
+	client, err := mongo.Connect(options.Client().ApplyURI(mongotest.Server(t, store.Reply)))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	cache := newRedis(t)
+	return &internal.BorrowServiceServer{
+		Service:          service.NewBaseService[model.Borrow, model.BorrowUpdateRequest](repository.NewRepository[model.Borrow](client.Database("test"), db.CollectionName)),
+		Cache:            cache,
+		CacheTTL:         *config.DefaultCacheTTLConfig(),
+		CollectionClient: mocks.NewMockCollectionService(cache),
+		BookClient:       mocks.NewMockBookService(cache),
+	}
+}
+
+func TestReturn_ReturnedBorrowReadsBack(t *testing.T) {
+	store := mongotest.Store{}
+	svc := newStoreServer(t, store)
+	collectionId, _, borrowId, book, borrowRecord, _ := ArrangeReturnData()
+	require.NoError(t, store.Insert(db.CollectionName, borrowRecord))
+	ctx := context.Background()
+
+	svc.BookClient.(*mocks.MockBookServiceClient).On("UpdateBook", mock.Anything, mock.MatchedBy(func(req *pb.UpdateBookRequest) bool {
+		return req.Id == book.Id
+	})).Return(&pb.BookResponse{Book: []*pb.Book{book}}, nil)
+	svc.CollectionClient.(*mocks.MockCollectionService).On("AdjustBookStock", mock.Anything, &pb.AdjustBookStockRequest{Id: collectionId.Hex(), AvailableDelta: 1}).Return(&pb.Response{Success: true}, nil)
+
+	before := time.Now()
+	resp, err := svc.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowId.Hex()})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+
+	returned, err := svc.Service.FindById(ctx, borrowId.Hex())
+	require.NoError(t, err)
+	assert.True(t, returned.IsReturned())
+	require.NotNil(t, returned.ReturnDate)
+	require.NotNil(t, returned.ReturnedAt)
+	assert.WithinDuration(t, before, *returned.ReturnDate, time.Minute)
+	assert.True(t, returned.ReturnDate.Equal(*returned.ReturnedAt))
+
+	// A second return finds the stored return date
+	_, err = svc.ReturnBook(ctx, &pb.ReturnRequest{BorrowId: borrowId.Hex()})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
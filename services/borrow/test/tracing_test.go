@@ -0,0 +1,143 @@
+package test
+
+import (
+	"borrow/internal"
+	"borrow/test/mocks"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"shared/config"
+	"shared/pkg/model"
+	"shared/pkg/tracing"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeBookServer struct {
+	pb.UnimplementedBookServiceServer
+	book *pb.Book
+}
+
+func (f *fakeBookServer) GetAvailableBook(ctx context.Context, in *pb.GetAvailableBookRequest) (*pb.BookResponse, error) {
+	return &pb.BookResponse{Success: true, Book: []*pb.Book{f.book}}, nil
+}
+
+func (f *fakeBookServer) UpdateBook(ctx context.Context, in *pb.UpdateBookRequest) (*pb.BookResponse, error) {
+	return &pb.BookResponse{Success: true, Book: []*pb.Book{f.book}}, nil
+}
+
+type fakeCollectionServer struct {
+	pb.UnimplementedCollectionServiceServer
+	collection *pb.Collection
+}
+
+func (f *fakeCollectionServer) FindCollectionById(ctx context.Context, in *pb.FindCollectionRequest) (*pb.Response, error) {
+	return &pb.Response{Success: true, Collection: []*pb.Collection{f.collection}}, nil
+}
+
+func (f *fakeCollectionServer) AdjustBookStock(ctx context.Context, in *pb.AdjustBookStockRequest) (*pb.Response, error) {
+	return &pb.Response{Success: true}, nil
+}
+
+// Serves the given services over an in-memory listener with the tracing
+// interceptors on both ends, like the deployed services
+func dialTraced(t *testing.T, register func(*grpc.Server)) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(tracing.UnaryServerInterceptor()))
+	register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestBorrow_TracesBookAndCollectionCalls(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	provider := tracing.NewProvider("borrow-test", sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+
+	collectionId, _, collection, book, _ := ArrangeBorrowData()
+	bookConn := dialTraced(t, func(s *grpc.Server) { pb.RegisterBookServiceServer(s, &fakeBookServer{book: book}) })
+	collectionConn := dialTraced(t, func(s *grpc.Server) {
+		pb.RegisterCollectionServiceServer(s, &fakeCollectionServer{collection: collection})
+	})
+
+	baseService := &mocks.MockService[model.Borrow, model.BorrowUpdateRequest]{}
+	baseService.On("Create", mock.Anything, mock.Anything).Return(nil)
+	baseService.On("Count", mock.Anything, mock.Anything).Return(int64(0), nil)
+	svc := &internal.BorrowServiceServer{
+		Service:          baseService,
+		Cache:            newRedis(t),
+		CacheTTL:         *config.DefaultCacheTTLConfig(),
+		CollectionClient: pb.NewCollectionServiceClient(collectionConn),
+		BookClient:       pb.NewBookServiceClient(bookConn),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx, root := tracing.StartSpan(ctx, "POST /api/v1/borrow", trace.SpanKindServer)
+	resp, err := svc.BorrowBook(ctx, &pb.BorrowRequest{
+		CollectionId: collectionId.Hex(),
+		UserId:       primitive.NewObjectID().Hex(),
+	})
+	root.End()
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+
+	spans := exporter.GetSpans()
+	byName := map[string][]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+	rootId := root.SpanContext().SpanID()
+
+	// Each downstream call is a client span under the root, and the server
+	// side of it continues the same trace
+	for _, method := range []string{
+		pb.BookService_GetAvailableBook_FullMethodName,
+		pb.BookService_UpdateBook_FullMethodName,
+		pb.CollectionService_FindCollectionById_FullMethodName,
+		pb.CollectionService_AdjustBookStock_FullMethodName,
+	} {
+		calls := byName[method]
+		require.Len(t, calls, 2, method)
+
+		var client, server tracetest.SpanStub
+		for _, span := range calls {
+			if span.SpanKind == trace.SpanKindClient {
+				client = span
+			} else {
+				server = span
+			}
+		}
+		assert.Equal(t, rootId, client.Parent.SpanID(), method)
+		assert.Equal(t, root.SpanContext().TraceID(), client.SpanContext.TraceID(), method)
+		assert.Equal(t, client.SpanContext.SpanID(), server.Parent.SpanID(), method)
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind, method)
+	}
+}
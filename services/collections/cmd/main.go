@@ -2,8 +2,11 @@ package main
 
 import (
 	"collection/internal"
+	"log"
 )
 
 func main() {
-	internal.Setup()
+	if err := internal.Setup(); err != nil {
+		log.Fatalf("collection service failed: %v", err)
+	}
 }
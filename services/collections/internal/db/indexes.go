@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"shared/pkg/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const CollectionName = "collections"
+
+// Text search over the searchable fields and one live collection per
+// name+author, a soft-deleted collection doesn't block re-creating it
+func IndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "author", Value: "text"}, {Key: "categories", Value: "text"}},
+			Options: options.Index().SetName("collection_search"),
+		},
+		{
+			Keys: bson.D{{Key: "name", Value: 1}, {Key: "author", Value: 1}},
+			Options: options.Index().SetName("name_author_unique").SetUnique(true).
+				SetPartialFilterExpression(bson.M{repository.DeletedAtField: nil}),
+		},
+	}
+}
+
+func EnsureIndexes(ctx context.Context, database *mongo.Database) error {
+	return repository.EnsureIndexes(ctx, database.Collection(CollectionName), IndexModels())
+}
@@ -3,8 +3,10 @@ package internal
 import (
 	"context"
 	"log"
+	"shared/config"
 	"shared/pkg/model"
 	"shared/pkg/repository"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -12,7 +14,10 @@ import (
 )
 
 type CollectionRepositoryInterface interface {
-	UpdateBookStock(ctx context.Context, obj map[string]interface{}, id string) (interface{}, error)
+	AdjustBookStock(ctx context.Context, id string, totalDelta, availableDelta int) (*mongo.UpdateResult, error)
+	SetSeedStatus(ctx context.Context, id string, seedStatus string) (*mongo.UpdateResult, error)
+	DistinctCategories(ctx context.Context) ([]string, error)
+	FindWithBookCounts(ctx context.Context, id string) (*model.Collection, error)
 }
 
 type CollectionRepository struct {
@@ -25,7 +30,8 @@ func NewCollectionRepository(database *mongo.Database, collection_name string) *
 	}
 }
 
-func (r *CollectionRepository) UpdateBookStock(ctx context.Context, obj map[string]interface{}, id string) (interface{}, error) {
+// Moves total_books and available_books by the given deltas in a single update
+func (r *CollectionRepository) AdjustBookStock(ctx context.Context, id string, totalDelta, availableDelta int) (*mongo.UpdateResult, error) {
 	coll := r.Repository.Database.Collection(r.Repository.CollectionName)
 
 	// Convert id into Object ID
@@ -35,10 +41,22 @@ func (r *CollectionRepository) UpdateBookStock(ctx context.Context, obj map[stri
 		return nil, err
 	}
 
+	// Only match while the counters can absorb the change, so neither drops below zero
+	filter := bson.M{"_id": objectId}
+	if totalDelta < 0 {
+		filter["total_books"] = bson.M{"$gte": -totalDelta}
+	}
+	if availableDelta < 0 {
+		filter["available_books"] = bson.M{"$gte": -availableDelta}
+	}
+
 	result, err := coll.UpdateOne(
 		ctx,
-		bson.M{"_id": objectId},
-		bson.M{"$inc": obj},
+		filter,
+		bson.M{"$inc": bson.M{
+			"total_books":     totalDelta,
+			"available_books": availableDelta,
+		}},
 	)
 
 	if err != nil {
@@ -47,3 +65,91 @@ func (r *CollectionRepository) UpdateBookStock(ctx context.Context, obj map[stri
 
 	return result, err
 }
+
+// Records how creating the books of a collection went
+func (r *CollectionRepository) SetSeedStatus(ctx context.Context, id string, seedStatus string) (*mongo.UpdateResult, error) {
+	coll := r.Repository.Database.Collection(r.Repository.CollectionName)
+
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("Error converting string to object ID: %s", err)
+		return nil, err
+	}
+
+	result, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": objectId},
+		bson.M{"$set": bson.M{"seed_status": seedStatus, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Error updating data: %s", err)
+	}
+
+	return result, err
+}
+
+// Every category used by at least one live collection, in no particular order
+func (r *CollectionRepository) DistinctCategories(ctx context.Context) ([]string, error) {
+	coll := r.Repository.Database.Collection(r.Repository.CollectionName)
+
+	var categories []string
+	err := coll.Distinct(ctx, "categories", repository.ExcludeDeleted(ctx, bson.M{})).Decode(&categories)
+	if err != nil {
+		log.Printf("Error reading categories: %s", err)
+	}
+
+	return categories, err
+}
+
+// Looks up a collection with total_books and available_books counted from its
+// live books instead of read from the stored counters, which drift when a
+// stock update is lost
+func BookCountsPipeline(id primitive.ObjectID) mongo.Pipeline {
+	countBooks := bson.A{
+		bson.M{"$match": bson.M{
+			"$expr":                   bson.M{"$eq": bson.A{"$collection_id", "$$collectionId"}},
+			repository.DeletedAtField: nil,
+		}},
+		bson.M{"$group": bson.M{
+			"_id":       nil,
+			"total":     bson.M{"$sum": 1},
+			"available": bson.M{"$sum": bson.M{"$cond": bson.A{"$is_borrowed", 0, 1}}},
+		}},
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": id}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":     config.BookCollectionName,
+			"let":      bson.M{"collectionId": "$_id"},
+			"pipeline": countBooks,
+			"as":       "book_counts",
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"total_books":     bson.M{"$ifNull": bson.A{bson.M{"$first": "$book_counts.total"}, 0}},
+			"available_books": bson.M{"$ifNull": bson.A{bson.M{"$first": "$book_counts.available"}, 0}},
+		}}},
+		{{Key: "$unset", Value: "book_counts"}},
+	}
+}
+
+// The collection with its book counts taken from the books themselves,
+// mongo.ErrNoDocuments when there is no such collection
+func (r *CollectionRepository) FindWithBookCounts(ctx context.Context, id string) (*model.Collection, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("Error converting string to object ID: %s", err)
+		return nil, err
+	}
+
+	var collections []model.Collection
+	if err := r.Repository.Aggregate(ctx, BookCountsPipeline(objectId), &collections); err != nil {
+		log.Printf("Error counting books: %s", err)
+		return nil, err
+	}
+	if len(collections) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return &collections[0], nil
+}
@@ -2,14 +2,23 @@ package internal
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"shared/config"
+	"shared/pkg/grpcutil"
 	interfaces "shared/pkg/interface"
 	"shared/pkg/model"
+	"shared/pkg/repository"
 	"shared/pkg/service"
 	"shared/pkg/utils"
+	"shared/pkg/worker"
 	pb "shared/proto/buffer"
 
 	"github.com/redis/go-redis/v9"
@@ -19,32 +28,43 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type CollectionServiceServer struct {
 	pb.UnimplementedCollectionServiceServer
-	Service    interfaces.ServiceInterface[model.Collection, model.CollectionUpdateRequest]
-	Repository CollectionRepositoryInterface
-	Cache      *redis.Client
-	BookClient pb.BookServiceClient
+	Service       interfaces.ServiceInterface[model.Collection, model.CollectionUpdateRequest]
+	Repository    CollectionRepositoryInterface
+	Cache         *redis.Client
+	CacheTTL      config.CacheTTLConfig
+	BookClient    pb.BookServiceClient
+	Background    *worker.Pool // Runs the book creates and deletes that follow a committed write
+	MaxTotalBooks int
+	SeedBatchSize int
 }
 
-func NewCollectionService(database *mongo.Database, collection_name string, connections map[string]*grpc.ClientConn, cache *redis.Client) *CollectionServiceServer {
+func NewCollectionService(database *mongo.Database, collection_name string, connections map[string]*grpc.ClientConn, cache *redis.Client, cacheTTL *config.CacheTTLConfig, collectionConfig *config.CollectionConfig) *CollectionServiceServer {
 	repository := NewCollectionRepository(database, collection_name)
 
 	return &CollectionServiceServer{
-		Service:    service.NewBaseService[model.Collection, model.CollectionUpdateRequest](repository.Repository),
-		Repository: repository,
-		Cache:      cache,
-		BookClient: pb.NewBookServiceClient(connections["book"]),
+		Service:       service.NewBaseService[model.Collection, model.CollectionUpdateRequest](repository.Repository),
+		Repository:    repository,
+		Cache:         cache,
+		CacheTTL:      *cacheTTL,
+		BookClient:    pb.NewBookServiceClient(connections["book"]),
+		Background:    worker.NewPool(worker.DefaultPoolSize),
+		MaxTotalBooks: collectionConfig.MaxTotalBooks,
+		SeedBatchSize: collectionConfig.SeedBatchSize,
 	}
 }
 
+// Fields the collection indexes can sort on, see db.IndexModels
+var collectionSortFields = []string{"_id", "name"}
+
 func (s *CollectionServiceServer) GetCollection(ctx context.Context, in *pb.GetCollectionRequest) (*pb.Response, error) {
 	// Parse filter and sort from protobuf
 	var filter bson.M
-	var sort bson.D
 
 	if len(in.Filter.Fields) > 0 {
 		filterMap := in.Filter.AsMap()
@@ -52,27 +72,102 @@ func (s *CollectionServiceServer) GetCollection(ctx context.Context, in *pb.GetC
 		for k, v := range filterMap {
 			filter[k] = v
 		}
+		// A list would only match collections with exactly those categories
+		// in that order, look for any of them instead
+		if categories, ok := filter["categories"].([]interface{}); ok {
+			filter["categories"] = bson.M{"$in": categories}
+		}
 	} else {
 		filter = bson.M{}
 	}
 
-	if len(in.Sort) > 0 {
-		sort = bson.D{}
-		for _, sortItem := range in.Sort {
-			sort = append(sort, bson.E{Key: sortItem.Key, Value: sortItem.Direction})
+	sort, err := grpcutil.BuildSort(in.Sort, collectionSortFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.IncludeDeleted {
+		ctx = repository.WithDeleted(ctx)
+	}
+
+	cacheKey, cacheable := s.listCacheKey(ctx, in)
+	if cacheable {
+		if page, ok := utils.GetCachedData[utils.ListPage[model.Collection]](ctx, s.Cache, cacheKey); ok {
+			return s.buildListResponse(page), nil
 		}
+	}
+
+	page := &utils.ListPage[model.Collection]{}
+	if in.UseCursor {
+		page.Data, page.NextCursor, err = s.Service.ListCursor(ctx, filter, sort, in.After, int(in.Limit), in.Fields...)
 	} else {
-		sort = bson.D{}
+		page.Data, page.Total, err = s.Service.ListWithTotal(ctx, filter, sort, int(in.Skip), int(in.Limit), in.Fields...)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if cacheable {
+		utils.SetCachedData(ctx, s.Cache, cacheKey, page, s.CacheTTL.ListTTL)
+	}
+	return s.buildListResponse(page), nil
+}
+
+// Longest search query accepted, longer ones only make the regex scan slower
+const MaxSearchQueryLength = 100
+
+func (s *CollectionServiceServer) SearchCollections(ctx context.Context, in *pb.SearchRequest) (*pb.Response, error) {
+	query := strings.TrimSpace(in.Query)
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "Search query is required")
+	}
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return nil, status.Errorf(codes.InvalidArgument, "Search query must be at most %d characters", MaxSearchQueryLength)
 	}
 
-	data, err := s.Service.List(ctx, filter, sort, int(in.Skip), int(in.Limit))
+	filter := BuildSearchFilter(query)
+	if in.Regex {
+		var err error
+		if filter, err = BuildRegexSearchFilter(query); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid search pattern: %v", err)
+		}
+	}
 
+	sort := bson.D{{Key: "name", Value: 1}}
+	data, total, err := s.Service.ListWithTotal(ctx, filter, sort, int(in.Skip), int(in.Limit))
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	collections := model.ToPbCollections(data)
-	return s.buildResponse(true, "Collections retrieved successfully", collections), nil
+	response := s.buildResponse(true, "Collections retrieved successfully", model.ToPbCollections(data))
+	response.Total = total
+	return response, nil
+}
+
+// Matches collections whose name, author or any category contains the query,
+// ignoring case. The query is escaped so it is always matched literally.
+func BuildSearchFilter(query string) bson.M {
+	return searchFilter(regexp.QuoteMeta(query))
+}
+
+// Matches the query as a regular expression, for admins only since a pattern
+// like (a+)+ can make Mongo backtrack for a long time. Patterns that don't
+// compile are rejected before they reach Mongo.
+func BuildRegexSearchFilter(pattern string) (bson.M, error) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, err
+	}
+	return searchFilter(pattern), nil
+}
+
+func searchFilter(pattern string) bson.M {
+	condition := bson.M{"$regex": pattern, "$options": "i"}
+
+	return bson.M{"$or": bson.A{
+		bson.M{"name": condition},
+		bson.M{"author": condition},
+		bson.M{"categories": condition},
+	}}
 }
 
 func (s *CollectionServiceServer) FindCollectionById(ctx context.Context, in *pb.FindCollectionRequest) (*pb.Response, error) {
@@ -91,26 +186,79 @@ func (s *CollectionServiceServer) FindCollectionById(ctx context.Context, in *pb
 		collection = data
 
 		// Set cache
-		bytes, err := json.Marshal(collection)
-		if err != nil {
-			log.Printf("Error packing JSON: %s", err)
-		} else {
-			err = s.Cache.Set(ctx, "collection:"+in.Id, bytes, time.Hour).Err()
-			if err != nil {
-				log.Printf("Error setting cache: %v", err)
-			}
-		}
+		utils.SetCachedData(ctx, s.Cache, "collection:"+in.Id, collection, s.CacheTTL.CollectionTTL)
 	}
 
 	pbCollection := model.ToPbCollection(collection)
 	return s.buildResponse(true, "Collection found", []*pb.Collection{pbCollection}), nil
 }
 
+// Answers a collection with total_books and available_books counted from its
+// books rather than the stored counters, which drift when a stock update is
+// lost. The counts are cached for BookCountsTTL.
+func (s *CollectionServiceServer) GetCollectionWithCounts(ctx context.Context, in *pb.FindCollectionRequest) (*pb.Response, error) {
+	if _, err := primitive.ObjectIDFromHex(in.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid collection ID")
+	}
+
+	cacheKey := bookCountsCacheKey(in.Id)
+	collection, ok := utils.GetCachedData[model.Collection](ctx, s.Cache, cacheKey)
+	if !ok {
+		var err error
+		collection, err = s.Repository.FindWithBookCounts(ctx, in.Id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, status.Error(codes.NotFound, "Collection not found")
+		}
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		utils.SetCachedData(ctx, s.Cache, cacheKey, collection, s.bookCountsTTL())
+	}
+
+	return s.buildResponse(true, "Collection found", []*pb.Collection{model.ToPbCollection(collection)}), nil
+}
+
+// Most ids a single FindCollectionsByIds call may ask for
+const MaxIdsPerRequest = 100
+
+// Fetches several collections in one query. Ids that aren't valid are skipped
+// and counted in the response, ids with no collection are simply missing.
+func (s *CollectionServiceServer) FindCollectionsByIds(ctx context.Context, in *pb.FindCollectionsByIdsRequest) (*pb.Response, error) {
+	if len(in.Ids) > MaxIdsPerRequest {
+		return nil, status.Errorf(codes.InvalidArgument, "At most %d ids can be requested at once", MaxIdsPerRequest)
+	}
+
+	_, skipped := repository.ObjectIdsFromHex(in.Ids)
+	collections, err := s.Service.FindByIds(ctx, in.Ids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	response := s.buildResponse(true, fmt.Sprintf("Found %d collections", len(collections)), model.ToPbCollections(collections))
+	response.Skipped = int32(skipped)
+	return response, nil
+}
+
 func (s *CollectionServiceServer) AddCollection(ctx context.Context, in *pb.AddCollectionRequest) (*pb.Response, error) {
 	currTime := time.Now().UTC().Format(time.RFC3339)
 	in.Collection.Id = primitive.NewObjectID().Hex()
 	in.Collection.CreatedAt = currTime
 	in.Collection.UpdatedAt = currTime
+	// Every book seeded below is held in memory, refuse counts that would
+	// exhaust it before anything is stored
+	if int(in.Collection.TotalBooks) > s.maxTotalBooks() {
+		return nil, grpcutil.InvalidArgumentStatus("Invalid collection", map[string]string{
+			"total_books": fmt.Sprintf("total_books must be less than or equal to %d", s.maxTotalBooks()),
+		})
+	}
+
+	// Every book of a new collection starts out available
+	in.Collection.AvailableBooks = in.Collection.TotalBooks
+	// The books are created in the background once the collection is stored
+	in.Collection.SeedStatus = model.SeedStatusComplete
+	if in.Collection.TotalBooks > 0 {
+		in.Collection.SeedStatus = model.SeedStatusPending
+	}
 
 	// Check if collection already exists
 	exists, err := s.checkIfExists(ctx, in.Collection.Name, in.Collection.Author)
@@ -118,47 +266,27 @@ func (s *CollectionServiceServer) AddCollection(ctx context.Context, in *pb.AddC
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	if exists {
-		return s.buildResponse(false, "Collection already exists", nil), nil
+		return nil, status.Error(codes.AlreadyExists, "Collection already exists")
 	}
 
 	collection := model.FromPbCollection(in.Collection)
 	err = s.Service.Create(ctx, *collection)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent create won the race past the existence check above
+		return nil, status.Error(codes.AlreadyExists, "Collection already exists")
+	}
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return nil, grpcutil.InvalidArgumentStatus("Invalid collection", validationErr.Fields)
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	s.invalidateLists(ctx)
+	s.invalidateCategories(ctx)
 
-	if in.Collection.TotalBooks > 0 {
-		backgroundCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		go func() {
-			defer cancel()
-
-			var books []*pb.Book
-			for range collection.TotalBooks {
-				book := pb.Book{
-					Id:           primitive.NewObjectID().Hex(),
-					CollectionId: collection.Id.Hex(),
-					IsBorrowed:   &wrapperspb.BoolValue{Value: false},
-					CreatedAt:    time.Now().UTC().Format(time.RFC3339),
-					UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
-				}
-				books = append(books, &book)
-			}
-
-			retries := 0
-			for retries < 3 {
-				if _, err := s.BookClient.BulkInsert(backgroundCtx, &pb.BulkInsertBookRequest{
-					Books: books,
-				}); err != nil {
-					// Log error but don't fail the main operation
-					log.Printf("Failed to bulk insert books for collection %s: %v", collection.Id, err)
-
-					// Optional: implement retry logic or send to dead letter queue
-					retries += 1
-				} else {
-					break
-				}
-			}
-		}()
+	if collection.TotalBooks > 0 {
+		s.createCollectionBooks(collection.Id.Hex(), collection.TotalBooks)
 	}
 
 	return s.buildResponse(true, "Collection added!", []*pb.Collection{in.Collection}), nil
@@ -166,7 +294,6 @@ func (s *CollectionServiceServer) AddCollection(ctx context.Context, in *pb.AddC
 
 func (s *CollectionServiceServer) UpdateCollection(ctx context.Context, in *pb.UpdateCollectionRequest) (*pb.Response, error) {
 	update := in.Payload.AsMap()
-	update["updated_at"] = time.Now().UTC().Format(time.RFC3339)
 
 	filter := bson.M{}
 	if name, ok := update["name"]; ok {
@@ -186,16 +313,30 @@ func (s *CollectionServiceServer) UpdateCollection(ctx context.Context, in *pb.U
 		}
 	}
 
-	// Update collection
-	data, err := s.Service.Update(ctx, update, in.Id)
+	// Update collection, only while it's unchanged when the caller sent an etag
+	var data model.Collection
+	var err error
+	if in.IfMatch != "" {
+		data, err = service.UpdateIfMatch(ctx, s.Service, update, in.Id, in.IfMatch)
+	} else {
+		data, err = s.Service.Update(ctx, update, in.Id)
+	}
 	if err == mongo.ErrNoDocuments {
 		reply := s.buildResponse(false, "Collection not found", nil)
 		return reply, nil
 	}
+	if errors.Is(err, service.ErrStaleETag) {
+		return nil, status.Error(codes.FailedPrecondition, "Collection was changed since it was read")
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, status.Error(codes.AlreadyExists, "Collection with the same name and author already exists")
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	s.invalidateCache(ctx, in.Id)
+	s.invalidateLists(ctx)
+	s.invalidateCategories(ctx)
 
 	dataPb := model.ToPbCollection(&data)
 	if dataPb == nil {
@@ -205,7 +346,7 @@ func (s *CollectionServiceServer) UpdateCollection(ctx context.Context, in *pb.U
 }
 
 func (s *CollectionServiceServer) DeleteCollection(ctx context.Context, in *pb.DeleteCollectionRequest) (*pb.Response, error) {
-	data, err := s.Service.Delete(ctx, in.Id)
+	data, err := s.Service.SoftDelete(ctx, in.Id)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return s.buildResponse(false, "Collection not found", nil), nil
@@ -213,42 +354,214 @@ func (s *CollectionServiceServer) DeleteCollection(ctx context.Context, in *pb.D
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	s.invalidateCache(ctx, in.Id)
+	s.invalidateLists(ctx)
+	s.invalidateCategories(ctx)
+	utils.InvalidateCache(ctx, s.Cache, "available_books:"+in.Id)
+	s.deleteCollectionBooks(in.Id)
 
 	newCollection := model.ToPbCollection(&data)
 	return s.buildResponse(true, "Collection deleted!", []*pb.Collection{newCollection}), nil
 }
 
-func (s *CollectionServiceServer) DecrementAvailableBooks(ctx context.Context, in *pb.DecrementAvailableBooksRequest) (*pb.Response, error) {
-	result, err := s.Repository.UpdateBookStock(ctx, map[string]interface{}{"total_books": in.Amount}, in.Id)
+func (s *CollectionServiceServer) AdjustBookStock(ctx context.Context, in *pb.AdjustBookStockRequest) (*pb.Response, error) {
+	result, err := s.Repository.AdjustBookStock(ctx, in.Id, int(in.TotalDelta), int(in.AvailableDelta))
 
 	if err != nil {
 		return s.buildResponse(false, err.Error(), []*pb.Collection{}), err
 	}
-	if result.(*mongo.UpdateResult).ModifiedCount == 0 {
+	if result.ModifiedCount == 0 {
+		// Either the collection doesn't exist or the stock can't go that low
 		return s.buildResponse(false, "No book updated", []*pb.Collection{}), err
 	}
+	s.invalidateLists(ctx)
 
 	// Update cache
 	cachedCollection, success := s.getCachedCollection(ctx, in.Id)
 	if !success {
 		log.Printf("Error getting cache")
 	} else {
-		cachedCollection.TotalBooks += int(in.Amount)
+		cachedCollection.TotalBooks += int(in.TotalDelta)
+		cachedCollection.AvailableBooks += int(in.AvailableDelta)
 
-		bytes, err := json.Marshal(cachedCollection)
-		if err != nil {
-			log.Printf("Error packing JSON: %s", err)
-			s.Cache.Del(ctx, "collection:"+in.Id)
+		// Drop the entry rather than leave stock counts that are out of date
+		if err := utils.SetCachedData(ctx, s.Cache, "collection:"+in.Id, cachedCollection, s.CacheTTL.CollectionTTL); err != nil {
+			s.invalidateCache(ctx, in.Id)
 		}
+	}
 
-		err = s.Cache.Set(ctx, "collection:"+in.Id, bytes, time.Hour).Err()
-		if err != nil {
-			log.Printf("Error updating cache: %s", err)
-			s.Cache.Del(ctx, "collection:"+in.Id)
+	return s.buildResponse(true, "Stock updated successfully!", []*pb.Collection{}), nil
+}
+
+// Records how creating a collection's books went, used by the book service
+// once it replays a create that had failed
+func (s *CollectionServiceServer) SetSeedStatus(ctx context.Context, in *pb.SetSeedStatusRequest) (*pb.Response, error) {
+	switch in.SeedStatus {
+	case model.SeedStatusPending, model.SeedStatusComplete, model.SeedStatusFailed:
+	default:
+		return nil, grpcutil.InvalidArgumentStatus("Invalid seed status", map[string]string{
+			"seed_status": "seed_status must be one of: pending complete failed",
+		})
+	}
+
+	if _, err := primitive.ObjectIDFromHex(in.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid collection ID")
+	}
+
+	result, err := s.Repository.SetSeedStatus(ctx, in.Id, in.SeedStatus)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if result.MatchedCount == 0 {
+		return s.buildResponse(false, "Collection not found", []*pb.Collection{}), nil
+	}
+	s.invalidateCache(ctx, in.Id)
+	s.invalidateLists(ctx)
+
+	return s.buildResponse(true, "Seed status updated", []*pb.Collection{}), nil
+}
+
+// Every category in use, for filters in the UI. Cached under one key dropped
+// on every collection write.
+func (s *CollectionServiceServer) GetCategories(ctx context.Context, in *emptypb.Empty) (*pb.CategoriesResponse, error) {
+	if categories, ok := utils.GetCachedData[[]string](ctx, s.Cache, categoriesCacheKey); ok {
+		return &pb.CategoriesResponse{Categories: *categories}, nil
+	}
+
+	categories, err := s.Repository.DistinctCategories(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if categories == nil {
+		categories = []string{}
+	}
+	sort.Strings(categories)
+	utils.SetCachedData(ctx, s.Cache, categoriesCacheKey, categories, s.categoriesTTL())
+
+	return &pb.CategoriesResponse{Categories: categories}, nil
+}
+
+// Creates the books of a new collection in the background, in batches of
+// SeedBatchSize with a few retries each since the collection itself is already
+// committed, and moves the collection's seed status off pending. When a batch
+// still fails, or the service is shutting down, the books not created yet are
+// queued in the stock DLQ and the book service creates them on replay.
+func (s *CollectionServiceServer) createCollectionBooks(collectionId string, count int) {
+	deadLetter := func(remaining int, err error) {
+		utils.PushDeadLetter(context.Background(), s.Cache, utils.StockDLQKey, utils.StockOperation{
+			Op:           utils.OpCreateCollectionBooks,
+			CollectionId: collectionId,
+			BookCount:    remaining,
+			Error:        err.Error(),
+			FailedAt:     time.Now().UTC(),
+		})
+		s.setSeedStatus(collectionId, model.SeedStatusFailed)
+	}
+
+	err := s.Background.Go(func() {
+		for created := 0; created < count; {
+			size := min(s.seedBatchSize(), count-created)
+			if err := s.insertCollectionBooks(collectionId, size); err != nil {
+				deadLetter(count-created, err)
+				return
+			}
+			created += size
+		}
+		s.setSeedStatus(collectionId, model.SeedStatusComplete)
+	})
+	if err != nil {
+		deadLetter(count, err)
+	}
+}
+
+// Creates one batch of books for a collection, retrying a few times
+func (s *CollectionServiceServer) insertCollectionBooks(collectionId string, count int) error {
+	backgroundCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	books := make([]*pb.Book, count)
+	for i := range books {
+		books[i] = &pb.Book{
+			Id:           primitive.NewObjectID().Hex(),
+			CollectionId: collectionId,
+			IsBorrowed:   &wrapperspb.BoolValue{Value: false},
+			CreatedAt:    time.Now().UTC().Format(time.RFC3339),
+			UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
 		}
 	}
 
-	return s.buildResponse(true, "Stock updated successfully!", []*pb.Collection{}), nil
+	var err error
+	for range 3 {
+		if _, err = s.BookClient.BulkInsert(backgroundCtx, &pb.BulkInsertBookRequest{
+			Books: books,
+		}); err == nil {
+			return nil
+		}
+		// Log error but don't fail the main operation
+		log.Printf("Failed to bulk insert books for collection %s: %v", collectionId, err)
+	}
+	return err
+}
+
+func (s *CollectionServiceServer) maxTotalBooks() int {
+	if s.MaxTotalBooks > 0 {
+		return s.MaxTotalBooks
+	}
+	return config.DefaultMaxTotalBooks
+}
+
+func (s *CollectionServiceServer) seedBatchSize() int {
+	if s.SeedBatchSize > 0 {
+		return s.SeedBatchSize
+	}
+	return config.DefaultSeedBatchSize
+}
+
+// Stores the seed status of a collection and drops the copies cached with the
+// previous one
+func (s *CollectionServiceServer) setSeedStatus(collectionId string, seedStatus string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.Repository.SetSeedStatus(ctx, collectionId, seedStatus); err != nil {
+		log.Printf("Failed to set seed status of collection %s to %s: %v", collectionId, seedStatus, err)
+		return
+	}
+	s.invalidateCache(ctx, collectionId)
+	s.invalidateLists(ctx)
+}
+
+// Deletes the books of a deleted collection in the background, retrying a few
+// times since the collection itself is already gone. Deletes that still fail,
+// or that arrive while the service is shutting down, are queued in the stock
+// DLQ.
+func (s *CollectionServiceServer) deleteCollectionBooks(collectionId string) {
+	deadLetter := func(err error) {
+		utils.PushDeadLetter(context.Background(), s.Cache, utils.StockDLQKey, utils.StockOperation{
+			Op:           utils.OpDeleteCollectionBooks,
+			CollectionId: collectionId,
+			Error:        err.Error(),
+			FailedAt:     time.Now().UTC(),
+		})
+	}
+
+	err := s.Background.Go(func() {
+		backgroundCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var err error
+		for range 3 {
+			if _, err = s.BookClient.BulkDelete(backgroundCtx, &pb.BulkDeleteBookRequest{
+				CollectionId: collectionId,
+			}); err == nil {
+				return
+			}
+			log.Printf("Failed to delete books of collection %s: %v", collectionId, err)
+		}
+		deadLetter(err)
+	})
+	if err != nil {
+		deadLetter(err)
+	}
 }
 
 func (s *CollectionServiceServer) getCachedCollection(ctx context.Context, id string) (*model.Collection, bool) {
@@ -262,10 +575,60 @@ func (s *CollectionServiceServer) getCachedCollection(ctx context.Context, id st
 }
 
 func (s *CollectionServiceServer) invalidateCache(ctx context.Context, id string) {
-	// Invalidate cache
-	err := s.Cache.Del(ctx, "collection:"+id).Err()
+	utils.InvalidateCache(ctx, s.Cache, "collection:"+id, bookCountsCacheKey(id))
+}
+
+func bookCountsCacheKey(id string) string {
+	return "collection_counts:" + id
+}
+
+func (s *CollectionServiceServer) bookCountsTTL() time.Duration {
+	if s.CacheTTL.BookCountsTTL > 0 {
+		return s.CacheTTL.BookCountsTTL
+	}
+	return config.DefaultBookCountsCacheTTL
+}
+
+const categoriesCacheKey = "collection_categories"
+
+func (s *CollectionServiceServer) categoriesTTL() time.Duration {
+	if s.CacheTTL.CategoriesTTL > 0 {
+		return s.CacheTTL.CategoriesTTL
+	}
+	return config.DefaultCategoriesCacheTTL
+}
+
+// Drops the cached categories after a write that can add or remove one
+func (s *CollectionServiceServer) invalidateCategories(ctx context.Context) {
+	utils.InvalidateCache(ctx, s.Cache, categoriesCacheKey)
+}
+
+func (s *CollectionServiceServer) buildListResponse(page *utils.ListPage[model.Collection]) *pb.Response {
+	response := s.buildResponse(true, "Collections retrieved successfully", model.ToPbCollections(page.Data))
+	response.Total = page.Total
+	response.NextCursor = page.NextCursor
+	return response
+}
+
+// Key of the cached page for a list request, false when list caching is off
+// or the key can't be built
+func (s *CollectionServiceServer) listCacheKey(ctx context.Context, in *pb.GetCollectionRequest) (string, bool) {
+	if s.CacheTTL.ListTTL <= 0 {
+		return "", false
+	}
+	key, err := utils.ListCacheKey(ctx, s.Cache, "collections", in)
 	if err != nil {
-		log.Printf("Error deleting cache: %v", err)
+		log.Printf("Error building list cache key: %v", err)
+		return "", false
+	}
+	return key, true
+}
+
+// Drops every cached collection list page, called after any write to
+// collections including stock changes
+func (s *CollectionServiceServer) invalidateLists(ctx context.Context) {
+	if s.CacheTTL.ListTTL > 0 {
+		utils.InvalidateLists(ctx, s.Cache, "collections")
 	}
 }
 
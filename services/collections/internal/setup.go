@@ -9,6 +9,11 @@ import (
 	"os"
 	"os/signal"
 	"shared/config"
+	"shared/pkg/grpcutil"
+	"shared/pkg/metrics"
+	"shared/pkg/repository"
+	"shared/pkg/tracing"
+	"shared/pkg/utils"
 	pb "shared/proto/buffer"
 	"syscall"
 	"time"
@@ -17,106 +22,163 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-func Setup() {
+// Starts the service and blocks until it is told to stop. Startup failures
+// are returned after releasing whatever was already set up, main decides
+// whether to exit.
+func Setup() error {
 	godotenv.Load(".env")
 
 	// Setup database connection
-	client, database, err := db.Connect()
+	client, database, err := repository.Connect(config.LoadMongoConfig("collection"))
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
+	defer func() {
+		if err := client.Disconnect(context.TODO()); err != nil {
+			log.Printf("Error disconnecting from database: %v", err)
+		}
+	}()
+
+	// Create the indexes queries rely on, existing ones are left untouched
+	indexCtx, cancelIndex := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := db.EnsureIndexes(indexCtx, database); err != nil {
+		log.Printf("Error creating indexes: %v", err)
+	}
+	cancelIndex()
+
+	// Export traces when a collector endpoint is configured
+	shutdownTracing, err := tracing.Init(context.Background(), "collection-service")
+	if err != nil {
+		return fmt.Errorf("failed to start tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Error flushing traces: %v", err)
+		}
+	}()
 
 	// Dial other services
-	connections := DialClients()
-	defer CloseClientConnections(connections)
+	connections, err := DialClients()
+	if err != nil {
+		return fmt.Errorf("failed to dial services: %w", err)
+	}
+	defer grpcutil.CloseConnections(connections)
 
 	// Setup Redis client
 	rdb, err := StartRedisClient(config.LoadRedisConfig())
 	if err != nil {
-		log.Fatalf("failed to start Redis client: %v", err)
+		return fmt.Errorf("failed to start Redis client: %w", err)
 	}
+	defer func() {
+		if err := rdb.Close(); err != nil {
+			log.Printf("Error closing Redis client: %v", err)
+		}
+	}()
 
 	// Setup gRPC server
-	server, err := StartServer(database, connections, rdb)
+	healthServer := grpcutil.NewHealthServer()
+	server, svc, serveErr, err := StartServer(database, connections, rdb, config.LoadCacheTTLConfig(), config.LoadCollectionConfig(), healthServer)
 	if err != nil {
-		log.Fatalf("failed to start gRPC server: %v", err)
+		return fmt.Errorf("failed to start gRPC server: %w", err)
 	}
 
+	// Serve prometheus metrics on their own port, unset disables the listener
+	metricsServer := metrics.StartServer(os.Getenv("COLLECTION_METRICS_PORT"))
+
+	// Report readiness while the database is reachable, a Redis outage only
+	// turns cache lookups into misses
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	go grpcutil.WatchHealth(healthCtx, healthServer, 10*time.Second,
+		func(ctx context.Context) error { return client.Ping(ctx, nil) },
+	)
+
 	// Setup signal handling
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	log.Println("Collection service started. Waiting for messages...")
 
-	// Wait for shutdown signal
-	<-quit
-	log.Println("Shutting down collection service...")
+	// Wait for a shutdown signal, or for the server to stop on its own
+	select {
+	case <-quit:
+		log.Println("Shutting down collection service...")
+	case err = <-serveErr:
+		log.Printf("gRPC server stopped: %v, shutting down collection service...", err)
+	}
 
 	// Stop services
+	stopHealth()
+	healthServer.Shutdown()
 	server.GracefulStop()
-	if err := rdb.Close(); err != nil {
-		log.Printf("Error closing Redis client: %v", err)
-	}
-	if err := client.Disconnect(context.TODO()); err != nil {
-		log.Printf("Error disconnecting from database: %v", err)
+	// Let book creates and deletes of already committed writes finish before
+	// the connections they use are closed, work still running at the deadline
+	// is lost with the process
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), config.LoadServerConfig("collection").DrainTimeout)
+	if err := svc.Background.Shutdown(drainCtx); err != nil {
+		log.Printf("Error waiting for background work: %v", err)
 	}
+	cancelDrain()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	metrics.StopServer(shutdownCtx, metricsServer)
+	cancelShutdown()
 
 	log.Println("Collection service shut down gracefully")
+	return err
 }
 
-func DialClients() map[string]*grpc.ClientConn {
-	services := map[string]string{
-		"book": os.Getenv("BOOK_SERVICE_PORT"),
-	}
-
-	connections := make(map[string]*grpc.ClientConn)
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-
-	for service, port := range services {
-		log.Printf("Attempting to connect to book service on port: %s", services["book"])
-		conn, err := grpc.NewClient("localhost:"+port, opts...)
-		if err != nil {
-			log.Fatalf("%s grpc server connection failed: %s", service, err)
-		}
-		connections[service] = conn
-	}
-	return connections
+// Dials the services this one calls
+func DialClients() (map[string]*grpc.ClientConn, error) {
+	return grpcutil.DialServices(map[string]string{
+		"book": config.LoadServerConfig("book").DialAddress(),
+	})
 }
 
-func CloseClientConnections(connections map[string]*grpc.ClientConn) {
-	for _, conn := range connections {
-		conn.Close()
+// Listens on the configured address and serves the service in the background.
+// An error from serving after startup is sent on the returned channel.
+func StartServer(database *mongo.Database, connections map[string]*grpc.ClientConn, redis *redis.Client, cacheTTL *config.CacheTTLConfig, collectionConfig *config.CollectionConfig, healthServer *health.Server) (*grpc.Server, *CollectionServiceServer, <-chan error, error) {
+	godotenv.Load(".env")
+	serverConfig := config.LoadServerConfig("collection")
+	address := serverConfig.ListenAddress()
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to listen on %s: %w", address, err)
 	}
-}
 
-func StartServer(database *mongo.Database, connections map[string]*grpc.ClientConn, redis *redis.Client) (*grpc.Server, error) {
-	godotenv.Load(".env")
-	log.Println(os.Getenv("COLLECTION_SERVICE_PORT"))
-	lis, err := net.Listen("tcp", ":"+os.Getenv("COLLECTION_SERVICE_PORT"))
+	creds, err := grpcutil.ServerCredentials(config.LoadTLSConfig())
 	if err != nil {
-		log.Printf("Error listening on port %s: %v", os.Getenv("COLLECTION_SERVICE_PORT"), err)
+		lis.Close()
+		return nil, nil, nil, err
 	}
 
-	s := grpc.NewServer()
-	svc := NewCollectionService(database, "collections", connections, redis)
+	opts := append([]grpc.ServerOption{
+		creds,
+		grpc.ChainUnaryInterceptor(
+			tracing.UnaryServerInterceptor(),
+			grpcutil.UnaryServerInterceptor(),
+			metrics.UnaryServerInterceptor(),
+		),
+	}, grpcutil.KeepaliveServerOptions(serverConfig)...)
+	s := grpc.NewServer(opts...)
+	svc := NewCollectionService(database, db.CollectionName, connections, redis, cacheTTL, collectionConfig)
 	pb.RegisterCollectionServiceServer(s, svc)
+	healthpb.RegisterHealthServer(s, healthServer)
 
 	log.Printf("server listening at %v", lis.Addr())
 
+	serveErr := make(chan error, 1)
 	go func() {
 		if err := s.Serve(lis); err != nil {
-			log.Fatalf("failed to serve: %v", err)
+			serveErr <- err
 		}
 	}()
 
-	if err != nil {
-		return nil, err
-	}
-	return s, nil
+	return s, svc, serveErr, nil
 }
 
 func StartRedisClient(cfg *config.RedisConfig) (*redis.Client, error) {
@@ -133,12 +195,15 @@ func StartRedisClient(cfg *config.RedisConfig) (*redis.Client, error) {
 		PoolTimeout:  cfg.PoolTimeout,
 	}
 	rdb := redis.NewClient(options)
+	utils.GuardCache(rdb, utils.DefaultCacheRetryAfter)
 
-	// Test connection
+	// Test connection, without Redis the service still starts and every
+	// cache lookup is treated as a miss
 	ctx := context.Background()
 	_, err := rdb.Ping(ctx).Result()
 	if err != nil {
-		return nil, err
+		log.Printf("Redis unavailable, starting without cache: %v", err)
+		return rdb, nil
 	}
 
 	// Setup cache configuration
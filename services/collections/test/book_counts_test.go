@@ -0,0 +1,188 @@
+package test
+
+import (
+	"collection/internal"
+	"collection/internal/db"
+	"context"
+	"testing"
+	"time"
+
+	"shared/config"
+	"shared/pkg/model"
+	"shared/pkg/mongotest"
+	"shared/pkg/utils"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBookCountsPipeline_JoinsLiveBooks(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	pipeline := internal.BookCountsPipeline(id)
+
+	require.Len(t, pipeline, 4)
+	assert.Equal(t, bson.E{Key: "$match", Value: bson.M{"_id": id}}, pipeline[0][0])
+
+	lookup := pipeline[1][0].Value.(bson.M)
+	assert.Equal(t, config.BookCollectionName, lookup["from"])
+	assert.Equal(t, bson.M{"collectionId": "$_id"}, lookup["let"])
+
+	// Only the collection's books that weren't deleted are counted
+	countBooks := lookup["pipeline"].(bson.A)
+	match := countBooks[0].(bson.M)["$match"].(bson.M)
+	assert.Equal(t, bson.M{"$eq": bson.A{"$collection_id", "$$collectionId"}}, match["$expr"])
+	assert.Contains(t, match, "deleted_at")
+	assert.Nil(t, match["deleted_at"])
+
+	// Stored counters are replaced, not added to
+	set := pipeline[2][0].Value.(bson.M)
+	assert.Contains(t, set, "total_books")
+	assert.Contains(t, set, "available_books")
+	assert.Equal(t, bson.E{Key: "$unset", Value: "book_counts"}, pipeline[3][0])
+}
+
+func TestFindWithBookCounts_InvalidId(t *testing.T) {
+	repo := internal.NewCollectionRepository(nil, "collections")
+
+	collection, err := repo.FindWithBookCounts(context.Background(), "not-an-id")
+
+	assert.Error(t, err)
+	assert.Nil(t, collection)
+}
+
+// Repository on a fake server that runs aggregations against store
+func newCountingRepository(t *testing.T, store mongotest.Store) *internal.CollectionRepository {
+	client, err := mongo.Connect(options.Client().ApplyURI(mongotest.Server(t, store.Reply)))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return internal.NewCollectionRepository(client.Database("test"), db.CollectionName)
+}
+
+func TestFindWithBookCounts_CountsLiveBooks(t *testing.T) {
+	now := time.Now().UTC()
+	deletedAt := now.Add(-time.Hour)
+
+	// The stored counters drifted, the books are what counts
+	dune := model.Collection{Id: primitive.NewObjectID(), Name: "Dune", TotalBooks: 10, AvailableBooks: 10, CreatedAt: now, UpdatedAt: now}
+	empty := model.Collection{Id: primitive.NewObjectID(), Name: "Empty", TotalBooks: 4, AvailableBooks: 4, CreatedAt: now, UpdatedAt: now}
+	removed := model.Collection{Id: primitive.NewObjectID(), Name: "Removed", CreatedAt: now, UpdatedAt: now, DeletedAt: &deletedAt}
+
+	book := func(collectionId primitive.ObjectID, borrowed bool, deletedAt *time.Time) model.Book {
+		return model.Book{Id: primitive.NewObjectID(), CollectionId: collectionId, IsBorrowed: borrowed, CreatedAt: now, UpdatedAt: now, DeletedAt: deletedAt}
+	}
+
+	store := mongotest.Store{}
+	require.NoError(t, store.Insert(db.CollectionName, dune, empty, removed))
+	require.NoError(t, store.Insert(config.BookCollectionName,
+		book(dune.Id, false, nil),
+		book(dune.Id, false, nil),
+		book(dune.Id, true, nil),
+		book(dune.Id, false, &deletedAt),
+		book(dune.Id, true, &deletedAt),
+		book(removed.Id, false, nil),
+		book(primitive.NewObjectID(), false, nil),
+	))
+	repo := newCountingRepository(t, store)
+	ctx := context.Background()
+
+	t.Run("borrowed and soft-deleted books", func(t *testing.T) {
+		collection, err := repo.FindWithBookCounts(ctx, dune.Id.Hex())
+
+		require.NoError(t, err)
+		assert.Equal(t, dune.Id, collection.Id)
+		assert.Equal(t, "Dune", collection.Name)
+		assert.Equal(t, 3, collection.TotalBooks)
+		assert.Equal(t, 2, collection.AvailableBooks)
+	})
+
+	t.Run("no books", func(t *testing.T) {
+		collection, err := repo.FindWithBookCounts(ctx, empty.Id.Hex())
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, collection.TotalBooks)
+		assert.Equal(t, 0, collection.AvailableBooks)
+	})
+
+	t.Run("soft-deleted collection", func(t *testing.T) {
+		_, err := repo.FindWithBookCounts(ctx, removed.Id.Hex())
+
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+
+	t.Run("missing collection", func(t *testing.T) {
+		_, err := repo.FindWithBookCounts(ctx, primitive.NewObjectID().Hex())
+
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+}
+
+func TestGetCollectionWithCounts_CountsWinOverStoredCounters(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService, repository := newServer(cache)
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+
+	// The stored counters drifted to 10/10, the collection has 3 books of
+	// which one is borrowed
+	stored := model.Collection{Id: id, Name: "Dune", Author: "Frank Herbert", TotalBooks: 10, AvailableBooks: 10}
+	require.NoError(t, utils.SetCachedData(ctx, cache, "collection:"+id.Hex(), stored, config.DefaultCacheTTL))
+	counted := stored
+	counted.TotalBooks, counted.AvailableBooks = 3, 2
+	repository.On("FindWithBookCounts", mockAnyCtx(), id.Hex()).Return(&counted, nil).Once()
+
+	resp, err := mockService.GetCollectionWithCounts(ctx, &pb.FindCollectionRequest{Id: id.Hex()})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Collection, 1)
+	assert.Equal(t, int32(3), resp.Collection[0].TotalBooks)
+	assert.Equal(t, int32(2), resp.Collection[0].AvailableBooks)
+}
+
+func TestGetCollectionWithCounts_CachedBriefly(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService, repository := newServer(cache)
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+
+	counted := &model.Collection{Id: id, Name: "Dune", TotalBooks: 3, AvailableBooks: 2}
+	repository.On("FindWithBookCounts", mockAnyCtx(), id.Hex()).Return(counted, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		resp, err := mockService.GetCollectionWithCounts(ctx, &pb.FindCollectionRequest{Id: id.Hex()})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), resp.Collection[0].AvailableBooks)
+	}
+
+	repository.AssertNumberOfCalls(t, "FindWithBookCounts", 1)
+	ttl, err := cache.TTL(ctx, "collection_counts:"+id.Hex()).Result()
+	require.NoError(t, err)
+	assert.InDelta(t, config.DefaultBookCountsCacheTTL.Seconds(), ttl.Seconds(), 1)
+}
+
+func TestGetCollectionWithCounts_NotFound(t *testing.T) {
+	_, mockService, repository := newServer(newRedis(t))
+	id := primitive.NewObjectID()
+	repository.On("FindWithBookCounts", mockAnyCtx(), id.Hex()).Return(nil, mongo.ErrNoDocuments).Once()
+
+	_, err := mockService.GetCollectionWithCounts(context.Background(), &pb.FindCollectionRequest{Id: id.Hex()})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGetCollectionWithCounts_InvalidId(t *testing.T) {
+	_, mockService, repository := newServer(newRedis(t))
+
+	_, err := mockService.GetCollectionWithCounts(context.Background(), &pb.FindCollectionRequest{Id: "not-an-id"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	repository.AssertNotCalled(t, "FindWithBookCounts", mockAnyCtx(), "not-an-id")
+}
@@ -9,7 +9,11 @@ import (
 	"testing"
 	"time"
 
+	"shared/config"
+	"shared/pkg/grpcutil"
 	"shared/pkg/model"
+	"shared/pkg/utils"
+	"shared/pkg/worker"
 	pb "shared/proto/buffer"
 
 	"github.com/alicebob/miniredis/v2"
@@ -22,6 +26,7 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -37,7 +42,9 @@ func newServer(cache *redis.Client) (*mocks.MockService[model.Collection, model.
 		Service:    mockService,
 		Repository: repository,
 		Cache:      cache,
+		CacheTTL:   *config.DefaultCacheTTLConfig(),
 		BookClient: &mocks.MockBookServiceClient{},
+		Background: worker.NewPool(worker.DefaultPoolSize),
 	}
 
 	return mockService, svc, repository
@@ -50,7 +57,7 @@ func TestGetCollection_Success(t *testing.T) {
 	// Arrange
 	ctx := context.Background()
 	mockData := []model.Collection{{Id: primitive.NewObjectID(), Name: "Test", Author: "Author"}}
-	mockBaseService.On("List", ctx).Return(mockData, nil)
+	mockBaseService.On("ListWithTotal", ctx).Return(mockData, int64(25), nil)
 
 	filterMap := map[string]interface{}{}
 	filter, err := structpb.NewStruct(filterMap)
@@ -70,6 +77,46 @@ func TestGetCollection_Success(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, resp.Success)
 	assert.NotEmpty(t, resp.Collection)
+	assert.Equal(t, int64(25), resp.Total)
+}
+
+func TestGetCollection_ListCachedUntilWrite(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+	mockService.CacheTTL.ListTTL = time.Minute
+
+	ctx := context.Background()
+	mockData := []model.Collection{{Id: primitive.NewObjectID(), Name: "Dune", Author: "Frank Herbert"}}
+	mockBaseService.On("ListWithTotal", ctx).Return(mockData, int64(1), nil).Twice()
+	mockBaseService.On("Exists", mockAnyCtx(), bson.M{"name": "Emma", "author": "Jane Austen"}).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{"author": "Frank Herbert"})
+	require.NoError(t, err)
+	request := &pb.GetCollectionRequest{Filter: filter, Limit: 10}
+
+	// A second identical list is served from cache
+	_, err = mockService.GetCollection(ctx, request)
+	require.NoError(t, err)
+	cached, err := mockService.GetCollection(ctx, request)
+	require.NoError(t, err)
+	require.Len(t, cached.Collection, 1)
+	assert.Equal(t, "Dune", cached.Collection[0].Name)
+	assert.Equal(t, int64(1), cached.Total)
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 1)
+
+	// A different page is a different cache entry
+	mockBaseService.On("ListWithTotal", ctx).Return(mockData, int64(1), nil).Once()
+	_, err = mockService.GetCollection(ctx, &pb.GetCollectionRequest{Filter: filter, Limit: 10, Skip: 10})
+	require.NoError(t, err)
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 2)
+
+	// A create busts every cached page
+	_, err = mockService.AddCollection(ctx, &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "Emma", Author: "Jane Austen"}})
+	require.NoError(t, err)
+	_, err = mockService.GetCollection(ctx, request)
+	require.NoError(t, err)
+	mockBaseService.AssertNumberOfCalls(t, "ListWithTotal", 3)
 }
 
 func TestGetCollection_Error(t *testing.T) {
@@ -77,7 +124,7 @@ func TestGetCollection_Error(t *testing.T) {
 	mockBaseService, mockService, _ := newServer(cache)
 
 	ctx := context.Background()
-	mockBaseService.On("List", ctx).Return(nil, errors.New("db error"))
+	mockBaseService.On("ListWithTotal", ctx).Return(nil, int64(0), errors.New("db error"))
 
 	filterMap := map[string]interface{}{}
 	filter, err := structpb.NewStruct(filterMap)
@@ -98,6 +145,31 @@ func TestGetCollection_Error(t *testing.T) {
 	assert.Equal(t, codes.Internal, status.Code(err))
 }
 
+func TestGetCollection_Cursor(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+
+	ctx := context.Background()
+	mockData := []model.Collection{{Id: primitive.NewObjectID(), Name: "Test", Author: "Author"}}
+	mockBaseService.On("ListCursor", ctx, "cursor-1", 1).Return(mockData, "cursor-2", nil)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{})
+	require.NoError(t, err)
+
+	resp, err := mockService.GetCollection(ctx, &pb.GetCollectionRequest{
+		Filter:    filter,
+		Limit:     1,
+		After:     "cursor-1",
+		UseCursor: true,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Collection, 1)
+	assert.Equal(t, "cursor-2", resp.NextCursor)
+	mockBaseService.AssertNotCalled(t, "ListWithTotal", ctx)
+}
+
 func TestFindCollectionById_CacheMissThenSet(t *testing.T) {
 	cache := newRedis(t)
 	mockBaseService, mockService, _ := newServer(cache)
@@ -121,6 +193,35 @@ func TestFindCollectionById_CacheMissThenSet(t *testing.T) {
 	assert.Equal(t, mc.Author, cached.Author)
 }
 
+func TestFindCollectionsByIds_SkipsInvalidIds(t *testing.T) {
+	mockBaseService, mockService, _ := newServer(newRedis(t))
+
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	ids := []string{first.Hex(), "not-an-id", second.Hex(), ""}
+	found := []model.Collection{{Id: first, Name: "Dune"}, {Id: second, Name: "Emma"}}
+	mockBaseService.On("FindByIds", mockAnyCtx(), ids).Return(found, nil).Once()
+
+	resp, err := mockService.FindCollectionsByIds(context.Background(), &pb.FindCollectionsByIdsRequest{Ids: ids})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	require.Len(t, resp.Collection, 2)
+	assert.Equal(t, first.Hex(), resp.Collection[0].Id)
+	assert.Equal(t, int32(2), resp.Skipped)
+}
+
+func TestFindCollectionsByIds_TooManyIds(t *testing.T) {
+	mockBaseService, mockService, _ := newServer(newRedis(t))
+
+	ids := make([]string, internal.MaxIdsPerRequest+1)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID().Hex()
+	}
+
+	_, err := mockService.FindCollectionsByIds(context.Background(), &pb.FindCollectionsByIdsRequest{Ids: ids})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockBaseService.AssertNotCalled(t, "FindByIds", mock.Anything, mock.Anything)
+}
+
 func TestAddCollection_AlreadyExists(t *testing.T) {
 	cache := newRedis(t)
 	mockBaseService, mockService, _ := newServer(cache)
@@ -128,10 +229,10 @@ func TestAddCollection_AlreadyExists(t *testing.T) {
 	inpb := &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "Name", Author: "Author"}}
 	mockBaseService.On("Exists", mockAnyCtx(), bson.M{"name": "Name", "author": "Author"}).Return(true, nil)
 
-	resp, err := mockService.AddCollection(context.Background(), inpb)
-	require.NoError(t, err)
-	assert.False(t, resp.Success)
-	assert.Equal(t, "Collection already exists", resp.Message)
+	_, err := mockService.AddCollection(context.Background(), inpb)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	assert.Equal(t, "Collection already exists", status.Convert(err).Message())
+	mockBaseService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
 func TestAddCollection_CreateSuccess_NoBooks(t *testing.T) {
@@ -148,6 +249,198 @@ func TestAddCollection_CreateSuccess_NoBooks(t *testing.T) {
 	assert.Equal(t, inpb.Collection.Name, resp.Collection[0].Name)
 }
 
+func TestAddCollection_ShutdownWaitsForBookSeeding(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, repository := newServer(cache)
+	ctx := context.Background()
+	repository.On("SetSeedStatus", mock.Anything, mock.Anything, model.SeedStatusComplete).Return(&mongo.UpdateResult{MatchedCount: 1}, nil)
+
+	inpb := &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A", TotalBooks: 3}}
+	mockBaseService.On("Exists", mockAnyCtx(), bson.M{"name": "C", "author": "A"}).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+
+	release := make(chan struct{})
+	bookClient := mockService.BookClient.(*mocks.MockBookServiceClient)
+	bookClient.On("BulkInsert", mock.Anything, mock.MatchedBy(func(req *pb.BulkInsertBookRequest) bool {
+		return len(req.Books) == 3
+	})).Run(func(mock.Arguments) { <-release }).Return(&pb.BookResponse{Success: true}, nil).Once()
+
+	_, err := mockService.AddCollection(ctx, inpb)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- mockService.Background.Shutdown(ctx) }()
+
+	select {
+	case <-done:
+		t.Fatal("shutdown returned while the books were still being created")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-done)
+	bookClient.AssertExpectations(t)
+	repository.AssertExpectations(t)
+	assert.Zero(t, cache.LLen(ctx, utils.StockDLQKey).Val())
+}
+
+func TestAddCollection_SeedStatusCompleteAfterInsert(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, repository := newServer(cache)
+	ctx := context.Background()
+
+	inpb := &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A", TotalBooks: 2}}
+	mockBaseService.On("Exists", mockAnyCtx(), bson.M{"name": "C", "author": "A"}).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.MatchedBy(func(c model.Collection) bool {
+		return c.SeedStatus == model.SeedStatusPending
+	})).Return(nil)
+	release := make(chan struct{})
+	mockService.BookClient.(*mocks.MockBookServiceClient).
+		On("BulkInsert", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { <-release }).
+		Return(&pb.BookResponse{Success: true}, nil).Once()
+
+	resp, err := mockService.AddCollection(ctx, inpb)
+	require.NoError(t, err)
+	id := resp.Collection[0].Id
+	assert.Equal(t, model.SeedStatusPending, resp.Collection[0].SeedStatus)
+
+	// A cached copy still saying pending is dropped once seeding is done
+	require.NoError(t, cache.Set(ctx, "collection:"+id, "{}", time.Hour).Err())
+	repository.On("SetSeedStatus", mock.Anything, id, model.SeedStatusComplete).Return(&mongo.UpdateResult{MatchedCount: 1}, nil).Once()
+	close(release)
+
+	require.NoError(t, mockService.Background.Shutdown(ctx))
+	repository.AssertExpectations(t)
+	assert.Zero(t, cache.Exists(ctx, "collection:"+id).Val())
+}
+
+func TestAddCollection_SeedStatusFailedAfterRetries(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, repository := newServer(cache)
+	ctx := context.Background()
+
+	inpb := &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A", TotalBooks: 2}}
+	mockBaseService.On("Exists", mockAnyCtx(), bson.M{"name": "C", "author": "A"}).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).
+		On("BulkInsert", mock.Anything, mock.Anything).Return(nil, errors.New("book service unavailable")).Times(3)
+	repository.On("SetSeedStatus", mock.Anything, mock.Anything, model.SeedStatusFailed).Return(&mongo.UpdateResult{MatchedCount: 1}, nil).Once()
+
+	_, err := mockService.AddCollection(ctx, inpb)
+	require.NoError(t, err)
+
+	require.NoError(t, mockService.Background.Shutdown(ctx))
+	repository.AssertExpectations(t)
+	repository.AssertNotCalled(t, "SetSeedStatus", mock.Anything, mock.Anything, model.SeedStatusComplete)
+	assert.Equal(t, int64(1), cache.LLen(ctx, utils.StockDLQKey).Val())
+}
+
+func TestAddCollection_RejectsTooManyBooks(t *testing.T) {
+	mockBaseService, mockService, _ := newServer(newRedis(t))
+	mockService.MaxTotalBooks = 100
+
+	_, err := mockService.AddCollection(context.Background(), &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A", TotalBooks: 101}})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{"total_books": "total_books must be less than or equal to 100"}, grpcutil.FieldViolations(err))
+	mockBaseService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockService.BookClient.(*mocks.MockBookServiceClient).AssertNotCalled(t, "BulkInsert", mock.Anything, mock.Anything)
+}
+
+func TestAddCollection_SeedsBooksInBatches(t *testing.T) {
+	mockBaseService, mockService, repository := newServer(newRedis(t))
+	ctx := context.Background()
+
+	mockBaseService.On("Exists", mockAnyCtx(), mock.Anything).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+	repository.On("SetSeedStatus", mock.Anything, mock.Anything, model.SeedStatusComplete).Return(&mongo.UpdateResult{MatchedCount: 1}, nil).Once()
+
+	var sizes []int
+	bookClient := mockService.BookClient.(*mocks.MockBookServiceClient)
+	bookClient.On("BulkInsert", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sizes = append(sizes, len(args.Get(1).(*pb.BulkInsertBookRequest).Books)) }).
+		Return(&pb.BookResponse{Success: true}, nil)
+
+	_, err := mockService.AddCollection(ctx, &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A", TotalBooks: 2500}})
+	require.NoError(t, err)
+
+	require.NoError(t, mockService.Background.Shutdown(ctx))
+	assert.Equal(t, []int{1000, 1000, 500}, sizes)
+	repository.AssertExpectations(t)
+}
+
+func TestAddCollection_FailedBatchQueuesRemainingBooks(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, repository := newServer(cache)
+	mockService.SeedBatchSize = 10
+	ctx := context.Background()
+
+	mockBaseService.On("Exists", mockAnyCtx(), mock.Anything).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+	repository.On("SetSeedStatus", mock.Anything, mock.Anything, model.SeedStatusFailed).Return(&mongo.UpdateResult{MatchedCount: 1}, nil).Once()
+
+	// The first batch goes through, the second never does
+	bookClient := mockService.BookClient.(*mocks.MockBookServiceClient)
+	bookClient.On("BulkInsert", mock.Anything, mock.Anything).Return(&pb.BookResponse{Success: true}, nil).Once()
+	bookClient.On("BulkInsert", mock.Anything, mock.Anything).Return(nil, errors.New("book service unavailable")).Times(3)
+
+	_, err := mockService.AddCollection(ctx, &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A", TotalBooks: 25}})
+	require.NoError(t, err)
+	require.NoError(t, mockService.Background.Shutdown(ctx))
+
+	op, ok, err := utils.PopDeadLetter[utils.StockOperation](ctx, cache, utils.StockDLQKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, utils.OpCreateCollectionBooks, op.Op)
+	assert.Equal(t, 15, op.BookCount)
+	bookClient.AssertExpectations(t)
+	repository.AssertExpectations(t)
+}
+
+func TestAddCollection_NoBooksIsSeeded(t *testing.T) {
+	mockBaseService, mockService, repository := newServer(newRedis(t))
+
+	mockBaseService.On("Exists", mockAnyCtx(), mock.Anything).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+
+	resp, err := mockService.AddCollection(context.Background(), &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A"}})
+	require.NoError(t, err)
+	assert.Equal(t, model.SeedStatusComplete, resp.Collection[0].SeedStatus)
+
+	require.NoError(t, mockService.Background.Shutdown(context.Background()))
+	repository.AssertNotCalled(t, "SetSeedStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetSeedStatus_RejectsUnknownStatus(t *testing.T) {
+	_, mockService, repository := newServer(newRedis(t))
+
+	_, err := mockService.SetSeedStatus(context.Background(), &pb.SetSeedStatusRequest{Id: primitive.NewObjectID().Hex(), SeedStatus: "done"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	repository.AssertNotCalled(t, "SetSeedStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddCollection_DuplicateKeyRace(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+
+	// Both creates pass the existence check, the unique index rejects the second
+	duplicate := mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+		Code:    11000,
+		Message: "E11000 duplicate key error collection: collections index: name_author_unique",
+	}}}
+	inpb := &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A"}}
+	mockBaseService.On("Exists", mockAnyCtx(), bson.M{"name": "C", "author": "A"}).Return(false, nil)
+	mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(duplicate)
+
+	resp, err := mockService.AddCollection(context.Background(), inpb)
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	assert.Equal(t, "Collection already exists", status.Convert(err).Message())
+}
+
 func TestUpdateCollection_NameAuthorConflict(t *testing.T) {
 	cache := newRedis(t)
 	mockBaseService, mockService, _ := newServer(cache)
@@ -174,7 +467,7 @@ func TestUpdateCollection_Success(t *testing.T) {
 	mockBaseService.On("Find", mockAnyCtx(), mock.Anything).Return(&model.Collection{}, mongo.ErrNoDocuments)
 
 	updated := model.Collection{Id: id, Name: "New", Author: "Auth"}
-	mockBaseService.On("Update", mockAnyCtx(), mock.MatchedBy(func(m map[string]any) bool { return m["updated_at"] != nil }), id.Hex()).Return(updated, nil)
+	mockBaseService.On("Update", mockAnyCtx(), mock.MatchedBy(func(m map[string]any) bool { return m["updated_at"] == nil }), id.Hex()).Return(updated, nil)
 
 	resp, err := mockService.UpdateCollection(context.Background(), &pb.UpdateCollectionRequest{Id: id.Hex(), Payload: &structpb.Struct{
 		Fields: map[string]*structpb.Value{
@@ -187,11 +480,90 @@ func TestUpdateCollection_Success(t *testing.T) {
 	assert.Equal(t, updated.Id.Hex(), resp.Collection[0].Id)
 }
 
+func TestUpdateCollection_IfMatch(t *testing.T) {
+	id := primitive.NewObjectID()
+	readAt := time.UnixMilli(time.Now().Add(-time.Minute).UnixMilli())
+	payload := &structpb.Struct{Fields: map[string]*structpb.Value{"categories": structpb.NewListValue(&structpb.ListValue{})}}
+	conditional := bson.M{"_id": id, "updated_at": readAt}
+
+	t.Run("matching etag", func(t *testing.T) {
+		mockBaseService, mockService, _ := newServer(newRedis(t))
+		updated := &model.Collection{Id: id, Name: "C", UpdatedAt: time.Now()}
+		mockBaseService.On("UpdateMany", mockAnyCtx(), conditional, mock.Anything).Return(int64(1), nil).Once()
+		mockBaseService.On("FindById", mockAnyCtx(), id.Hex()).Return(updated, nil).Once()
+
+		resp, err := mockService.UpdateCollection(context.Background(), &pb.UpdateCollectionRequest{
+			Id: id.Hex(), Payload: payload, IfMatch: model.ETag(readAt),
+		})
+
+		require.NoError(t, err)
+		assert.True(t, resp.Success)
+		assert.Equal(t, model.ETag(updated.UpdatedAt), resp.Collection[0].Etag)
+	})
+
+	t.Run("stale etag", func(t *testing.T) {
+		mockBaseService, mockService, _ := newServer(newRedis(t))
+		mockBaseService.On("UpdateMany", mockAnyCtx(), conditional, mock.Anything).Return(int64(0), nil).Once()
+		mockBaseService.On("FindById", mockAnyCtx(), id.Hex()).Return(&model.Collection{Id: id, UpdatedAt: time.Now()}, nil).Once()
+
+		_, err := mockService.UpdateCollection(context.Background(), &pb.UpdateCollectionRequest{
+			Id: id.Hex(), Payload: payload, IfMatch: model.ETag(readAt),
+		})
+
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestCollectionTimestamps_RoundTrip(t *testing.T) {
+	t.Run("created", func(t *testing.T) {
+		mockBaseService, mockService, _ := newServer(newRedis(t))
+		mockBaseService.On("Exists", mockAnyCtx(), mock.Anything).Return(false, nil)
+		mockBaseService.On("Create", mockAnyCtx(), mock.Anything).Return(nil)
+
+		resp, err := mockService.AddCollection(context.Background(), &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "C", Author: "A"}})
+		require.NoError(t, err)
+
+		collection := model.FromPbCollection(resp.Collection[0])
+		require.NotNil(t, collection)
+		assert.WithinDuration(t, time.Now(), collection.CreatedAt, 2*time.Second)
+		roundTrip := model.ToPbCollection(collection)
+		// The etag keeps the milliseconds the RFC3339 timestamps drop
+		roundTrip.Etag = resp.Collection[0].Etag
+		assert.Equal(t, resp.Collection[0], roundTrip)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		mockBaseService, mockService, _ := newServer(newRedis(t))
+		id := primitive.NewObjectID()
+		mockBaseService.On("Find", mockAnyCtx(), mock.Anything).Return(&model.Collection{}, mongo.ErrNoDocuments)
+
+		// The driver hands back dates in whatever zone it was configured with
+		zone := time.FixedZone("UTC+7", 7*60*60)
+		created := time.Date(2024, 3, 1, 8, 0, 0, 0, zone)
+		mockBaseService.On("Update", mockAnyCtx(), mock.MatchedBy(func(m map[string]any) bool {
+			// Left for the repository to stamp as a date
+			_, set := m["updated_at"]
+			return !set
+		}), id.Hex()).Return(model.Collection{Id: id, Name: "New", CreatedAt: created, UpdatedAt: time.Now().In(zone)}, nil)
+
+		resp, err := mockService.UpdateCollection(context.Background(), &pb.UpdateCollectionRequest{Id: id.Hex(), Payload: &structpb.Struct{
+			Fields: map[string]*structpb.Value{"name": structpb.NewStringValue("New")},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-01T01:00:00Z", resp.Collection[0].CreatedAt)
+
+		collection := model.FromPbCollection(resp.Collection[0])
+		require.NotNil(t, collection)
+		assert.True(t, created.Equal(collection.CreatedAt))
+		assert.WithinDuration(t, time.Now(), collection.UpdatedAt, 2*time.Second)
+	})
+}
+
 func TestDeleteCollection_NotFound(t *testing.T) {
 	cache := newRedis(t)
 	mockBaseService, mockService, _ := newServer(cache)
 
-	mockBaseService.On("Delete", mockAnyCtx(), "missing").Return(model.Collection{}, mongo.ErrNoDocuments)
+	mockBaseService.On("SoftDelete", mockAnyCtx(), "missing").Return(model.Collection{}, mongo.ErrNoDocuments)
 
 	resp, err := mockService.DeleteCollection(context.Background(), &pb.DeleteCollectionRequest{Id: "missing"})
 	require.NoError(t, err)
@@ -205,7 +577,8 @@ func TestDeleteCollection_Success(t *testing.T) {
 
 	id := primitive.NewObjectID()
 	deleted := model.Collection{Id: id}
-	mockBaseService.On("Delete", mockAnyCtx(), id.Hex()).Return(deleted, nil)
+	mockBaseService.On("SoftDelete", mockAnyCtx(), id.Hex()).Return(deleted, nil)
+	mockService.BookClient.(*mocks.MockBookServiceClient).On("BulkDelete", mock.Anything, mock.Anything).Return(&pb.BookResponse{Success: true}, nil)
 
 	resp, err := mockService.DeleteCollection(context.Background(), &pb.DeleteCollectionRequest{Id: id.Hex()})
 	require.NoError(t, err)
@@ -213,38 +586,77 @@ func TestDeleteCollection_Success(t *testing.T) {
 	assert.Equal(t, id.Hex(), resp.Collection[0].Id)
 }
 
-func TestDecrementAvailableBooks_UpdatesStockAndCache(t *testing.T) {
+func TestDeleteCollection_CascadesToBooks(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+	ctx := context.Background()
+
+	id := primitive.NewObjectID().Hex()
+	require.NoError(t, cache.SAdd(ctx, "available_books:"+id, primitive.NewObjectID().Hex()).Err())
+	mockBaseService.On("SoftDelete", mockAnyCtx(), id).Return(model.Collection{Id: mustOID(id)}, nil)
+
+	deleted := make(chan struct{})
+	bookClient := mockService.BookClient.(*mocks.MockBookServiceClient)
+	bookClient.On("BulkDelete", mock.Anything, &pb.BulkDeleteBookRequest{CollectionId: id}).
+		Return(nil, errors.New("book service unavailable")).Once()
+	bookClient.On("BulkDelete", mock.Anything, &pb.BulkDeleteBookRequest{CollectionId: id}).
+		Run(func(mock.Arguments) { close(deleted) }).
+		Return(&pb.BookResponse{Success: true}, nil).Once()
+
+	_, err := mockService.DeleteCollection(ctx, &pb.DeleteCollectionRequest{Id: id})
+	require.NoError(t, err)
+
+	exists, err := cache.Exists(ctx, "available_books:"+id).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+
+	// The failed attempt is retried in the background
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		t.Fatal("books of the deleted collection were never deleted")
+	}
+	bookClient.AssertExpectations(t)
+}
+
+func TestDeleteCollection_ExhaustedCascadeIsDeadLettered(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+	ctx := context.Background()
+
+	id := primitive.NewObjectID().Hex()
+	mockBaseService.On("SoftDelete", mockAnyCtx(), id).Return(model.Collection{Id: mustOID(id)}, nil)
+	bookClient := mockService.BookClient.(*mocks.MockBookServiceClient)
+	bookClient.On("BulkDelete", mock.Anything, &pb.BulkDeleteBookRequest{CollectionId: id}).
+		Return(nil, errors.New("book service unavailable")).Times(3)
+
+	_, err := mockService.DeleteCollection(ctx, &pb.DeleteCollectionRequest{Id: id})
+	require.NoError(t, err)
+
+	// Shutdown waits for the retries to run out
+	require.NoError(t, mockService.Background.Shutdown(ctx))
+
+	op, ok, err := utils.PopDeadLetter[utils.StockOperation](ctx, cache, utils.StockDLQKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, utils.OpDeleteCollectionBooks, op.Op)
+	assert.Equal(t, id, op.CollectionId)
+	assert.Equal(t, "book service unavailable", op.Error)
+	bookClient.AssertExpectations(t)
+}
+
+func TestAdjustBookStock_AddBookMovesBothCounters(t *testing.T) {
 	cache := newRedis(t)
-	// repo := new(mocks.MockCollectionRepository)
 	_, mockService, repo := newServer(cache)
 
 	id := primitive.NewObjectID().Hex()
-	// seed cache with collection having AvailableBooks=5
-	seed := &model.Collection{Id: mustOID(id), TotalBooks: 5}
+	seed := &model.Collection{Id: mustOID(id), TotalBooks: 5, AvailableBooks: 3}
 	raw, _ := json.Marshal(seed)
 	require.NoError(t, cache.Set(context.Background(), "collection:"+id, raw, time.Hour).Err())
 
-	// use a matcher to allow flexible map matching (int vs int32)
-	repo.On("UpdateBookStock", mockAnyCtx(), mock.MatchedBy(func(m map[string]interface{}) bool {
-		v, ok := m["total_books"]
-		if !ok {
-			return false
-		}
-		switch x := v.(type) {
-		case int:
-			return x == 1
-		case int32:
-			return x == 1
-		case int64:
-			return x == 1
-		case float64:
-			return int(x) == 1
-		default:
-			return false
-		}
-	}), id).Return(mongo.UpdateResult{ModifiedCount: 1}, nil)
-
-	resp, err := mockService.DecrementAvailableBooks(context.Background(), &pb.DecrementAvailableBooksRequest{Id: id, Amount: 1})
+	repo.On("AdjustBookStock", mockAnyCtx(), id, 1, 1).Return(&mongo.UpdateResult{ModifiedCount: 1}, nil)
+
+	resp, err := mockService.AdjustBookStock(context.Background(), &pb.AdjustBookStockRequest{Id: id, TotalDelta: 1, AvailableDelta: 1})
 	require.NoError(t, err)
 	assert.True(t, resp.Success)
 
@@ -253,6 +665,31 @@ func TestDecrementAvailableBooks_UpdatesStockAndCache(t *testing.T) {
 	var cached model.Collection
 	require.NoError(t, json.Unmarshal(out, &cached))
 	assert.Equal(t, 6, cached.TotalBooks)
+	assert.Equal(t, 4, cached.AvailableBooks)
+	repo.AssertExpectations(t)
+}
+
+func TestAdjustBookStock_BorrowMovesAvailableOnly(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService, repo := newServer(cache)
+
+	id := primitive.NewObjectID().Hex()
+	seed := &model.Collection{Id: mustOID(id), TotalBooks: 5, AvailableBooks: 3}
+	raw, _ := json.Marshal(seed)
+	require.NoError(t, cache.Set(context.Background(), "collection:"+id, raw, time.Hour).Err())
+
+	repo.On("AdjustBookStock", mockAnyCtx(), id, 0, -1).Return(&mongo.UpdateResult{ModifiedCount: 1}, nil)
+
+	resp, err := mockService.AdjustBookStock(context.Background(), &pb.AdjustBookStockRequest{Id: id, AvailableDelta: -1})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	out, err := cache.Get(context.Background(), "collection:"+id).Bytes()
+	require.NoError(t, err)
+	var cached model.Collection
+	require.NoError(t, json.Unmarshal(out, &cached))
+	assert.Equal(t, 5, cached.TotalBooks)
+	assert.Equal(t, 2, cached.AvailableBooks)
 }
 
 func mockAnyCtx() interface{} { return mock.MatchedBy(func(ctx context.Context) bool { return true }) }
@@ -260,3 +697,72 @@ func mustOID(hex string) primitive.ObjectID {
 	id, _ := primitive.ObjectIDFromHex(hex)
 	return id
 }
+
+func TestFindCollectionById_UsesConfiguredTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockBaseService, mockService, _ := newServer(cache)
+	mockService.CacheTTL.CollectionTTL = 15 * time.Minute
+
+	id := primitive.NewObjectID()
+	mc := &model.Collection{Id: id, Name: "Dune", Author: "Frank Herbert"}
+	mockBaseService.On("Find", mockAnyCtx(), bson.M{"_id": id.Hex()}).Return(mc, nil)
+
+	_, err := mockService.FindCollectionById(context.Background(), &pb.FindCollectionRequest{Id: id.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, mr.TTL("collection:"+id.Hex()))
+
+	mr.FastForward(14 * time.Minute)
+	assert.True(t, mr.Exists("collection:"+id.Hex()))
+	mr.FastForward(time.Minute)
+	assert.False(t, mr.Exists("collection:"+id.Hex()))
+}
+
+func TestGetCategories(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, repository := newServer(cache)
+	ctx := context.Background()
+
+	// Distinct over collections tagged {fantasy, classic}, {science} and {classic}
+	repository.On("DistinctCategories", mockAnyCtx()).Return([]string{"science", "fantasy", "classic"}, nil).Once()
+
+	resp, err := mockService.GetCategories(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"classic", "fantasy", "science"}, resp.Categories)
+
+	// The repeat is served from the cache
+	resp, err = mockService.GetCategories(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"classic", "fantasy", "science"}, resp.Categories)
+	repository.AssertNumberOfCalls(t, "DistinctCategories", 1)
+	ttl, err := cache.TTL(ctx, "collection_categories").Result()
+	require.NoError(t, err)
+	assert.InDelta(t, config.DefaultCategoriesCacheTTL.Seconds(), ttl.Seconds(), 1)
+
+	// Updating a collection can change the set, so it's read again
+	id := primitive.NewObjectID()
+	mockBaseService.On("Update", mockAnyCtx(), mock.Anything, id.Hex()).
+		Return(model.Collection{Id: id, Categories: []string{"poetry"}}, nil).Once()
+	_, err = mockService.UpdateCollection(ctx, &pb.UpdateCollectionRequest{Id: id.Hex(), Payload: &structpb.Struct{
+		Fields: map[string]*structpb.Value{"categories": structpb.NewListValue(&structpb.ListValue{
+			Values: []*structpb.Value{structpb.NewStringValue("poetry")},
+		})},
+	}})
+	require.NoError(t, err)
+
+	repository.On("DistinctCategories", mockAnyCtx()).Return([]string{"poetry", "science", "classic"}, nil).Once()
+	resp, err = mockService.GetCategories(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"classic", "poetry", "science"}, resp.Categories)
+	repository.AssertExpectations(t)
+}
+
+func TestGetCategories_NoneInUse(t *testing.T) {
+	_, mockService, repository := newServer(newRedis(t))
+	repository.On("DistinctCategories", mockAnyCtx()).Return(nil, nil).Once()
+
+	resp, err := mockService.GetCategories(context.Background(), &emptypb.Empty{})
+
+	require.NoError(t, err)
+	assert.Empty(t, resp.Categories)
+}
@@ -0,0 +1,32 @@
+package test
+
+import (
+	"collection/internal/db"
+	"shared/pkg/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestIndexModels_WellFormed(t *testing.T) {
+	models := db.IndexModels()
+	require.NoError(t, repository.ValidateIndexModels(models))
+
+	byName := make(map[string]bson.D)
+	for _, model := range models {
+		opts := repository.IndexOptions(model)
+		byName[*opts.Name] = model.Keys.(bson.D)
+
+		if *opts.Name == "name_author_unique" {
+			require.NotNil(t, opts.Unique)
+			assert.True(t, *opts.Unique)
+			// Soft-deleted collections are left out so they can be re-created
+			assert.Equal(t, bson.M{repository.DeletedAtField: nil}, opts.PartialFilterExpression)
+		}
+	}
+
+	assert.Equal(t, bson.D{{Key: "name", Value: "text"}, {Key: "author", Value: "text"}, {Key: "categories", Value: "text"}}, byName["collection_search"])
+	assert.Equal(t, bson.D{{Key: "name", Value: 1}, {Key: "author", Value: 1}}, byName["name_author_unique"])
+}
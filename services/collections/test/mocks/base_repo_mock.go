@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 // Mock repository for testing
@@ -17,6 +18,11 @@ func (m *MockRepository[K]) GetAll(ctx context.Context) ([]K, error) {
 	return args.Get(0).([]K), args.Error(1)
 }
 
+func (m *MockRepository[K]) ListWithTotal(ctx context.Context, filter bson.M) ([]K, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]K), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockRepository[K]) Find(ctx context.Context, filter bson.M) (*K, error) {
 	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
@@ -25,6 +31,11 @@ func (m *MockRepository[K]) Find(ctx context.Context, filter bson.M) (*K, error)
 	return args.Get(0).(*K), args.Error(1)
 }
 
+func (m *MockRepository[K]) FindByIds(ctx context.Context, ids []string) ([]K, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]K), args.Error(1)
+}
+
 func (m *MockRepository[K]) Insert(ctx context.Context, entity K) (interface{}, error) {
 	args := m.Called(ctx, entity)
 	return args.Get(0), args.Error(1)
@@ -50,7 +61,30 @@ func (m *MockRepository[K]) Count(ctx context.Context, filter bson.M) (int64, er
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockRepository[K]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error {
+	args := m.Called(ctx, pipeline, results)
+	return args.Error(0)
+}
+
 func (m *MockRepository[K]) BulkInsert(ctx context.Context, entities []K) (interface{}, error) {
 	args := m.Called(ctx, entities)
 	return args.Get(0), args.Error(1)
 }
+
+func (m *MockRepository[K]) BulkDelete(ctx context.Context, filter bson.M) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository[K]) UpdateMany(ctx context.Context, filter bson.M, update map[string]interface{}) (int64, error) {
+	args := m.Called(ctx, filter, update)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository[K]) Upsert(ctx context.Context, data K, filter bson.M) (*mongo.UpdateResult, error) {
+	args := m.Called(ctx, data, filter)
+	if result, ok := args.Get(0).(*mongo.UpdateResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
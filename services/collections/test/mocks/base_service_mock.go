@@ -5,17 +5,33 @@ import (
 
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type MockService[T any, U any] struct{ mock.Mock }
 
-func (m *MockService[T, U]) List(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int) ([]T, error) {
+func (m *MockService[T, U]) List(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]T, error) {
 	args := m.Called(ctx)
 	if v, ok := args.Get(0).([]T); ok {
 		return v, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
+func (m *MockService[T, U]) ListWithTotal(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]T, int64, error) {
+	args := m.Called(ctx)
+	total, _ := args.Get(1).(int64)
+	if v, ok := args.Get(0).([]T); ok {
+		return v, total, args.Error(2)
+	}
+	return nil, total, args.Error(2)
+}
+func (m *MockService[T, U]) ListCursor(ctx context.Context, filter bson.M, sort bson.D, after string, limit int, fields ...string) ([]T, string, error) {
+	args := m.Called(ctx, after, limit)
+	if v, ok := args.Get(0).([]T); ok {
+		return v, args.String(1), args.Error(2)
+	}
+	return nil, args.String(1), args.Error(2)
+}
 func (m *MockService[T, U]) FindById(ctx context.Context, id string) (*T, error) {
 	args := m.Called(ctx, id)
 	if v, ok := args.Get(0).(*T); ok {
@@ -23,13 +39,21 @@ func (m *MockService[T, U]) FindById(ctx context.Context, id string) (*T, error)
 	}
 	return nil, args.Error(1)
 }
-func (m *MockService[T, U]) Find(ctx context.Context, filter bson.M) (*T, error) {
+func (m *MockService[T, U]) Find(ctx context.Context, filter bson.M, fields ...string) (*T, error) {
 	args := m.Called(ctx, filter)
 	if v, ok := args.Get(0).(*T); ok {
 		return v, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
+
+func (m *MockService[T, U]) FindByIds(ctx context.Context, ids []string) ([]T, error) {
+	args := m.Called(ctx, ids)
+	if v, ok := args.Get(0).([]T); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
 func (m *MockService[T, U]) Create(ctx context.Context, doc T) error {
 	args := m.Called(ctx, doc)
 	return args.Error(0)
@@ -50,6 +74,14 @@ func (m *MockService[T, U]) Delete(ctx context.Context, id string) (T, error) {
 	}
 	return zero, args.Error(1)
 }
+func (m *MockService[T, U]) SoftDelete(ctx context.Context, id string) (T, error) {
+	args := m.Called(ctx, id)
+	var zero T
+	if v, ok := args.Get(0).(T); ok {
+		return v, args.Error(1)
+	}
+	return zero, args.Error(1)
+}
 func (m *MockService[T, U]) Exists(ctx context.Context, filter bson.M) (bool, error) {
 	args := m.Called(ctx, filter)
 	return args.Bool(0), args.Error(1)
@@ -60,6 +92,27 @@ func (m *MockService[T, U]) BulkInsert(ctx context.Context, entities []T) error
 	return args.Error(0)
 }
 
+func (m *MockService[T, U]) BulkDelete(ctx context.Context, filter bson.M) (int64, error) {
+	args := m.Called(ctx, filter)
+	if v, ok := args.Get(0).(int64); ok {
+		return v, args.Error(1)
+	}
+	return 0, args.Error(1)
+}
+
+func (m *MockService[T, U]) UpdateMany(ctx context.Context, filter bson.M, update map[string]interface{}) (int64, error) {
+	args := m.Called(ctx, filter, update)
+	if v, ok := args.Get(0).(int64); ok {
+		return v, args.Error(1)
+	}
+	return 0, args.Error(1)
+}
+
+func (m *MockService[T, U]) Upsert(ctx context.Context, data T, filter bson.M) error {
+	args := m.Called(ctx, data, filter)
+	return args.Error(0)
+}
+
 func (m *MockService[T, U]) Count(ctx context.Context, filter bson.M) (int64, error) {
 	args := m.Called(ctx, filter)
 	if v, ok := args.Get(0).(int64); ok {
@@ -67,3 +120,12 @@ func (m *MockService[T, U]) Count(ctx context.Context, filter bson.M) (int64, er
 	}
 	return 0, args.Error(1)
 }
+
+func (m *MockService[T, U]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error {
+	args := m.Called(ctx, pipeline, results)
+	return args.Error(0)
+}
+
+func (m *MockService[T, U]) WithTransaction(ctx context.Context, fn func(sessCtx context.Context) error) error {
+	return fn(ctx)
+}
@@ -18,6 +18,14 @@ func (m *MockBookServiceClient) BulkInsert(ctx context.Context, in *pb.BulkInser
 	return &pb.BookResponse{}, args.Error(1)
 }
 
+func (m *MockBookServiceClient) BulkDelete(ctx context.Context, in *pb.BulkDeleteBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	args := m.Called(ctx, in)
+	if v, ok := args.Get(0).(*pb.BookResponse); ok {
+		return v, args.Error(1)
+	}
+	return &pb.BookResponse{}, args.Error(1)
+}
+
 func (m *MockBookServiceClient) GetBook(ctx context.Context, in *pb.GetBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
 	return nil, nil
 }
@@ -42,6 +50,22 @@ func (m *MockBookServiceClient) GetAvailableBook(ctx context.Context, in *pb.Get
 	return nil, nil
 }
 
+func (m *MockBookServiceClient) GetAvailableBooks(ctx context.Context, in *pb.GetAvailableBookRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	return nil, nil
+}
+
+func (m *MockBookServiceClient) GetBooksByCollection(ctx context.Context, in *pb.GetBooksByCollectionRequest, opts ...grpc.CallOption) (*pb.BookResponse, error) {
+	return nil, nil
+}
+
 func (m *MockBookServiceClient) CountBook(ctx context.Context, in *pb.CountBookRequest, opts ...grpc.CallOption) (*pb.BookCountResponse, error) {
 	return nil, nil
 }
+
+func (m *MockBookServiceClient) ReplayStockDLQ(ctx context.Context, in *pb.ReplayStockDLQRequest, opts ...grpc.CallOption) (*pb.ReplayStockDLQResponse, error) {
+	return nil, nil
+}
+
+func (m *MockBookServiceClient) UpdateBooks(ctx context.Context, in *pb.UpdateBooksRequest, opts ...grpc.CallOption) (*pb.UpdateBooksResponse, error) {
+	return nil, nil
+}
@@ -13,10 +13,34 @@ type MockCollectionRepository struct {
 	Repository MockRepository[model.Collection]
 }
 
-func (m *MockCollectionRepository) UpdateBookStock(ctx context.Context, update map[string]interface{}, id string) (interface{}, error) {
-	args := m.Called(ctx, update, id)
-	if res := args.Get(0); res != nil {
+func (m *MockCollectionRepository) AdjustBookStock(ctx context.Context, id string, totalDelta, availableDelta int) (*mongo.UpdateResult, error) {
+	args := m.Called(ctx, id, totalDelta, availableDelta)
+	if res, ok := args.Get(0).(*mongo.UpdateResult); ok {
 		return res, args.Error(1)
 	}
-	return mongo.UpdateResult{}, args.Error(1)
+	return &mongo.UpdateResult{}, args.Error(1)
+}
+
+func (m *MockCollectionRepository) SetSeedStatus(ctx context.Context, id string, seedStatus string) (*mongo.UpdateResult, error) {
+	args := m.Called(ctx, id, seedStatus)
+	if res, ok := args.Get(0).(*mongo.UpdateResult); ok {
+		return res, args.Error(1)
+	}
+	return &mongo.UpdateResult{}, args.Error(1)
+}
+
+func (m *MockCollectionRepository) DistinctCategories(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if categories, ok := args.Get(0).([]string); ok {
+		return categories, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCollectionRepository) FindWithBookCounts(ctx context.Context, id string) (*model.Collection, error) {
+	args := m.Called(ctx, id)
+	if collection, ok := args.Get(0).(*model.Collection); ok {
+		return collection, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
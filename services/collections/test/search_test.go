@@ -0,0 +1,136 @@
+package test
+
+import (
+	"collection/internal"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"shared/pkg/model"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Compiles the pattern of each $or clause the way Mongo applies it
+func searchPatterns(t *testing.T, query string) map[string]*regexp.Regexp {
+	clauses := internal.BuildSearchFilter(query)["$or"].(bson.A)
+	require.Len(t, clauses, 3)
+
+	patterns := make(map[string]*regexp.Regexp)
+	for _, clause := range clauses {
+		for field, condition := range clause.(bson.M) {
+			cond := condition.(bson.M)
+			require.Equal(t, "i", cond["$options"])
+			patterns[field] = regexp.MustCompile("(?i)" + cond["$regex"].(string))
+		}
+	}
+	return patterns
+}
+
+func TestBuildSearchFilter_PartialMatch(t *testing.T) {
+	patterns := searchPatterns(t, "potter")
+
+	assert.True(t, patterns["name"].MatchString("Harry Potter and the Goblet of Fire"))
+	assert.True(t, patterns["author"].MatchString("Beatrix Potter"))
+	assert.False(t, patterns["name"].MatchString("The Hobbit"))
+}
+
+func TestBuildSearchFilter_CaseInsensitive(t *testing.T) {
+	patterns := searchPatterns(t, "FANTASY")
+
+	assert.True(t, patterns["categories"].MatchString("fantasy"))
+	assert.True(t, patterns["categories"].MatchString("Dark Fantasy"))
+}
+
+func TestBuildSearchFilter_EscapesMetacharacters(t *testing.T) {
+	patterns := searchPatterns(t, "C++ (3rd ed.)")
+
+	assert.True(t, patterns["name"].MatchString("Learning C++ (3rd ed.)"))
+	assert.False(t, patterns["name"].MatchString("CCC 3rd edX"))
+}
+
+func TestSearchCollections_Success(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+
+	ctx := context.Background()
+	found := []model.Collection{{Id: primitive.NewObjectID(), Name: "Harry Potter", Author: "J. K. Rowling"}}
+	mockBaseService.On("ListWithTotal", ctx).Return(found, int64(1), nil)
+
+	resp, err := mockService.SearchCollections(ctx, &pb.SearchRequest{Query: "harry", Limit: 10})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Collection, 1)
+	assert.Equal(t, int64(1), resp.Total)
+}
+
+func TestSearchCollections_NoResults(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+
+	ctx := context.Background()
+	mockBaseService.On("ListWithTotal", ctx).Return([]model.Collection{}, int64(0), nil)
+
+	resp, err := mockService.SearchCollections(ctx, &pb.SearchRequest{Query: "nothing like this", Limit: 10})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Empty(t, resp.Collection)
+	assert.Equal(t, int64(0), resp.Total)
+}
+
+func TestSearchCollections_EmptyQuery(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService, _ := newServer(cache)
+
+	_, err := mockService.SearchCollections(context.Background(), &pb.SearchRequest{Query: "  "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestBuildSearchFilter_EscapesRegexOperators(t *testing.T) {
+	for _, query := range []string{".*", "(a+)+", "^admin$", "[a-z]{1,}|x"} {
+		patterns := searchPatterns(t, query)
+
+		assert.Equal(t, regexp.QuoteMeta(query), strings.TrimPrefix(patterns["name"].String(), "(?i)"))
+		assert.True(t, patterns["name"].MatchString("title with "+query+" inside"), query)
+		assert.False(t, patterns["name"].MatchString("aaaa"), query)
+	}
+}
+
+func TestBuildRegexSearchFilter_RejectsInvalidPattern(t *testing.T) {
+	_, err := internal.BuildRegexSearchFilter("(unclosed")
+	assert.Error(t, err)
+
+	filter, err := internal.BuildRegexSearchFilter("^Harry")
+	require.NoError(t, err)
+	clause := filter["$or"].(bson.A)[0].(bson.M)
+	assert.Equal(t, "^Harry", clause["name"].(bson.M)["$regex"])
+}
+
+func TestSearchCollections_RejectsLongQuery(t *testing.T) {
+	cache := newRedis(t)
+	mockBaseService, mockService, _ := newServer(cache)
+
+	_, err := mockService.SearchCollections(context.Background(), &pb.SearchRequest{
+		Query: strings.Repeat("a", internal.MaxSearchQueryLength+1),
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockBaseService.AssertNotCalled(t, "ListWithTotal", mock.Anything)
+}
+
+func TestSearchCollections_InvalidRegex(t *testing.T) {
+	cache := newRedis(t)
+	_, mockService, _ := newServer(cache)
+
+	_, err := mockService.SearchCollections(context.Background(), &pb.SearchRequest{Query: "(unclosed", Regex: true})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"collection/internal"
+	"collection/internal/db"
+	"collection/test/mocks"
+	"context"
+	"testing"
+
+	"shared/config"
+	"shared/pkg/model"
+	"shared/pkg/mongotest"
+	"shared/pkg/repository"
+	"shared/pkg/service"
+	"shared/pkg/worker"
+	pb "shared/proto/buffer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server whose collections are stored in store instead of a mock service
+func newStoreServer(t *testing.T, store mongotest.Store) *internal.CollectionServiceServer {
+	client, err := mongo.Connect(options.Client().ApplyURI(mongotest.Server(t, store.Reply)))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	database := client.Database("test")
+	bookClient := &mocks.MockBookServiceClient{}
+	bookClient.On("BulkDelete", mock.Anything, mock.Anything).Return(&pb.BookResponse{Success: true}, nil).Maybe()
+	return &internal.CollectionServiceServer{
+		Service:    service.NewBaseService[model.Collection, model.CollectionUpdateRequest](repository.NewRepository[model.Collection](database, db.CollectionName)),
+		Repository: internal.NewCollectionRepository(database, db.CollectionName),
+		Cache:      newRedis(t),
+		CacheTTL:   *config.DefaultCacheTTLConfig(),
+		BookClient: bookClient,
+		Background: worker.NewPool(worker.DefaultPoolSize),
+	}
+}
+
+func TestAddCollection_RecreatesDeletedCollection(t *testing.T) {
+	store := mongotest.Store{}
+	svc := newStoreServer(t, store)
+	ctx := context.Background()
+	dune := func() *pb.AddCollectionRequest {
+		return &pb.AddCollectionRequest{Collection: &pb.Collection{Name: "Dune", Author: "Frank Herbert", Categories: []string{"Fiction"}}}
+	}
+
+	created, err := svc.AddCollection(ctx, dune())
+	require.NoError(t, err)
+	require.True(t, created.Success)
+
+	deleted, err := svc.DeleteCollection(ctx, &pb.DeleteCollectionRequest{Id: created.Collection[0].Id})
+	require.NoError(t, err)
+	require.True(t, deleted.Success)
+
+	recreated, err := svc.AddCollection(ctx, dune())
+	require.NoError(t, err)
+	assert.True(t, recreated.Success)
+	assert.NotEqual(t, created.Collection[0].Id, recreated.Collection[0].Id)
+
+	// The deleted collection is kept next to the new one
+	require.Len(t, store[db.CollectionName], 2)
+	assert.NotNil(t, store[db.CollectionName][0][repository.DeletedAtField])
+	assert.Nil(t, store[db.CollectionName][1][repository.DeletedAtField])
+
+	// The live one still blocks a duplicate
+	_, err = svc.AddCollection(ctx, dune())
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type BorrowConfig struct {
+	LoanDays        int           `json:"loan_days"`         // Loan period when a request doesn't ask for one
+	MaxLoanDays     int           `json:"max_loan_days"`     // Longest loan period a request may ask for
+	MaxActive       int           `json:"max_active"`        // Most unreturned borrows a user may hold at once
+	RenewalDays     int           `json:"renewal_days"`      // Days a renewal adds to the due date
+	MaxRenewals     int           `json:"max_renewals"`      // Most times a single borrow may be renewed
+	FinePerDay      int64         `json:"fine_per_day"`      // Fee per started day overdue, in the smallest currency unit
+	DueSoonDays     int           `json:"due_soon_days"`     // How far ahead a borrow counts as due soon
+	DueSoonNotify   bool          `json:"due_soon_notify"`   // Whether the due-soon webhook is dispatched on a schedule
+	DueSoonWebhook  string        `json:"due_soon_webhook"`  // URL the due-soon list is POSTed to
+	DueSoonInterval time.Duration `json:"due_soon_interval"` // Time between due-soon dispatches
+}
+
+const (
+	DefaultLoanDays        = 7
+	DefaultMaxLoanDays     = 60
+	DefaultMaxActive       = 5
+	DefaultRenewalDays     = 7
+	DefaultMaxRenewals     = 2
+	DefaultFinePerDay      = 1000
+	DefaultDueSoonDays     = 3
+	DefaultDueSoonInterval = 24 * time.Hour
+)
+
+func DefaultBorrowConfig() *BorrowConfig {
+	return &BorrowConfig{
+		LoanDays:        DefaultLoanDays,
+		MaxLoanDays:     DefaultMaxLoanDays,
+		MaxActive:       DefaultMaxActive,
+		RenewalDays:     DefaultRenewalDays,
+		MaxRenewals:     DefaultMaxRenewals,
+		FinePerDay:      DefaultFinePerDay,
+		DueSoonDays:     DefaultDueSoonDays,
+		DueSoonInterval: DefaultDueSoonInterval,
+	}
+}
+
+// Load borrow settings from environment
+func LoadBorrowConfig() *BorrowConfig {
+	godotenv.Load(".env")
+	config := DefaultBorrowConfig()
+
+	loadPositiveInt("BORROW_LOAN_DAYS", &config.LoanDays)
+	loadPositiveInt("BORROW_MAX_LOAN_DAYS", &config.MaxLoanDays)
+	loadPositiveInt("BORROW_MAX_ACTIVE", &config.MaxActive)
+	loadPositiveInt("BORROW_RENEWAL_DAYS", &config.RenewalDays)
+	loadPositiveInt("BORROW_MAX_RENEWALS", &config.MaxRenewals)
+	loadNonNegativeInt64("BORROW_FINE_PER_DAY", &config.FinePerDay)
+
+	if config.LoanDays > config.MaxLoanDays {
+		log.Printf("BORROW_LOAN_DAYS %d exceeds the maximum, using %d", config.LoanDays, config.MaxLoanDays)
+		config.LoanDays = config.MaxLoanDays
+	}
+
+	loadPositiveInt("BORROW_DUE_SOON_DAYS", &config.DueSoonDays)
+	loadDuration("BORROW_DUE_SOON_INTERVAL", &config.DueSoonInterval)
+	config.DueSoonWebhook = os.Getenv("BORROW_DUE_SOON_WEBHOOK_URL")
+	config.DueSoonNotify = os.Getenv("BORROW_DUE_SOON_NOTIFY") == "true"
+	if config.DueSoonNotify && config.DueSoonWebhook == "" {
+		log.Printf("BORROW_DUE_SOON_NOTIFY is set without BORROW_DUE_SOON_WEBHOOK_URL, notifications are disabled")
+		config.DueSoonNotify = false
+	}
+
+	return config
+}
+
+// Overrides target with a positive integer from the environment, invalid
+// values are logged and the current value is kept
+func loadPositiveInt(key string, target *int) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Ignoring invalid %s %q, using %d", key, value, *target)
+		return
+	}
+	*target = parsed
+}
+
+// Overrides target with a non-negative integer from the environment, so zero
+// can turn a fee off, invalid values are logged and the current value is kept
+func loadNonNegativeInt64(key string, target *int64) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed < 0 {
+		log.Printf("Ignoring invalid %s %q, using %d", key, value, *target)
+		return
+	}
+	*target = parsed
+}
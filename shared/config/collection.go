@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/joho/godotenv"
+)
+
+type CollectionConfig struct {
+	MaxTotalBooks int `json:"max_total_books"` // Most books a new collection may be created with
+	SeedBatchSize int `json:"seed_batch_size"` // Books sent to the book service per bulk insert
+}
+
+const (
+	DefaultMaxTotalBooks = 100000
+	DefaultSeedBatchSize = 1000
+)
+
+func DefaultCollectionConfig() *CollectionConfig {
+	return &CollectionConfig{
+		MaxTotalBooks: DefaultMaxTotalBooks,
+		SeedBatchSize: DefaultSeedBatchSize,
+	}
+}
+
+// Load collection settings from environment
+func LoadCollectionConfig() *CollectionConfig {
+	godotenv.Load(".env")
+	config := DefaultCollectionConfig()
+
+	loadPositiveInt("COLLECTION_MAX_TOTAL_BOOKS", &config.MaxTotalBooks)
+	loadPositiveInt("COLLECTION_SEED_BATCH_SIZE", &config.SeedBatchSize)
+
+	return config
+}
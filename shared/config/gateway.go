@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type GatewayConfig struct {
+	Addr            string        `json:"addr"`             // host:port the HTTP server binds, an empty host binds every interface
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"` // How long in-flight requests get to finish on shutdown
+	AdminToken      string        `json:"-"`                // Bearer token for the admin endpoints, they're disabled when empty
+	MaxBodyBytes    int           `json:"max_body_bytes"`   // Largest request body accepted
+	MaxBulkBytes    int           `json:"max_bulk_bytes"`   // Largest request body accepted by bulk endpoints
+}
+
+const (
+	DefaultGatewayAddr            = ":8080"
+	DefaultGatewayShutdownTimeout = 5 * time.Second
+	DefaultGatewayMaxBodyBytes    = 1 << 20
+	DefaultGatewayMaxBulkBytes    = 10 << 20
+)
+
+func DefaultGatewayConfig() *GatewayConfig {
+	return &GatewayConfig{
+		Addr:            DefaultGatewayAddr,
+		ShutdownTimeout: DefaultGatewayShutdownTimeout,
+		MaxBodyBytes:    DefaultGatewayMaxBodyBytes,
+		MaxBulkBytes:    DefaultGatewayMaxBulkBytes,
+	}
+}
+
+// Load gateway settings from environment, a malformed GATEWAY_ADDR is an
+// error since the server could never bind it
+func LoadGatewayConfig() (*GatewayConfig, error) {
+	godotenv.Load(".env")
+	config := DefaultGatewayConfig()
+
+	if addr := os.Getenv("GATEWAY_ADDR"); addr != "" {
+		if err := validateAddr(addr); err != nil {
+			return nil, fmt.Errorf("invalid GATEWAY_ADDR %q: %w", addr, err)
+		}
+		config.Addr = addr
+	}
+	loadDuration("GATEWAY_SHUTDOWN_TIMEOUT", &config.ShutdownTimeout)
+	config.AdminToken = os.Getenv("GATEWAY_ADMIN_TOKEN")
+	loadPositiveInt("GATEWAY_MAX_BODY_BYTES", &config.MaxBodyBytes)
+	loadPositiveInt("GATEWAY_MAX_BULK_BYTES", &config.MaxBulkBytes)
+
+	return config, nil
+}
+
+func validateAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if number, err := strconv.Atoi(port); err != nil || number < 0 || number > 65535 {
+		return fmt.Errorf("port %q is not a number between 0 and 65535", port)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// How services connect to MongoDB
+type MongoConfig struct {
+	URI                    string        `json:"uri"`
+	Database               string        `json:"database"`
+	MaxPoolSize            int           `json:"max_pool_size"`
+	MinPoolSize            int           `json:"min_pool_size"`
+	MaxConnIdleTime        time.Duration `json:"max_conn_idle_time"`
+	ConnectTimeout         time.Duration `json:"connect_timeout"`
+	ServerSelectionTimeout time.Duration `json:"server_selection_timeout"`
+	// How long the primary gets to answer the startup ping
+	PingTimeout time.Duration `json:"ping_timeout"`
+	// Tries at startup before giving up, MongoDB may still be starting
+	ConnectAttempts int `json:"connect_attempts"`
+	// Wait before the first retry, doubled after each one
+	ConnectBackoff time.Duration `json:"connect_backoff"`
+	// Members a write waits for, "majority" or a number of members
+	WriteConcern string `json:"write_concern"`
+	// Members reads go to, one of primary, primaryPreferred, secondary,
+	// secondaryPreferred or nearest
+	ReadPreference string `json:"read_preference"`
+}
+
+const (
+	DefaultMongoDatabase       = "library_management_system"
+	DefaultMongoWriteConcern   = "1"
+	DefaultMongoReadPreference = "primary"
+)
+
+// Collection the book service stores books in. The collection service counts
+// books from it with $lookup, which only joins within one database, so both
+// services must be configured with the same MONGODB_DATABASE.
+const BookCollectionName = "book"
+
+// Write concerns of services that don't use the default. Borrows and returns
+// must survive a failover, a w:1 write can be rolled back with the primary.
+var DefaultMongoWriteConcerns = map[string]string{
+	"borrow": "majority",
+}
+
+// Read preferences MONGODB_READ_PREFERENCE accepts
+var mongoReadPreferences = []string{"primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest"}
+
+func DefaultMongoConfig() *MongoConfig {
+	return &MongoConfig{
+		URI:                    "mongodb://localhost:27017",
+		Database:               DefaultMongoDatabase,
+		MaxPoolSize:            100,
+		MinPoolSize:            25,
+		MaxConnIdleTime:        30 * time.Second,
+		ConnectTimeout:         5 * time.Second,
+		ServerSelectionTimeout: 5 * time.Second,
+		PingTimeout:            5 * time.Second,
+		ConnectAttempts:        5,
+		ConnectBackoff:         time.Second,
+		WriteConcern:           DefaultMongoWriteConcern,
+		ReadPreference:         DefaultMongoReadPreference,
+	}
+}
+
+// Load the MongoDB connection settings of the named service from MONGODB_URI,
+// MONGODB_DATABASE, MONGODB_MAX_POOL_SIZE, MONGODB_MIN_POOL_SIZE,
+// MONGODB_MAX_CONN_IDLE_TIME, MONGODB_CONNECT_TIMEOUT,
+// MONGODB_SERVER_SELECTION_TIMEOUT, MONGODB_PING_TIMEOUT,
+// MONGODB_CONNECT_ATTEMPTS and MONGODB_CONNECT_BACKOFF. The write concern and
+// read preference come from <NAME>_MONGODB_WRITE_CONCERN and
+// <NAME>_MONGODB_READ_PREFERENCE, e.g. BORROW_MONGODB_WRITE_CONCERN for
+// "borrow", then MONGODB_WRITE_CONCERN and MONGODB_READ_PREFERENCE, then the
+// service's default.
+func LoadMongoConfig(serviceName string) *MongoConfig {
+	godotenv.Load(".env")
+	prefix := strings.ToUpper(serviceName)
+	config := DefaultMongoConfig()
+	if writeConcern, ok := DefaultMongoWriteConcerns[serviceName]; ok {
+		config.WriteConcern = writeConcern
+	}
+
+	if uri := os.Getenv("MONGODB_URI"); uri != "" {
+		config.URI = uri
+	}
+	if database := os.Getenv("MONGODB_DATABASE"); database != "" {
+		config.Database = database
+	}
+	loadPositiveInt("MONGODB_MAX_POOL_SIZE", &config.MaxPoolSize)
+	loadPositiveInt("MONGODB_MIN_POOL_SIZE", &config.MinPoolSize)
+	loadDuration("MONGODB_MAX_CONN_IDLE_TIME", &config.MaxConnIdleTime)
+	loadDuration("MONGODB_CONNECT_TIMEOUT", &config.ConnectTimeout)
+	loadDuration("MONGODB_SERVER_SELECTION_TIMEOUT", &config.ServerSelectionTimeout)
+	loadDuration("MONGODB_PING_TIMEOUT", &config.PingTimeout)
+	loadPositiveInt("MONGODB_CONNECT_ATTEMPTS", &config.ConnectAttempts)
+	loadDuration("MONGODB_CONNECT_BACKOFF", &config.ConnectBackoff)
+
+	loadWriteConcern("MONGODB_WRITE_CONCERN", &config.WriteConcern)
+	loadWriteConcern(prefix+"_MONGODB_WRITE_CONCERN", &config.WriteConcern)
+	loadReadPreference("MONGODB_READ_PREFERENCE", &config.ReadPreference)
+	loadReadPreference(prefix+"_MONGODB_READ_PREFERENCE", &config.ReadPreference)
+
+	if config.MinPoolSize > config.MaxPoolSize {
+		log.Printf("MONGODB_MIN_POOL_SIZE %d exceeds MONGODB_MAX_POOL_SIZE %d, using %d", config.MinPoolSize, config.MaxPoolSize, config.MaxPoolSize)
+		config.MinPoolSize = config.MaxPoolSize
+	}
+
+	return config
+}
+
+// Overrides target with "majority" or a positive member count from the
+// environment, invalid values are logged and the current value is kept
+func loadWriteConcern(key string, target *string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	if value != "majority" {
+		if members, err := strconv.Atoi(value); err != nil || members <= 0 {
+			log.Printf("Ignoring invalid %s %q, using %s", key, value, *target)
+			return
+		}
+	}
+	*target = value
+}
+
+// Overrides target with a read preference mode from the environment, matched
+// case-insensitively, invalid values are logged and the current value is kept
+func loadReadPreference(key string, target *string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	for _, mode := range mongoReadPreferences {
+		if strings.EqualFold(value, mode) {
+			*target = mode
+			return
+		}
+	}
+	log.Printf("Ignoring invalid %s %q, using %s", key, value, *target)
+}
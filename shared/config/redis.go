@@ -1,6 +1,7 @@
 package config
 
 import (
+	"log"
 	"os"
 	"time"
 
@@ -25,6 +26,27 @@ type CacheConfig struct {
 	Policy    string // eviction policy
 }
 
+// How long cached entries live before they are read from the database again
+type CacheTTLConfig struct {
+	BookTTL           time.Duration `json:"book_ttl"`
+	CollectionTTL     time.Duration `json:"collection_ttl"`
+	AvailableBooksTTL time.Duration `json:"available_books_ttl"`
+	BorrowTTL         time.Duration `json:"borrow_ttl"`
+	ListTTL           time.Duration `json:"list_ttl"` // List pages are only cached when positive
+	CategoriesTTL     time.Duration `json:"categories_ttl"`
+	BookCountsTTL     time.Duration `json:"book_counts_ttl"` // Stock counted from the books, see GetCollectionWithCounts
+}
+
+const (
+	DefaultCacheTTL = time.Hour
+	// Categories are dropped on every collection write anyway, the short TTL
+	// only bounds drift from writes made outside the service
+	DefaultCategoriesCacheTTL = 5 * time.Minute
+	// Counting the books is the point of the call, so its result only briefly
+	// stands in for the count
+	DefaultBookCountsCacheTTL = 30 * time.Second
+)
+
 // Default configuration
 func DefaultRedisConfig() *RedisConfig {
 	return &RedisConfig{
@@ -56,3 +78,46 @@ func LoadRedisConfig() *RedisConfig {
 
 	return config
 }
+
+func DefaultCacheTTLConfig() *CacheTTLConfig {
+	return &CacheTTLConfig{
+		BookTTL:           DefaultCacheTTL,
+		CollectionTTL:     DefaultCacheTTL,
+		AvailableBooksTTL: DefaultCacheTTL,
+		BorrowTTL:         DefaultCacheTTL,
+		CategoriesTTL:     DefaultCategoriesCacheTTL,
+		BookCountsTTL:     DefaultBookCountsCacheTTL,
+	}
+}
+
+// Load cache TTLs from environment, values use Go duration syntax e.g. "30m"
+func LoadCacheTTLConfig() *CacheTTLConfig {
+	godotenv.Load(".env")
+	config := DefaultCacheTTLConfig()
+
+	loadDuration("BOOK_CACHE_TTL", &config.BookTTL)
+	loadDuration("COLLECTION_CACHE_TTL", &config.CollectionTTL)
+	loadDuration("AVAILABLE_BOOKS_CACHE_TTL", &config.AvailableBooksTTL)
+	loadDuration("BORROW_CACHE_TTL", &config.BorrowTTL)
+	loadDuration("LIST_CACHE_TTL", &config.ListTTL)
+	loadDuration("CATEGORIES_CACHE_TTL", &config.CategoriesTTL)
+	loadDuration("BOOK_COUNTS_CACHE_TTL", &config.BookCountsTTL)
+
+	return config
+}
+
+// Overrides target with a positive duration from the environment, invalid
+// values are logged and the current value is kept
+func loadDuration(key string, target *time.Duration) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Ignoring invalid %s %q, using %s", key, value, *target)
+		return
+	}
+	*target = duration
+}
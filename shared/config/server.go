@@ -0,0 +1,86 @@
+package config
+
+import (
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Where a service's gRPC server listens and where other services reach it
+type ServerConfig struct {
+	Host string `json:"host"` // Interface to bind, empty binds every interface
+	Port string `json:"port"`
+	// How long shutdown waits for background work started by earlier calls
+	DrainTimeout time.Duration `json:"drain_timeout"`
+	// How long a client connection may live before the server sends GOAWAY,
+	// so connections left over from a restarted client don't linger
+	MaxConnectionAge time.Duration `json:"max_connection_age"`
+	// How long calls in flight get to finish after MaxConnectionAge
+	MaxConnectionAgeGrace time.Duration `json:"max_connection_age_grace"`
+	// Shortest interval between client pings, faster clients are disconnected
+	KeepaliveMinTime time.Duration `json:"keepalive_min_time"`
+}
+
+// Long enough for a background task that just started to use up its own
+// 5 second deadline
+const DefaultDrainTimeout = 10 * time.Second
+
+const (
+	DefaultMaxConnectionAge      = 30 * time.Minute
+	DefaultMaxConnectionAgeGrace = 30 * time.Second
+	// Half the 30 second interval clients ping at, so jitter never trips it
+	DefaultKeepaliveMinTime = 15 * time.Second
+)
+
+// Ports used when a service's *_SERVICE_PORT is unset
+var DefaultServicePorts = map[string]string{
+	"collection": "50051",
+	"book":       "50052",
+	"borrow":     "50053",
+}
+
+// Load the listen address of the named service from <NAME>_SERVICE_HOST and
+// <NAME>_SERVICE_PORT, e.g. BOOK_SERVICE_PORT for "book", and its drain
+// timeout from <NAME>_DRAIN_TIMEOUT. Keepalive settings come from
+// <NAME>_MAX_CONNECTION_AGE, <NAME>_MAX_CONNECTION_AGE_GRACE and
+// <NAME>_KEEPALIVE_MIN_TIME.
+func LoadServerConfig(serviceName string) *ServerConfig {
+	godotenv.Load(".env")
+	prefix := strings.ToUpper(serviceName)
+
+	config := &ServerConfig{
+		Host:         os.Getenv(prefix + "_SERVICE_HOST"),
+		Port:         DefaultServicePorts[serviceName],
+		DrainTimeout: DefaultDrainTimeout,
+
+		MaxConnectionAge:      DefaultMaxConnectionAge,
+		MaxConnectionAgeGrace: DefaultMaxConnectionAgeGrace,
+		KeepaliveMinTime:      DefaultKeepaliveMinTime,
+	}
+	if port := os.Getenv(prefix + "_SERVICE_PORT"); port != "" {
+		config.Port = port
+	}
+	loadDuration(prefix+"_DRAIN_TIMEOUT", &config.DrainTimeout)
+	loadDuration(prefix+"_MAX_CONNECTION_AGE", &config.MaxConnectionAge)
+	loadDuration(prefix+"_MAX_CONNECTION_AGE_GRACE", &config.MaxConnectionAgeGrace)
+	loadDuration(prefix+"_KEEPALIVE_MIN_TIME", &config.KeepaliveMinTime)
+
+	return config
+}
+
+// Address the server binds to
+func (c *ServerConfig) ListenAddress() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// Address clients dial, localhost when the server binds every interface
+func (c *ServerConfig) DialAddress() string {
+	host := c.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, c.Port)
+}
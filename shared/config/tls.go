@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+// Certificates used to secure gRPC traffic between services. Setting a CA on
+// the server makes it require client certificates, and setting a certificate
+// on a client makes it present one, which together give mutual TLS.
+type TLSConfig struct {
+	CertFile   string `json:"cert_file"`   // PEM certificate this process presents
+	KeyFile    string `json:"key_file"`    // PEM private key of CertFile
+	CAFile     string `json:"ca_file"`     // PEM CA bundle the peer's certificate is verified against
+	ServerName string `json:"server_name"` // Overrides the name clients expect on server certificates
+	Insecure   bool   `json:"insecure"`    // Plaintext connections, only meant for local development
+}
+
+// Load TLS settings from environment
+func LoadTLSConfig() *TLSConfig {
+	godotenv.Load(".env")
+
+	insecure, _ := strconv.ParseBool(os.Getenv("GRPC_INSECURE"))
+	return &TLSConfig{
+		CertFile:   os.Getenv("GRPC_TLS_CERT"),
+		KeyFile:    os.Getenv("GRPC_TLS_KEY"),
+		CAFile:     os.Getenv("GRPC_TLS_CA"),
+		ServerName: os.Getenv("GRPC_TLS_SERVER_NAME"),
+		Insecure:   insecure,
+	}
+}
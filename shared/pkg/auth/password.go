@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrEmptyPassword = errors.New("password must not be empty")
+
+// HashPassword returns the bcrypt hash of plain to store in place of the
+// password. bcrypt refuses passwords longer than 72 bytes.
+func HashPassword(plain string) (string, error) {
+	if plain == "" {
+		return "", ErrEmptyPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether plain is the password hash was made from
+func CheckPassword(hash, plain string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
+}
@@ -0,0 +1,131 @@
+package grpcutil
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"shared/config"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// How long a connection may sit idle before the client pings it
+	KeepaliveTime = 30 * time.Second
+	// How long the client waits for a ping ack before closing the connection
+	KeepaliveTimeout = 10 * time.Second
+)
+
+// KeepaliveDialOption pings idle connections so a backend that went away is
+// noticed before a request is sent to it
+func KeepaliveDialOption() grpc.DialOption {
+	return grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                KeepaliveTime,
+		Timeout:             KeepaliveTimeout,
+		PermitWithoutStream: true,
+	})
+}
+
+// KeepaliveServerOptions accepts the pings sent by clients dialed with
+// KeepaliveDialOption, the default policy would answer them with GOAWAY. It
+// also pings idle clients and ages connections out after MaxConnectionAge,
+// calls in flight get MaxConnectionAgeGrace to finish before the connection
+// is closed and the client reconnects.
+func KeepaliveServerOptions(cfg *config.ServerConfig) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionAge:      cfg.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
+			Time:                  KeepaliveTime,
+			Timeout:               KeepaliveTimeout,
+		}),
+	}
+}
+
+// WaitForReady starts connecting conn and blocks until it is Ready or ctx is done
+func WaitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection not ready, last state %s: %w", state, ctx.Err())
+		}
+	}
+}
+
+// WaitForConnections waits for every connection concurrently and returns the
+// error of each one that did not become Ready before ctx was done
+func WaitForConnections(ctx context.Context, connections map[string]*grpc.ClientConn) map[string]error {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed = make(map[string]error)
+	)
+
+	for service, conn := range connections {
+		wg.Add(1)
+		go func(service string, conn *grpc.ClientConn) {
+			defer wg.Done()
+			if err := WaitForReady(ctx, conn); err != nil {
+				mu.Lock()
+				failed[service] = err
+				mu.Unlock()
+			}
+		}(service, conn)
+	}
+	wg.Wait()
+
+	return failed
+}
+
+// ConnectionStates reports the connectivity state of every connection and
+// whether all of them are Ready
+func ConnectionStates(connections map[string]*grpc.ClientConn) (map[string]string, bool) {
+	states := make(map[string]string, len(connections))
+	ready := true
+	for service, conn := range connections {
+		state := conn.GetState()
+		states[service] = state.String()
+		if state != connectivity.Ready {
+			ready = false
+		}
+	}
+	return states, ready
+}
+
+// WatchConnections checks every connection each interval until ctx is
+// cancelled. Idle connections are reconnected and ones in TransientFailure
+// skip their remaining backoff, so a restarted backend is picked up right away
+// instead of failing the first request sent to it.
+func WatchConnections(ctx context.Context, connections map[string]*grpc.ClientConn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for service, conn := range connections {
+				switch conn.GetState() {
+				case connectivity.Idle:
+					conn.Connect()
+				case connectivity.TransientFailure:
+					log.Printf("%s connection failing, reconnecting", service)
+					conn.ResetConnectBackoff()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package grpcutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"shared/config"
+	"shared/pkg/tracing"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// DialServices creates a client connection for every service name to address
+// pair with the options every caller shares: transport credentials from the
+// TLS config, keepalive pings and the tracing and request id interceptors.
+// Extra options are applied after them. Connections are established lazily,
+// use WaitForConnections to wait for the peers to be up. Every address that
+// can't be dialed is reported in the returned error, in which case the
+// connections already created are closed and none are returned.
+func DialServices(services map[string]string, extra ...grpc.DialOption) (map[string]*grpc.ClientConn, error) {
+	creds, err := ClientCredentials(config.LoadTLSConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC client credentials: %w", err)
+	}
+
+	opts := []grpc.DialOption{
+		creds,
+		KeepaliveDialOption(),
+		grpc.WithChainUnaryInterceptor(
+			tracing.UnaryClientInterceptor(),
+			UnaryClientInterceptor(),
+		),
+	}
+	opts = append(opts, extra...)
+
+	var errs []error
+	connections := make(map[string]*grpc.ClientConn, len(services))
+	for service, address := range services {
+		if err := validateAddress(address); err != nil {
+			errs = append(errs, fmt.Errorf("%s service: %w", service, err))
+			continue
+		}
+		conn, err := grpc.NewClient(address, opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s service at %s: %w", service, address, err))
+			continue
+		}
+		connections[service] = conn
+	}
+
+	if len(errs) > 0 {
+		CloseConnections(connections)
+		return nil, errors.Join(errs...)
+	}
+	return connections, nil
+}
+
+// CloseConnections closes every connection, errors are ignored since the
+// connections are only closed on the way out
+func CloseConnections(connections map[string]*grpc.ClientConn) {
+	for _, conn := range connections {
+		conn.Close()
+	}
+}
+
+// grpc.NewClient accepts nearly any string and only fails once a call is
+// made, so a missing or malformed host:port is caught here instead. Targets
+// with a resolver scheme are left for gRPC to parse.
+func validateAddress(address string) error {
+	if strings.Contains(address, "://") {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	if port == "" {
+		return fmt.Errorf("invalid address %q: missing port", address)
+	}
+	return nil
+}
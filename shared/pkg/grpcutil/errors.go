@@ -0,0 +1,85 @@
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Messages for failures whose own text isn't meant for clients
+const (
+	MessageTimeout     = "Backend service did not respond in time"
+	MessageUnavailable = "Backend service is unavailable"
+	MessageInternal    = "Internal Server Error"
+)
+
+// HTTPError maps the error of a failed backend call to the HTTP status and a
+// message that is safe to show clients. Status messages are written by our
+// services for callers and passed on, while deadlines, transport failures and
+// errors without a gRPC status get a generic message so addresses and
+// driver errors don't leak.
+func HTTPError(err error) (int, string) {
+	if err == nil {
+		return http.StatusOK, ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+		return http.StatusGatewayTimeout, MessageTimeout
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		if isConnectionError(err) {
+			return http.StatusServiceUnavailable, MessageUnavailable
+		}
+		return http.StatusInternalServerError, MessageInternal
+	}
+
+	switch st.Code() {
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable, MessageUnavailable
+	case codes.Unknown:
+		return http.StatusInternalServerError, MessageInternal
+	}
+	return httpStatus(st.Code()), st.Message()
+}
+
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Whether err comes from reaching the backend rather than from the backend
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
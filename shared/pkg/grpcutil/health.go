@@ -0,0 +1,61 @@
+package grpcutil
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Pinger checks a dependency the service needs to serve requests
+type Pinger func(ctx context.Context) error
+
+const pingTimeout = 2 * time.Second
+
+// NewHealthServer returns a health server that reports NOT_SERVING until a
+// CheckHealth call finds every dependency reachable.
+func NewHealthServer() *health.Server {
+	server := health.NewServer()
+	server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return server
+}
+
+// CheckHealth pings every dependency and sets the overall status of server
+// to SERVING only when all of them respond.
+func CheckHealth(ctx context.Context, server *health.Server, pingers ...Pinger) healthpb.HealthCheckResponse_ServingStatus {
+	status := healthpb.HealthCheckResponse_SERVING
+	for _, ping := range pingers {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := ping(pingCtx)
+		cancel()
+
+		if err != nil {
+			log.Printf("Health check failed: %v", err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			break
+		}
+	}
+
+	server.SetServingStatus("", status)
+	return status
+}
+
+// WatchHealth runs CheckHealth right away and then every interval until ctx
+// is cancelled.
+func WatchHealth(ctx context.Context, server *health.Server, interval time.Duration, pingers ...Pinger) {
+	CheckHealth(ctx, server, pingers...)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			CheckHealth(ctx, server, pingers...)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIdMetadata is the metadata key carrying the correlation id between services
+const RequestIdMetadata = "x-request-id"
+
+type requestIdKey struct{}
+
+// WithRequestId returns a context carrying the given correlation id.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestIdFromContext returns the correlation id of the current call, looking at
+// the value set by the server interceptor first and incoming metadata second.
+func RequestIdFromContext(ctx context.Context) string {
+	if requestId, ok := ctx.Value(requestIdKey{}).(string); ok && requestId != "" {
+		return requestId
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIdMetadata); len(ids) > 0 {
+			return ids[0]
+		}
+	}
+	return ""
+}
+
+// UnaryServerInterceptor reads the correlation id from incoming metadata, or
+// generates one when the caller sent none, stores it in the handler context and
+// logs it with the method name.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestId := RequestIdFromContext(ctx)
+		if requestId == "" {
+			requestId = newRequestId()
+		}
+
+		log.Printf("[%s] %s", requestId, info.FullMethod)
+		return handler(WithRequestId(ctx, requestId), req)
+	}
+}
+
+// UnaryClientInterceptor forwards the correlation id of the current call to
+// the service being called. Ids already set on the outgoing metadata are kept.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if md, ok := metadata.FromOutgoingContext(ctx); !ok || len(md.Get(RequestIdMetadata)) == 0 {
+			if requestId := RequestIdFromContext(ctx); requestId != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, RequestIdMetadata, requestId)
+			}
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+package grpcutil
+
+import (
+	"sort"
+	"strings"
+
+	pb "shared/proto/buffer"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InvalidArgumentStatus builds an InvalidArgument error carrying one field
+// violation per entry of fields, so callers can report them individually.
+func InvalidArgumentStatus(message string, fields map[string]string) error {
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range names {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fields[field],
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, message).WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, message)
+	}
+	return st.Err()
+}
+
+// FieldViolations returns the field violations attached to a gRPC error by
+// InvalidArgumentStatus, or nil when there are none.
+func FieldViolations(err error) map[string]string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]string
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, violation := range badRequest.GetFieldViolations() {
+			if fields == nil {
+				fields = map[string]string{}
+			}
+			fields[violation.GetField()] = violation.GetDescription()
+		}
+	}
+	return fields
+}
+
+// BuildSort converts the sort of a list request into a Mongo sort, answering
+// InvalidArgument for keys outside allowed. Sorting on a field no index covers
+// makes Mongo sort in memory, which fails past 32MB on large collections, so
+// services only allow their index-backed fields.
+func BuildSort(items []*pb.Sort, allowed ...string) (bson.D, error) {
+	sortable := make(map[string]bool, len(allowed))
+	for _, key := range allowed {
+		sortable[key] = true
+	}
+
+	order := bson.D{}
+	violations := map[string]string{}
+	for _, item := range items {
+		if !sortable[item.GetKey()] {
+			violations[item.GetKey()] = item.GetKey() + " can't be sorted on, use one of: " + strings.Join(allowed, " ")
+			continue
+		}
+		order = append(order, bson.E{Key: item.GetKey(), Value: item.GetDirection()})
+	}
+	if len(violations) > 0 {
+		return nil, InvalidArgumentStatus("Invalid sort", violations)
+	}
+	return order, nil
+}
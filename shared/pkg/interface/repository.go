@@ -4,15 +4,25 @@ import (
 	"context"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type RepositoryInterface[K any] interface {
-	GetAll(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int) ([]K, error)
-	Find(ctx context.Context, filter bson.M) (*K, error)
+	GetAll(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, error)
+	ListWithTotal(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, int64, error)
+	GetAllCursor(ctx context.Context, filter bson.M, sort bson.D, after string, limit int, fields ...string) ([]K, string, error)
+	Find(ctx context.Context, filter bson.M, fields ...string) (*K, error)
+	FindByIds(ctx context.Context, ids []string) ([]K, error)
 	Insert(ctx context.Context, entity K) (interface{}, error)
 	UpdateOne(ctx context.Context, update map[string]interface{}, id string) (K, error)
 	DeleteOne(ctx context.Context, id string) (K, error)
+	SoftDelete(ctx context.Context, id string) (K, error)
 	DataExists(ctx context.Context, filter bson.M) (bool, error)
 	Count(ctx context.Context, filter bson.M) (int64, error)
+	Aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error
 	BulkInsert(ctx context.Context, entities []K) (interface{}, error)
+	BulkDelete(ctx context.Context, filter bson.M) (int64, error)
+	UpdateMany(ctx context.Context, filter bson.M, update map[string]interface{}) (int64, error)
+	Upsert(ctx context.Context, data K, filter bson.M) (*mongo.UpdateResult, error)
+	WithTransaction(ctx context.Context, fn func(sessCtx context.Context) error) error
 }
@@ -2,5 +2,6 @@ package interfaces
 
 type ValidatorInterface[K any, V any] interface {
 	Validate(entity K) error
+	ValidateWithDetails(entity K) (map[string]string, error)
 	ValidateUpdateRequest(payload map[string]interface{}) (map[string]interface{}, error)
 }
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Registry holds every metric this process reports, it is what /metrics serves
+var Registry = prometheus.NewRegistry()
+
+var (
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_requests_total",
+		Help: "gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	GRPCLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_request_duration_seconds",
+		Help:    "Time spent handling gRPC requests, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time spent handling HTTP requests, by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	CacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_hits_total",
+		Help: "Cache lookups that found an entry, by key prefix.",
+	}, []string{"prefix"})
+
+	CacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_misses_total",
+		Help: "Cache lookups that found nothing usable, by key prefix.",
+	}, []string{"prefix"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		GRPCRequests,
+		GRPCLatency,
+		HTTPRequests,
+		HTTPLatency,
+		CacheHits,
+		CacheMisses,
+	)
+}
+
+// Handler serves the registry in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// UnaryServerInterceptor records the latency and status code of every call
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		GRPCLatency.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		GRPCRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		return resp, err
+	}
+}
+
+// Counts a cache lookup under the part of the key before the first ':', so
+// per-entity keys don't each get their own series
+func ObserveCacheLookup(key string, hit bool) {
+	prefix, _, _ := strings.Cut(key, ":")
+	if hit {
+		CacheHits.WithLabelValues(prefix).Inc()
+	} else {
+		CacheMisses.WithLabelValues(prefix).Inc()
+	}
+}
+
+// StartServer serves /metrics on the given port in the background. An empty
+// port disables the listener and returns nil.
+func StartServer(port string) *http.Server {
+	if port == "" {
+		log.Println("Metrics port not set, metrics listener disabled")
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Printf("metrics listening at %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error serving metrics: %v", err)
+		}
+	}()
+	return server
+}
+
+// StopServer shuts down a listener started by StartServer, nil is ignored
+func StopServer(ctx context.Context, server *http.Server) {
+	if server == nil {
+		return
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down metrics listener: %v", err)
+	}
+}
@@ -15,6 +15,7 @@ type Book struct {
 	IsBorrowed   bool               `bson:"is_borrowed" json:"is_borrowed" validate:"boolean"`
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at" validate:"required"`
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt    *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 }
 
 type BookUpdateRequest struct {
@@ -38,12 +39,19 @@ func ToPbBook(c *Book) *pb.Book {
 		return nil
 	}
 
+	var deletedAt string
+	if c.DeletedAt != nil {
+		deletedAt = c.DeletedAt.Format(time.RFC3339)
+	}
+
 	return &pb.Book{
 		Id:           c.Id.Hex(),
 		CollectionId: c.CollectionId.Hex(),
 		IsBorrowed:   wrapperspb.Bool(c.IsBorrowed),
 		CreatedAt:    c.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    c.UpdatedAt.Format(time.RFC3339),
+		DeletedAt:    deletedAt,
+		Etag:         ETag(c.UpdatedAt),
 	}
 }
 
@@ -76,12 +84,20 @@ func FromPbBook(p *pb.Book) *Book {
 		return nil
 	}
 
+	var deletedAt *time.Time
+	if p.DeletedAt != "" {
+		if parsedDeletedTime, err := time.Parse(time.RFC3339, p.DeletedAt); err == nil {
+			deletedAt = &parsedDeletedTime
+		}
+	}
+
 	return &Book{
 		Id:           objId,
 		CollectionId: collectionId,
 		IsBorrowed:   p.IsBorrowed.Value,
 		CreatedAt:    parsedCreatedTime,
 		UpdatedAt:    parsedUpdatedTime,
+		DeletedAt:    deletedAt,
 	}
 }
 
@@ -16,10 +16,21 @@ type Borrow struct {
 	BorrowDate   time.Time          `bson:"borrow_date" json:"borrow_date" validate:"required"`
 	DueDate      *time.Time         `bson:"due_date,omitempty" json:"due_date,omitempty" validate:"required,gtfield=BorrowDate"`
 	ReturnDate   *time.Time         `bson:"return_date,omitempty" json:"return_date,omitempty" validate:"omitempty"`
+	ReturnedAt   *time.Time         `bson:"returned_at,omitempty" json:"returned_at,omitempty" validate:"omitempty"` // Exact time of the return, for audit
+	ReturnedBy   string             `bson:"returned_by,omitempty" json:"returned_by,omitempty" validate:"omitempty,max=200"`
+	RenewalCount int                `bson:"renewal_count" json:"renewal_count" validate:"min=0"`
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at" validate:"required"`
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at" validate:"required"`
+	Status       string             `bson:"-" json:"status,omitempty"` // Computed on read, see Borrow.StatusAt
 }
 
+// Lifecycle states of a borrow, derived from its dates rather than stored
+const (
+	BorrowStatusActive   = "active"
+	BorrowStatusReturned = "returned"
+	BorrowStatusOverdue  = "overdue"
+)
+
 type BorrowUpdateRequest struct {
 	BookId       *primitive.ObjectID `json:"book_id,omitempty" validate:"omitempty,min=1,max=200"`
 	UserId       *primitive.ObjectID `json:"user_id,omitempty" validate:"omitempty,min=1,max=200"`
@@ -27,6 +38,49 @@ type BorrowUpdateRequest struct {
 	BorrowDate   *time.Time          `json:"borrow_date,omitempty" validate:"omitempty"`
 	DueDate      *time.Time          `json:"due_date,omitempty" validate:"omitempty,gtfield=BorrowDate"`
 	ReturnDate   *time.Time          `json:"return_date,omitempty" validate:"omitempty"`
+	ReturnedAt   *time.Time          `json:"returned_at,omitempty" validate:"omitempty"`
+	ReturnedBy   *string             `json:"returned_by,omitempty" validate:"omitempty,min=1,max=200"`
+	RenewalCount *int                `json:"renewal_count,omitempty" validate:"omitempty,min=0"`
+}
+
+func (b *Borrow) IsReturned() bool {
+	return b.ReturnDate != nil && !b.ReturnDate.IsZero()
+}
+
+// A borrow is overdue once its due date has passed without the book being returned
+func (b *Borrow) IsOverdue(now time.Time) bool {
+	return b.StatusAt(now) == BorrowStatusOverdue
+}
+
+// Started days between the due date and the return, or now while the book is
+// still out, a return within the due date is zero days
+func (b *Borrow) DaysOverdue(now time.Time) int {
+	if b.DueDate == nil {
+		return 0
+	}
+	end := now
+	if b.IsReturned() {
+		end = *b.ReturnDate
+	}
+
+	late := end.Sub(*b.DueDate)
+	if late <= 0 {
+		return 0
+	}
+	return int((late + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// Where the borrow stands at now: returned once the book is back, otherwise
+// overdue after the due date and active up to and including it
+func (b *Borrow) StatusAt(now time.Time) string {
+	switch {
+	case b.IsReturned():
+		return BorrowStatusReturned
+	case b.DueDate != nil && b.DueDate.Before(now):
+		return BorrowStatusOverdue
+	default:
+		return BorrowStatusActive
+	}
 }
 
 func ToPbBorrow(c *Borrow) *pb.Borrow {
@@ -34,21 +88,35 @@ func ToPbBorrow(c *Borrow) *pb.Borrow {
 		return nil
 	}
 
+	var dueDate string
+	if c.DueDate != nil {
+		dueDate = c.DueDate.Format(time.RFC3339)
+	}
+
 	var returnDate string
 	if c.ReturnDate != nil {
 		returnDate = c.ReturnDate.Format(time.RFC3339)
 	}
 
+	var returnedAt string
+	if c.ReturnedAt != nil {
+		returnedAt = c.ReturnedAt.Format(time.RFC3339Nano)
+	}
+
 	return &pb.Borrow{
 		Id:           c.Id.Hex(),
 		BookId:       c.BookId.Hex(),
 		UserId:       c.UserId.Hex(),
 		CollectionId: c.CollectionId.Hex(),
 		BorrowDate:   c.BorrowDate.Format(time.RFC3339),
-		DueDate:      c.DueDate.Format(time.RFC3339),
+		DueDate:      dueDate,
 		ReturnDate:   returnDate,
+		ReturnedAt:   returnedAt,
+		ReturnedBy:   c.ReturnedBy,
+		RenewalCount: int32(c.RenewalCount),
 		CreatedAt:    c.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    c.UpdatedAt.Format(time.RFC3339),
+		Status:       c.StatusAt(time.Now()),
 	}
 }
 
@@ -75,15 +143,23 @@ func FromPbBorrow(p *pb.Borrow) *Borrow {
 		return nil
 	}
 
-	dueDate, err := time.Parse(time.RFC3339, p.DueDate)
+	// Empty optional dates stay nil, matching ToPbBorrow
+	dueDate, err := parseOptionalTime(p.DueDate)
 	if err != nil {
 		log.Printf("Failed to parse due date: %v", err)
 		return nil
 	}
 
-	var returnDate time.Time
-	if p.ReturnDate != "" {
-		returnDate, _ = time.Parse(time.RFC3339, p.ReturnDate)
+	returnDate, err := parseOptionalTime(p.ReturnDate)
+	if err != nil {
+		log.Printf("Failed to parse return date: %v", err)
+		return nil
+	}
+
+	returnedAt, err := parseOptionalTime(p.ReturnedAt)
+	if err != nil {
+		log.Printf("Failed to parse returned at time: %v", err)
+		return nil
 	}
 
 	createdAt, err := time.Parse(time.RFC3339, p.CreatedAt)
@@ -104,11 +180,27 @@ func FromPbBorrow(p *pb.Borrow) *Borrow {
 		UserId:       userId,
 		CollectionId: collectionId,
 		BorrowDate:   borrowDate,
-		DueDate:      &dueDate,
-		ReturnDate:   &returnDate,
+		DueDate:      dueDate,
+		ReturnDate:   returnDate,
+		ReturnedAt:   returnedAt,
+		ReturnedBy:   p.ReturnedBy,
+		RenewalCount: int(p.RenewalCount),
 		CreatedAt:    createdAt,
 		UpdatedAt:    updatedAt,
+		Status:       p.Status,
+	}
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
 	}
+	return &parsed, nil
 }
 
 func FromPbBorrows(pBorrows []*pb.Borrow) []*Borrow {
@@ -17,8 +17,19 @@ type Collection struct {
 	AvailableBooks int                `bson:"available_books" json:"available_books" validate:"gte=0"`
 	CreatedAt      time.Time          `bson:"created_at" json:"created_at" validate:"required"`
 	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt      *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	// How creating the collection's books went, empty for collections created
+	// before the status was tracked
+	SeedStatus string `bson:"seed_status,omitempty" json:"seed_status,omitempty" validate:"omitempty,oneof=pending complete failed"`
 }
 
+// Values of Collection.SeedStatus
+const (
+	SeedStatusPending  = "pending"  // Books are being created in the background
+	SeedStatusComplete = "complete" // Every book has been created
+	SeedStatusFailed   = "failed"   // Creating the books ran out of retries, see the stock DLQ
+)
+
 type CollectionUpdateRequest struct {
 	Name           *string   `json:"name" validate:"omitempty,min=1,max=200"`
 	Author         *string   `json:"author" validate:"omitempty,min=1,max=100"`
@@ -41,11 +52,18 @@ func NewCollection() Collection {
 	return collection
 }
 
+// Converts c for the wire, every timestamp is rendered as RFC3339 in UTC which
+// is the only format FromPbCollection parses
 func ToPbCollection(c *Collection) *pb.Collection {
 	if c == nil {
 		return nil
 	}
 
+	var deletedAt string
+	if c.DeletedAt != nil {
+		deletedAt = c.DeletedAt.UTC().Format(time.RFC3339)
+	}
+
 	return &pb.Collection{
 		Id:             c.Id.Hex(),
 		Name:           c.Name,
@@ -53,8 +71,11 @@ func ToPbCollection(c *Collection) *pb.Collection {
 		Categories:     c.Categories,
 		TotalBooks:     int32(c.TotalBooks),
 		AvailableBooks: int32(c.AvailableBooks),
-		CreatedAt:      c.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:      c.UpdatedAt.Format(time.RFC3339),
+		CreatedAt:      c.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:      c.UpdatedAt.UTC().Format(time.RFC3339),
+		DeletedAt:      deletedAt,
+		SeedStatus:     c.SeedStatus,
+		Etag:           ETag(c.UpdatedAt),
 	}
 }
 
@@ -81,6 +102,13 @@ func FromPbCollection(p *pb.Collection) *Collection {
 		return nil
 	}
 
+	var deletedAt *time.Time
+	if p.DeletedAt != "" {
+		if parsedDeletedTime, err := time.Parse(time.RFC3339, p.DeletedAt); err == nil {
+			deletedAt = &parsedDeletedTime
+		}
+	}
+
 	return &Collection{
 		Id:             objId,
 		Name:           p.Name,
@@ -90,6 +118,8 @@ func FromPbCollection(p *pb.Collection) *Collection {
 		AvailableBooks: int(p.AvailableBooks),
 		CreatedAt:      parsedCreatedTime,
 		UpdatedAt:      parsedUpdatedTime,
+		DeletedAt:      deletedAt,
+		SeedStatus:     p.SeedStatus,
 	}
 }
 
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidETag = errors.New("invalid etag")
+
+// Builds the HTTP entity tag of a document from its updated_at, which every
+// write moves. Mongo keeps dates in milliseconds so that's the precision used.
+func ETag(updatedAt time.Time) string {
+	return strconv.Quote(strconv.FormatInt(updatedAt.UnixMilli(), 36))
+}
+
+// Reads back the updated_at an entity tag was built from, a weak W/ prefix is
+// accepted since the tag is compared by value anyway
+func ParseETag(etag string) (time.Time, error) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	unquoted, err := strconv.Unquote(etag)
+	if err != nil {
+		return time.Time{}, ErrInvalidETag
+	}
+	millis, err := strconv.ParseInt(unquoted, 36, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidETag
+	}
+	return time.UnixMilli(millis), nil
+}
@@ -1,10 +1,20 @@
 package model
 
 type HttpResponse struct {
-	Success bool          `json:"success"`
-	Code    int           `json:"code"`
-	Data    []interface{} `json:"data"`
-	Message string        `json:"message"`
+	Success    bool          `json:"success"`
+	Code       int           `json:"code"`
+	Data       []interface{} `json:"data"`
+	Message    string        `json:"message"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	Meta       *Meta         `json:"meta,omitempty"`
+}
+
+type Meta struct {
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
 }
 
 type GrpcResponse struct {
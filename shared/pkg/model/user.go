@@ -1,13 +1,133 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"log"
+	"shared/pkg/auth"
+	pb "shared/proto/buffer"
+	"time"
+)
 
 type User struct {
 	Id        string    `bson:"_id,omitempty" json:"id"`
 	Name      string    `bson:"name,omitempty" json:"name"`
 	Username  string    `bson:"username,omitempty" json:"username"`
 	Email     string    `bson:"email,omitempty" json:"email"`
-	Password  string    `bson:"password,omitempty" json:"password"`
+	Password  string    `bson:"password,omitempty" json:"password"` // bcrypt hash, see SetPassword
 	CreatedAt time.Time `bson:"created_at,omitempty" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at,omitempty" json:"updated_at"`
 }
+
+// What clients see of a user. Response DTOs list the public fields of an
+// entity explicitly, so a field added to the model stays private until it is
+// added here too. Entities with sensitive fields are answered through their
+// DTO instead of the model.
+type UserResponse struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Hashes plain into Password, users must never be stored with the password
+// they signed up with
+func (u *User) SetPassword(plain string) error {
+	hash, err := auth.HashPassword(plain)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	return nil
+}
+
+// Whether plain matches the stored password hash
+func (u *User) CheckPassword(plain string) bool {
+	return auth.CheckPassword(u.Password, plain)
+}
+
+// A user marshalled by accident still only shows its public fields, while
+// requests can send a password
+func (u User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ToUserResponse(&u))
+}
+
+func ToUserResponse(u *User) *UserResponse {
+	if u == nil {
+		return nil
+	}
+
+	return &UserResponse{
+		Id:        u.Id,
+		Name:      u.Name,
+		Username:  u.Username,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+func ToUserResponses(users []*User) []*UserResponse {
+	var responses []*UserResponse
+	for _, u := range users {
+		if response := ToUserResponse(u); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	return responses
+}
+
+func ToPbUser(u *User) *pb.User {
+	if u == nil {
+		return nil
+	}
+
+	return &pb.User{
+		Id:        u.Id,
+		Name:      u.Name,
+		Username:  u.Username,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: u.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// The user service never sends passwords, so a user from it maps straight to
+// its response
+func FromPbUser(p *pb.User) *UserResponse {
+	if p == nil {
+		return nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, p.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to parse created at date: %v", err)
+		return nil
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339, p.UpdatedAt)
+	if err != nil {
+		log.Printf("Failed to parse updated at date: %v", err)
+		return nil
+	}
+
+	return &UserResponse{
+		Id:        p.Id,
+		Name:      p.Name,
+		Username:  p.Username,
+		Email:     p.Email,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+func FromPbUsers(pUsers []*pb.User) []*UserResponse {
+	var users []*UserResponse
+	for _, p := range pUsers {
+		if user := FromPbUser(p); user != nil {
+			users = append(users, user)
+		}
+	}
+	return users
+}
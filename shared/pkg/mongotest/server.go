@@ -0,0 +1,161 @@
+package mongotest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Listens on a local port like a MongoDB server would, handle gets every
+// connection the driver opens
+func Listen(t testing.TB, handle func(conn net.Conn)) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return "mongodb://" + lis.Addr().String() + "/?directConnection=true"
+}
+
+// Wire protocol opcodes Server speaks
+const (
+	opReply = 1
+	opQuery = 2004
+	opMsg   = 2013
+)
+
+// Answers like a standalone MongoDB server, reply gets every command besides
+// the handshake and session cleanup and returns the response document
+func Server(t testing.TB, reply func(command bson.Raw) bson.D) string {
+	respond := func(command bson.Raw) []byte {
+		response := bson.D{{Key: "ok", Value: 1.0}}
+		switch command.Index(0).Key() {
+		case "hello", "isMaster", "ismaster":
+			response = bson.D{
+				{Key: "ismaster", Value: true},
+				{Key: "isWritablePrimary", Value: true},
+				{Key: "helloOk", Value: true},
+				{Key: "minWireVersion", Value: int32(0)},
+				{Key: "maxWireVersion", Value: int32(21)},
+				{Key: "maxBsonObjectSize", Value: int32(16 * 1024 * 1024)},
+				{Key: "maxMessageSizeBytes", Value: int32(48000000)},
+				{Key: "maxWriteBatchSize", Value: int32(100000)},
+				{Key: "ok", Value: 1.0},
+			}
+		case "endSessions":
+		default:
+			response = reply(command)
+		}
+		doc, err := bson.Marshal(response)
+		require.NoError(t, err)
+		return doc
+	}
+
+	return Listen(t, func(conn net.Conn) {
+		defer conn.Close()
+		for {
+			header := make([]byte, 16)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			requestId := binary.LittleEndian.Uint32(header[4:])
+			body := make([]byte, binary.LittleEndian.Uint32(header)-16)
+			if _, err := io.ReadFull(conn, body); err != nil {
+				return
+			}
+
+			var message []byte
+			switch binary.LittleEndian.Uint32(header[12:]) {
+			case opQuery:
+				// Flags, collection name, skip and limit come before the command
+				rest := body[4:]
+				rest = rest[bytes.IndexByte(rest, 0)+9:]
+				doc := respond(bson.Raw(rest[:binary.LittleEndian.Uint32(rest)]))
+				message = binary.LittleEndian.AppendUint32(nil, 0)     // Response flags
+				message = binary.LittleEndian.AppendUint64(message, 0) // Cursor id
+				message = binary.LittleEndian.AppendUint32(message, 0) // Starting from
+				message = binary.LittleEndian.AppendUint32(message, 1) // Documents returned
+				message = append(wireHeader(len(message)+len(doc), requestId, opReply), append(message, doc...)...)
+			case opMsg:
+				command, err := msgCommand(body)
+				require.NoError(t, err)
+				doc := respond(command)
+				message = append(wireHeader(5+len(doc), requestId, opMsg), 0, 0, 0, 0, 0)
+				message = append(message, doc...)
+			default:
+				return
+			}
+			if _, err := conn.Write(message); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// Command of an OP_MSG body: flags, the command in a body section, then any
+// document sequences (inserted documents, for one) which are folded into the
+// command as arrays named by their identifier
+func msgCommand(body []byte) (bson.Raw, error) {
+	rest := body[4:]
+	var command bson.D
+	for len(rest) > 0 {
+		kind := rest[0]
+		rest = rest[1:]
+		size := binary.LittleEndian.Uint32(rest)
+		switch kind {
+		case 0:
+			if err := bson.Unmarshal(rest[:size], &command); err != nil {
+				return nil, err
+			}
+		case 1:
+			section := rest[4:size]
+			end := bytes.IndexByte(section, 0)
+			identifier := string(section[:end])
+			docs := bson.A{}
+			for section = section[end+1:]; len(section) > 0; {
+				docSize := binary.LittleEndian.Uint32(section)
+				docs = append(docs, bson.Raw(section[:docSize]))
+				section = section[docSize:]
+			}
+			command = append(command, bson.E{Key: identifier, Value: docs})
+		}
+		rest = rest[size:]
+	}
+	return bson.Marshal(command)
+}
+
+func wireHeader(bodyLength int, responseTo uint32, opCode uint32) []byte {
+	header := binary.LittleEndian.AppendUint32(nil, uint32(16+bodyLength))
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	header = binary.LittleEndian.AppendUint32(header, responseTo)
+	return binary.LittleEndian.AppendUint32(header, opCode)
+}
+
+// Response whose cursor holds docs in a single batch, as find and aggregate
+// commands on ns answer
+func CursorReply(ns string, docs ...interface{}) bson.D {
+	batch := bson.A{}
+	batch = append(batch, docs...)
+	return bson.D{
+		{Key: "cursor", Value: bson.D{{Key: "id", Value: int64(0)}, {Key: "ns", Value: ns}, {Key: "firstBatch", Value: batch}}},
+		{Key: "ok", Value: 1.0},
+	}
+}
+
+// Response of a command that failed on the server
+func ErrorReply(code int32, message string) bson.D {
+	return bson.D{{Key: "ok", Value: 0.0}, {Key: "errmsg", Value: message}, {Key: "code", Value: code}}
+}
@@ -0,0 +1,555 @@
+package mongotest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Documents kept in memory by collection name. Reply answers commands against
+// them so a Server can run a service's real queries without a mongod: find and
+// $match filters on equality, null and $expr, inserts, update and findAndModify
+// with $set, and aggregations with $lookup (let and a pipeline), $group, $set and $unset
+// using the $eq, $cond, $ifNull, $first and $sum operators. Anything else fails
+// the command, so a new query shape can't silently pass.
+type Store map[string][]bson.M
+
+// Guards every Store, the driver may send commands over several connections
+var storeMu sync.Mutex
+
+// Adds docs to collection the way the driver would store them, so values
+// compare like the ones decoded from commands
+func (s Store) Insert(collection string, docs ...interface{}) error {
+	for _, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		var stored bson.M
+		if err := bson.Unmarshal(raw, &stored); err != nil {
+			return err
+		}
+		storeMu.Lock()
+		s[collection] = append(s[collection], stored)
+		storeMu.Unlock()
+	}
+	return nil
+}
+
+func (s Store) Reply(command bson.Raw) bson.D {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	name := command.Index(0).Key()
+	collection, _ := command.Index(0).Value().StringValueOK()
+	ns := command.Lookup("$db").StringValue() + "." + collection
+
+	var docs []bson.M
+	var err error
+	switch name {
+	case "aggregate":
+		var stages bson.A
+		if err := command.Lookup("pipeline").Unmarshal(&stages); err != nil {
+			return ErrorReply(14, err.Error())
+		}
+		docs, err = s.run(s[collection], stages, bson.M{})
+	case "find":
+		var filter bson.M
+		if value, err := command.LookupErr("filter"); err == nil {
+			if err := value.Unmarshal(&filter); err != nil {
+				return ErrorReply(14, err.Error())
+			}
+		}
+		docs, err = match(s[collection], filter, bson.M{})
+		if limit, ok := command.Lookup("limit").AsInt64OK(); ok && limit > 0 && int64(len(docs)) > limit {
+			docs = docs[:limit]
+		}
+	case "insert":
+		var inserted bson.A
+		if err := command.Lookup("documents").Unmarshal(&inserted); err != nil {
+			return ErrorReply(14, err.Error())
+		}
+		for _, doc := range inserted {
+			stored, _ := asDocument(doc)
+			s[collection] = append(s[collection], stored)
+		}
+		return bson.D{{Key: "n", Value: int32(len(inserted))}, {Key: "ok", Value: 1.0}}
+	case "update":
+		return s.update(collection, command)
+	case "findAndModify":
+		return s.findAndModify(collection, command)
+	default:
+		return ErrorReply(59, "mongotest: unsupported command "+name)
+	}
+	if err != nil {
+		return ErrorReply(40324, err.Error())
+	}
+
+	batch := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		batch[i] = doc
+	}
+	return CursorReply(ns, batch...)
+}
+
+// Applies each $set update to the documents matching its query, only the
+// first one unless the update is multi
+func (s Store) update(collection string, command bson.Raw) bson.D {
+	var updates bson.A
+	if err := command.Lookup("updates").Unmarshal(&updates); err != nil {
+		return ErrorReply(14, err.Error())
+	}
+
+	var n, modified int32
+	for _, value := range updates {
+		statement, _ := asDocument(value)
+		query, _ := asDocument(statement["q"])
+		update, _ := asDocument(statement["u"])
+		fields, ok := asDocument(update["$set"])
+		if !ok || len(update) != 1 {
+			return ErrorReply(9, fmt.Sprintf("mongotest: only $set updates are supported, got %v", update))
+		}
+		multi, _ := statement["multi"].(bool)
+
+		for i, doc := range s[collection] {
+			ok, err := matches(doc, query, bson.M{})
+			if err != nil {
+				return ErrorReply(2, err.Error())
+			}
+			if !ok {
+				continue
+			}
+
+			updated := doc
+			for name, value := range fields {
+				updated = with(updated, name, value)
+			}
+			n++
+			if !reflect.DeepEqual(doc, updated) {
+				modified++
+			}
+			s[collection][i] = updated
+			if !multi {
+				break
+			}
+		}
+	}
+	return bson.D{{Key: "n", Value: n}, {Key: "nModified", Value: modified}, {Key: "ok", Value: 1.0}}
+}
+
+// Applies a $set update to the first document matching the query
+func (s Store) findAndModify(collection string, command bson.Raw) bson.D {
+	var query, update bson.M
+	if err := command.Lookup("query").Unmarshal(&query); err != nil {
+		return ErrorReply(14, err.Error())
+	}
+	if err := command.Lookup("update").Unmarshal(&update); err != nil {
+		return ErrorReply(14, err.Error())
+	}
+	fields, ok := asDocument(update["$set"])
+	if !ok || len(update) != 1 {
+		return ErrorReply(9, fmt.Sprintf("mongotest: only $set updates are supported, got %v", update))
+	}
+
+	for i, doc := range s[collection] {
+		ok, err := matches(doc, query, bson.M{})
+		if err != nil {
+			return ErrorReply(2, err.Error())
+		}
+		if !ok {
+			continue
+		}
+
+		before := doc
+		for name, value := range fields {
+			doc = with(doc, name, value)
+		}
+		s[collection][i] = doc
+
+		value := before
+		if returnNew, _ := command.Lookup("new").BooleanOK(); returnNew {
+			value = doc
+		}
+		return bson.D{
+			{Key: "lastErrorObject", Value: bson.D{{Key: "n", Value: int32(1)}, {Key: "updatedExisting", Value: true}}},
+			{Key: "value", Value: value},
+			{Key: "ok", Value: 1.0},
+		}
+	}
+	return bson.D{
+		{Key: "lastErrorObject", Value: bson.D{{Key: "n", Value: int32(0)}, {Key: "updatedExisting", Value: false}}},
+		{Key: "value", Value: nil},
+		{Key: "ok", Value: 1.0},
+	}
+}
+
+func (s Store) run(docs []bson.M, stages bson.A, vars bson.M) ([]bson.M, error) {
+	for _, value := range stages {
+		stage, ok := asDocument(value)
+		if !ok || len(stage) != 1 {
+			return nil, fmt.Errorf("mongotest: a stage must have exactly one operator, got %v", value)
+		}
+
+		var err error
+		for op, spec := range stage {
+			switch op {
+			case "$match":
+				docs, err = match(docs, spec, vars)
+			case "$lookup":
+				docs, err = s.lookup(docs, spec, vars)
+			case "$group":
+				docs, err = group(docs, spec, vars)
+			case "$set", "$addFields":
+				docs, err = set(docs, spec, vars)
+			case "$unset":
+				docs, err = unset(docs, spec)
+			default:
+				err = fmt.Errorf("mongotest: unsupported stage %s", op)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+func match(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	filter, ok := asDocument(spec)
+	if !ok {
+		return nil, fmt.Errorf("mongotest: $match needs a document, got %v", spec)
+	}
+
+	matched := []bson.M{}
+	for _, doc := range docs {
+		ok, err := matches(doc, filter, vars)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, doc)
+		}
+	}
+	return matched, nil
+}
+
+func matches(doc bson.M, filter bson.M, vars bson.M) (bool, error) {
+	for key, cond := range filter {
+		if key == "$expr" {
+			value, err := eval(doc, cond, vars)
+			if err != nil || !truthy(value) {
+				return false, err
+			}
+			continue
+		}
+		if strings.HasPrefix(key, "$") {
+			return false, fmt.Errorf("mongotest: unsupported query operator %s", key)
+		}
+		if operators, ok := asDocument(cond); ok && hasOperator(operators) {
+			return false, fmt.Errorf("mongotest: unsupported condition on %s: %v", key, cond)
+		}
+		// A null condition also matches documents without the field
+		if !equal(field(doc, key), cond) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s Store) lookup(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	options, ok := asDocument(spec)
+	if !ok {
+		return nil, fmt.Errorf("mongotest: $lookup needs a document, got %v", spec)
+	}
+	from, _ := options["from"].(string)
+	as, _ := options["as"].(string)
+	pipeline, ok := options["pipeline"].(bson.A)
+	if from == "" || as == "" || !ok {
+		return nil, fmt.Errorf("mongotest: $lookup needs from, as and a pipeline, got %v", spec)
+	}
+	let, _ := asDocument(options["let"])
+
+	joined := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		scope := bson.M{}
+		for name, value := range vars {
+			scope[name] = value
+		}
+		for name, expr := range let {
+			value, err := eval(doc, expr, vars)
+			if err != nil {
+				return nil, err
+			}
+			scope[name] = value
+		}
+
+		found, err := s.run(s[from], pipeline, scope)
+		if err != nil {
+			return nil, err
+		}
+		results := bson.A{}
+		for _, result := range found {
+			results = append(results, result)
+		}
+		joined[i] = with(doc, as, results)
+	}
+	return joined, nil
+}
+
+func group(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	options, ok := asDocument(spec)
+	if !ok {
+		return nil, fmt.Errorf("mongotest: $group needs a document, got %v", spec)
+	}
+
+	groups := []bson.M{}
+	for _, doc := range docs {
+		key, err := eval(doc, options["_id"], vars)
+		if err != nil {
+			return nil, err
+		}
+
+		var current bson.M
+		for _, g := range groups {
+			if equal(g["_id"], key) {
+				current = g
+				break
+			}
+		}
+		if current == nil {
+			current = bson.M{"_id": key}
+			groups = append(groups, current)
+		}
+
+		for name, accumulator := range options {
+			if name == "_id" {
+				continue
+			}
+			acc, ok := asDocument(accumulator)
+			sumExpr, isSum := acc["$sum"]
+			if !ok || len(acc) != 1 || !isSum {
+				return nil, fmt.Errorf("mongotest: unsupported accumulator for %s: %v", name, accumulator)
+			}
+			value, err := eval(doc, sumExpr, vars)
+			if err != nil {
+				return nil, err
+			}
+			total, _ := current[name].(int64)
+			if n, ok := number(value); ok {
+				total += int64(n)
+			}
+			current[name] = total
+		}
+	}
+	return groups, nil
+}
+
+func set(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	fields, ok := asDocument(spec)
+	if !ok {
+		return nil, fmt.Errorf("mongotest: $set needs a document, got %v", spec)
+	}
+
+	updated := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		updated[i] = doc
+		for name, expr := range fields {
+			value, err := eval(doc, expr, vars)
+			if err != nil {
+				return nil, err
+			}
+			updated[i] = with(updated[i], name, value)
+		}
+	}
+	return updated, nil
+}
+
+func unset(docs []bson.M, spec interface{}) ([]bson.M, error) {
+	var names []string
+	switch spec := spec.(type) {
+	case string:
+		names = []string{spec}
+	case bson.A:
+		for _, name := range spec {
+			name, ok := name.(string)
+			if !ok {
+				return nil, fmt.Errorf("mongotest: $unset takes field names, got %v", spec)
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, fmt.Errorf("mongotest: $unset takes field names, got %v", spec)
+	}
+
+	updated := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		updated[i] = with(doc, "", nil)
+		for _, name := range names {
+			delete(updated[i], name)
+		}
+	}
+	return updated, nil
+}
+
+// Value of an aggregation expression for doc
+func eval(doc bson.M, expr interface{}, vars bson.M) (interface{}, error) {
+	if path, ok := expr.(string); ok {
+		if name, ok := strings.CutPrefix(path, "$$"); ok {
+			value, found := vars[name]
+			if !found {
+				return nil, fmt.Errorf("mongotest: undefined variable $$%s", name)
+			}
+			return value, nil
+		}
+		if name, ok := strings.CutPrefix(path, "$"); ok {
+			return field(doc, name), nil
+		}
+		return path, nil
+	}
+
+	operator, ok := asDocument(expr)
+	if !ok || !hasOperator(operator) {
+		return expr, nil
+	}
+	if len(operator) != 1 {
+		return nil, fmt.Errorf("mongotest: an expression must have exactly one operator, got %v", expr)
+	}
+
+	for op, arg := range operator {
+		args, _ := arg.(bson.A)
+		values := make([]interface{}, len(args))
+		for i, a := range args {
+			value, err := eval(doc, a, vars)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+
+		switch {
+		case op == "$eq" && len(values) == 2:
+			return equal(values[0], values[1]), nil
+		case op == "$cond" && len(values) == 3:
+			if truthy(values[0]) {
+				return values[1], nil
+			}
+			return values[2], nil
+		case op == "$ifNull" && len(values) == 2:
+			if values[0] != nil {
+				return values[0], nil
+			}
+			return values[1], nil
+		case op == "$first":
+			value, err := eval(doc, arg, vars)
+			if err != nil {
+				return nil, err
+			}
+			if array, ok := value.(bson.A); ok && len(array) > 0 {
+				return array[0], nil
+			}
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mongotest: unsupported expression %s: %v", op, arg)
+	}
+	return nil, nil
+}
+
+// Value at a dotted path, nil when the path is missing. A path through an
+// array collects the value from each element, as in aggregation expressions.
+func field(doc bson.M, path string) interface{} {
+	return walk(doc, strings.Split(path, "."))
+}
+
+func walk(value interface{}, keys []string) interface{} {
+	if len(keys) == 0 {
+		return value
+	}
+	if array, ok := value.(bson.A); ok {
+		values := bson.A{}
+		for _, element := range array {
+			if v := walk(element, keys); v != nil {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	doc, ok := asDocument(value)
+	if !ok {
+		return nil
+	}
+	return walk(doc[keys[0]], keys[1:])
+}
+
+// Shallow copy of doc with name set to value, an empty name only copies
+func with(doc bson.M, name string, value interface{}) bson.M {
+	copied := make(bson.M, len(doc)+1)
+	for k, v := range doc {
+		copied[k] = v
+	}
+	if name != "" {
+		copied[name] = value
+	}
+	return copied
+}
+
+func asDocument(value interface{}) (bson.M, bool) {
+	switch value := value.(type) {
+	case bson.M:
+		return value, true
+	case bson.D:
+		doc := make(bson.M, len(value))
+		for _, e := range value {
+			doc[e.Key] = e.Value
+		}
+		return doc, true
+	}
+	return nil, false
+}
+
+func hasOperator(doc bson.M) bool {
+	for key := range doc {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// Compares numbers by value whatever their BSON type
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func truthy(value interface{}) bool {
+	if n, ok := number(value); ok {
+		return n != 0
+	}
+	switch value := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return value
+	}
+	return true
+}
@@ -2,15 +2,21 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"shared/pkg/tracing"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type BaseRepository[K any] struct {
@@ -22,10 +28,15 @@ func NewRepository[K any](database *mongo.Database, collection_name string) *Bas
 	return &BaseRepository[K]{Database: database, CollectionName: collection_name}
 }
 
-func (r BaseRepository[K]) GetAll(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int) ([]K, error) {
+func (r BaseRepository[K]) GetAll(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, error) {
+	ctx, span := r.startSpan(ctx, "find")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
 
 	findOptions := options.Find()
+	if projection := BuildProjection(fields); projection != nil {
+		findOptions.SetProjection(projection)
+	}
 	if len(sort) > 0 {
 		findOptions.SetSort(sort)
 	}
@@ -36,9 +47,10 @@ func (r BaseRepository[K]) GetAll(ctx context.Context, filter bson.M, sort bson.
 		findOptions.SetSkip(int64(skip))
 	}
 
-	cursor, err := coll.Find(ctx, filter, findOptions)
+	cursor, err := coll.Find(ctx, ExcludeDeleted(ctx, filter), findOptions)
 	if err != nil {
 		log.Printf("Error fetching data: %s", err)
+		tracing.Fail(span, err)
 		return []K{}, err
 	}
 	defer cursor.Close(ctx)
@@ -46,49 +58,233 @@ func (r BaseRepository[K]) GetAll(ctx context.Context, filter bson.M, sort bson.
 	var results []K
 	if err = cursor.All(ctx, &results); err != nil {
 		log.Printf("Error decoding data: %s", err)
+		tracing.Fail(span, err)
 		return []K{}, err
 	}
 
 	return results, err
 }
 
-func (r BaseRepository[K]) Find(ctx context.Context, filter bson.M) (*K, error) {
+// ListWithTotal returns a page of documents along with the number of documents
+// matching filter. The count uses the same filter but ignores skip and limit.
+func (r BaseRepository[K]) ListWithTotal(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, int64, error) {
+	data, err := r.GetAll(ctx, filter, sort, skip, limit, fields...)
+	if err != nil {
+		return data, 0, err
+	}
+
+	total, err := r.Count(ctx, filter)
+	if err != nil {
+		return data, 0, err
+	}
+
+	return data, total, nil
+}
+
+// GetAllCursor returns a page of documents that come after the given cursor
+// along with the cursor for the next page. The cursor is an opaque base64
+// encoded JSON document holding the last document's sort key values and _id.
+// An empty after starts from the first page, and an empty next cursor means
+// there are no more results.
+func (r BaseRepository[K]) GetAllCursor(ctx context.Context, filter bson.M, sort bson.D, after string, limit int, fields ...string) ([]K, string, error) {
+	ctx, span := r.startSpan(ctx, "find")
+	defer span.End()
+	coll := r.Database.Collection(r.CollectionName)
+	sort = withIdTiebreaker(sort)
+	filter = ExcludeDeleted(ctx, filter)
+
+	query := filter
+	if after != "" {
+		values, err := decodeCursor(after)
+		if err != nil {
+			log.Printf("Error decoding cursor: %s", err)
+			return []K{}, "", err
+		}
+
+		cursorFilter, err := buildCursorFilter(sort, values)
+		if err != nil {
+			log.Printf("Error decoding cursor: %s", err)
+			return []K{}, "", err
+		}
+
+		if len(filter) > 0 {
+			query = bson.M{"$and": bson.A{filter, cursorFilter}}
+		} else {
+			query = cursorFilter
+		}
+	}
+
+	findOptions := options.Find().SetSort(sort)
+	if len(fields) > 0 {
+		// Sort keys must be returned to build the next cursor, append them to a
+		// copy so the caller's backing array is left alone
+		projected := append([]string(nil), fields...)
+		for _, e := range sort {
+			projected = append(projected, e.Key)
+		}
+		findOptions.SetProjection(BuildProjection(projected))
+	}
+	if limit > 0 {
+		// Fetch one extra document to know whether there is a next page
+		findOptions.SetLimit(int64(limit + 1))
+	}
+
+	cursor, err := coll.Find(ctx, query, findOptions)
+	if err != nil {
+		log.Printf("Error fetching data: %s", err)
+		tracing.Fail(span, err)
+		return []K{}, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var raws []bson.Raw
+	if err = cursor.All(ctx, &raws); err != nil {
+		log.Printf("Error decoding data: %s", err)
+		tracing.Fail(span, err)
+		return []K{}, "", err
+	}
+
+	hasNext := limit > 0 && len(raws) > limit
+	if hasNext {
+		raws = raws[:limit]
+	}
+
+	results := make([]K, len(raws))
+	for i, raw := range raws {
+		if err = bson.Unmarshal(raw, &results[i]); err != nil {
+			log.Printf("Error decoding data: %s", err)
+			return []K{}, "", err
+		}
+	}
+
+	if !hasNext {
+		return results, "", nil
+	}
+
+	nextCursor, err := encodeCursor(sort, raws[len(raws)-1])
+	if err != nil {
+		log.Printf("Error encoding cursor: %s", err)
+		return []K{}, "", err
+	}
+
+	return results, nextCursor, nil
+}
+
+func (r BaseRepository[K]) Find(ctx context.Context, filter bson.M, fields ...string) (*K, error) {
 	var result K
 
-	idStr, ok := filter["_id"].(string)
-	if ok {
-		objectID, err := primitive.ObjectIDFromHex(idStr)
+	// Work on a copy so the caller's filter can be reused
+	query := copyFilter(filter)
+	switch id := query["_id"].(type) {
+	case string:
+		objectID, err := primitive.ObjectIDFromHex(id)
 		if err != nil {
 			log.Printf("Error converting string to object ID: %s", err)
 			return &result, err
 		}
-		filter["_id"] = objectID
+		query["_id"] = objectID
+	case primitive.ObjectID:
+		// Already typed, use as is
+	}
+
+	opts := options.FindOne()
+	if projection := BuildProjection(fields); projection != nil {
+		opts.SetProjection(projection)
 	}
 
+	ctx, span := r.startSpan(ctx, "findOne")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
-	err := coll.FindOne(ctx, filter).Decode(&result)
+	err := coll.FindOne(ctx, ExcludeDeleted(ctx, query), opts).Decode(&result)
 
 	if err != nil {
 		log.Printf("Error finding data: %s", err)
+		tracing.Fail(span, err)
 	}
 
 	return &result, err
 }
 
+// Fetches the documents with the given hex ids in a single $in query. Ids
+// that aren't valid ObjectIDs can't match anything so they are dropped, with
+// the count logged, and no query is made when none are left.
+func (r BaseRepository[K]) FindByIds(ctx context.Context, ids []string) ([]K, error) {
+	objectIds, skipped := ObjectIdsFromHex(ids)
+	if skipped > 0 {
+		log.Printf("Skipping %d invalid ids", skipped)
+	}
+	if len(objectIds) == 0 {
+		return []K{}, nil
+	}
+
+	ctx, span := r.startSpan(ctx, "find")
+	defer span.End()
+	coll := r.Database.Collection(r.CollectionName)
+
+	cursor, err := coll.Find(ctx, ExcludeDeleted(ctx, bson.M{"_id": bson.M{"$in": objectIds}}))
+	if err != nil {
+		log.Printf("Error fetching data: %s", err)
+		tracing.Fail(span, err)
+		return []K{}, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []K{}
+	if err = cursor.All(ctx, &results); err != nil {
+		log.Printf("Error decoding data: %s", err)
+		tracing.Fail(span, err)
+		return []K{}, err
+	}
+
+	return results, nil
+}
+
+// Converts hex ids to ObjectIDs, dropping duplicates, and returns how many
+// were skipped for not being valid ObjectIDs
+func ObjectIdsFromHex(ids []string) ([]primitive.ObjectID, int) {
+	objectIds := make([]primitive.ObjectID, 0, len(ids))
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	skipped := 0
+
+	for _, id := range ids {
+		objectId, err := primitive.ObjectIDFromHex(strings.TrimSpace(id))
+		if err != nil {
+			skipped++
+			continue
+		}
+		if seen[objectId] {
+			continue
+		}
+		seen[objectId] = true
+		objectIds = append(objectIds, objectId)
+	}
+
+	return objectIds, skipped
+}
+
+// Timestamps left zero are stamped with the current time, see StampTimestamps
 func (r BaseRepository[K]) Insert(ctx context.Context, obj K) (interface{}, error) {
+	ctx, span := r.startSpan(ctx, "insertOne")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
+	StampTimestamps(&obj, time.Now())
 	result, err := coll.InsertOne(ctx, obj)
 
 	if err != nil {
 		log.Printf("Error inserting data: %s", err)
+		tracing.Fail(span, err)
 	}
 
 	return result, err
 }
 
+// updated_at is always stamped here, replacing any value the caller passed so
+// it's never stored as anything but a date
 func (r BaseRepository[K]) UpdateOne(ctx context.Context, obj map[string]interface{}, id string) (K, error) {
+	ctx, span := r.startSpan(ctx, "findOneAndUpdate")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
-	obj["updated_at"] = time.Now()
+	obj[UpdatedAtField] = time.Now()
 
 	// Convert id into Object ID
 	var result K
@@ -108,12 +304,15 @@ func (r BaseRepository[K]) UpdateOne(ctx context.Context, obj map[string]interfa
 
 	if err != nil {
 		log.Printf("Error updating data: %s", err)
+		tracing.Fail(span, err)
 	}
 
 	return result, err
 }
 
 func (r BaseRepository[K]) DeleteOne(ctx context.Context, id string) (K, error) {
+	ctx, span := r.startSpan(ctx, "findOneAndDelete")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
 	var result K
 
@@ -127,21 +326,25 @@ func (r BaseRepository[K]) DeleteOne(ctx context.Context, id string) (K, error)
 	err = coll.FindOneAndDelete(ctx, bson.M{"_id": objectId}).Decode(&result)
 	if err != nil {
 		log.Printf("Error deleting data: %s", err)
+		tracing.Fail(span, err)
 	}
 	return result, err
 }
 
 func (r BaseRepository[K]) DataExists(ctx context.Context, filter bson.M) (bool, error) {
+	ctx, span := r.startSpan(ctx, "findOne")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
 
 	opts := options.FindOne().SetProjection(bson.M{"_id": 1})
 	var result bson.M
-	err := coll.FindOne(ctx, filter, opts).Decode(&result)
+	err := coll.FindOne(ctx, ExcludeDeleted(ctx, filter), opts).Decode(&result)
 
 	if err == mongo.ErrNoDocuments {
 		return false, nil
 	}
 	if err != nil {
+		tracing.Fail(span, err)
 		return false, fmt.Errorf("failed to check data existence: %w", err)
 	}
 
@@ -149,37 +352,93 @@ func (r BaseRepository[K]) DataExists(ctx context.Context, filter bson.M) (bool,
 }
 
 func (r BaseRepository[K]) Count(ctx context.Context, filter bson.M) (int64, error) {
+	ctx, span := r.startSpan(ctx, "countDocuments")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
 
-	count, err := coll.CountDocuments(ctx, filter)
+	count, err := coll.CountDocuments(ctx, ExcludeDeleted(ctx, filter))
 	if err != nil {
 		log.Printf("Error counting document: %v", err)
+		tracing.Fail(span, err)
 		return 0, err
 	}
 
 	return count, err
 }
 
+// Runs pipeline on the collection and decodes every resulting document into
+// results, a pointer to a slice. Like the other reads it skips soft-deleted
+// documents unless ctx was created by WithDeleted, by matching them out ahead
+// of the pipeline, so stages that must come first like $geoNear need
+// WithDeleted. Joined collections are the pipeline's own business.
+func (r BaseRepository[K]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error {
+	ctx, span := r.startSpan(ctx, "aggregate")
+	defer span.End()
+	coll := r.Database.Collection(r.CollectionName)
+
+	if !IncludesDeleted(ctx) {
+		pipeline = append(mongo.Pipeline{{{Key: "$match", Value: ExcludeDeleted(ctx, bson.M{})}}}, pipeline...)
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Printf("Error running aggregation: %s", err)
+		tracing.Fail(span, err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, results); err != nil {
+		log.Printf("Error decoding data: %s", err)
+		tracing.Fail(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// Updates the document matching filter with data, or inserts it when none
+// matches. created_at is only written on insert so updates keep the original.
 func (r BaseRepository[K]) Upsert(ctx context.Context, data K, filter bson.M) (*mongo.UpdateResult, error) {
+	ctx, span := r.startSpan(ctx, "updateOne")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
-	update := r.buildUpdateDocument(data)
-	update["created_at"] = time.Now()
-	update["updated_at"] = time.Now()
 
 	opts := options.UpdateOne().SetUpsert(true)
-	result, err := coll.UpdateOne(
-		ctx,
-		filter,
-		bson.M{"$setOnInsert": update},
-		opts,
-	)
-	options.UpdateOne().SetUpsert(false)
+	result, err := coll.UpdateOne(ctx, filter, BuildUpsertDocument(data, time.Now()), opts)
+	if err != nil {
+		log.Printf("Error upserting data: %s", err)
+		tracing.Fail(span, err)
+	}
 
 	return result, err
 }
 
+// Builds the update an upsert sends: every field of data goes under $set
+// except _id, which Mongo generates on insert, and created_at, which goes
+// under $setOnInsert. Zero values of omitempty fields are left out so an
+// update doesn't clear them.
+func BuildUpsertDocument(data any, now time.Time) bson.M {
+	set := buildUpdateDocument(data)
+	delete(set, "_id")
+	delete(set, CreatedAtField)
+	set[UpdatedAtField] = now
+
+	return bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{CreatedAtField: now},
+	}
+}
+
+// Stamps every document like Insert, in place so the caller sees the times
 func (r BaseRepository[K]) BulkInsert(ctx context.Context, obj []K) (interface{}, error) {
+	ctx, span := r.startSpan(ctx, "insertMany")
+	defer span.End()
 	coll := r.Database.Collection(r.CollectionName)
+	now := time.Now()
+	for i := range obj {
+		StampTimestamps(&obj[i], now)
+	}
 	result, err := coll.InsertMany(ctx, obj)
 
 	// result.InsertedIDs
@@ -187,12 +446,109 @@ func (r BaseRepository[K]) BulkInsert(ctx context.Context, obj []K) (interface{}
 
 	if err != nil {
 		log.Printf("Error inserting data: %s", err)
+		tracing.Fail(span, err)
 	}
 
 	return result, err
 }
 
-func (r BaseRepository[K]) buildUpdateDocument(data K) bson.M {
+// Deletes every document matching the filter in one call and returns how many
+// were removed. An empty filter is refused rather than clearing the collection.
+func (r BaseRepository[K]) BulkDelete(ctx context.Context, filter bson.M) (int64, error) {
+	if len(filter) == 0 {
+		return 0, errors.New("bulk delete requires a filter")
+	}
+
+	ctx, span := r.startSpan(ctx, "deleteMany")
+	defer span.End()
+	coll := r.Database.Collection(r.CollectionName)
+	result, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		log.Printf("Error deleting data: %s", err)
+		tracing.Fail(span, err)
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Sets the given fields on every live document matching the filter in one
+// call and returns how many were modified. updated_at is stamped like in
+// UpdateOne, an empty filter is refused like in BulkDelete.
+func (r BaseRepository[K]) UpdateMany(ctx context.Context, filter bson.M, update map[string]interface{}) (int64, error) {
+	if len(filter) == 0 {
+		return 0, errors.New("update many requires a filter")
+	}
+	if len(update) == 0 {
+		return 0, errors.New("update many requires fields to set")
+	}
+	if _, ok := update["_id"]; ok {
+		return 0, errors.New("update many cannot change _id")
+	}
+
+	set := bson.M{}
+	for key, value := range update {
+		set[key] = value
+	}
+	set[UpdatedAtField] = time.Now()
+
+	ctx, span := r.startSpan(ctx, "updateMany")
+	defer span.End()
+	coll := r.Database.Collection(r.CollectionName)
+	result, err := coll.UpdateMany(ctx, ExcludeDeleted(ctx, filter), bson.M{"$set": set})
+	if err != nil {
+		log.Printf("Error updating data: %s", err)
+		tracing.Fail(span, err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Starts a client span for a Mongo operation on the repository's collection
+func (r BaseRepository[K]) startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, "mongo."+operation, trace.SpanKindClient,
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.collection.name", r.CollectionName),
+		attribute.String("db.operation.name", operation),
+	)
+}
+
+// Bson fields holding the document's creation and last write time
+const (
+	CreatedAtField = "created_at"
+	UpdatedAtField = "updated_at"
+)
+
+// StampTimestamps sets the time.Time fields of the struct obj points to whose
+// bson name is created_at or updated_at to now when they are still zero, so
+// dates are always stored as dates whichever service writes the document.
+// Anything else than a pointer to a struct is left alone.
+func StampTimestamps(obj any, now time.Time) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldName := t.Field(i).Tag.Get("bson")
+		if commaIdx := indexComma(fieldName); commaIdx != -1 {
+			fieldName = fieldName[:commaIdx]
+		}
+		if fieldName != CreatedAtField && fieldName != UpdatedAtField {
+			continue
+		}
+
+		field := v.Field(i)
+		if stamp, ok := field.Interface().(time.Time); ok && stamp.IsZero() && field.CanSet() {
+			field.Set(reflect.ValueOf(now))
+		}
+	}
+}
+
+func buildUpdateDocument(data any) bson.M {
 	update := bson.M{}
 	v := reflect.ValueOf(data)
 	t := reflect.TypeOf(data)
@@ -212,6 +568,9 @@ func (r BaseRepository[K]) buildUpdateDocument(data K) bson.M {
 		// Extract field name before comma
 		fieldName := bsonTag
 		if commaIdx := indexComma(fieldName); commaIdx != -1 {
+			if strings.Contains(fieldName[commaIdx:], "omitempty") && valueField.IsZero() {
+				continue
+			}
 			fieldName = fieldName[:commaIdx]
 		}
 		if fieldName == "" {
@@ -232,3 +591,124 @@ func indexComma(tag string) int {
 	}
 	return -1
 }
+
+// BuildProjection builds an inclusion projection for the given field names.
+// _id is always included so ObjectID conversion keeps working, and unknown
+// field names are left for Mongo to ignore. Returns nil when no fields are given.
+func BuildProjection(fields []string) bson.M {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	projection := bson.M{"_id": 1}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "$") {
+			continue
+		}
+		projection[field] = 1
+	}
+
+	return projection
+}
+
+// withIdTiebreaker appends _id to the sort so that every document has a
+// unique position, which cursor pagination relies on.
+func withIdTiebreaker(sort bson.D) bson.D {
+	for _, e := range sort {
+		if e.Key == "_id" {
+			return sort
+		}
+	}
+
+	result := make(bson.D, 0, len(sort)+1)
+	result = append(result, sort...)
+	return append(result, bson.E{Key: "_id", Value: 1})
+}
+
+// copyFilter returns a shallow copy of filter that can be modified freely.
+func copyFilter(filter bson.M) bson.M {
+	copied := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		copied[k] = v
+	}
+	return copied
+}
+
+func encodeCursor(sort bson.D, last bson.Raw) (string, error) {
+	values := bson.D{}
+	for _, e := range sort {
+		value, err := last.LookupErr(strings.Split(e.Key, ".")...)
+		if err != nil {
+			// Missing sort fields are stored as null, which sorts first in Mongo
+			values = append(values, bson.E{Key: e.Key, Value: nil})
+			continue
+		}
+		values = append(values, bson.E{Key: e.Key, Value: value})
+	}
+
+	data, err := bson.MarshalExtJSON(values, true, false)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (bson.D, error) {
+	data, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var values bson.D
+	if err := bson.UnmarshalExtJSON(data, true, &values); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	return values, nil
+}
+
+// buildCursorFilter builds a keyset filter matching documents positioned after
+// the cursor values for the given sort, e.g. for sort {a: 1, _id: 1}:
+// {$or: [{a: {$gt: va}}, {a: va, _id: {$gt: vid}}]}
+func buildCursorFilter(sort bson.D, values bson.D) (bson.M, error) {
+	if len(values) != len(sort) {
+		return nil, fmt.Errorf("invalid cursor: sort mismatch")
+	}
+
+	conditions := bson.A{}
+	for i, e := range sort {
+		if values[i].Key != e.Key {
+			return nil, fmt.Errorf("invalid cursor: sort mismatch")
+		}
+
+		condition := bson.M{}
+		for j := 0; j < i; j++ {
+			condition[values[j].Key] = values[j].Value
+		}
+
+		operator := "$gt"
+		if sortDirection(e.Value) < 0 {
+			operator = "$lt"
+		}
+		condition[e.Key] = bson.M{operator: values[i].Value}
+		conditions = append(conditions, condition)
+	}
+
+	return bson.M{"$or": conditions}, nil
+}
+
+func sortDirection(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 1
+}
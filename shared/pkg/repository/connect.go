@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"shared/config"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+// Longest wait between two connection attempts
+const maxConnectBackoff = 30 * time.Second
+
+// ClientOptions builds the MongoDB client options every service connects with.
+// The write concern and read preference are set after the URI, so w and
+// readPreference options in MONGODB_URI don't change them.
+func ClientOptions(cfg *config.MongoConfig) *options.ClientOptions {
+	return options.Client().
+		ApplyURI(cfg.URI).
+		SetMaxPoolSize(uint64(cfg.MaxPoolSize)).
+		SetMinPoolSize(uint64(cfg.MinPoolSize)).
+		SetWriteConcern(writeConcern(cfg.WriteConcern)).
+		SetReadPreference(readPreference(cfg.ReadPreference)).
+		SetMaxConnIdleTime(cfg.MaxConnIdleTime).
+		SetConnectTimeout(cfg.ConnectTimeout).
+		SetServerSelectionTimeout(cfg.ServerSelectionTimeout)
+}
+
+// Write concern for "majority" or a member count. Anything else falls back to
+// w:1, which writes are acknowledged with unless configured otherwise, the
+// server default of majority on 5.0+ would make each write wait for the
+// secondaries.
+func writeConcern(value string) *writeconcern.WriteConcern {
+	if value == "majority" {
+		return writeconcern.Majority()
+	}
+	if members, err := strconv.Atoi(value); err == nil && members > 0 {
+		return &writeconcern.WriteConcern{W: members}
+	}
+	return writeconcern.W1()
+}
+
+// Read preference for a mode name, reads go to the primary for anything else
+func readPreference(value string) *readpref.ReadPref {
+	mode, err := readpref.ModeFromString(value)
+	if err != nil {
+		return readpref.Primary()
+	}
+	pref, err := readpref.New(mode)
+	if err != nil {
+		return readpref.Primary()
+	}
+	return pref
+}
+
+// Connect creates a client for cfg and returns it along with the configured
+// database once the primary answers a ping. A service started before MongoDB
+// is ready keeps trying up to cfg.ConnectAttempts times instead of crashing.
+func Connect(cfg *config.MongoConfig) (*mongo.Client, *mongo.Database, error) {
+	client, err := Retry(context.Background(), cfg.ConnectAttempts, cfg.ConnectBackoff, func(ctx context.Context) (*mongo.Client, error) {
+		return dial(ctx, cfg)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	return client, client.Database(cfg.Database), nil
+}
+
+// Creates a client and pings the primary within cfg.PingTimeout. mongo.Connect
+// doesn't reach the server, without the ping an unreachable database would
+// only show up on the first query. The client is disconnected again when the
+// ping fails.
+func dial(ctx context.Context, cfg *config.MongoConfig) (*mongo.Client, error) {
+	client, err := mongo.Connect(ClientOptions(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, cfg.PingTimeout)
+	defer cancel()
+	if err := client.Ping(pingCtx, readpref.Primary()); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("MongoDB primary did not answer ping: %w", err)
+	}
+	return client, nil
+}
+
+// Retry calls connect until it succeeds, attempts runs out or ctx is done.
+// The wait before a retry starts at backoff and doubles after each one, up to
+// 30 seconds. The last error is returned wrapped once every attempt failed.
+func Retry[T any](ctx context.Context, attempts int, backoff time.Duration, connect func(ctx context.Context) (T, error)) (T, error) {
+	attempts = max(attempts, 1)
+	for attempt := 1; ; attempt++ {
+		result, err := connect(ctx)
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= attempts {
+			return result, fmt.Errorf("gave up after %d attempts: %w", attempts, err)
+		}
+
+		log.Printf("Connection attempt %d of %d failed, retrying in %v: %v", attempt, attempts, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return result, fmt.Errorf("gave up after %d attempts: %w", attempt, ctx.Err())
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Creates the given indexes on coll. Creating an index that already exists
+// with the same keys and options is a no-op, so this is safe on every startup.
+func EnsureIndexes(ctx context.Context, coll *mongo.Collection, models []mongo.IndexModel) error {
+	if err := ValidateIndexModels(models); err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		return nil
+	}
+
+	names, err := coll.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return fmt.Errorf("failed to create indexes on %s: %w", coll.Name(), err)
+	}
+
+	log.Printf("Ensured indexes on %s: %s", coll.Name(), strings.Join(names, ", "))
+	return nil
+}
+
+// Checks that every model has ordered keys with a valid direction or type and
+// a unique name, so a typo fails loudly instead of building the wrong index
+func ValidateIndexModels(models []mongo.IndexModel) error {
+	seen := make(map[string]bool)
+
+	for i, model := range models {
+		keys, ok := model.Keys.(bson.D)
+		if !ok || len(keys) == 0 {
+			return fmt.Errorf("index %d: keys must be a non-empty bson.D", i)
+		}
+		for _, key := range keys {
+			switch key.Value {
+			case 1, -1, "text":
+			default:
+				return fmt.Errorf("index %d: invalid value %v for key %s", i, key.Value, key.Key)
+			}
+		}
+
+		name := IndexOptions(model).Name
+		if name == nil || *name == "" {
+			return fmt.Errorf("index %d: name is required", i)
+		}
+		if seen[*name] {
+			return fmt.Errorf("index %d: duplicate name %s", i, *name)
+		}
+		seen[*name] = true
+	}
+
+	return nil
+}
+
+// Resolves the options set on an index model
+func IndexOptions(model mongo.IndexModel) options.IndexOptions {
+	var opts options.IndexOptions
+	if model.Options == nil {
+		return opts
+	}
+
+	for _, set := range model.Options.List() {
+		set(&opts)
+	}
+	return opts
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DeletedAtField is set on documents removed with SoftDelete. Reads skip
+// documents where it is set unless the context was created by WithDeleted.
+const DeletedAtField = "deleted_at"
+
+type includeDeletedKey struct{}
+
+// WithDeleted returns a context whose reads also return soft-deleted documents.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// IncludesDeleted reports whether ctx was created by WithDeleted.
+func IncludesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
+// ExcludeDeleted returns a copy of filter that skips soft-deleted documents.
+// The filter is returned unchanged when ctx includes deleted documents or the
+// caller already filters on deleted_at.
+func ExcludeDeleted(ctx context.Context, filter bson.M) bson.M {
+	if IncludesDeleted(ctx) {
+		return filter
+	}
+	if _, ok := filter[DeletedAtField]; ok {
+		return filter
+	}
+
+	scoped := copyFilter(filter)
+	scoped[DeletedAtField] = nil
+
+	return scoped
+}
+
+func (r BaseRepository[K]) SoftDelete(ctx context.Context, id string) (K, error) {
+	coll := r.Database.Collection(r.CollectionName)
+	var result K
+
+	// Convert id into Object ID
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("Error converting string to object ID: %s", err)
+		return result, err
+	}
+
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = coll.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objectId, DeletedAtField: nil},
+		bson.M{"$set": bson.M{DeletedAtField: now, "updated_at": now}},
+		opts,
+	).Decode(&result)
+
+	if err != nil {
+		log.Printf("Error deleting data: %s", err)
+	}
+
+	return result, err
+}
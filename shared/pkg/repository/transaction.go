@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// WithTransaction runs fn inside a MongoDB transaction, committing when fn
+// returns nil and aborting otherwise. The context passed to fn carries the
+// session, so any repository call made with it (Insert, UpdateOne, ...) joins
+// the transaction. Transactions require a replica set or sharded cluster.
+//
+// Only writes to this database are atomic: calls to other services made inside
+// fn are not rolled back on abort, so they should happen after commit.
+func WithTransaction(ctx context.Context, client *mongo.Client, fn func(sessCtx context.Context) error) error {
+	session, err := client.StartSession()
+	if err != nil {
+		log.Printf("Error starting session: %s", err)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx context.Context) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	if err != nil {
+		log.Printf("Error running transaction: %s", err)
+	}
+
+	return err
+}
+
+func (r BaseRepository[K]) WithTransaction(ctx context.Context, fn func(sessCtx context.Context) error) error {
+	return WithTransaction(ctx, r.Database.Client(), fn)
+}
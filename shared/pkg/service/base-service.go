@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
 	interfaces "shared/pkg/interface"
+	"shared/pkg/model"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type BaseService[K any, V any] struct {
@@ -20,23 +24,42 @@ func NewBaseService[K any, V any](repository interfaces.RepositoryInterface[K])
 	}
 }
 
-func (s *BaseService[K, V]) List(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int) ([]K, error) {
-	return s.Repo.GetAll(ctx, filter, sort, skip, limit)
+func (s *BaseService[K, V]) List(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, error) {
+	return s.Repo.GetAll(ctx, filter, sort, skip, limit, fields...)
+}
+
+// ListWithTotal returns a page of documents along with the number of documents
+// matching filter, regardless of skip and limit.
+func (s *BaseService[K, V]) ListWithTotal(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, int64, error) {
+	return s.Repo.ListWithTotal(ctx, filter, sort, skip, limit, fields...)
+}
+
+func (s *BaseService[K, V]) ListCursor(ctx context.Context, filter bson.M, sort bson.D, after string, limit int, fields ...string) ([]K, string, error) {
+	return s.Repo.GetAllCursor(ctx, filter, sort, after, limit, fields...)
 }
 
 func (s *BaseService[K, V]) FindById(ctx context.Context, id string) (*K, error) {
 	return s.Repo.Find(ctx, bson.M{"_id": id})
 }
 
-func (s *BaseService[K, V]) Find(ctx context.Context, filter bson.M) (*K, error) {
-	return s.Repo.Find(ctx, filter)
+func (s *BaseService[K, V]) Find(ctx context.Context, filter bson.M, fields ...string) (*K, error) {
+	return s.Repo.Find(ctx, filter, fields...)
+}
+
+// FindByIds fetches the documents with the given ids in one query, invalid
+// ids are skipped.
+func (s *BaseService[K, V]) FindByIds(ctx context.Context, ids []string) ([]K, error) {
+	return s.Repo.FindByIds(ctx, ids)
 }
 
 func (s *BaseService[K, V]) Create(ctx context.Context, entity K) error {
 	// Validate the entity
-	err := s.Validator.Validate(entity)
+	details, err := s.Validator.ValidateWithDetails(entity)
 	if err != nil {
 		log.Printf("Error validating data: %v", err)
+		if details != nil {
+			return &ValidationError{Fields: details, Err: err}
+		}
 		return err
 	}
 
@@ -59,6 +82,12 @@ func (s *BaseService[K, V]) Delete(ctx context.Context, id string) (K, error) {
 	return s.Repo.DeleteOne(ctx, id)
 }
 
+// SoftDelete marks the document as deleted without removing it so records
+// referencing it stay intact.
+func (s *BaseService[K, V]) SoftDelete(ctx context.Context, id string) (K, error) {
+	return s.Repo.SoftDelete(ctx, id)
+}
+
 func (s *BaseService[K, V]) Exists(ctx context.Context, filter bson.M) (bool, error) {
 	return s.Repo.DataExists(ctx, filter)
 }
@@ -67,6 +96,12 @@ func (s *BaseService[K, V]) Count(ctx context.Context, filter bson.M) (int64, er
 	return s.Repo.Count(ctx, filter)
 }
 
+// Runs pipeline and decodes the resulting documents into results, for reports
+// the CRUD methods can't express
+func (s *BaseService[K, V]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error {
+	return s.Repo.Aggregate(ctx, pipeline, results)
+}
+
 func (s *BaseService[K, V]) BulkInsert(ctx context.Context, entities []K) error {
 	// Validate the entity
 	for _, entity := range entities {
@@ -80,3 +115,81 @@ func (s *BaseService[K, V]) BulkInsert(ctx context.Context, entities []K) error
 	_, err := s.Repo.BulkInsert(ctx, entities)
 	return err
 }
+
+func (s *BaseService[K, V]) BulkDelete(ctx context.Context, filter bson.M) (int64, error) {
+	return s.Repo.BulkDelete(ctx, filter)
+}
+
+// UpdateMany validates update against the update schema then sets it on every
+// document matching filter, returning how many were modified.
+func (s *BaseService[K, V]) UpdateMany(ctx context.Context, filter bson.M, update map[string]interface{}) (int64, error) {
+	if _, err := s.Validator.ValidateUpdateRequest(update); err != nil {
+		if details := ValidationDetails(err); details != nil {
+			return 0, &ValidationError{Fields: details, Err: err}
+		}
+		return 0, err
+	}
+
+	return s.Repo.UpdateMany(ctx, filter, update)
+}
+
+// ErrStaleETag is returned by UpdateIfMatch when the document was written
+// after the caller read it.
+var ErrStaleETag = errors.New("document changed since the etag was issued")
+
+// The part of ServiceInterface UpdateIfMatch needs
+type conditionalUpdater[K any] interface {
+	UpdateMany(ctx context.Context, filter bson.M, update map[string]interface{}) (int64, error)
+	FindById(ctx context.Context, id string) (*K, error)
+}
+
+// UpdateIfMatch applies update to the document with id only while it still
+// carries etag, checked in the update filter so a concurrent write can't slip
+// in between. A tag that can't be parsed never matches. Missing documents
+// return mongo.ErrNoDocuments like Update.
+func UpdateIfMatch[K any](ctx context.Context, s conditionalUpdater[K], update map[string]interface{}, id string, etag string) (K, error) {
+	var entity K
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entity, err
+	}
+
+	modified := int64(0)
+	if updatedAt, err := model.ParseETag(etag); err == nil {
+		modified, err = s.UpdateMany(ctx, bson.M{"_id": objectId, "updated_at": updatedAt}, update)
+		if err != nil {
+			return entity, err
+		}
+	}
+
+	found, err := s.FindById(ctx, id)
+	if err != nil {
+		return entity, err
+	}
+	if modified == 0 {
+		return entity, ErrStaleETag
+	}
+	return *found, nil
+}
+
+// Upsert validates data then updates the document matching filter with it,
+// inserting it when nothing matches.
+func (s *BaseService[K, V]) Upsert(ctx context.Context, data K, filter bson.M) error {
+	details, err := s.Validator.ValidateWithDetails(data)
+	if err != nil {
+		log.Printf("Error validating data: %v", err)
+		if details != nil {
+			return &ValidationError{Fields: details, Err: err}
+		}
+		return err
+	}
+
+	_, err = s.Repo.Upsert(ctx, data, filter)
+	return err
+}
+
+// WithTransaction runs fn in a database transaction. Service calls made with
+// the context passed to fn are part of the transaction.
+func (s *BaseService[K, V]) WithTransaction(ctx context.Context, fn func(sessCtx context.Context) error) error {
+	return s.Repo.WithTransaction(ctx, fn)
+}
@@ -2,6 +2,11 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -10,9 +15,44 @@ type ValidationService[K any, V any] struct {
 	validator *validator.Validate
 }
 
+// Returned when an entity fails validation, Fields maps each failing field to
+// a message that can be shown to API clients
+type ValidationError struct {
+	Fields map[string]string
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = e.Fields[field]
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
 func NewValidationService[K any, V any]() *ValidationService[K, V] {
+	validate := validator.New()
+	// Report fields by their JSON name, which is what API clients send
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
 	return &ValidationService[K, V]{
-		validator: validator.New(),
+		validator: validate,
 	}
 }
 
@@ -25,6 +65,19 @@ func (v *ValidationService[K, V]) Validate(entity K) error {
 	return nil
 }
 
+// Validates the entity and maps each failing field to a readable message. The
+// map is nil when the entity is valid or the error isn't a validation failure.
+func (v *ValidationService[K, V]) ValidateWithDetails(entity K) (map[string]string, error) {
+	err := v.validator.Struct(entity)
+	return ValidationDetails(err), err
+}
+
+// Validates the entity while skipping the given struct fields, for payloads
+// whose remaining fields are only filled in later, like creation timestamps
+func (v *ValidationService[K, V]) ValidateExcept(entity K, fields ...string) error {
+	return v.validator.StructExcept(entity, fields...)
+}
+
 func (v *ValidationService[K, V]) ValidateUpdateRequest(payload map[string]interface{}) (map[string]interface{}, error) {
 	// Convert payload to JSON then to struct
 	jsonData, err := json.Marshal(payload)
@@ -47,3 +100,60 @@ func (v *ValidationService[K, V]) ValidateUpdateRequest(payload map[string]inter
 
 	return payload, nil
 }
+
+// Maps the field errors of a validator error to readable messages keyed by field
+func ValidationDetails(err error) map[string]string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	details := make(map[string]string, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		details[fieldErr.Field()] = fieldErrorMessage(fieldErr)
+	}
+	return details
+}
+
+func fieldErrorMessage(fieldErr validator.FieldError) string {
+	field, param := fieldErr.Field(), fieldErr.Param()
+
+	// Length rules read differently for text and lists than for numbers
+	var unit string
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " character"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " item"
+	}
+	if unit != "" && param != "1" {
+		unit += "s"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "min":
+		return fmt.Sprintf("%s must be at least %s%s", field, param, unit)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s%s", field, param, unit)
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s%s", field, param, unit)
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, param)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", field, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, param)
+	case "boolean":
+		return fmt.Sprintf("%s must be a boolean", field)
+	default:
+		return fmt.Sprintf("%s failed the '%s' rule", field, fieldErr.Tag())
+	}
+}
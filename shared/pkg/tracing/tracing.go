@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// EndpointEnv names the OTLP/HTTP collector address spans are exported to,
+// e.g. "localhost:4318". Tracing stays a no-op when it is unset.
+const EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+const instrumentationName = "shared/pkg/tracing"
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// Init installs a tracer provider exporting to the endpoint in EndpointEnv and
+// returns the function that flushes and stops it. Without an endpoint the
+// global no-op provider is kept and the returned function does nothing.
+func Init(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	endpoint := os.Getenv(EndpointEnv)
+	if endpoint == "" {
+		log.Printf("%s not set, tracing disabled", EndpointEnv)
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpoint(endpoint),
+		otlptracehttp.WithInsecure(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(serviceName, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	log.Printf("Exporting traces to %s", endpoint)
+	return provider.Shutdown, nil
+}
+
+// NewProvider builds a tracer provider tagging spans with the service name.
+// Tests pass a syncer with an in-memory exporter.
+func NewProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(attribute.String("service.name", serviceName))
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)...)
+}
+
+// StartSpan starts a child of the span in ctx using the global provider
+func StartSpan(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// Fail marks the span as failed with err, nil is ignored
+func Fail(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	Fail(span, err)
+	span.End()
+}
+
+// UnaryServerInterceptor continues the trace sent by the caller, or starts a
+// new one, with a server span around the handler
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+		ctx, span := StartSpan(ctx, info.FullMethod, trace.SpanKindServer,
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", info.FullMethod),
+		)
+		resp, err := handler(ctx, req)
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", status.Code(err).String()))
+		End(span, err)
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor wraps outgoing calls in a client span and sends its
+// context to the service being called through metadata
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := StartSpan(ctx, method, trace.SpanKindClient,
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		)
+
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = metadata.MD{}
+		}
+		otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+
+		err := invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", status.Code(err).String()))
+		End(span, err)
+		return err
+	}
+}
+
+// Lets the propagator read and write gRPC metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
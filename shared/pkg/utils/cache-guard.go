@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// How long commands skip Redis after a connection failure when no pause is configured
+const DefaultCacheRetryAfter = 5 * time.Second
+
+var ErrCacheUnavailable = errors.New("cache unavailable")
+
+// CacheGuard is a Redis hook that lets a service keep working while Redis is
+// down. After a connection failure every command fails fast with
+// ErrCacheUnavailable until the retry pause has passed, instead of waiting on
+// dial timeouts. Callers already treat command errors as cache misses.
+type CacheGuard struct {
+	retryAfter time.Duration
+	downUntil  atomic.Int64
+	failures   atomic.Int64
+}
+
+func NewCacheGuard(retryAfter time.Duration) *CacheGuard {
+	if retryAfter <= 0 {
+		retryAfter = DefaultCacheRetryAfter
+	}
+	return &CacheGuard{retryAfter: retryAfter}
+}
+
+// Attaches a guard to client and returns it so its state can be inspected
+func GuardCache(client *redis.Client, retryAfter time.Duration) *CacheGuard {
+	guard := NewCacheGuard(retryAfter)
+	client.AddHook(guard)
+	return guard
+}
+
+// Reports whether commands are currently sent to Redis
+func (g *CacheGuard) Available() bool {
+	return time.Now().UnixNano() >= g.downUntil.Load()
+}
+
+// Number of connection failures seen since the guard was created
+func (g *CacheGuard) Failures() int64 {
+	return g.failures.Load()
+}
+
+func (g *CacheGuard) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (g *CacheGuard) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !g.Available() {
+			cmd.SetErr(ErrCacheUnavailable)
+			return ErrCacheUnavailable
+		}
+
+		err := next(ctx, cmd)
+		g.observe(err)
+		return err
+	}
+}
+
+func (g *CacheGuard) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !g.Available() {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCacheUnavailable)
+			}
+			return ErrCacheUnavailable
+		}
+
+		err := next(ctx, cmds)
+		g.observe(err)
+		return err
+	}
+}
+
+// Pauses Redis usage when a command failed to reach the server
+func (g *CacheGuard) observe(err error) {
+	var netErr net.Error
+	if err == nil || !errors.As(err, &netErr) {
+		return
+	}
+
+	failures := g.failures.Add(1)
+	g.downUntil.Store(time.Now().Add(g.retryAfter).UnixNano())
+	log.Printf("Redis unavailable (%d failures), serving without cache for %s: %v", failures, g.retryAfter, err)
+}
@@ -2,25 +2,152 @@ package utils
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"log"
+	"shared/pkg/metrics"
+	"shared/pkg/tracing"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func GetCachedData[K any](ctx context.Context, cache *redis.Client, key string) (*K, bool) {
+	ctx, span := startCacheSpan(ctx, "get", key)
+	defer span.End()
+
 	data, err := cache.Get(ctx, key).Result()
 	if err != nil {
 		log.Printf("Error getting cache: %v", err)
 		log.Println("Cache miss")
+		metrics.ObserveCacheLookup(key, false)
 		return nil, false
 	}
 
 	var obj K
 	if err := json.Unmarshal([]byte(data), &obj); err != nil {
 		log.Printf("Error unpacking cache data: %v", err)
+		metrics.ObserveCacheLookup(key, false)
 		return nil, false
 	}
 
+	metrics.ObserveCacheLookup(key, true)
+	span.SetAttributes(attribute.Bool("cache.hit", true))
 	return &obj, true
 }
+
+// Stores value as JSON at key. Failures are logged and returned so callers can
+// decide whether a stale entry needs dropping, nothing is deleted here.
+func SetCachedData[K any](ctx context.Context, cache *redis.Client, key string, value K, ttl time.Duration) error {
+	ctx, span := startCacheSpan(ctx, "set", key)
+	defer span.End()
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error packing JSON for %s: %v", key, err)
+		tracing.Fail(span, err)
+		return err
+	}
+
+	if err := cache.Set(ctx, key, bytes, ttl).Err(); err != nil {
+		log.Printf("Error setting cache for %s: %v", key, err)
+		tracing.Fail(span, err)
+		return err
+	}
+	return nil
+}
+
+// Deletes the given keys in one round trip, keys that don't exist are ignored
+func InvalidateCache(ctx context.Context, cache *redis.Client, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ctx, span := startCacheSpan(ctx, "del", strings.Join(keys, " "))
+	defer span.End()
+
+	if err := cache.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("Error deleting cache: %v", err)
+		tracing.Fail(span, err)
+		return err
+	}
+	return nil
+}
+
+// Adds members to the set at key and refreshes its expiry in one round trip.
+// SAdd has no TTL argument of its own, anything past the key is a member.
+// A ttl of zero leaves the set without expiry.
+func AddToSet(ctx context.Context, cache *redis.Client, key string, ttl time.Duration, members ...interface{}) error {
+	ctx, span := startCacheSpan(ctx, "sadd", key)
+	defer span.End()
+
+	_, err := cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, members...)
+		if ttl > 0 {
+			pipe.Expire(ctx, key, ttl)
+		}
+		return nil
+	})
+	tracing.Fail(span, err)
+	return err
+}
+
+// A cached page of a list query
+type ListPage[K any] struct {
+	Data       []K    `json:"data"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Builds the cache key of a list page from a hash of the query that produced
+// it. The key embeds the current list version of prefix, so InvalidateLists
+// orphans every cached page at once without enumerating keys, the old pages
+// then expire on their own TTL.
+func ListCacheKey(ctx context.Context, cache *redis.Client, prefix string, query interface{}) (string, error) {
+	raw, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(raw)
+
+	versionKey := prefix + ":list_version"
+	ctx, span := startCacheSpan(ctx, "get", versionKey)
+	defer span.End()
+
+	version, err := cache.Get(ctx, versionKey).Int64()
+	if err != nil && err != redis.Nil {
+		tracing.Fail(span, err)
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:list:%d:%s", prefix, version, hex.EncodeToString(hash[:])), nil
+}
+
+// Bumps the list version of prefix so pages cached before a write are no
+// longer read
+func InvalidateLists(ctx context.Context, cache *redis.Client, prefix string) error {
+	versionKey := prefix + ":list_version"
+	ctx, span := startCacheSpan(ctx, "incr", versionKey)
+	defer span.End()
+
+	if err := cache.Incr(ctx, versionKey).Err(); err != nil {
+		log.Printf("Error invalidating %s lists: %v", prefix, err)
+		tracing.Fail(span, err)
+		return err
+	}
+	return nil
+}
+
+// Starts a client span for a Redis command on the given key(s)
+func startCacheSpan(ctx context.Context, operation string, key string) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, "redis."+operation, trace.SpanKindClient,
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation.name", operation),
+		attribute.String("cache.key", key),
+	)
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"shared/pkg/tracing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis list holding background stock updates that ran out of retries
+const StockDLQKey = "stock_dlq"
+
+// Kinds of background operation that can end up in the stock DLQ
+const (
+	OpAdjustBookStock       = "adjust_book_stock"       // Move a collection's book counters
+	OpCreateCollectionBooks = "create_collection_books" // Create the books of a new collection
+	OpDeleteCollectionBooks = "delete_collection_books" // Delete the books of a deleted collection
+)
+
+// A failed background operation with everything needed to run it again
+type StockOperation struct {
+	Op             string    `json:"op"`
+	CollectionId   string    `json:"collection_id"`
+	TotalDelta     int32     `json:"total_delta,omitempty"`
+	AvailableDelta int32     `json:"available_delta,omitempty"`
+	BookCount      int       `json:"book_count,omitempty"`
+	Error          string    `json:"error"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+// Appends value as JSON to the list at key
+func PushDeadLetter[K any](ctx context.Context, cache *redis.Client, key string, value K) error {
+	ctx, span := startCacheSpan(ctx, "rpush", key)
+	defer span.End()
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error packing dead letter for %s: %v", key, err)
+		tracing.Fail(span, err)
+		return err
+	}
+
+	if err := cache.RPush(ctx, key, bytes).Err(); err != nil {
+		log.Printf("Error pushing dead letter to %s: %v", key, err)
+		tracing.Fail(span, err)
+		return err
+	}
+	return nil
+}
+
+// Removes and returns the oldest entry of the list at key, false when the
+// list is empty. Entries that can't be decoded are dropped with a log line.
+func PopDeadLetter[K any](ctx context.Context, cache *redis.Client, key string) (*K, bool, error) {
+	ctx, span := startCacheSpan(ctx, "lpop", key)
+	defer span.End()
+
+	data, err := cache.LPop(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		tracing.Fail(span, err)
+		return nil, false, err
+	}
+
+	var value K
+	if err := json.Unmarshal(data, &value); err != nil {
+		log.Printf("Dropping undecodable dead letter from %s: %v", key, err)
+		return nil, true, nil
+	}
+	return &value, true, nil
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Number of background tasks a service runs at once unless configured otherwise
+const DefaultPoolSize = 16
+
+// ErrPoolClosed is returned by Go once Shutdown has been called
+var ErrPoolClosed = errors.New("worker pool is shut down")
+
+// Pool runs fire-and-forget work on a bounded number of goroutines and keeps
+// track of it, so shutdown can wait for tasks still in flight instead of
+// dropping them with the process
+type Pool struct {
+	slots  chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewPool returns a pool running at most size tasks at once, a size below 1
+// uses DefaultPoolSize
+func NewPool(size int) *Pool {
+	if size < 1 {
+		size = DefaultPoolSize
+	}
+	return &Pool{slots: make(chan struct{}, size)}
+}
+
+// Go queues task without blocking the caller, it starts as soon as a slot is
+// free. Returns ErrPoolClosed without running task once the pool is shutting
+// down.
+func (p *Pool) Go(task func()) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+		task()
+	}()
+	return nil
+}
+
+// Shutdown stops accepting tasks and waits for the queued and running ones to
+// finish, or for ctx to be done, whichever comes first
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -30,6 +30,8 @@ type Book struct {
 	IsBorrowed    *wrapperspb.BoolValue  `protobuf:"bytes,3,opt,name=is_borrowed,json=isBorrowed,proto3" json:"is_borrowed,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     string                 `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt     string                 `protobuf:"bytes,6,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	Etag          string                 `protobuf:"bytes,7,opt,name=etag,proto3" json:"etag,omitempty"` // Changes on every write, sent back as if_match to update only this version
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -99,11 +101,27 @@ func (x *Book) GetUpdatedAt() string {
 	return ""
 }
 
+func (x *Book) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *Book) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
 type BookResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Book          []*Book                `protobuf:"bytes,1,rep,name=book,proto3" json:"book,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,4,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	Total         int64                  `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -159,6 +177,20 @@ func (x *BookResponse) GetSuccess() bool {
 	return false
 }
 
+func (x *BookResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *BookResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
 type BookCountResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
@@ -221,13 +253,17 @@ func (x *BookCountResponse) GetSuccess() bool {
 
 // Get Book messages
 type GetBookRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filter        *structpb.Struct       `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
-	Sort          []*Sort                `protobuf:"bytes,2,rep,name=sort,proto3" json:"sort,omitempty"`
-	Skip          int32                  `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
-	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Filter         *structpb.Struct       `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort           []*Sort                `protobuf:"bytes,2,rep,name=sort,proto3" json:"sort,omitempty"`
+	Skip           int32                  `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit          int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	After          string                 `protobuf:"bytes,5,opt,name=after,proto3" json:"after,omitempty"`
+	UseCursor      bool                   `protobuf:"varint,6,opt,name=use_cursor,json=useCursor,proto3" json:"use_cursor,omitempty"`
+	Fields         []string               `protobuf:"bytes,7,rep,name=fields,proto3" json:"fields,omitempty"`
+	IncludeDeleted bool                   `protobuf:"varint,8,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *GetBookRequest) Reset() {
@@ -288,6 +324,34 @@ func (x *GetBookRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *GetBookRequest) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *GetBookRequest) GetUseCursor() bool {
+	if x != nil {
+		return x.UseCursor
+	}
+	return false
+}
+
+func (x *GetBookRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *GetBookRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
 // Find Book messages
 type FindBookRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -383,6 +447,7 @@ type UpdateBookRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Payload       *structpb.Struct       `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	IfMatch       string                 `protobuf:"bytes,3,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"` // Optional etag, the update fails with FAILED_PRECONDITION when the book changed since
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -431,6 +496,13 @@ func (x *UpdateBookRequest) GetPayload() *structpb.Struct {
 	return nil
 }
 
+func (x *UpdateBookRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
 // Delete Book messages
 type DeleteBookRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -479,6 +551,8 @@ func (x *DeleteBookRequest) GetId() string {
 type GetAvailableBookRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CollectionId  string                 `protobuf:"bytes,1,opt,name=collection_id,json=collectionId,proto3" json:"collection_id,omitempty"`
+	Skip          int32                  `protobuf:"varint,2,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -520,6 +594,89 @@ func (x *GetAvailableBookRequest) GetCollectionId() string {
 	return ""
 }
 
+func (x *GetAvailableBookRequest) GetSkip() int32 {
+	if x != nil {
+		return x.Skip
+	}
+	return 0
+}
+
+func (x *GetAvailableBookRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Lists the books of a collection, total in the response counts every match
+type GetBooksByCollectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectionId  string                 `protobuf:"bytes,1,opt,name=collection_id,json=collectionId,proto3" json:"collection_id,omitempty"`
+	AvailableOnly bool                   `protobuf:"varint,2,opt,name=available_only,json=availableOnly,proto3" json:"available_only,omitempty"` // Only books that aren't borrowed
+	Skip          int32                  `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBooksByCollectionRequest) Reset() {
+	*x = GetBooksByCollectionRequest{}
+	mi := &file_book_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBooksByCollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBooksByCollectionRequest) ProtoMessage() {}
+
+func (x *GetBooksByCollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBooksByCollectionRequest.ProtoReflect.Descriptor instead.
+func (*GetBooksByCollectionRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetBooksByCollectionRequest) GetCollectionId() string {
+	if x != nil {
+		return x.CollectionId
+	}
+	return ""
+}
+
+func (x *GetBooksByCollectionRequest) GetAvailableOnly() bool {
+	if x != nil {
+		return x.AvailableOnly
+	}
+	return false
+}
+
+func (x *GetBooksByCollectionRequest) GetSkip() int32 {
+	if x != nil {
+		return x.Skip
+	}
+	return 0
+}
+
+func (x *GetBooksByCollectionRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
 type CountBookRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CollectionId  string                 `protobuf:"bytes,1,opt,name=collection_id,json=collectionId,proto3" json:"collection_id,omitempty"`
@@ -529,7 +686,7 @@ type CountBookRequest struct {
 
 func (x *CountBookRequest) Reset() {
 	*x = CountBookRequest{}
-	mi := &file_book_proto_msgTypes[9]
+	mi := &file_book_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -541,7 +698,7 @@ func (x *CountBookRequest) String() string {
 func (*CountBookRequest) ProtoMessage() {}
 
 func (x *CountBookRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_book_proto_msgTypes[9]
+	mi := &file_book_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -554,7 +711,7 @@ func (x *CountBookRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CountBookRequest.ProtoReflect.Descriptor instead.
 func (*CountBookRequest) Descriptor() ([]byte, []int) {
-	return file_book_proto_rawDescGZIP(), []int{9}
+	return file_book_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *CountBookRequest) GetCollectionId() string {
@@ -573,7 +730,7 @@ type BulkInsertBookRequest struct {
 
 func (x *BulkInsertBookRequest) Reset() {
 	*x = BulkInsertBookRequest{}
-	mi := &file_book_proto_msgTypes[10]
+	mi := &file_book_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -585,7 +742,7 @@ func (x *BulkInsertBookRequest) String() string {
 func (*BulkInsertBookRequest) ProtoMessage() {}
 
 func (x *BulkInsertBookRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_book_proto_msgTypes[10]
+	mi := &file_book_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -598,7 +755,7 @@ func (x *BulkInsertBookRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulkInsertBookRequest.ProtoReflect.Descriptor instead.
 func (*BulkInsertBookRequest) Descriptor() ([]byte, []int) {
-	return file_book_proto_rawDescGZIP(), []int{10}
+	return file_book_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *BulkInsertBookRequest) GetBooks() []*Book {
@@ -608,12 +765,290 @@ func (x *BulkInsertBookRequest) GetBooks() []*Book {
 	return nil
 }
 
+type BulkDeleteBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectionId  string                 `protobuf:"bytes,1,opt,name=collection_id,json=collectionId,proto3" json:"collection_id,omitempty"`
+	Ids           []string               `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkDeleteBookRequest) Reset() {
+	*x = BulkDeleteBookRequest{}
+	mi := &file_book_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkDeleteBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkDeleteBookRequest) ProtoMessage() {}
+
+func (x *BulkDeleteBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkDeleteBookRequest.ProtoReflect.Descriptor instead.
+func (*BulkDeleteBookRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BulkDeleteBookRequest) GetCollectionId() string {
+	if x != nil {
+		return x.CollectionId
+	}
+	return ""
+}
+
+func (x *BulkDeleteBookRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// Reruns the background stock updates that ran out of retries
+type ReplayStockDLQRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayStockDLQRequest) Reset() {
+	*x = ReplayStockDLQRequest{}
+	mi := &file_book_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayStockDLQRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayStockDLQRequest) ProtoMessage() {}
+
+func (x *ReplayStockDLQRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayStockDLQRequest.ProtoReflect.Descriptor instead.
+func (*ReplayStockDLQRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{13}
+}
+
+type ReplayStockDLQResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replayed      int32                  `protobuf:"varint,1,opt,name=replayed,proto3" json:"replayed,omitempty"`   // Operations that succeeded this time
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`       // Operations that failed again and were queued back
+	Remaining     int64                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"` // Operations left in the queue afterwards
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayStockDLQResponse) Reset() {
+	*x = ReplayStockDLQResponse{}
+	mi := &file_book_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayStockDLQResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayStockDLQResponse) ProtoMessage() {}
+
+func (x *ReplayStockDLQResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayStockDLQResponse.ProtoReflect.Descriptor instead.
+func (*ReplayStockDLQResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReplayStockDLQResponse) GetReplayed() int32 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
+func (x *ReplayStockDLQResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ReplayStockDLQResponse) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *ReplayStockDLQResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ReplayStockDLQResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Sets the same fields on every book matching the filter, for admin clean-ups
+type UpdateBooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *structpb.Struct       `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`   // Only _id, collection_id and is_borrowed may be matched on
+	Payload       *structpb.Struct       `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"` // Fields of a book update
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBooksRequest) Reset() {
+	*x = UpdateBooksRequest{}
+	mi := &file_book_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBooksRequest) ProtoMessage() {}
+
+func (x *UpdateBooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBooksRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBooksRequest) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateBooksRequest) GetFilter() *structpb.Struct {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *UpdateBooksRequest) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type UpdateBooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Modified      int64                  `protobuf:"varint,1,opt,name=modified,proto3" json:"modified,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBooksResponse) Reset() {
+	*x = UpdateBooksResponse{}
+	mi := &file_book_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBooksResponse) ProtoMessage() {}
+
+func (x *UpdateBooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_book_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBooksResponse.ProtoReflect.Descriptor instead.
+func (*UpdateBooksResponse) Descriptor() ([]byte, []int) {
+	return file_book_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UpdateBooksResponse) GetModified() int64 {
+	if x != nil {
+		return x.Modified
+	}
+	return 0
+}
+
+func (x *UpdateBooksResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateBooksResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
 var File_book_proto protoreflect.FileDescriptor
 
 const file_book_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"book.proto\x12\x06shared\x1a\x1egoogle/protobuf/wrappers.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x10collection.proto\"\xb6\x01\n" +
+	"book.proto\x12\x06shared\x1a\x1egoogle/protobuf/wrappers.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x10collection.proto\"\xe9\x01\n" +
 	"\x04Book\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
 	"\rcollection_id\x18\x02 \x01(\tR\fcollectionId\x12;\n" +
@@ -622,35 +1057,71 @@ const file_book_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x04 \x01(\tR\tcreatedAt\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\x05 \x01(\tR\tupdatedAt\"d\n" +
+	"updated_at\x18\x05 \x01(\tR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\x06 \x01(\tR\tdeletedAt\x12\x12\n" +
+	"\x04etag\x18\a \x01(\tR\x04etag\"\x9b\x01\n" +
 	"\fBookResponse\x12 \n" +
 	"\x04book\x18\x01 \x03(\v2\f.shared.BookR\x04book\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
-	"\asuccess\x18\x03 \x01(\bR\asuccess\"]\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x1f\n" +
+	"\vnext_cursor\x18\x04 \x01(\tR\n" +
+	"nextCursor\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\x03R\x05total\"]\n" +
 	"\x11BookCountResponse\x12\x14\n" +
 	"\x05count\x18\x01 \x01(\x03R\x05count\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
-	"\asuccess\x18\x03 \x01(\bR\asuccess\"\x8d\x01\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\"\x83\x02\n" +
 	"\x0eGetBookRequest\x12/\n" +
 	"\x06filter\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06filter\x12 \n" +
 	"\x04sort\x18\x02 \x03(\v2\f.shared.SortR\x04sort\x12\x12\n" +
 	"\x04skip\x18\x03 \x01(\x05R\x04skip\x12\x14\n" +
-	"\x05limit\x18\x04 \x01(\x05R\x05limit\"!\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x14\n" +
+	"\x05after\x18\x05 \x01(\tR\x05after\x12\x1d\n" +
+	"\n" +
+	"use_cursor\x18\x06 \x01(\bR\tuseCursor\x12\x16\n" +
+	"\x06fields\x18\a \x03(\tR\x06fields\x12'\n" +
+	"\x0finclude_deleted\x18\b \x01(\bR\x0eincludeDeleted\"!\n" +
 	"\x0fFindBookRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"2\n" +
 	"\x0eAddBookRequest\x12 \n" +
-	"\x04book\x18\x01 \x01(\v2\f.shared.BookR\x04book\"V\n" +
+	"\x04book\x18\x01 \x01(\v2\f.shared.BookR\x04book\"q\n" +
 	"\x11UpdateBookRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
-	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\"#\n" +
+	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\x12\x19\n" +
+	"\bif_match\x18\x03 \x01(\tR\aifMatch\"#\n" +
 	"\x11DeleteBookRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\">\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"h\n" +
 	"\x17GetAvailableBookRequest\x12#\n" +
-	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\"7\n" +
+	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\x12\x12\n" +
+	"\x04skip\x18\x02 \x01(\x05R\x04skip\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x93\x01\n" +
+	"\x1bGetBooksByCollectionRequest\x12#\n" +
+	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\x12%\n" +
+	"\x0eavailable_only\x18\x02 \x01(\bR\ravailableOnly\x12\x12\n" +
+	"\x04skip\x18\x03 \x01(\x05R\x04skip\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"7\n" +
 	"\x10CountBookRequest\x12#\n" +
 	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\";\n" +
 	"\x15BulkInsertBookRequest\x12\"\n" +
-	"\x05books\x18\x01 \x03(\v2\f.shared.BookR\x05books2\x8c\x04\n" +
+	"\x05books\x18\x01 \x03(\v2\f.shared.BookR\x05books\"N\n" +
+	"\x15BulkDeleteBookRequest\x12#\n" +
+	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\x12\x10\n" +
+	"\x03ids\x18\x02 \x03(\tR\x03ids\"\x17\n" +
+	"\x15ReplayStockDLQRequest\"\x9e\x01\n" +
+	"\x16ReplayStockDLQResponse\x12\x1a\n" +
+	"\breplayed\x18\x01 \x01(\x05R\breplayed\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x03R\tremaining\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x18\n" +
+	"\asuccess\x18\x05 \x01(\bR\asuccess\"x\n" +
+	"\x12UpdateBooksRequest\x12/\n" +
+	"\x06filter\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06filter\x121\n" +
+	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\"e\n" +
+	"\x13UpdateBooksResponse\x12\x1a\n" +
+	"\bmodified\x18\x01 \x01(\x03R\bmodified\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess2\x87\a\n" +
 	"\vBookService\x127\n" +
 	"\aGetBook\x12\x16.shared.GetBookRequest\x1a\x14.shared.BookResponse\x12=\n" +
 	"\fFindBookById\x12\x17.shared.FindBookRequest\x1a\x14.shared.BookResponse\x127\n" +
@@ -659,10 +1130,16 @@ const file_book_proto_rawDesc = "" +
 	"UpdateBook\x12\x19.shared.UpdateBookRequest\x1a\x14.shared.BookResponse\x12=\n" +
 	"\n" +
 	"DeleteBook\x12\x19.shared.DeleteBookRequest\x1a\x14.shared.BookResponse\x12I\n" +
-	"\x10GetAvailableBook\x12\x1f.shared.GetAvailableBookRequest\x1a\x14.shared.BookResponse\x12@\n" +
+	"\x10GetAvailableBook\x12\x1f.shared.GetAvailableBookRequest\x1a\x14.shared.BookResponse\x12J\n" +
+	"\x11GetAvailableBooks\x12\x1f.shared.GetAvailableBookRequest\x1a\x14.shared.BookResponse\x12Q\n" +
+	"\x14GetBooksByCollection\x12#.shared.GetBooksByCollectionRequest\x1a\x14.shared.BookResponse\x12@\n" +
 	"\tCountBook\x12\x18.shared.CountBookRequest\x1a\x19.shared.BookCountResponse\x12A\n" +
 	"\n" +
-	"BulkInsert\x12\x1d.shared.BulkInsertBookRequest\x1a\x14.shared.BookResponseB\n" +
+	"BulkInsert\x12\x1d.shared.BulkInsertBookRequest\x1a\x14.shared.BookResponse\x12A\n" +
+	"\n" +
+	"BulkDelete\x12\x1d.shared.BulkDeleteBookRequest\x1a\x14.shared.BookResponse\x12O\n" +
+	"\x0eReplayStockDLQ\x12\x1d.shared.ReplayStockDLQRequest\x1a\x1e.shared.ReplayStockDLQResponse\x12F\n" +
+	"\vUpdateBooks\x12\x1a.shared.UpdateBooksRequest\x1a\x1b.shared.UpdateBooksResponseB\n" +
 	"Z\b./bufferb\x06proto3"
 
 var (
@@ -677,52 +1154,70 @@ func file_book_proto_rawDescGZIP() []byte {
 	return file_book_proto_rawDescData
 }
 
-var file_book_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_book_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_book_proto_goTypes = []any{
-	(*Book)(nil),                    // 0: shared.Book
-	(*BookResponse)(nil),            // 1: shared.BookResponse
-	(*BookCountResponse)(nil),       // 2: shared.BookCountResponse
-	(*GetBookRequest)(nil),          // 3: shared.GetBookRequest
-	(*FindBookRequest)(nil),         // 4: shared.FindBookRequest
-	(*AddBookRequest)(nil),          // 5: shared.AddBookRequest
-	(*UpdateBookRequest)(nil),       // 6: shared.UpdateBookRequest
-	(*DeleteBookRequest)(nil),       // 7: shared.DeleteBookRequest
-	(*GetAvailableBookRequest)(nil), // 8: shared.GetAvailableBookRequest
-	(*CountBookRequest)(nil),        // 9: shared.CountBookRequest
-	(*BulkInsertBookRequest)(nil),   // 10: shared.BulkInsertBookRequest
-	(*wrapperspb.BoolValue)(nil),    // 11: google.protobuf.BoolValue
-	(*structpb.Struct)(nil),         // 12: google.protobuf.Struct
-	(*Sort)(nil),                    // 13: shared.Sort
+	(*Book)(nil),                        // 0: shared.Book
+	(*BookResponse)(nil),                // 1: shared.BookResponse
+	(*BookCountResponse)(nil),           // 2: shared.BookCountResponse
+	(*GetBookRequest)(nil),              // 3: shared.GetBookRequest
+	(*FindBookRequest)(nil),             // 4: shared.FindBookRequest
+	(*AddBookRequest)(nil),              // 5: shared.AddBookRequest
+	(*UpdateBookRequest)(nil),           // 6: shared.UpdateBookRequest
+	(*DeleteBookRequest)(nil),           // 7: shared.DeleteBookRequest
+	(*GetAvailableBookRequest)(nil),     // 8: shared.GetAvailableBookRequest
+	(*GetBooksByCollectionRequest)(nil), // 9: shared.GetBooksByCollectionRequest
+	(*CountBookRequest)(nil),            // 10: shared.CountBookRequest
+	(*BulkInsertBookRequest)(nil),       // 11: shared.BulkInsertBookRequest
+	(*BulkDeleteBookRequest)(nil),       // 12: shared.BulkDeleteBookRequest
+	(*ReplayStockDLQRequest)(nil),       // 13: shared.ReplayStockDLQRequest
+	(*ReplayStockDLQResponse)(nil),      // 14: shared.ReplayStockDLQResponse
+	(*UpdateBooksRequest)(nil),          // 15: shared.UpdateBooksRequest
+	(*UpdateBooksResponse)(nil),         // 16: shared.UpdateBooksResponse
+	(*wrapperspb.BoolValue)(nil),        // 17: google.protobuf.BoolValue
+	(*structpb.Struct)(nil),             // 18: google.protobuf.Struct
+	(*Sort)(nil),                        // 19: shared.Sort
 }
 var file_book_proto_depIdxs = []int32{
-	11, // 0: shared.Book.is_borrowed:type_name -> google.protobuf.BoolValue
+	17, // 0: shared.Book.is_borrowed:type_name -> google.protobuf.BoolValue
 	0,  // 1: shared.BookResponse.book:type_name -> shared.Book
-	12, // 2: shared.GetBookRequest.filter:type_name -> google.protobuf.Struct
-	13, // 3: shared.GetBookRequest.sort:type_name -> shared.Sort
+	18, // 2: shared.GetBookRequest.filter:type_name -> google.protobuf.Struct
+	19, // 3: shared.GetBookRequest.sort:type_name -> shared.Sort
 	0,  // 4: shared.AddBookRequest.book:type_name -> shared.Book
-	12, // 5: shared.UpdateBookRequest.payload:type_name -> google.protobuf.Struct
+	18, // 5: shared.UpdateBookRequest.payload:type_name -> google.protobuf.Struct
 	0,  // 6: shared.BulkInsertBookRequest.books:type_name -> shared.Book
-	3,  // 7: shared.BookService.GetBook:input_type -> shared.GetBookRequest
-	4,  // 8: shared.BookService.FindBookById:input_type -> shared.FindBookRequest
-	5,  // 9: shared.BookService.AddBook:input_type -> shared.AddBookRequest
-	6,  // 10: shared.BookService.UpdateBook:input_type -> shared.UpdateBookRequest
-	7,  // 11: shared.BookService.DeleteBook:input_type -> shared.DeleteBookRequest
-	8,  // 12: shared.BookService.GetAvailableBook:input_type -> shared.GetAvailableBookRequest
-	9,  // 13: shared.BookService.CountBook:input_type -> shared.CountBookRequest
-	10, // 14: shared.BookService.BulkInsert:input_type -> shared.BulkInsertBookRequest
-	1,  // 15: shared.BookService.GetBook:output_type -> shared.BookResponse
-	1,  // 16: shared.BookService.FindBookById:output_type -> shared.BookResponse
-	1,  // 17: shared.BookService.AddBook:output_type -> shared.BookResponse
-	1,  // 18: shared.BookService.UpdateBook:output_type -> shared.BookResponse
-	1,  // 19: shared.BookService.DeleteBook:output_type -> shared.BookResponse
-	1,  // 20: shared.BookService.GetAvailableBook:output_type -> shared.BookResponse
-	2,  // 21: shared.BookService.CountBook:output_type -> shared.BookCountResponse
-	1,  // 22: shared.BookService.BulkInsert:output_type -> shared.BookResponse
-	15, // [15:23] is the sub-list for method output_type
-	7,  // [7:15] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	18, // 7: shared.UpdateBooksRequest.filter:type_name -> google.protobuf.Struct
+	18, // 8: shared.UpdateBooksRequest.payload:type_name -> google.protobuf.Struct
+	3,  // 9: shared.BookService.GetBook:input_type -> shared.GetBookRequest
+	4,  // 10: shared.BookService.FindBookById:input_type -> shared.FindBookRequest
+	5,  // 11: shared.BookService.AddBook:input_type -> shared.AddBookRequest
+	6,  // 12: shared.BookService.UpdateBook:input_type -> shared.UpdateBookRequest
+	7,  // 13: shared.BookService.DeleteBook:input_type -> shared.DeleteBookRequest
+	8,  // 14: shared.BookService.GetAvailableBook:input_type -> shared.GetAvailableBookRequest
+	8,  // 15: shared.BookService.GetAvailableBooks:input_type -> shared.GetAvailableBookRequest
+	9,  // 16: shared.BookService.GetBooksByCollection:input_type -> shared.GetBooksByCollectionRequest
+	10, // 17: shared.BookService.CountBook:input_type -> shared.CountBookRequest
+	11, // 18: shared.BookService.BulkInsert:input_type -> shared.BulkInsertBookRequest
+	12, // 19: shared.BookService.BulkDelete:input_type -> shared.BulkDeleteBookRequest
+	13, // 20: shared.BookService.ReplayStockDLQ:input_type -> shared.ReplayStockDLQRequest
+	15, // 21: shared.BookService.UpdateBooks:input_type -> shared.UpdateBooksRequest
+	1,  // 22: shared.BookService.GetBook:output_type -> shared.BookResponse
+	1,  // 23: shared.BookService.FindBookById:output_type -> shared.BookResponse
+	1,  // 24: shared.BookService.AddBook:output_type -> shared.BookResponse
+	1,  // 25: shared.BookService.UpdateBook:output_type -> shared.BookResponse
+	1,  // 26: shared.BookService.DeleteBook:output_type -> shared.BookResponse
+	1,  // 27: shared.BookService.GetAvailableBook:output_type -> shared.BookResponse
+	1,  // 28: shared.BookService.GetAvailableBooks:output_type -> shared.BookResponse
+	1,  // 29: shared.BookService.GetBooksByCollection:output_type -> shared.BookResponse
+	2,  // 30: shared.BookService.CountBook:output_type -> shared.BookCountResponse
+	1,  // 31: shared.BookService.BulkInsert:output_type -> shared.BookResponse
+	1,  // 32: shared.BookService.BulkDelete:output_type -> shared.BookResponse
+	14, // 33: shared.BookService.ReplayStockDLQ:output_type -> shared.ReplayStockDLQResponse
+	16, // 34: shared.BookService.UpdateBooks:output_type -> shared.UpdateBooksResponse
+	22, // [22:35] is the sub-list for method output_type
+	9,  // [9:22] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_book_proto_init() }
@@ -737,7 +1232,7 @@ func file_book_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_book_proto_rawDesc), len(file_book_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,14 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	BookService_GetBook_FullMethodName          = "/shared.BookService/GetBook"
-	BookService_FindBookById_FullMethodName     = "/shared.BookService/FindBookById"
-	BookService_AddBook_FullMethodName          = "/shared.BookService/AddBook"
-	BookService_UpdateBook_FullMethodName       = "/shared.BookService/UpdateBook"
-	BookService_DeleteBook_FullMethodName       = "/shared.BookService/DeleteBook"
-	BookService_GetAvailableBook_FullMethodName = "/shared.BookService/GetAvailableBook"
-	BookService_CountBook_FullMethodName        = "/shared.BookService/CountBook"
-	BookService_BulkInsert_FullMethodName       = "/shared.BookService/BulkInsert"
+	BookService_GetBook_FullMethodName              = "/shared.BookService/GetBook"
+	BookService_FindBookById_FullMethodName         = "/shared.BookService/FindBookById"
+	BookService_AddBook_FullMethodName              = "/shared.BookService/AddBook"
+	BookService_UpdateBook_FullMethodName           = "/shared.BookService/UpdateBook"
+	BookService_DeleteBook_FullMethodName           = "/shared.BookService/DeleteBook"
+	BookService_GetAvailableBook_FullMethodName     = "/shared.BookService/GetAvailableBook"
+	BookService_GetAvailableBooks_FullMethodName    = "/shared.BookService/GetAvailableBooks"
+	BookService_GetBooksByCollection_FullMethodName = "/shared.BookService/GetBooksByCollection"
+	BookService_CountBook_FullMethodName            = "/shared.BookService/CountBook"
+	BookService_BulkInsert_FullMethodName           = "/shared.BookService/BulkInsert"
+	BookService_BulkDelete_FullMethodName           = "/shared.BookService/BulkDelete"
+	BookService_ReplayStockDLQ_FullMethodName       = "/shared.BookService/ReplayStockDLQ"
+	BookService_UpdateBooks_FullMethodName          = "/shared.BookService/UpdateBooks"
 )
 
 // BookServiceClient is the client API for BookService service.
@@ -39,8 +44,13 @@ type BookServiceClient interface {
 	UpdateBook(ctx context.Context, in *UpdateBookRequest, opts ...grpc.CallOption) (*BookResponse, error)
 	DeleteBook(ctx context.Context, in *DeleteBookRequest, opts ...grpc.CallOption) (*BookResponse, error)
 	GetAvailableBook(ctx context.Context, in *GetAvailableBookRequest, opts ...grpc.CallOption) (*BookResponse, error)
+	GetAvailableBooks(ctx context.Context, in *GetAvailableBookRequest, opts ...grpc.CallOption) (*BookResponse, error)
+	GetBooksByCollection(ctx context.Context, in *GetBooksByCollectionRequest, opts ...grpc.CallOption) (*BookResponse, error)
 	CountBook(ctx context.Context, in *CountBookRequest, opts ...grpc.CallOption) (*BookCountResponse, error)
 	BulkInsert(ctx context.Context, in *BulkInsertBookRequest, opts ...grpc.CallOption) (*BookResponse, error)
+	BulkDelete(ctx context.Context, in *BulkDeleteBookRequest, opts ...grpc.CallOption) (*BookResponse, error)
+	ReplayStockDLQ(ctx context.Context, in *ReplayStockDLQRequest, opts ...grpc.CallOption) (*ReplayStockDLQResponse, error)
+	UpdateBooks(ctx context.Context, in *UpdateBooksRequest, opts ...grpc.CallOption) (*UpdateBooksResponse, error)
 }
 
 type bookServiceClient struct {
@@ -111,6 +121,26 @@ func (c *bookServiceClient) GetAvailableBook(ctx context.Context, in *GetAvailab
 	return out, nil
 }
 
+func (c *bookServiceClient) GetAvailableBooks(ctx context.Context, in *GetAvailableBookRequest, opts ...grpc.CallOption) (*BookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookResponse)
+	err := c.cc.Invoke(ctx, BookService_GetAvailableBooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) GetBooksByCollection(ctx context.Context, in *GetBooksByCollectionRequest, opts ...grpc.CallOption) (*BookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookResponse)
+	err := c.cc.Invoke(ctx, BookService_GetBooksByCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *bookServiceClient) CountBook(ctx context.Context, in *CountBookRequest, opts ...grpc.CallOption) (*BookCountResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BookCountResponse)
@@ -131,6 +161,36 @@ func (c *bookServiceClient) BulkInsert(ctx context.Context, in *BulkInsertBookRe
 	return out, nil
 }
 
+func (c *bookServiceClient) BulkDelete(ctx context.Context, in *BulkDeleteBookRequest, opts ...grpc.CallOption) (*BookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookResponse)
+	err := c.cc.Invoke(ctx, BookService_BulkDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) ReplayStockDLQ(ctx context.Context, in *ReplayStockDLQRequest, opts ...grpc.CallOption) (*ReplayStockDLQResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayStockDLQResponse)
+	err := c.cc.Invoke(ctx, BookService_ReplayStockDLQ_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookServiceClient) UpdateBooks(ctx context.Context, in *UpdateBooksRequest, opts ...grpc.CallOption) (*UpdateBooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateBooksResponse)
+	err := c.cc.Invoke(ctx, BookService_UpdateBooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BookServiceServer is the server API for BookService service.
 // All implementations must embed UnimplementedBookServiceServer
 // for forward compatibility.
@@ -141,8 +201,13 @@ type BookServiceServer interface {
 	UpdateBook(context.Context, *UpdateBookRequest) (*BookResponse, error)
 	DeleteBook(context.Context, *DeleteBookRequest) (*BookResponse, error)
 	GetAvailableBook(context.Context, *GetAvailableBookRequest) (*BookResponse, error)
+	GetAvailableBooks(context.Context, *GetAvailableBookRequest) (*BookResponse, error)
+	GetBooksByCollection(context.Context, *GetBooksByCollectionRequest) (*BookResponse, error)
 	CountBook(context.Context, *CountBookRequest) (*BookCountResponse, error)
 	BulkInsert(context.Context, *BulkInsertBookRequest) (*BookResponse, error)
+	BulkDelete(context.Context, *BulkDeleteBookRequest) (*BookResponse, error)
+	ReplayStockDLQ(context.Context, *ReplayStockDLQRequest) (*ReplayStockDLQResponse, error)
+	UpdateBooks(context.Context, *UpdateBooksRequest) (*UpdateBooksResponse, error)
 	mustEmbedUnimplementedBookServiceServer()
 }
 
@@ -171,12 +236,27 @@ func (UnimplementedBookServiceServer) DeleteBook(context.Context, *DeleteBookReq
 func (UnimplementedBookServiceServer) GetAvailableBook(context.Context, *GetAvailableBookRequest) (*BookResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableBook not implemented")
 }
+func (UnimplementedBookServiceServer) GetAvailableBooks(context.Context, *GetAvailableBookRequest) (*BookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableBooks not implemented")
+}
+func (UnimplementedBookServiceServer) GetBooksByCollection(context.Context, *GetBooksByCollectionRequest) (*BookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBooksByCollection not implemented")
+}
 func (UnimplementedBookServiceServer) CountBook(context.Context, *CountBookRequest) (*BookCountResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CountBook not implemented")
 }
 func (UnimplementedBookServiceServer) BulkInsert(context.Context, *BulkInsertBookRequest) (*BookResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BulkInsert not implemented")
 }
+func (UnimplementedBookServiceServer) BulkDelete(context.Context, *BulkDeleteBookRequest) (*BookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkDelete not implemented")
+}
+func (UnimplementedBookServiceServer) ReplayStockDLQ(context.Context, *ReplayStockDLQRequest) (*ReplayStockDLQResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayStockDLQ not implemented")
+}
+func (UnimplementedBookServiceServer) UpdateBooks(context.Context, *UpdateBooksRequest) (*UpdateBooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBooks not implemented")
+}
 func (UnimplementedBookServiceServer) mustEmbedUnimplementedBookServiceServer() {}
 func (UnimplementedBookServiceServer) testEmbeddedByValue()                     {}
 
@@ -306,6 +386,42 @@ func _BookService_GetAvailableBook_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BookService_GetAvailableBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAvailableBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).GetAvailableBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_GetAvailableBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).GetAvailableBooks(ctx, req.(*GetAvailableBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_GetBooksByCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBooksByCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).GetBooksByCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_GetBooksByCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).GetBooksByCollection(ctx, req.(*GetBooksByCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BookService_CountBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CountBookRequest)
 	if err := dec(in); err != nil {
@@ -342,6 +458,60 @@ func _BookService_BulkInsert_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BookService_BulkDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkDeleteBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).BulkDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_BulkDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).BulkDelete(ctx, req.(*BulkDeleteBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_ReplayStockDLQ_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayStockDLQRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).ReplayStockDLQ(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_ReplayStockDLQ_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).ReplayStockDLQ(ctx, req.(*ReplayStockDLQRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookService_UpdateBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookServiceServer).UpdateBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookService_UpdateBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookServiceServer).UpdateBooks(ctx, req.(*UpdateBooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BookService_ServiceDesc is the grpc.ServiceDesc for BookService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -373,6 +543,14 @@ var BookService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAvailableBook",
 			Handler:    _BookService_GetAvailableBook_Handler,
 		},
+		{
+			MethodName: "GetAvailableBooks",
+			Handler:    _BookService_GetAvailableBooks_Handler,
+		},
+		{
+			MethodName: "GetBooksByCollection",
+			Handler:    _BookService_GetBooksByCollection_Handler,
+		},
 		{
 			MethodName: "CountBook",
 			Handler:    _BookService_CountBook_Handler,
@@ -381,6 +559,18 @@ var BookService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BulkInsert",
 			Handler:    _BookService_BulkInsert_Handler,
 		},
+		{
+			MethodName: "BulkDelete",
+			Handler:    _BookService_BulkDelete_Handler,
+		},
+		{
+			MethodName: "ReplayStockDLQ",
+			Handler:    _BookService_ReplayStockDLQ_Handler,
+		},
+		{
+			MethodName: "UpdateBooks",
+			Handler:    _BookService_UpdateBooks_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "book.proto",
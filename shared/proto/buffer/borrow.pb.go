@@ -9,6 +9,7 @@ package buffer
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -32,6 +33,10 @@ type Borrow struct {
 	ReturnDate    string                 `protobuf:"bytes,7,opt,name=return_date,json=returnDate,proto3" json:"return_date,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     string                 `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	RenewalCount  int32                  `protobuf:"varint,10,opt,name=renewal_count,json=renewalCount,proto3" json:"renewal_count,omitempty"`
+	Status        string                 `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"`                           // active, returned or overdue when the borrow was read, never stored
+	ReturnedAt    string                 `protobuf:"bytes,12,opt,name=returned_at,json=returnedAt,proto3" json:"returned_at,omitempty"` // RFC3339 with nanoseconds
+	ReturnedBy    string                 `protobuf:"bytes,13,opt,name=returned_by,json=returnedBy,proto3" json:"returned_by,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -129,17 +134,115 @@ func (x *Borrow) GetUpdatedAt() string {
 	return ""
 }
 
-type BorrowRequest struct {
+func (x *Borrow) GetRenewalCount() int32 {
+	if x != nil {
+		return x.RenewalCount
+	}
+	return 0
+}
+
+func (x *Borrow) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Borrow) GetReturnedAt() string {
+	if x != nil {
+		return x.ReturnedAt
+	}
+	return ""
+}
+
+func (x *Borrow) GetReturnedBy() string {
+	if x != nil {
+		return x.ReturnedBy
+	}
+	return ""
+}
+
+type GetBorrowsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CollectionId  string                 `protobuf:"bytes,1,opt,name=collection_id,json=collectionId,proto3" json:"collection_id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Filter        *structpb.Struct       `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort          []*Sort                `protobuf:"bytes,2,rep,name=sort,proto3" json:"sort,omitempty"`
+	Skip          int32                  `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *GetBorrowsRequest) Reset() {
+	*x = GetBorrowsRequest{}
+	mi := &file_borrow_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBorrowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBorrowsRequest) ProtoMessage() {}
+
+func (x *GetBorrowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBorrowsRequest.ProtoReflect.Descriptor instead.
+func (*GetBorrowsRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBorrowsRequest) GetFilter() *structpb.Struct {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *GetBorrowsRequest) GetSort() []*Sort {
+	if x != nil {
+		return x.Sort
+	}
+	return nil
+}
+
+func (x *GetBorrowsRequest) GetSkip() int32 {
+	if x != nil {
+		return x.Skip
+	}
+	return 0
+}
+
+func (x *GetBorrowsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type BorrowRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CollectionId   string                 `protobuf:"bytes,1,opt,name=collection_id,json=collectionId,proto3" json:"collection_id,omitempty"`
+	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	LoanDays       int32                  `protobuf:"varint,3,opt,name=loan_days,json=loanDays,proto3" json:"loan_days,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
 func (x *BorrowRequest) Reset() {
 	*x = BorrowRequest{}
-	mi := &file_borrow_proto_msgTypes[1]
+	mi := &file_borrow_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -151,7 +254,7 @@ func (x *BorrowRequest) String() string {
 func (*BorrowRequest) ProtoMessage() {}
 
 func (x *BorrowRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_borrow_proto_msgTypes[1]
+	mi := &file_borrow_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -164,7 +267,7 @@ func (x *BorrowRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BorrowRequest.ProtoReflect.Descriptor instead.
 func (*BorrowRequest) Descriptor() ([]byte, []int) {
-	return file_borrow_proto_rawDescGZIP(), []int{1}
+	return file_borrow_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *BorrowRequest) GetCollectionId() string {
@@ -181,16 +284,31 @@ func (x *BorrowRequest) GetUserId() string {
 	return ""
 }
 
+func (x *BorrowRequest) GetLoanDays() int32 {
+	if x != nil {
+		return x.LoanDays
+	}
+	return 0
+}
+
+func (x *BorrowRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type ReturnRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BorrowId      string                 `protobuf:"bytes,1,opt,name=borrow_id,json=borrowId,proto3" json:"borrow_id,omitempty"`
+	ReturnedBy    string                 `protobuf:"bytes,2,opt,name=returned_by,json=returnedBy,proto3" json:"returned_by,omitempty"` // Optional, who handed the book back
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ReturnRequest) Reset() {
 	*x = ReturnRequest{}
-	mi := &file_borrow_proto_msgTypes[2]
+	mi := &file_borrow_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -202,7 +320,7 @@ func (x *ReturnRequest) String() string {
 func (*ReturnRequest) ProtoMessage() {}
 
 func (x *ReturnRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_borrow_proto_msgTypes[2]
+	mi := &file_borrow_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -215,7 +333,7 @@ func (x *ReturnRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReturnRequest.ProtoReflect.Descriptor instead.
 func (*ReturnRequest) Descriptor() ([]byte, []int) {
-	return file_borrow_proto_rawDescGZIP(), []int{2}
+	return file_borrow_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ReturnRequest) GetBorrowId() string {
@@ -225,19 +343,162 @@ func (x *ReturnRequest) GetBorrowId() string {
 	return ""
 }
 
+func (x *ReturnRequest) GetReturnedBy() string {
+	if x != nil {
+		return x.ReturnedBy
+	}
+	return ""
+}
+
+type DeleteBorrowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBorrowRequest) Reset() {
+	*x = DeleteBorrowRequest{}
+	mi := &file_borrow_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBorrowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBorrowRequest) ProtoMessage() {}
+
+func (x *DeleteBorrowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBorrowRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBorrowRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteBorrowRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type FindBorrowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindBorrowRequest) Reset() {
+	*x = FindBorrowRequest{}
+	mi := &file_borrow_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindBorrowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindBorrowRequest) ProtoMessage() {}
+
+func (x *FindBorrowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindBorrowRequest.ProtoReflect.Descriptor instead.
+func (*FindBorrowRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FindBorrowRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RenewRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BorrowId      string                 `protobuf:"bytes,1,opt,name=borrow_id,json=borrowId,proto3" json:"borrow_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewRequest) Reset() {
+	*x = RenewRequest{}
+	mi := &file_borrow_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewRequest) ProtoMessage() {}
+
+func (x *RenewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewRequest.ProtoReflect.Descriptor instead.
+func (*RenewRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RenewRequest) GetBorrowId() string {
+	if x != nil {
+		return x.BorrowId
+	}
+	return ""
+}
+
 type BorrowServiceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	BookId        string                 `protobuf:"bytes,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
 	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
 	Success       bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	Borrow        *Borrow                `protobuf:"bytes,5,opt,name=borrow,proto3" json:"borrow,omitempty"`
+	IsOverdue     bool                   `protobuf:"varint,6,opt,name=is_overdue,json=isOverdue,proto3" json:"is_overdue,omitempty"`
+	DaysOverdue   int32                  `protobuf:"varint,7,opt,name=days_overdue,json=daysOverdue,proto3" json:"days_overdue,omitempty"` // Set on return
+	Fine          int64                  `protobuf:"varint,8,opt,name=fine,proto3" json:"fine,omitempty"`                                  // Set on return, in the smallest currency unit
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BorrowServiceResponse) Reset() {
 	*x = BorrowServiceResponse{}
-	mi := &file_borrow_proto_msgTypes[3]
+	mi := &file_borrow_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -249,7 +510,7 @@ func (x *BorrowServiceResponse) String() string {
 func (*BorrowServiceResponse) ProtoMessage() {}
 
 func (x *BorrowServiceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_borrow_proto_msgTypes[3]
+	mi := &file_borrow_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -262,7 +523,7 @@ func (x *BorrowServiceResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BorrowServiceResponse.ProtoReflect.Descriptor instead.
 func (*BorrowServiceResponse) Descriptor() ([]byte, []int) {
-	return file_borrow_proto_rawDescGZIP(), []int{3}
+	return file_borrow_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *BorrowServiceResponse) GetId() string {
@@ -293,40 +554,620 @@ func (x *BorrowServiceResponse) GetSuccess() bool {
 	return false
 }
 
-var File_borrow_proto protoreflect.FileDescriptor
+func (x *BorrowServiceResponse) GetBorrow() *Borrow {
+	if x != nil {
+		return x.Borrow
+	}
+	return nil
+}
 
-const file_borrow_proto_rawDesc = "" +
-	"\n" +
-	"\fborrow.proto\x12\x06shared\"\x8a\x02\n" +
-	"\x06Borrow\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
-	"\abook_id\x18\x02 \x01(\tR\x06bookId\x12\x17\n" +
-	"\auser_id\x18\x03 \x01(\tR\x06userId\x12#\n" +
-	"\rcollection_id\x18\x04 \x01(\tR\fcollectionId\x12\x1f\n" +
-	"\vborrow_date\x18\x05 \x01(\tR\n" +
-	"borrowDate\x12\x19\n" +
-	"\bdue_date\x18\x06 \x01(\tR\adueDate\x12\x1f\n" +
-	"\vreturn_date\x18\a \x01(\tR\n" +
-	"returnDate\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\b \x01(\tR\tcreatedAt\x12\x1d\n" +
-	"\n" +
-	"updated_at\x18\t \x01(\tR\tupdatedAt\"M\n" +
-	"\rBorrowRequest\x12#\n" +
-	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\",\n" +
-	"\rReturnRequest\x12\x1b\n" +
-	"\tborrow_id\x18\x01 \x01(\tR\bborrowId\"t\n" +
-	"\x15BorrowServiceResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
-	"\abook_id\x18\x02 \x01(\tR\x06bookId\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\x12\x18\n" +
-	"\asuccess\x18\x04 \x01(\bR\asuccess2\x97\x01\n" +
-	"\rBorrowService\x12B\n" +
-	"\n" +
-	"BorrowBook\x12\x15.shared.BorrowRequest\x1a\x1d.shared.BorrowServiceResponse\x12B\n" +
+func (x *BorrowServiceResponse) GetIsOverdue() bool {
+	if x != nil {
+		return x.IsOverdue
+	}
+	return false
+}
+
+func (x *BorrowServiceResponse) GetDaysOverdue() int32 {
+	if x != nil {
+		return x.DaysOverdue
+	}
+	return 0
+}
+
+func (x *BorrowServiceResponse) GetFine() int64 {
+	if x != nil {
+		return x.Fine
+	}
+	return 0
+}
+
+type BorrowListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Borrow        []*Borrow              `protobuf:"bytes,1,rep,name=borrow,proto3" json:"borrow,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BorrowListResponse) Reset() {
+	*x = BorrowListResponse{}
+	mi := &file_borrow_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BorrowListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BorrowListResponse) ProtoMessage() {}
+
+func (x *BorrowListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BorrowListResponse.ProtoReflect.Descriptor instead.
+func (*BorrowListResponse) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BorrowListResponse) GetBorrow() []*Borrow {
+	if x != nil {
+		return x.Borrow
+	}
+	return nil
+}
+
+func (x *BorrowListResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BorrowListResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type OverdueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Skip          int32                  `protobuf:"varint,1,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OverdueRequest) Reset() {
+	*x = OverdueRequest{}
+	mi := &file_borrow_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OverdueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OverdueRequest) ProtoMessage() {}
+
+func (x *OverdueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OverdueRequest.ProtoReflect.Descriptor instead.
+func (*OverdueRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OverdueRequest) GetSkip() int32 {
+	if x != nil {
+		return x.Skip
+	}
+	return 0
+}
+
+func (x *OverdueRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type FineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BorrowId      string                 `protobuf:"bytes,1,opt,name=borrow_id,json=borrowId,proto3" json:"borrow_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FineRequest) Reset() {
+	*x = FineRequest{}
+	mi := &file_borrow_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FineRequest) ProtoMessage() {}
+
+func (x *FineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FineRequest.ProtoReflect.Descriptor instead.
+func (*FineRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FineRequest) GetBorrowId() string {
+	if x != nil {
+		return x.BorrowId
+	}
+	return ""
+}
+
+type FineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BorrowId      string                 `protobuf:"bytes,1,opt,name=borrow_id,json=borrowId,proto3" json:"borrow_id,omitempty"`
+	DaysOverdue   int32                  `protobuf:"varint,2,opt,name=days_overdue,json=daysOverdue,proto3" json:"days_overdue,omitempty"` // Started days past the due date, up to the return or now
+	Fine          int64                  `protobuf:"varint,3,opt,name=fine,proto3" json:"fine,omitempty"`                                  // days_overdue times fine_per_day
+	FinePerDay    int64                  `protobuf:"varint,4,opt,name=fine_per_day,json=finePerDay,proto3" json:"fine_per_day,omitempty"`
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Success       bool                   `protobuf:"varint,6,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FineResponse) Reset() {
+	*x = FineResponse{}
+	mi := &file_borrow_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FineResponse) ProtoMessage() {}
+
+func (x *FineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FineResponse.ProtoReflect.Descriptor instead.
+func (*FineResponse) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FineResponse) GetBorrowId() string {
+	if x != nil {
+		return x.BorrowId
+	}
+	return ""
+}
+
+func (x *FineResponse) GetDaysOverdue() int32 {
+	if x != nil {
+		return x.DaysOverdue
+	}
+	return 0
+}
+
+func (x *FineResponse) GetFine() int64 {
+	if x != nil {
+		return x.Fine
+	}
+	return 0
+}
+
+func (x *FineResponse) GetFinePerDay() int64 {
+	if x != nil {
+		return x.FinePerDay
+	}
+	return 0
+}
+
+func (x *FineResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *FineResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type DueSoonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Days          int32                  `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"` // Window from now, the service default applies when unset
+	Skip          int32                  `protobuf:"varint,2,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DueSoonRequest) Reset() {
+	*x = DueSoonRequest{}
+	mi := &file_borrow_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DueSoonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DueSoonRequest) ProtoMessage() {}
+
+func (x *DueSoonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DueSoonRequest.ProtoReflect.Descriptor instead.
+func (*DueSoonRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DueSoonRequest) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *DueSoonRequest) GetSkip() int32 {
+	if x != nil {
+		return x.Skip
+	}
+	return 0
+}
+
+func (x *DueSoonRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type UserBorrowsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ActiveOnly    bool                   `protobuf:"varint,2,opt,name=active_only,json=activeOnly,proto3" json:"active_only,omitempty"`
+	Skip          int32                  `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserBorrowsRequest) Reset() {
+	*x = UserBorrowsRequest{}
+	mi := &file_borrow_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserBorrowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserBorrowsRequest) ProtoMessage() {}
+
+func (x *UserBorrowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserBorrowsRequest.ProtoReflect.Descriptor instead.
+func (*UserBorrowsRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UserBorrowsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UserBorrowsRequest) GetActiveOnly() bool {
+	if x != nil {
+		return x.ActiveOnly
+	}
+	return false
+}
+
+func (x *UserBorrowsRequest) GetSkip() int32 {
+	if x != nil {
+		return x.Skip
+	}
+	return 0
+}
+
+func (x *UserBorrowsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type CountBorrowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectionId  string                 `protobuf:"bytes,1,opt,name=collection_id,json=collectionId,proto3" json:"collection_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountBorrowRequest) Reset() {
+	*x = CountBorrowRequest{}
+	mi := &file_borrow_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountBorrowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountBorrowRequest) ProtoMessage() {}
+
+func (x *CountBorrowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountBorrowRequest.ProtoReflect.Descriptor instead.
+func (*CountBorrowRequest) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CountBorrowRequest) GetCollectionId() string {
+	if x != nil {
+		return x.CollectionId
+	}
+	return ""
+}
+
+func (x *CountBorrowRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type BorrowCountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BorrowCountResponse) Reset() {
+	*x = BorrowCountResponse{}
+	mi := &file_borrow_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BorrowCountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BorrowCountResponse) ProtoMessage() {}
+
+func (x *BorrowCountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_borrow_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BorrowCountResponse.ProtoReflect.Descriptor instead.
+func (*BorrowCountResponse) Descriptor() ([]byte, []int) {
+	return file_borrow_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BorrowCountResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *BorrowCountResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BorrowCountResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+var File_borrow_proto protoreflect.FileDescriptor
+
+const file_borrow_proto_rawDesc = "" +
+	"\n" +
+	"\fborrow.proto\x12\x06shared\x1a\x1cgoogle/protobuf/struct.proto\x1a\x10collection.proto\"\x89\x03\n" +
+	"\x06Borrow\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\tR\x06bookId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12#\n" +
+	"\rcollection_id\x18\x04 \x01(\tR\fcollectionId\x12\x1f\n" +
+	"\vborrow_date\x18\x05 \x01(\tR\n" +
+	"borrowDate\x12\x19\n" +
+	"\bdue_date\x18\x06 \x01(\tR\adueDate\x12\x1f\n" +
+	"\vreturn_date\x18\a \x01(\tR\n" +
+	"returnDate\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\tR\tupdatedAt\x12#\n" +
+	"\rrenewal_count\x18\n" +
+	" \x01(\x05R\frenewalCount\x12\x16\n" +
+	"\x06status\x18\v \x01(\tR\x06status\x12\x1f\n" +
+	"\vreturned_at\x18\f \x01(\tR\n" +
+	"returnedAt\x12\x1f\n" +
+	"\vreturned_by\x18\r \x01(\tR\n" +
+	"returnedBy\"\x90\x01\n" +
+	"\x11GetBorrowsRequest\x12/\n" +
+	"\x06filter\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06filter\x12 \n" +
+	"\x04sort\x18\x02 \x03(\v2\f.shared.SortR\x04sort\x12\x12\n" +
+	"\x04skip\x18\x03 \x01(\x05R\x04skip\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\x93\x01\n" +
+	"\rBorrowRequest\x12#\n" +
+	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tloan_days\x18\x03 \x01(\x05R\bloanDays\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"M\n" +
+	"\rReturnRequest\x12\x1b\n" +
+	"\tborrow_id\x18\x01 \x01(\tR\bborrowId\x12\x1f\n" +
+	"\vreturned_by\x18\x02 \x01(\tR\n" +
+	"returnedBy\"%\n" +
+	"\x13DeleteBorrowRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"#\n" +
+	"\x11FindBorrowRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"+\n" +
+	"\fRenewRequest\x12\x1b\n" +
+	"\tborrow_id\x18\x01 \x01(\tR\bborrowId\"\xf2\x01\n" +
+	"\x15BorrowServiceResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\tR\x06bookId\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x18\n" +
+	"\asuccess\x18\x04 \x01(\bR\asuccess\x12&\n" +
+	"\x06borrow\x18\x05 \x01(\v2\x0e.shared.BorrowR\x06borrow\x12\x1d\n" +
+	"\n" +
+	"is_overdue\x18\x06 \x01(\bR\tisOverdue\x12!\n" +
+	"\fdays_overdue\x18\a \x01(\x05R\vdaysOverdue\x12\x12\n" +
+	"\x04fine\x18\b \x01(\x03R\x04fine\"p\n" +
+	"\x12BorrowListResponse\x12&\n" +
+	"\x06borrow\x18\x01 \x03(\v2\x0e.shared.BorrowR\x06borrow\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\":\n" +
+	"\x0eOverdueRequest\x12\x12\n" +
+	"\x04skip\x18\x01 \x01(\x05R\x04skip\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"*\n" +
+	"\vFineRequest\x12\x1b\n" +
+	"\tborrow_id\x18\x01 \x01(\tR\bborrowId\"\xb8\x01\n" +
+	"\fFineResponse\x12\x1b\n" +
+	"\tborrow_id\x18\x01 \x01(\tR\bborrowId\x12!\n" +
+	"\fdays_overdue\x18\x02 \x01(\x05R\vdaysOverdue\x12\x12\n" +
+	"\x04fine\x18\x03 \x01(\x03R\x04fine\x12 \n" +
+	"\ffine_per_day\x18\x04 \x01(\x03R\n" +
+	"finePerDay\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12\x18\n" +
+	"\asuccess\x18\x06 \x01(\bR\asuccess\"N\n" +
+	"\x0eDueSoonRequest\x12\x12\n" +
+	"\x04days\x18\x01 \x01(\x05R\x04days\x12\x12\n" +
+	"\x04skip\x18\x02 \x01(\x05R\x04skip\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"x\n" +
+	"\x12UserBorrowsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vactive_only\x18\x02 \x01(\bR\n" +
+	"activeOnly\x12\x12\n" +
+	"\x04skip\x18\x03 \x01(\x05R\x04skip\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"R\n" +
+	"\x12CountBorrowRequest\x12#\n" +
+	"\rcollection_id\x18\x01 \x01(\tR\fcollectionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"_\n" +
+	"\x13BorrowCountResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess2\x98\x06\n" +
+	"\rBorrowService\x12C\n" +
+	"\n" +
+	"GetBorrows\x12\x19.shared.GetBorrowsRequest\x1a\x1a.shared.BorrowListResponse\x12B\n" +
+	"\n" +
+	"BorrowBook\x12\x15.shared.BorrowRequest\x1a\x1d.shared.BorrowServiceResponse\x12B\n" +
+	"\n" +
+	"ReturnBook\x12\x15.shared.ReturnRequest\x1a\x1d.shared.BorrowServiceResponse\x12G\n" +
+	"\x11GetOverdueBorrows\x12\x16.shared.OverdueRequest\x1a\x1a.shared.BorrowListResponse\x12@\n" +
+	"\tRenewBook\x12\x14.shared.RenewRequest\x1a\x1d.shared.BorrowServiceResponse\x12J\n" +
+	"\x10GetBorrowsByUser\x12\x1a.shared.UserBorrowsRequest\x1a\x1a.shared.BorrowListResponse\x12J\n" +
+	"\fDeleteBorrow\x12\x1b.shared.DeleteBorrowRequest\x1a\x1d.shared.BorrowServiceResponse\x12M\n" +
+	"\x12CountActiveBorrows\x12\x1a.shared.CountBorrowRequest\x1a\x1b.shared.BorrowCountResponse\x12J\n" +
+	"\x0eFindBorrowById\x12\x19.shared.FindBorrowRequest\x1a\x1d.shared.BorrowServiceResponse\x12@\n" +
 	"\n" +
-	"ReturnBook\x12\x15.shared.ReturnRequest\x1a\x1d.shared.BorrowServiceResponseB\n" +
+	"GetDueSoon\x12\x16.shared.DueSoonRequest\x1a\x1a.shared.BorrowListResponse\x12:\n" +
+	"\rCalculateFine\x12\x13.shared.FineRequest\x1a\x14.shared.FineResponseB\n" +
 	"Z\b./bufferb\x06proto3"
 
 var (
@@ -341,23 +1182,59 @@ func file_borrow_proto_rawDescGZIP() []byte {
 	return file_borrow_proto_rawDescData
 }
 
-var file_borrow_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_borrow_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_borrow_proto_goTypes = []any{
 	(*Borrow)(nil),                // 0: shared.Borrow
-	(*BorrowRequest)(nil),         // 1: shared.BorrowRequest
-	(*ReturnRequest)(nil),         // 2: shared.ReturnRequest
-	(*BorrowServiceResponse)(nil), // 3: shared.BorrowServiceResponse
+	(*GetBorrowsRequest)(nil),     // 1: shared.GetBorrowsRequest
+	(*BorrowRequest)(nil),         // 2: shared.BorrowRequest
+	(*ReturnRequest)(nil),         // 3: shared.ReturnRequest
+	(*DeleteBorrowRequest)(nil),   // 4: shared.DeleteBorrowRequest
+	(*FindBorrowRequest)(nil),     // 5: shared.FindBorrowRequest
+	(*RenewRequest)(nil),          // 6: shared.RenewRequest
+	(*BorrowServiceResponse)(nil), // 7: shared.BorrowServiceResponse
+	(*BorrowListResponse)(nil),    // 8: shared.BorrowListResponse
+	(*OverdueRequest)(nil),        // 9: shared.OverdueRequest
+	(*FineRequest)(nil),           // 10: shared.FineRequest
+	(*FineResponse)(nil),          // 11: shared.FineResponse
+	(*DueSoonRequest)(nil),        // 12: shared.DueSoonRequest
+	(*UserBorrowsRequest)(nil),    // 13: shared.UserBorrowsRequest
+	(*CountBorrowRequest)(nil),    // 14: shared.CountBorrowRequest
+	(*BorrowCountResponse)(nil),   // 15: shared.BorrowCountResponse
+	(*structpb.Struct)(nil),       // 16: google.protobuf.Struct
+	(*Sort)(nil),                  // 17: shared.Sort
 }
 var file_borrow_proto_depIdxs = []int32{
-	1, // 0: shared.BorrowService.BorrowBook:input_type -> shared.BorrowRequest
-	2, // 1: shared.BorrowService.ReturnBook:input_type -> shared.ReturnRequest
-	3, // 2: shared.BorrowService.BorrowBook:output_type -> shared.BorrowServiceResponse
-	3, // 3: shared.BorrowService.ReturnBook:output_type -> shared.BorrowServiceResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	16, // 0: shared.GetBorrowsRequest.filter:type_name -> google.protobuf.Struct
+	17, // 1: shared.GetBorrowsRequest.sort:type_name -> shared.Sort
+	0,  // 2: shared.BorrowServiceResponse.borrow:type_name -> shared.Borrow
+	0,  // 3: shared.BorrowListResponse.borrow:type_name -> shared.Borrow
+	1,  // 4: shared.BorrowService.GetBorrows:input_type -> shared.GetBorrowsRequest
+	2,  // 5: shared.BorrowService.BorrowBook:input_type -> shared.BorrowRequest
+	3,  // 6: shared.BorrowService.ReturnBook:input_type -> shared.ReturnRequest
+	9,  // 7: shared.BorrowService.GetOverdueBorrows:input_type -> shared.OverdueRequest
+	6,  // 8: shared.BorrowService.RenewBook:input_type -> shared.RenewRequest
+	13, // 9: shared.BorrowService.GetBorrowsByUser:input_type -> shared.UserBorrowsRequest
+	4,  // 10: shared.BorrowService.DeleteBorrow:input_type -> shared.DeleteBorrowRequest
+	14, // 11: shared.BorrowService.CountActiveBorrows:input_type -> shared.CountBorrowRequest
+	5,  // 12: shared.BorrowService.FindBorrowById:input_type -> shared.FindBorrowRequest
+	12, // 13: shared.BorrowService.GetDueSoon:input_type -> shared.DueSoonRequest
+	10, // 14: shared.BorrowService.CalculateFine:input_type -> shared.FineRequest
+	8,  // 15: shared.BorrowService.GetBorrows:output_type -> shared.BorrowListResponse
+	7,  // 16: shared.BorrowService.BorrowBook:output_type -> shared.BorrowServiceResponse
+	7,  // 17: shared.BorrowService.ReturnBook:output_type -> shared.BorrowServiceResponse
+	8,  // 18: shared.BorrowService.GetOverdueBorrows:output_type -> shared.BorrowListResponse
+	7,  // 19: shared.BorrowService.RenewBook:output_type -> shared.BorrowServiceResponse
+	8,  // 20: shared.BorrowService.GetBorrowsByUser:output_type -> shared.BorrowListResponse
+	7,  // 21: shared.BorrowService.DeleteBorrow:output_type -> shared.BorrowServiceResponse
+	15, // 22: shared.BorrowService.CountActiveBorrows:output_type -> shared.BorrowCountResponse
+	7,  // 23: shared.BorrowService.FindBorrowById:output_type -> shared.BorrowServiceResponse
+	8,  // 24: shared.BorrowService.GetDueSoon:output_type -> shared.BorrowListResponse
+	11, // 25: shared.BorrowService.CalculateFine:output_type -> shared.FineResponse
+	15, // [15:26] is the sub-list for method output_type
+	4,  // [4:15] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_borrow_proto_init() }
@@ -365,13 +1242,14 @@ func file_borrow_proto_init() {
 	if File_borrow_proto != nil {
 		return
 	}
+	file_collection_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_borrow_proto_rawDesc), len(file_borrow_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,16 +19,34 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	BorrowService_BorrowBook_FullMethodName = "/shared.BorrowService/BorrowBook"
-	BorrowService_ReturnBook_FullMethodName = "/shared.BorrowService/ReturnBook"
+	BorrowService_GetBorrows_FullMethodName         = "/shared.BorrowService/GetBorrows"
+	BorrowService_BorrowBook_FullMethodName         = "/shared.BorrowService/BorrowBook"
+	BorrowService_ReturnBook_FullMethodName         = "/shared.BorrowService/ReturnBook"
+	BorrowService_GetOverdueBorrows_FullMethodName  = "/shared.BorrowService/GetOverdueBorrows"
+	BorrowService_RenewBook_FullMethodName          = "/shared.BorrowService/RenewBook"
+	BorrowService_GetBorrowsByUser_FullMethodName   = "/shared.BorrowService/GetBorrowsByUser"
+	BorrowService_DeleteBorrow_FullMethodName       = "/shared.BorrowService/DeleteBorrow"
+	BorrowService_CountActiveBorrows_FullMethodName = "/shared.BorrowService/CountActiveBorrows"
+	BorrowService_FindBorrowById_FullMethodName     = "/shared.BorrowService/FindBorrowById"
+	BorrowService_GetDueSoon_FullMethodName         = "/shared.BorrowService/GetDueSoon"
+	BorrowService_CalculateFine_FullMethodName      = "/shared.BorrowService/CalculateFine"
 )
 
 // BorrowServiceClient is the client API for BorrowService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type BorrowServiceClient interface {
+	GetBorrows(ctx context.Context, in *GetBorrowsRequest, opts ...grpc.CallOption) (*BorrowListResponse, error)
 	BorrowBook(ctx context.Context, in *BorrowRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error)
 	ReturnBook(ctx context.Context, in *ReturnRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error)
+	GetOverdueBorrows(ctx context.Context, in *OverdueRequest, opts ...grpc.CallOption) (*BorrowListResponse, error)
+	RenewBook(ctx context.Context, in *RenewRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error)
+	GetBorrowsByUser(ctx context.Context, in *UserBorrowsRequest, opts ...grpc.CallOption) (*BorrowListResponse, error)
+	DeleteBorrow(ctx context.Context, in *DeleteBorrowRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error)
+	CountActiveBorrows(ctx context.Context, in *CountBorrowRequest, opts ...grpc.CallOption) (*BorrowCountResponse, error)
+	FindBorrowById(ctx context.Context, in *FindBorrowRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error)
+	GetDueSoon(ctx context.Context, in *DueSoonRequest, opts ...grpc.CallOption) (*BorrowListResponse, error)
+	CalculateFine(ctx context.Context, in *FineRequest, opts ...grpc.CallOption) (*FineResponse, error)
 }
 
 type borrowServiceClient struct {
@@ -39,6 +57,16 @@ func NewBorrowServiceClient(cc grpc.ClientConnInterface) BorrowServiceClient {
 	return &borrowServiceClient{cc}
 }
 
+func (c *borrowServiceClient) GetBorrows(ctx context.Context, in *GetBorrowsRequest, opts ...grpc.CallOption) (*BorrowListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowListResponse)
+	err := c.cc.Invoke(ctx, BorrowService_GetBorrows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *borrowServiceClient) BorrowBook(ctx context.Context, in *BorrowRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BorrowServiceResponse)
@@ -59,12 +87,101 @@ func (c *borrowServiceClient) ReturnBook(ctx context.Context, in *ReturnRequest,
 	return out, nil
 }
 
+func (c *borrowServiceClient) GetOverdueBorrows(ctx context.Context, in *OverdueRequest, opts ...grpc.CallOption) (*BorrowListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowListResponse)
+	err := c.cc.Invoke(ctx, BorrowService_GetOverdueBorrows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowServiceClient) RenewBook(ctx context.Context, in *RenewRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowServiceResponse)
+	err := c.cc.Invoke(ctx, BorrowService_RenewBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowServiceClient) GetBorrowsByUser(ctx context.Context, in *UserBorrowsRequest, opts ...grpc.CallOption) (*BorrowListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowListResponse)
+	err := c.cc.Invoke(ctx, BorrowService_GetBorrowsByUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowServiceClient) DeleteBorrow(ctx context.Context, in *DeleteBorrowRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowServiceResponse)
+	err := c.cc.Invoke(ctx, BorrowService_DeleteBorrow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowServiceClient) CountActiveBorrows(ctx context.Context, in *CountBorrowRequest, opts ...grpc.CallOption) (*BorrowCountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowCountResponse)
+	err := c.cc.Invoke(ctx, BorrowService_CountActiveBorrows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowServiceClient) FindBorrowById(ctx context.Context, in *FindBorrowRequest, opts ...grpc.CallOption) (*BorrowServiceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowServiceResponse)
+	err := c.cc.Invoke(ctx, BorrowService_FindBorrowById_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowServiceClient) GetDueSoon(ctx context.Context, in *DueSoonRequest, opts ...grpc.CallOption) (*BorrowListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BorrowListResponse)
+	err := c.cc.Invoke(ctx, BorrowService_GetDueSoon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *borrowServiceClient) CalculateFine(ctx context.Context, in *FineRequest, opts ...grpc.CallOption) (*FineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FineResponse)
+	err := c.cc.Invoke(ctx, BorrowService_CalculateFine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BorrowServiceServer is the server API for BorrowService service.
 // All implementations must embed UnimplementedBorrowServiceServer
 // for forward compatibility.
 type BorrowServiceServer interface {
+	GetBorrows(context.Context, *GetBorrowsRequest) (*BorrowListResponse, error)
 	BorrowBook(context.Context, *BorrowRequest) (*BorrowServiceResponse, error)
 	ReturnBook(context.Context, *ReturnRequest) (*BorrowServiceResponse, error)
+	GetOverdueBorrows(context.Context, *OverdueRequest) (*BorrowListResponse, error)
+	RenewBook(context.Context, *RenewRequest) (*BorrowServiceResponse, error)
+	GetBorrowsByUser(context.Context, *UserBorrowsRequest) (*BorrowListResponse, error)
+	DeleteBorrow(context.Context, *DeleteBorrowRequest) (*BorrowServiceResponse, error)
+	CountActiveBorrows(context.Context, *CountBorrowRequest) (*BorrowCountResponse, error)
+	FindBorrowById(context.Context, *FindBorrowRequest) (*BorrowServiceResponse, error)
+	GetDueSoon(context.Context, *DueSoonRequest) (*BorrowListResponse, error)
+	CalculateFine(context.Context, *FineRequest) (*FineResponse, error)
 	mustEmbedUnimplementedBorrowServiceServer()
 }
 
@@ -75,12 +192,39 @@ type BorrowServiceServer interface {
 // pointer dereference when methods are called.
 type UnimplementedBorrowServiceServer struct{}
 
+func (UnimplementedBorrowServiceServer) GetBorrows(context.Context, *GetBorrowsRequest) (*BorrowListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBorrows not implemented")
+}
 func (UnimplementedBorrowServiceServer) BorrowBook(context.Context, *BorrowRequest) (*BorrowServiceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BorrowBook not implemented")
 }
 func (UnimplementedBorrowServiceServer) ReturnBook(context.Context, *ReturnRequest) (*BorrowServiceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReturnBook not implemented")
 }
+func (UnimplementedBorrowServiceServer) GetOverdueBorrows(context.Context, *OverdueRequest) (*BorrowListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOverdueBorrows not implemented")
+}
+func (UnimplementedBorrowServiceServer) RenewBook(context.Context, *RenewRequest) (*BorrowServiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewBook not implemented")
+}
+func (UnimplementedBorrowServiceServer) GetBorrowsByUser(context.Context, *UserBorrowsRequest) (*BorrowListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBorrowsByUser not implemented")
+}
+func (UnimplementedBorrowServiceServer) DeleteBorrow(context.Context, *DeleteBorrowRequest) (*BorrowServiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBorrow not implemented")
+}
+func (UnimplementedBorrowServiceServer) CountActiveBorrows(context.Context, *CountBorrowRequest) (*BorrowCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountActiveBorrows not implemented")
+}
+func (UnimplementedBorrowServiceServer) FindBorrowById(context.Context, *FindBorrowRequest) (*BorrowServiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindBorrowById not implemented")
+}
+func (UnimplementedBorrowServiceServer) GetDueSoon(context.Context, *DueSoonRequest) (*BorrowListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDueSoon not implemented")
+}
+func (UnimplementedBorrowServiceServer) CalculateFine(context.Context, *FineRequest) (*FineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CalculateFine not implemented")
+}
 func (UnimplementedBorrowServiceServer) mustEmbedUnimplementedBorrowServiceServer() {}
 func (UnimplementedBorrowServiceServer) testEmbeddedByValue()                       {}
 
@@ -102,6 +246,24 @@ func RegisterBorrowServiceServer(s grpc.ServiceRegistrar, srv BorrowServiceServe
 	s.RegisterService(&BorrowService_ServiceDesc, srv)
 }
 
+func _BorrowService_GetBorrows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBorrowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).GetBorrows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_GetBorrows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).GetBorrows(ctx, req.(*GetBorrowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BorrowService_BorrowBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BorrowRequest)
 	if err := dec(in); err != nil {
@@ -138,6 +300,150 @@ func _BorrowService_ReturnBook_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BorrowService_GetOverdueBorrows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OverdueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).GetOverdueBorrows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_GetOverdueBorrows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).GetOverdueBorrows(ctx, req.(*OverdueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowService_RenewBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).RenewBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_RenewBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).RenewBook(ctx, req.(*RenewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowService_GetBorrowsByUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserBorrowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).GetBorrowsByUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_GetBorrowsByUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).GetBorrowsByUser(ctx, req.(*UserBorrowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowService_DeleteBorrow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBorrowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).DeleteBorrow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_DeleteBorrow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).DeleteBorrow(ctx, req.(*DeleteBorrowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowService_CountActiveBorrows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountBorrowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).CountActiveBorrows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_CountActiveBorrows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).CountActiveBorrows(ctx, req.(*CountBorrowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowService_FindBorrowById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindBorrowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).FindBorrowById(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_FindBorrowById_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).FindBorrowById(ctx, req.(*FindBorrowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowService_GetDueSoon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DueSoonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).GetDueSoon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_GetDueSoon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).GetDueSoon(ctx, req.(*DueSoonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BorrowService_CalculateFine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BorrowServiceServer).CalculateFine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BorrowService_CalculateFine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BorrowServiceServer).CalculateFine(ctx, req.(*FineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BorrowService_ServiceDesc is the grpc.ServiceDesc for BorrowService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -145,6 +451,10 @@ var BorrowService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "shared.BorrowService",
 	HandlerType: (*BorrowServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBorrows",
+			Handler:    _BorrowService_GetBorrows_Handler,
+		},
 		{
 			MethodName: "BorrowBook",
 			Handler:    _BorrowService_BorrowBook_Handler,
@@ -153,6 +463,38 @@ var BorrowService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReturnBook",
 			Handler:    _BorrowService_ReturnBook_Handler,
 		},
+		{
+			MethodName: "GetOverdueBorrows",
+			Handler:    _BorrowService_GetOverdueBorrows_Handler,
+		},
+		{
+			MethodName: "RenewBook",
+			Handler:    _BorrowService_RenewBook_Handler,
+		},
+		{
+			MethodName: "GetBorrowsByUser",
+			Handler:    _BorrowService_GetBorrowsByUser_Handler,
+		},
+		{
+			MethodName: "DeleteBorrow",
+			Handler:    _BorrowService_DeleteBorrow_Handler,
+		},
+		{
+			MethodName: "CountActiveBorrows",
+			Handler:    _BorrowService_CountActiveBorrows_Handler,
+		},
+		{
+			MethodName: "FindBorrowById",
+			Handler:    _BorrowService_FindBorrowById_Handler,
+		},
+		{
+			MethodName: "GetDueSoon",
+			Handler:    _BorrowService_GetDueSoon_Handler,
+		},
+		{
+			MethodName: "CalculateFine",
+			Handler:    _BorrowService_CalculateFine_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "borrow.proto",
@@ -9,6 +9,7 @@ package buffer
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	structpb "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
 	sync "sync"
@@ -32,6 +33,9 @@ type Collection struct {
 	AvailableBooks int32                  `protobuf:"varint,6,opt,name=available_books,json=availableBooks,proto3" json:"available_books,omitempty"`
 	CreatedAt      string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt      string                 `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt      string                 `protobuf:"bytes,9,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	SeedStatus     string                 `protobuf:"bytes,10,opt,name=seed_status,json=seedStatus,proto3" json:"seed_status,omitempty"` // pending, complete or failed while the collection's books are created
+	Etag           string                 `protobuf:"bytes,11,opt,name=etag,proto3" json:"etag,omitempty"`                               // Changes on every write, sent back as if_match to update only this version
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -122,11 +126,35 @@ func (x *Collection) GetUpdatedAt() string {
 	return ""
 }
 
+func (x *Collection) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *Collection) GetSeedStatus() string {
+	if x != nil {
+		return x.SeedStatus
+	}
+	return ""
+}
+
+func (x *Collection) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
 type Response struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Collection    []*Collection          `protobuf:"bytes,1,rep,name=collection,proto3" json:"collection,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,4,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	Total         int64                  `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"`
+	Skipped       int32                  `protobuf:"varint,6,opt,name=skipped,proto3" json:"skipped,omitempty"` // Requested ids dropped for not being valid ids
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -182,15 +210,40 @@ func (x *Response) GetSuccess() bool {
 	return false
 }
 
+func (x *Response) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *Response) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Response) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
 // Get Collection messages
 type GetCollectionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filter        *structpb.Struct       `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
-	Sort          []*Sort                `protobuf:"bytes,2,rep,name=sort,proto3" json:"sort,omitempty"`
-	Skip          int32                  `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
-	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Filter         *structpb.Struct       `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort           []*Sort                `protobuf:"bytes,2,rep,name=sort,proto3" json:"sort,omitempty"`
+	Skip           int32                  `protobuf:"varint,3,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit          int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	After          string                 `protobuf:"bytes,5,opt,name=after,proto3" json:"after,omitempty"`
+	UseCursor      bool                   `protobuf:"varint,6,opt,name=use_cursor,json=useCursor,proto3" json:"use_cursor,omitempty"`
+	Fields         []string               `protobuf:"bytes,7,rep,name=fields,proto3" json:"fields,omitempty"`
+	IncludeDeleted bool                   `protobuf:"varint,8,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *GetCollectionRequest) Reset() {
@@ -251,6 +304,34 @@ func (x *GetCollectionRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *GetCollectionRequest) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *GetCollectionRequest) GetUseCursor() bool {
+	if x != nil {
+		return x.UseCursor
+	}
+	return false
+}
+
+func (x *GetCollectionRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *GetCollectionRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
 type Sort struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -348,6 +429,50 @@ func (x *FindCollectionRequest) GetId() string {
 	return ""
 }
 
+type FindCollectionsByIdsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindCollectionsByIdsRequest) Reset() {
+	*x = FindCollectionsByIdsRequest{}
+	mi := &file_collection_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindCollectionsByIdsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindCollectionsByIdsRequest) ProtoMessage() {}
+
+func (x *FindCollectionsByIdsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collection_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindCollectionsByIdsRequest.ProtoReflect.Descriptor instead.
+func (*FindCollectionsByIdsRequest) Descriptor() ([]byte, []int) {
+	return file_collection_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FindCollectionsByIdsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
 // Add Collection messages
 type AddCollectionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -358,7 +483,7 @@ type AddCollectionRequest struct {
 
 func (x *AddCollectionRequest) Reset() {
 	*x = AddCollectionRequest{}
-	mi := &file_collection_proto_msgTypes[5]
+	mi := &file_collection_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -370,7 +495,7 @@ func (x *AddCollectionRequest) String() string {
 func (*AddCollectionRequest) ProtoMessage() {}
 
 func (x *AddCollectionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_collection_proto_msgTypes[5]
+	mi := &file_collection_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +508,7 @@ func (x *AddCollectionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddCollectionRequest.ProtoReflect.Descriptor instead.
 func (*AddCollectionRequest) Descriptor() ([]byte, []int) {
-	return file_collection_proto_rawDescGZIP(), []int{5}
+	return file_collection_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *AddCollectionRequest) GetCollection() *Collection {
@@ -398,13 +523,14 @@ type UpdateCollectionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Payload       *structpb.Struct       `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	IfMatch       string                 `protobuf:"bytes,3,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"` // Optional etag, the update fails with FAILED_PRECONDITION when the collection changed since
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateCollectionRequest) Reset() {
 	*x = UpdateCollectionRequest{}
-	mi := &file_collection_proto_msgTypes[6]
+	mi := &file_collection_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -416,7 +542,7 @@ func (x *UpdateCollectionRequest) String() string {
 func (*UpdateCollectionRequest) ProtoMessage() {}
 
 func (x *UpdateCollectionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_collection_proto_msgTypes[6]
+	mi := &file_collection_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,7 +555,7 @@ func (x *UpdateCollectionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateCollectionRequest.ProtoReflect.Descriptor instead.
 func (*UpdateCollectionRequest) Descriptor() ([]byte, []int) {
-	return file_collection_proto_rawDescGZIP(), []int{6}
+	return file_collection_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *UpdateCollectionRequest) GetId() string {
@@ -446,6 +572,13 @@ func (x *UpdateCollectionRequest) GetPayload() *structpb.Struct {
 	return nil
 }
 
+func (x *UpdateCollectionRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
 // Delete Collection messages
 type DeleteCollectionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -456,7 +589,7 @@ type DeleteCollectionRequest struct {
 
 func (x *DeleteCollectionRequest) Reset() {
 	*x = DeleteCollectionRequest{}
-	mi := &file_collection_proto_msgTypes[7]
+	mi := &file_collection_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -468,7 +601,7 @@ func (x *DeleteCollectionRequest) String() string {
 func (*DeleteCollectionRequest) ProtoMessage() {}
 
 func (x *DeleteCollectionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_collection_proto_msgTypes[7]
+	mi := &file_collection_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -481,7 +614,7 @@ func (x *DeleteCollectionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteCollectionRequest.ProtoReflect.Descriptor instead.
 func (*DeleteCollectionRequest) Descriptor() ([]byte, []int) {
-	return file_collection_proto_rawDescGZIP(), []int{7}
+	return file_collection_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DeleteCollectionRequest) GetId() string {
@@ -491,29 +624,91 @@ func (x *DeleteCollectionRequest) GetId() string {
 	return ""
 }
 
-type DecrementAvailableBooksRequest struct {
+// Moves the book counters of a collection by the given deltas
+type AdjustBookStockRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TotalDelta     int32                  `protobuf:"varint,2,opt,name=total_delta,json=totalDelta,proto3" json:"total_delta,omitempty"`
+	AvailableDelta int32                  `protobuf:"varint,3,opt,name=available_delta,json=availableDelta,proto3" json:"available_delta,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AdjustBookStockRequest) Reset() {
+	*x = AdjustBookStockRequest{}
+	mi := &file_collection_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustBookStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustBookStockRequest) ProtoMessage() {}
+
+func (x *AdjustBookStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collection_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustBookStockRequest.ProtoReflect.Descriptor instead.
+func (*AdjustBookStockRequest) Descriptor() ([]byte, []int) {
+	return file_collection_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AdjustBookStockRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AdjustBookStockRequest) GetTotalDelta() int32 {
+	if x != nil {
+		return x.TotalDelta
+	}
+	return 0
+}
+
+func (x *AdjustBookStockRequest) GetAvailableDelta() int32 {
+	if x != nil {
+		return x.AvailableDelta
+	}
+	return 0
+}
+
+// Records how creating the books of a new collection went
+type SetSeedStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Amount        int32                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	SeedStatus    string                 `protobuf:"bytes,2,opt,name=seed_status,json=seedStatus,proto3" json:"seed_status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DecrementAvailableBooksRequest) Reset() {
-	*x = DecrementAvailableBooksRequest{}
-	mi := &file_collection_proto_msgTypes[8]
+func (x *SetSeedStatusRequest) Reset() {
+	*x = SetSeedStatusRequest{}
+	mi := &file_collection_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DecrementAvailableBooksRequest) String() string {
+func (x *SetSeedStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DecrementAvailableBooksRequest) ProtoMessage() {}
+func (*SetSeedStatusRequest) ProtoMessage() {}
 
-func (x *DecrementAvailableBooksRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_collection_proto_msgTypes[8]
+func (x *SetSeedStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collection_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -524,30 +719,144 @@ func (x *DecrementAvailableBooksRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DecrementAvailableBooksRequest.ProtoReflect.Descriptor instead.
-func (*DecrementAvailableBooksRequest) Descriptor() ([]byte, []int) {
-	return file_collection_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use SetSeedStatusRequest.ProtoReflect.Descriptor instead.
+func (*SetSeedStatusRequest) Descriptor() ([]byte, []int) {
+	return file_collection_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *DecrementAvailableBooksRequest) GetId() string {
+func (x *SetSeedStatusRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *DecrementAvailableBooksRequest) GetAmount() int32 {
+func (x *SetSeedStatusRequest) GetSeedStatus() string {
 	if x != nil {
-		return x.Amount
+		return x.SeedStatus
+	}
+	return ""
+}
+
+// Search Collection messages
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Skip          int32                  `protobuf:"varint,2,opt,name=skip,proto3" json:"skip,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Regex         bool                   `protobuf:"varint,4,opt,name=regex,proto3" json:"regex,omitempty"` // Match query as a regular expression instead of literally, only admins may ask for it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_collection_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collection_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_collection_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetSkip() int32 {
+	if x != nil {
+		return x.Skip
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
 	}
 	return 0
 }
 
+func (x *SearchRequest) GetRegex() bool {
+	if x != nil {
+		return x.Regex
+	}
+	return false
+}
+
+// Every category used by a live collection, sorted
+type CategoriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Categories    []string               `protobuf:"bytes,1,rep,name=categories,proto3" json:"categories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CategoriesResponse) Reset() {
+	*x = CategoriesResponse{}
+	mi := &file_collection_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CategoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CategoriesResponse) ProtoMessage() {}
+
+func (x *CategoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collection_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CategoriesResponse.ProtoReflect.Descriptor instead.
+func (*CategoriesResponse) Descriptor() ([]byte, []int) {
+	return file_collection_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CategoriesResponse) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
 var File_collection_proto protoreflect.FileDescriptor
 
 const file_collection_proto_rawDesc = "" +
 	"\n" +
-	"\x10collection.proto\x12\x06shared\x1a\x1cgoogle/protobuf/struct.proto\"\xf0\x01\n" +
+	"\x10collection.proto\x12\x06shared\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1bgoogle/protobuf/empty.proto\"\xc4\x02\n" +
 	"\n" +
 	"Collection\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
@@ -562,42 +871,80 @@ const file_collection_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\b \x01(\tR\tupdatedAt\"r\n" +
+	"updated_at\x18\b \x01(\tR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\t \x01(\tR\tdeletedAt\x12\x1f\n" +
+	"\vseed_status\x18\n" +
+	" \x01(\tR\n" +
+	"seedStatus\x12\x12\n" +
+	"\x04etag\x18\v \x01(\tR\x04etag\"\xc3\x01\n" +
 	"\bResponse\x122\n" +
 	"\n" +
 	"collection\x18\x01 \x03(\v2\x12.shared.CollectionR\n" +
 	"collection\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
-	"\asuccess\x18\x03 \x01(\bR\asuccess\"\x93\x01\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x1f\n" +
+	"\vnext_cursor\x18\x04 \x01(\tR\n" +
+	"nextCursor\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\x03R\x05total\x12\x18\n" +
+	"\askipped\x18\x06 \x01(\x05R\askipped\"\x89\x02\n" +
 	"\x14GetCollectionRequest\x12/\n" +
 	"\x06filter\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06filter\x12 \n" +
 	"\x04sort\x18\x02 \x03(\v2\f.shared.SortR\x04sort\x12\x12\n" +
 	"\x04skip\x18\x03 \x01(\x05R\x04skip\x12\x14\n" +
-	"\x05limit\x18\x04 \x01(\x05R\x05limit\"6\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x14\n" +
+	"\x05after\x18\x05 \x01(\tR\x05after\x12\x1d\n" +
+	"\n" +
+	"use_cursor\x18\x06 \x01(\bR\tuseCursor\x12\x16\n" +
+	"\x06fields\x18\a \x03(\tR\x06fields\x12'\n" +
+	"\x0finclude_deleted\x18\b \x01(\bR\x0eincludeDeleted\"6\n" +
 	"\x04Sort\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1c\n" +
 	"\tdirection\x18\x02 \x01(\x05R\tdirection\"'\n" +
 	"\x15FindCollectionRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"J\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
+	"\x1bFindCollectionsByIdsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"J\n" +
 	"\x14AddCollectionRequest\x122\n" +
 	"\n" +
 	"collection\x18\x01 \x01(\v2\x12.shared.CollectionR\n" +
-	"collection\"\\\n" +
+	"collection\"w\n" +
 	"\x17UpdateCollectionRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
-	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\")\n" +
+	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\x12\x19\n" +
+	"\bif_match\x18\x03 \x01(\tR\aifMatch\")\n" +
 	"\x17DeleteCollectionRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"H\n" +
-	"\x1eDecrementAvailableBooksRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x05R\x06amount2\xbf\x03\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"r\n" +
+	"\x16AdjustBookStockRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vtotal_delta\x18\x02 \x01(\x05R\n" +
+	"totalDelta\x12'\n" +
+	"\x0favailable_delta\x18\x03 \x01(\x05R\x0eavailableDelta\"G\n" +
+	"\x14SetSeedStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vseed_status\x18\x02 \x01(\tR\n" +
+	"seedStatus\"e\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
+	"\x04skip\x18\x02 \x01(\x05R\x04skip\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x14\n" +
+	"\x05regex\x18\x04 \x01(\bR\x05regex\"4\n" +
+	"\x12CategoriesResponse\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x01 \x03(\tR\n" +
+	"categories2\x8e\x06\n" +
 	"\x11CollectionService\x12?\n" +
 	"\rGetCollection\x12\x1c.shared.GetCollectionRequest\x1a\x10.shared.Response\x12E\n" +
-	"\x12FindCollectionById\x12\x1d.shared.FindCollectionRequest\x1a\x10.shared.Response\x12?\n" +
+	"\x12FindCollectionById\x12\x1d.shared.FindCollectionRequest\x1a\x10.shared.Response\x12J\n" +
+	"\x17GetCollectionWithCounts\x12\x1d.shared.FindCollectionRequest\x1a\x10.shared.Response\x12M\n" +
+	"\x14FindCollectionsByIds\x12#.shared.FindCollectionsByIdsRequest\x1a\x10.shared.Response\x12?\n" +
 	"\rAddCollection\x12\x1c.shared.AddCollectionRequest\x1a\x10.shared.Response\x12E\n" +
 	"\x10UpdateCollection\x12\x1f.shared.UpdateCollectionRequest\x1a\x10.shared.Response\x12E\n" +
-	"\x10DeleteCollection\x12\x1f.shared.DeleteCollectionRequest\x1a\x10.shared.Response\x12S\n" +
-	"\x17DecrementAvailableBooks\x12&.shared.DecrementAvailableBooksRequest\x1a\x10.shared.ResponseB\n" +
+	"\x10DeleteCollection\x12\x1f.shared.DeleteCollectionRequest\x1a\x10.shared.Response\x12C\n" +
+	"\x0fAdjustBookStock\x12\x1e.shared.AdjustBookStockRequest\x1a\x10.shared.Response\x12?\n" +
+	"\rSetSeedStatus\x12\x1c.shared.SetSeedStatusRequest\x1a\x10.shared.Response\x12<\n" +
+	"\x11SearchCollections\x12\x15.shared.SearchRequest\x1a\x10.shared.Response\x12C\n" +
+	"\rGetCategories\x12\x16.google.protobuf.Empty\x1a\x1a.shared.CategoriesResponseB\n" +
 	"Z\b./bufferb\x06proto3"
 
 var (
@@ -612,39 +959,54 @@ func file_collection_proto_rawDescGZIP() []byte {
 	return file_collection_proto_rawDescData
 }
 
-var file_collection_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_collection_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_collection_proto_goTypes = []any{
-	(*Collection)(nil),                     // 0: shared.Collection
-	(*Response)(nil),                       // 1: shared.Response
-	(*GetCollectionRequest)(nil),           // 2: shared.GetCollectionRequest
-	(*Sort)(nil),                           // 3: shared.Sort
-	(*FindCollectionRequest)(nil),          // 4: shared.FindCollectionRequest
-	(*AddCollectionRequest)(nil),           // 5: shared.AddCollectionRequest
-	(*UpdateCollectionRequest)(nil),        // 6: shared.UpdateCollectionRequest
-	(*DeleteCollectionRequest)(nil),        // 7: shared.DeleteCollectionRequest
-	(*DecrementAvailableBooksRequest)(nil), // 8: shared.DecrementAvailableBooksRequest
-	(*structpb.Struct)(nil),                // 9: google.protobuf.Struct
+	(*Collection)(nil),                  // 0: shared.Collection
+	(*Response)(nil),                    // 1: shared.Response
+	(*GetCollectionRequest)(nil),        // 2: shared.GetCollectionRequest
+	(*Sort)(nil),                        // 3: shared.Sort
+	(*FindCollectionRequest)(nil),       // 4: shared.FindCollectionRequest
+	(*FindCollectionsByIdsRequest)(nil), // 5: shared.FindCollectionsByIdsRequest
+	(*AddCollectionRequest)(nil),        // 6: shared.AddCollectionRequest
+	(*UpdateCollectionRequest)(nil),     // 7: shared.UpdateCollectionRequest
+	(*DeleteCollectionRequest)(nil),     // 8: shared.DeleteCollectionRequest
+	(*AdjustBookStockRequest)(nil),      // 9: shared.AdjustBookStockRequest
+	(*SetSeedStatusRequest)(nil),        // 10: shared.SetSeedStatusRequest
+	(*SearchRequest)(nil),               // 11: shared.SearchRequest
+	(*CategoriesResponse)(nil),          // 12: shared.CategoriesResponse
+	(*structpb.Struct)(nil),             // 13: google.protobuf.Struct
+	(*emptypb.Empty)(nil),               // 14: google.protobuf.Empty
 }
 var file_collection_proto_depIdxs = []int32{
 	0,  // 0: shared.Response.collection:type_name -> shared.Collection
-	9,  // 1: shared.GetCollectionRequest.filter:type_name -> google.protobuf.Struct
+	13, // 1: shared.GetCollectionRequest.filter:type_name -> google.protobuf.Struct
 	3,  // 2: shared.GetCollectionRequest.sort:type_name -> shared.Sort
 	0,  // 3: shared.AddCollectionRequest.collection:type_name -> shared.Collection
-	9,  // 4: shared.UpdateCollectionRequest.payload:type_name -> google.protobuf.Struct
+	13, // 4: shared.UpdateCollectionRequest.payload:type_name -> google.protobuf.Struct
 	2,  // 5: shared.CollectionService.GetCollection:input_type -> shared.GetCollectionRequest
 	4,  // 6: shared.CollectionService.FindCollectionById:input_type -> shared.FindCollectionRequest
-	5,  // 7: shared.CollectionService.AddCollection:input_type -> shared.AddCollectionRequest
-	6,  // 8: shared.CollectionService.UpdateCollection:input_type -> shared.UpdateCollectionRequest
-	7,  // 9: shared.CollectionService.DeleteCollection:input_type -> shared.DeleteCollectionRequest
-	8,  // 10: shared.CollectionService.DecrementAvailableBooks:input_type -> shared.DecrementAvailableBooksRequest
-	1,  // 11: shared.CollectionService.GetCollection:output_type -> shared.Response
-	1,  // 12: shared.CollectionService.FindCollectionById:output_type -> shared.Response
-	1,  // 13: shared.CollectionService.AddCollection:output_type -> shared.Response
-	1,  // 14: shared.CollectionService.UpdateCollection:output_type -> shared.Response
-	1,  // 15: shared.CollectionService.DeleteCollection:output_type -> shared.Response
-	1,  // 16: shared.CollectionService.DecrementAvailableBooks:output_type -> shared.Response
-	11, // [11:17] is the sub-list for method output_type
-	5,  // [5:11] is the sub-list for method input_type
+	4,  // 7: shared.CollectionService.GetCollectionWithCounts:input_type -> shared.FindCollectionRequest
+	5,  // 8: shared.CollectionService.FindCollectionsByIds:input_type -> shared.FindCollectionsByIdsRequest
+	6,  // 9: shared.CollectionService.AddCollection:input_type -> shared.AddCollectionRequest
+	7,  // 10: shared.CollectionService.UpdateCollection:input_type -> shared.UpdateCollectionRequest
+	8,  // 11: shared.CollectionService.DeleteCollection:input_type -> shared.DeleteCollectionRequest
+	9,  // 12: shared.CollectionService.AdjustBookStock:input_type -> shared.AdjustBookStockRequest
+	10, // 13: shared.CollectionService.SetSeedStatus:input_type -> shared.SetSeedStatusRequest
+	11, // 14: shared.CollectionService.SearchCollections:input_type -> shared.SearchRequest
+	14, // 15: shared.CollectionService.GetCategories:input_type -> google.protobuf.Empty
+	1,  // 16: shared.CollectionService.GetCollection:output_type -> shared.Response
+	1,  // 17: shared.CollectionService.FindCollectionById:output_type -> shared.Response
+	1,  // 18: shared.CollectionService.GetCollectionWithCounts:output_type -> shared.Response
+	1,  // 19: shared.CollectionService.FindCollectionsByIds:output_type -> shared.Response
+	1,  // 20: shared.CollectionService.AddCollection:output_type -> shared.Response
+	1,  // 21: shared.CollectionService.UpdateCollection:output_type -> shared.Response
+	1,  // 22: shared.CollectionService.DeleteCollection:output_type -> shared.Response
+	1,  // 23: shared.CollectionService.AdjustBookStock:output_type -> shared.Response
+	1,  // 24: shared.CollectionService.SetSeedStatus:output_type -> shared.Response
+	1,  // 25: shared.CollectionService.SearchCollections:output_type -> shared.Response
+	12, // 26: shared.CollectionService.GetCategories:output_type -> shared.CategoriesResponse
+	16, // [16:27] is the sub-list for method output_type
+	5,  // [5:16] is the sub-list for method input_type
 	5,  // [5:5] is the sub-list for extension type_name
 	5,  // [5:5] is the sub-list for extension extendee
 	0,  // [0:5] is the sub-list for field type_name
@@ -661,7 +1023,7 @@ func file_collection_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_collection_proto_rawDesc), len(file_collection_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -21,10 +22,15 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	CollectionService_GetCollection_FullMethodName           = "/shared.CollectionService/GetCollection"
 	CollectionService_FindCollectionById_FullMethodName      = "/shared.CollectionService/FindCollectionById"
+	CollectionService_GetCollectionWithCounts_FullMethodName = "/shared.CollectionService/GetCollectionWithCounts"
+	CollectionService_FindCollectionsByIds_FullMethodName    = "/shared.CollectionService/FindCollectionsByIds"
 	CollectionService_AddCollection_FullMethodName           = "/shared.CollectionService/AddCollection"
 	CollectionService_UpdateCollection_FullMethodName        = "/shared.CollectionService/UpdateCollection"
 	CollectionService_DeleteCollection_FullMethodName        = "/shared.CollectionService/DeleteCollection"
-	CollectionService_DecrementAvailableBooks_FullMethodName = "/shared.CollectionService/DecrementAvailableBooks"
+	CollectionService_AdjustBookStock_FullMethodName         = "/shared.CollectionService/AdjustBookStock"
+	CollectionService_SetSeedStatus_FullMethodName           = "/shared.CollectionService/SetSeedStatus"
+	CollectionService_SearchCollections_FullMethodName       = "/shared.CollectionService/SearchCollections"
+	CollectionService_GetCategories_FullMethodName           = "/shared.CollectionService/GetCategories"
 )
 
 // CollectionServiceClient is the client API for CollectionService service.
@@ -33,10 +39,15 @@ const (
 type CollectionServiceClient interface {
 	GetCollection(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*Response, error)
 	FindCollectionById(ctx context.Context, in *FindCollectionRequest, opts ...grpc.CallOption) (*Response, error)
+	GetCollectionWithCounts(ctx context.Context, in *FindCollectionRequest, opts ...grpc.CallOption) (*Response, error)
+	FindCollectionsByIds(ctx context.Context, in *FindCollectionsByIdsRequest, opts ...grpc.CallOption) (*Response, error)
 	AddCollection(ctx context.Context, in *AddCollectionRequest, opts ...grpc.CallOption) (*Response, error)
 	UpdateCollection(ctx context.Context, in *UpdateCollectionRequest, opts ...grpc.CallOption) (*Response, error)
 	DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*Response, error)
-	DecrementAvailableBooks(ctx context.Context, in *DecrementAvailableBooksRequest, opts ...grpc.CallOption) (*Response, error)
+	AdjustBookStock(ctx context.Context, in *AdjustBookStockRequest, opts ...grpc.CallOption) (*Response, error)
+	SetSeedStatus(ctx context.Context, in *SetSeedStatusRequest, opts ...grpc.CallOption) (*Response, error)
+	SearchCollections(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*Response, error)
+	GetCategories(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CategoriesResponse, error)
 }
 
 type collectionServiceClient struct {
@@ -67,6 +78,26 @@ func (c *collectionServiceClient) FindCollectionById(ctx context.Context, in *Fi
 	return out, nil
 }
 
+func (c *collectionServiceClient) GetCollectionWithCounts(ctx context.Context, in *FindCollectionRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, CollectionService_GetCollectionWithCounts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectionServiceClient) FindCollectionsByIds(ctx context.Context, in *FindCollectionsByIdsRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, CollectionService_FindCollectionsByIds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *collectionServiceClient) AddCollection(ctx context.Context, in *AddCollectionRequest, opts ...grpc.CallOption) (*Response, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Response)
@@ -97,10 +128,40 @@ func (c *collectionServiceClient) DeleteCollection(ctx context.Context, in *Dele
 	return out, nil
 }
 
-func (c *collectionServiceClient) DecrementAvailableBooks(ctx context.Context, in *DecrementAvailableBooksRequest, opts ...grpc.CallOption) (*Response, error) {
+func (c *collectionServiceClient) AdjustBookStock(ctx context.Context, in *AdjustBookStockRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, CollectionService_AdjustBookStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectionServiceClient) SetSeedStatus(ctx context.Context, in *SetSeedStatusRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, CollectionService_SetSeedStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectionServiceClient) SearchCollections(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*Response, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Response)
-	err := c.cc.Invoke(ctx, CollectionService_DecrementAvailableBooks_FullMethodName, in, out, cOpts...)
+	err := c.cc.Invoke(ctx, CollectionService_SearchCollections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectionServiceClient) GetCategories(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CategoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoriesResponse)
+	err := c.cc.Invoke(ctx, CollectionService_GetCategories_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -113,10 +174,15 @@ func (c *collectionServiceClient) DecrementAvailableBooks(ctx context.Context, i
 type CollectionServiceServer interface {
 	GetCollection(context.Context, *GetCollectionRequest) (*Response, error)
 	FindCollectionById(context.Context, *FindCollectionRequest) (*Response, error)
+	GetCollectionWithCounts(context.Context, *FindCollectionRequest) (*Response, error)
+	FindCollectionsByIds(context.Context, *FindCollectionsByIdsRequest) (*Response, error)
 	AddCollection(context.Context, *AddCollectionRequest) (*Response, error)
 	UpdateCollection(context.Context, *UpdateCollectionRequest) (*Response, error)
 	DeleteCollection(context.Context, *DeleteCollectionRequest) (*Response, error)
-	DecrementAvailableBooks(context.Context, *DecrementAvailableBooksRequest) (*Response, error)
+	AdjustBookStock(context.Context, *AdjustBookStockRequest) (*Response, error)
+	SetSeedStatus(context.Context, *SetSeedStatusRequest) (*Response, error)
+	SearchCollections(context.Context, *SearchRequest) (*Response, error)
+	GetCategories(context.Context, *emptypb.Empty) (*CategoriesResponse, error)
 	mustEmbedUnimplementedCollectionServiceServer()
 }
 
@@ -133,6 +199,12 @@ func (UnimplementedCollectionServiceServer) GetCollection(context.Context, *GetC
 func (UnimplementedCollectionServiceServer) FindCollectionById(context.Context, *FindCollectionRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindCollectionById not implemented")
 }
+func (UnimplementedCollectionServiceServer) GetCollectionWithCounts(context.Context, *FindCollectionRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCollectionWithCounts not implemented")
+}
+func (UnimplementedCollectionServiceServer) FindCollectionsByIds(context.Context, *FindCollectionsByIdsRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindCollectionsByIds not implemented")
+}
 func (UnimplementedCollectionServiceServer) AddCollection(context.Context, *AddCollectionRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddCollection not implemented")
 }
@@ -142,8 +214,17 @@ func (UnimplementedCollectionServiceServer) UpdateCollection(context.Context, *U
 func (UnimplementedCollectionServiceServer) DeleteCollection(context.Context, *DeleteCollectionRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteCollection not implemented")
 }
-func (UnimplementedCollectionServiceServer) DecrementAvailableBooks(context.Context, *DecrementAvailableBooksRequest) (*Response, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DecrementAvailableBooks not implemented")
+func (UnimplementedCollectionServiceServer) AdjustBookStock(context.Context, *AdjustBookStockRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustBookStock not implemented")
+}
+func (UnimplementedCollectionServiceServer) SetSeedStatus(context.Context, *SetSeedStatusRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSeedStatus not implemented")
+}
+func (UnimplementedCollectionServiceServer) SearchCollections(context.Context, *SearchRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchCollections not implemented")
+}
+func (UnimplementedCollectionServiceServer) GetCategories(context.Context, *emptypb.Empty) (*CategoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCategories not implemented")
 }
 func (UnimplementedCollectionServiceServer) mustEmbedUnimplementedCollectionServiceServer() {}
 func (UnimplementedCollectionServiceServer) testEmbeddedByValue()                           {}
@@ -202,6 +283,42 @@ func _CollectionService_FindCollectionById_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CollectionService_GetCollectionWithCounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectionServiceServer).GetCollectionWithCounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectionService_GetCollectionWithCounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectionServiceServer).GetCollectionWithCounts(ctx, req.(*FindCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectionService_FindCollectionsByIds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindCollectionsByIdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectionServiceServer).FindCollectionsByIds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectionService_FindCollectionsByIds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectionServiceServer).FindCollectionsByIds(ctx, req.(*FindCollectionsByIdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CollectionService_AddCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddCollectionRequest)
 	if err := dec(in); err != nil {
@@ -256,20 +373,74 @@ func _CollectionService_DeleteCollection_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CollectionService_DecrementAvailableBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DecrementAvailableBooksRequest)
+func _CollectionService_AdjustBookStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustBookStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectionServiceServer).AdjustBookStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectionService_AdjustBookStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectionServiceServer).AdjustBookStock(ctx, req.(*AdjustBookStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectionService_SetSeedStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSeedStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectionServiceServer).SetSeedStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectionService_SetSeedStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectionServiceServer).SetSeedStatus(ctx, req.(*SetSeedStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectionService_SearchCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectionServiceServer).SearchCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectionService_SearchCollections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectionServiceServer).SearchCollections(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectionService_GetCategories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CollectionServiceServer).DecrementAvailableBooks(ctx, in)
+		return srv.(CollectionServiceServer).GetCategories(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CollectionService_DecrementAvailableBooks_FullMethodName,
+		FullMethod: CollectionService_GetCategories_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CollectionServiceServer).DecrementAvailableBooks(ctx, req.(*DecrementAvailableBooksRequest))
+		return srv.(CollectionServiceServer).GetCategories(ctx, req.(*emptypb.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -289,6 +460,14 @@ var CollectionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FindCollectionById",
 			Handler:    _CollectionService_FindCollectionById_Handler,
 		},
+		{
+			MethodName: "GetCollectionWithCounts",
+			Handler:    _CollectionService_GetCollectionWithCounts_Handler,
+		},
+		{
+			MethodName: "FindCollectionsByIds",
+			Handler:    _CollectionService_FindCollectionsByIds_Handler,
+		},
 		{
 			MethodName: "AddCollection",
 			Handler:    _CollectionService_AddCollection_Handler,
@@ -302,8 +481,20 @@ var CollectionService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _CollectionService_DeleteCollection_Handler,
 		},
 		{
-			MethodName: "DecrementAvailableBooks",
-			Handler:    _CollectionService_DecrementAvailableBooks_Handler,
+			MethodName: "AdjustBookStock",
+			Handler:    _CollectionService_AdjustBookStock_Handler,
+		},
+		{
+			MethodName: "SetSeedStatus",
+			Handler:    _CollectionService_SetSeedStatus_Handler,
+		},
+		{
+			MethodName: "SearchCollections",
+			Handler:    _CollectionService_SearchCollections_Handler,
+		},
+		{
+			MethodName: "GetCategories",
+			Handler:    _CollectionService_GetCategories_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"encoding/json"
+	"shared/pkg/auth"
+	"shared/pkg/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword_RoundTrip(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse")
+
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+	assert.True(t, auth.CheckPassword(hash, "correct horse"))
+	assert.False(t, auth.CheckPassword(hash, "battery staple"))
+}
+
+func TestHashPassword_SaltsEveryHash(t *testing.T) {
+	first, err := auth.HashPassword("correct horse")
+	require.NoError(t, err)
+	second, err := auth.HashPassword("correct horse")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashPassword_RejectsEmptyAndTooLong(t *testing.T) {
+	_, err := auth.HashPassword("")
+	assert.ErrorIs(t, err, auth.ErrEmptyPassword)
+
+	_, err = auth.HashPassword(strings.Repeat("a", 73))
+	assert.Error(t, err)
+}
+
+func TestCheckPassword_InvalidHash(t *testing.T) {
+	assert.False(t, auth.CheckPassword("not-a-hash", "correct horse"))
+}
+
+func TestUser_SetPasswordStoresHash(t *testing.T) {
+	user := model.User{Username: "ada"}
+
+	require.NoError(t, user.SetPassword("correct horse"))
+
+	assert.NotEqual(t, "correct horse", user.Password)
+	assert.True(t, user.CheckPassword("correct horse"))
+}
+
+func TestUser_JSONOmitsPassword(t *testing.T) {
+	user := model.User{Id: "u1", Username: "ada", Email: "ada@example.com"}
+	require.NoError(t, user.SetPassword("correct horse"))
+
+	for _, value := range []interface{}{user, &user, []model.User{user}} {
+		raw, err := json.Marshal(value)
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(raw), "password")
+		assert.NotContains(t, string(raw), user.Password)
+		assert.Contains(t, string(raw), `"username":"ada"`)
+	}
+}
+
+func TestUser_JSONStillReadsPassword(t *testing.T) {
+	var user model.User
+	require.NoError(t, json.Unmarshal([]byte(`{"username":"ada","password":"correct horse"}`), &user))
+
+	assert.Equal(t, "correct horse", user.Password)
+}
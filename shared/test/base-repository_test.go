@@ -0,0 +1,413 @@
+package test
+
+import (
+	"context"
+	"shared/pkg/model"
+	"shared/pkg/mongotest"
+	"shared/pkg/repository"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestBuildProjection(t *testing.T) {
+	t.Run("no fields", func(t *testing.T) {
+		assert.Nil(t, repository.BuildProjection(nil))
+		assert.Nil(t, repository.BuildProjection([]string{}))
+	})
+
+	t.Run("always includes id", func(t *testing.T) {
+		projection := repository.BuildProjection([]string{"name", " email "})
+
+		assert.Equal(t, bson.M{"_id": 1, "name": 1, "email": 1}, projection)
+	})
+
+	t.Run("skips blank and operator fields", func(t *testing.T) {
+		projection := repository.BuildProjection([]string{"name", "", "$where"})
+
+		assert.Equal(t, bson.M{"_id": 1, "name": 1}, projection)
+	})
+}
+
+// Fields a find command asked the server to return, sorted
+func projectedFields(t *testing.T, command bson.Raw) []string {
+	elements, err := command.Lookup("projection").Document().Elements()
+	require.NoError(t, err)
+
+	fields := make([]string, 0, len(elements))
+	for _, element := range elements {
+		fields = append(fields, element.Key())
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestGetAll_SendsProjection(t *testing.T) {
+	commands := make(chan bson.Raw, 1)
+	repo := newFakeRepository(t, func(command bson.Raw) bson.D {
+		commands <- command
+		// What Mongo returns for ?fields=name
+		return mongotest.CursorReply("test.books", bson.D{{Key: "_id", Value: "123"}, {Key: "name", Value: "John"}})
+	})
+
+	results, err := repo.GetAll(context.Background(), bson.M{}, bson.D{}, 0, 10, "name")
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, bson.M{"_id": "123", "name": "John"}, results[0])
+
+	command := <-commands
+	assert.Equal(t, "books", command.Lookup("find").StringValue())
+	assert.Equal(t, []string{"_id", "name"}, projectedFields(t, command))
+}
+
+func TestListWithTotal_TotalIndependentOfPageSize(t *testing.T) {
+	stored := []interface{}{
+		bson.D{{Key: "_id", Value: "1"}, {Key: "name", Value: "John"}},
+		bson.D{{Key: "_id", Value: "2"}, {Key: "name", Value: "John"}},
+		bson.D{{Key: "_id", Value: "3"}, {Key: "name", Value: "John"}},
+	}
+	counts := make(chan bson.Raw, 2)
+	repo := newFakeRepository(t, func(command bson.Raw) bson.D {
+		if _, ok := command.Lookup("find").StringValueOK(); ok {
+			limit := int(command.Lookup("limit").AsInt64())
+			return mongotest.CursorReply("test.books", stored[:limit]...)
+		}
+		// CountDocuments runs as an aggregation
+		counts <- command
+		return mongotest.CursorReply("test.books", bson.D{{Key: "_id", Value: 1}, {Key: "n", Value: int32(len(stored))}})
+	})
+	ctx := context.Background()
+	filter := bson.M{"name": "John"}
+
+	smallPage, smallTotal, err := repo.ListWithTotal(ctx, filter, bson.D{}, 1, 2)
+	require.NoError(t, err)
+	fullPage, fullTotal, err := repo.ListWithTotal(ctx, filter, bson.D{}, 0, 3)
+	require.NoError(t, err)
+
+	assert.Len(t, smallPage, 2)
+	assert.Len(t, fullPage, 3)
+	assert.Equal(t, int64(3), smallTotal)
+	assert.Equal(t, smallTotal, fullTotal)
+
+	// The count matches on the same filter and never skips or limits
+	command := <-counts
+	assert.Equal(t, []string{"$match", "$group"}, pipelineStages(t, command))
+	assert.Equal(t, "John", command.Lookup("pipeline", "0", "$match", "name").StringValue())
+	assert.Equal(t, bson.TypeNull, command.Lookup("pipeline", "0", "$match", "deleted_at").Type)
+}
+
+func TestGetAllCursor_ProjectsSortKeysWithoutTouchingFields(t *testing.T) {
+	commands := make(chan bson.Raw, 1)
+	repo := newFakeRepository(t, func(command bson.Raw) bson.D {
+		commands <- command
+		return mongotest.CursorReply("test.books")
+	})
+
+	// Spare capacity after the passed fields, appending to them in place
+	// would overwrite "author"
+	fields := []string{"name", "author"}
+	_, _, err := repo.GetAllCursor(context.Background(), bson.M{}, bson.D{{Key: "created_at", Value: -1}}, "", 10, fields[:1]...)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "author"}, fields)
+	assert.Equal(t, []string{"_id", "created_at", "name"}, projectedFields(t, <-commands))
+}
+
+func TestExcludeDeleted(t *testing.T) {
+	filter := bson.M{"name": "Dune"}
+
+	t.Run("normal reads skip soft-deleted documents", func(t *testing.T) {
+		scoped := repository.ExcludeDeleted(context.Background(), filter)
+
+		assert.Equal(t, bson.M{"name": "Dune", repository.DeletedAtField: nil}, scoped)
+		assert.Equal(t, bson.M{"name": "Dune"}, filter)
+	})
+
+	t.Run("include deleted returns soft-deleted documents", func(t *testing.T) {
+		ctx := repository.WithDeleted(context.Background())
+		scoped := repository.ExcludeDeleted(ctx, filter)
+
+		assert.True(t, repository.IncludesDeleted(ctx))
+		assert.NotContains(t, scoped, repository.DeletedAtField)
+	})
+
+	t.Run("explicit deleted_at filter is kept", func(t *testing.T) {
+		explicit := bson.M{repository.DeletedAtField: bson.M{"$ne": nil}}
+		scoped := repository.ExcludeDeleted(context.Background(), explicit)
+
+		assert.Equal(t, explicit, scoped)
+	})
+}
+
+// newUnreachableRepository returns a repository whose queries fail fast, for
+// tests that only care about what happens before the query reaches the server.
+func newUnreachableRepository(t *testing.T) *repository.BaseRepository[bson.M] {
+	client, err := mongo.Connect(options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(10 * time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return repository.NewRepository[bson.M](client.Database("test"), "items")
+}
+
+func TestFind_DoesNotMutateFilter(t *testing.T) {
+	repo := newUnreachableRepository(t)
+	id := primitive.NewObjectID()
+
+	t.Run("string id", func(t *testing.T) {
+		filter := bson.M{"_id": id.Hex(), "name": "Dune"}
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.Find(context.Background(), filter)
+			require.Error(t, err)
+			assert.Equal(t, bson.M{"_id": id.Hex(), "name": "Dune"}, filter)
+		}
+	})
+
+	t.Run("typed id", func(t *testing.T) {
+		filter := bson.M{"_id": id}
+
+		_, err := repo.Find(context.Background(), filter)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, primitive.ErrInvalidHex)
+		assert.Equal(t, bson.M{"_id": id}, filter)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := repo.Find(context.Background(), bson.M{"_id": "not-an-id"})
+		assert.ErrorIs(t, err, primitive.ErrInvalidHex)
+	})
+}
+
+func TestBuildUpsertDocument(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-24 * time.Hour)
+	book := model.Book{
+		Id:           primitive.NewObjectID(),
+		CollectionId: primitive.NewObjectID(),
+		IsBorrowed:   true,
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+
+	update := repository.BuildUpsertDocument(book, now)
+
+	t.Run("insert on miss sets created_at once", func(t *testing.T) {
+		assert.Equal(t, bson.M{"created_at": now}, update["$setOnInsert"])
+	})
+
+	t.Run("update on hit overwrites fields but keeps created_at", func(t *testing.T) {
+		set := update["$set"].(bson.M)
+
+		assert.Equal(t, book.CollectionId, set["collection_id"])
+		assert.Equal(t, true, set["is_borrowed"])
+		assert.Equal(t, now, set["updated_at"])
+		assert.NotContains(t, set, "created_at")
+		assert.NotContains(t, set, "_id")
+	})
+
+	t.Run("unset omitempty fields are left alone", func(t *testing.T) {
+		assert.NotContains(t, update["$set"].(bson.M), repository.DeletedAtField)
+	})
+}
+
+func TestObjectIdsFromHex(t *testing.T) {
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+
+	t.Run("mix of valid and invalid ids", func(t *testing.T) {
+		ids, skipped := repository.ObjectIdsFromHex([]string{first.Hex(), "nope", " " + second.Hex() + " ", "", first.Hex()})
+
+		assert.Equal(t, []primitive.ObjectID{first, second}, ids)
+		assert.Equal(t, 2, skipped)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		ids, skipped := repository.ObjectIdsFromHex(nil)
+
+		assert.Empty(t, ids)
+		assert.Zero(t, skipped)
+	})
+}
+
+func TestFindByIds(t *testing.T) {
+	repo := newUnreachableRepository(t)
+
+	t.Run("empty list skips the query", func(t *testing.T) {
+		results, err := repo.FindByIds(context.Background(), []string{})
+
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("only invalid ids skips the query", func(t *testing.T) {
+		results, err := repo.FindByIds(context.Background(), []string{"nope", "123"})
+
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("valid ids reach the database", func(t *testing.T) {
+		_, err := repo.FindByIds(context.Background(), []string{primitive.NewObjectID().Hex(), "nope"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestUpdateMany_RefusesUnscopedUpdates(t *testing.T) {
+	repo := newUnreachableRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.UpdateMany(ctx, bson.M{}, map[string]interface{}{"name": "Dune"})
+	assert.EqualError(t, err, "update many requires a filter")
+
+	_, err = repo.UpdateMany(ctx, bson.M{"name": "Dune"}, map[string]interface{}{})
+	assert.EqualError(t, err, "update many requires fields to set")
+
+	_, err = repo.UpdateMany(ctx, bson.M{"name": "Dune"}, map[string]interface{}{"_id": primitive.NewObjectID()})
+	assert.EqualError(t, err, "update many cannot change _id")
+
+	// A scoped update reaches the database
+	_, err = repo.UpdateMany(ctx, bson.M{"name": "Dune"}, map[string]interface{}{"name": "Dune Messiah"})
+	assert.Error(t, err)
+}
+
+func TestStampTimestamps(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+
+	t.Run("zero times are stamped", func(t *testing.T) {
+		book := model.Book{Id: primitive.NewObjectID()}
+		repository.StampTimestamps(&book, now)
+
+		assert.Equal(t, now, book.CreatedAt)
+		assert.Equal(t, now, book.UpdatedAt)
+		assert.Nil(t, book.DeletedAt)
+	})
+
+	t.Run("times already set are kept", func(t *testing.T) {
+		book := model.Book{CreatedAt: created, UpdatedAt: created}
+		repository.StampTimestamps(&book, now)
+
+		assert.Equal(t, created, book.CreatedAt)
+		assert.Equal(t, created, book.UpdatedAt)
+	})
+
+	t.Run("omitempty tags are matched by name", func(t *testing.T) {
+		user := model.User{}
+		repository.StampTimestamps(&user, now)
+
+		assert.Equal(t, now, user.CreatedAt)
+		assert.Equal(t, now, user.UpdatedAt)
+	})
+
+	t.Run("non structs are left alone", func(t *testing.T) {
+		doc := bson.M{}
+		repository.StampTimestamps(&doc, now)
+		repository.StampTimestamps(model.Book{}, now)
+
+		assert.Empty(t, doc)
+	})
+}
+
+func TestUpdateOne_ReplacesSuppliedUpdatedAt(t *testing.T) {
+	repo := newUnreachableRepository(t)
+	update := map[string]interface{}{"name": "Dune", "updated_at": "2024-05-01T12:00:00Z"}
+
+	before := time.Now()
+	_, err := repo.UpdateOne(context.Background(), update, primitive.NewObjectID().Hex())
+	require.Error(t, err)
+
+	// A string from the caller is never what gets stored
+	stamp, ok := update["updated_at"].(time.Time)
+	require.True(t, ok, "updated_at is %T", update["updated_at"])
+	assert.False(t, stamp.Before(before))
+}
+
+func newFakeRepository(t *testing.T, reply func(command bson.Raw) bson.D) *repository.BaseRepository[bson.M] {
+	client, err := mongo.Connect(options.Client().ApplyURI(mongotest.Server(t, reply)))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return repository.NewRepository[bson.M](client.Database("test"), "books")
+}
+
+// Stages of the pipeline an aggregate command ran, by their operator
+func pipelineStages(t *testing.T, command bson.Raw) []string {
+	values, err := command.Lookup("pipeline").Array().Values()
+	require.NoError(t, err)
+
+	stages := make([]string, 0, len(values))
+	for _, value := range values {
+		stages = append(stages, value.Document().Index(0).Key())
+	}
+	return stages
+}
+
+func TestAggregate_DecodesGroupedResults(t *testing.T) {
+	commands := make(chan bson.Raw, 1)
+	repo := newFakeRepository(t, func(command bson.Raw) bson.D {
+		commands <- command
+		return mongotest.CursorReply("test.books",
+			bson.D{{Key: "_id", Value: "c1"}, {Key: "available", Value: int32(3)}},
+			bson.D{{Key: "_id", Value: "c2"}, {Key: "available", Value: int32(1)}},
+		)
+	})
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"is_borrowed": false}}},
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$collection_id"}, {Key: "available", Value: bson.M{"$sum": 1}}}}},
+	}
+	var results []struct {
+		CollectionId string `bson:"_id"`
+		Available    int    `bson:"available"`
+	}
+	require.NoError(t, repo.Aggregate(context.Background(), pipeline, &results))
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "c1", results[0].CollectionId)
+	assert.Equal(t, 3, results[0].Available)
+	assert.Equal(t, "c2", results[1].CollectionId)
+	assert.Equal(t, 1, results[1].Available)
+
+	command := <-commands
+	assert.Equal(t, "books", command.Lookup("aggregate").StringValue())
+	// Soft-deleted documents are matched out ahead of the caller's stages
+	assert.Equal(t, []string{"$match", "$match", "$group"}, pipelineStages(t, command))
+	assert.Equal(t, bson.TypeNull, command.Lookup("pipeline", "0", "$match", "deleted_at").Type)
+}
+
+func TestAggregate_WithDeletedRunsPipelineAsIs(t *testing.T) {
+	commands := make(chan bson.Raw, 1)
+	repo := newFakeRepository(t, func(command bson.Raw) bson.D {
+		commands <- command
+		return mongotest.CursorReply("test.books")
+	})
+
+	pipeline := mongo.Pipeline{{{Key: "$group", Value: bson.D{{Key: "_id", Value: nil}, {Key: "total", Value: bson.M{"$sum": 1}}}}}}
+	var results []bson.M
+	require.NoError(t, repo.Aggregate(repository.WithDeleted(context.Background()), pipeline, &results))
+
+	assert.Empty(t, results)
+	assert.Equal(t, []string{"$group"}, pipelineStages(t, <-commands))
+}
+
+func TestAggregate_CommandErrorIsReturned(t *testing.T) {
+	repo := newFakeRepository(t, func(command bson.Raw) bson.D {
+		return mongotest.ErrorReply(40324, "Unrecognized pipeline stage name: '$bogus'")
+	})
+
+	var results []bson.M
+	err := repo.Aggregate(context.Background(), mongo.Pipeline{{{Key: "$bogus", Value: 1}}}, &results)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "$bogus")
+}
@@ -3,13 +3,18 @@ package test
 import (
 	"context"
 	"errors"
+	"shared/pkg/model"
 	"shared/pkg/repository"
 	"shared/pkg/service"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 // Mock repository for testing
@@ -17,12 +22,22 @@ type MockRepository[K any] struct {
 	mock.Mock
 }
 
-func (m *MockRepository[K]) GetAll(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int) ([]K, error) {
+func (m *MockRepository[K]) GetAll(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]K), args.Error(1)
 }
 
-func (m *MockRepository[K]) Find(ctx context.Context, filter bson.M) (*K, error) {
+func (m *MockRepository[K]) ListWithTotal(ctx context.Context, filter bson.M, sort bson.D, skip int, limit int, fields ...string) ([]K, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]K), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRepository[K]) GetAllCursor(ctx context.Context, filter bson.M, sort bson.D, after string, limit int, fields ...string) ([]K, string, error) {
+	args := m.Called(ctx, after, limit)
+	return args.Get(0).([]K), args.String(1), args.Error(2)
+}
+
+func (m *MockRepository[K]) Find(ctx context.Context, filter bson.M, fields ...string) (*K, error) {
 	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -30,6 +45,11 @@ func (m *MockRepository[K]) Find(ctx context.Context, filter bson.M) (*K, error)
 	return args.Get(0).(*K), args.Error(1)
 }
 
+func (m *MockRepository[K]) FindByIds(ctx context.Context, ids []string) ([]K, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]K), args.Error(1)
+}
+
 func (m *MockRepository[K]) Insert(ctx context.Context, entity K) (interface{}, error) {
 	args := m.Called(ctx, entity)
 	return args.Get(0), args.Error(1)
@@ -45,6 +65,11 @@ func (m *MockRepository[K]) DeleteOne(ctx context.Context, id string) (K, error)
 	return args.Get(0).(K), args.Error(1)
 }
 
+func (m *MockRepository[K]) SoftDelete(ctx context.Context, id string) (K, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(K), args.Error(1)
+}
+
 func (m *MockRepository[K]) DataExists(ctx context.Context, filter bson.M) (bool, error) {
 	args := m.Called(ctx, filter)
 	return args.Bool(0), args.Error(1)
@@ -55,11 +80,42 @@ func (m *MockRepository[K]) Count(ctx context.Context, filter bson.M) (int64, er
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockRepository[K]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error {
+	args := m.Called(ctx, pipeline, results)
+	return args.Error(0)
+}
+
 func (m *MockRepository[K]) BulkInsert(ctx context.Context, entities []K) (interface{}, error) {
 	args := m.Called(ctx, entities)
 	return args.Get(0), args.Error(1)
 }
 
+func (m *MockRepository[K]) BulkDelete(ctx context.Context, filter bson.M) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository[K]) UpdateMany(ctx context.Context, filter bson.M, update map[string]interface{}) (int64, error) {
+	args := m.Called(ctx, filter, update)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository[K]) Upsert(ctx context.Context, data K, filter bson.M) (*mongo.UpdateResult, error) {
+	args := m.Called(ctx, data, filter)
+	if result, ok := args.Get(0).(*mongo.UpdateResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepository[K]) WithTransaction(ctx context.Context, fn func(sessCtx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
 // Mock validation service for testing
 type MockValidationService[K any, V any] struct {
 	mock.Mock
@@ -70,6 +126,14 @@ func (m *MockValidationService[K, V]) Validate(entity K) error {
 	return args.Error(0)
 }
 
+func (m *MockValidationService[K, V]) ValidateWithDetails(entity K) (map[string]string, error) {
+	args := m.Called(entity)
+	if details, ok := args.Get(0).(map[string]string); ok {
+		return details, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockValidationService[K, V]) ValidateUpdateRequest(payload map[string]interface{}) (map[string]interface{}, error) {
 	args := m.Called(payload)
 	return args.Get(0).(map[string]interface{}), args.Error(1)
@@ -140,6 +204,80 @@ func TestBaseService_List(t *testing.T) {
 	})
 }
 
+func TestBaseService_ListWithTotal(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	ctx := context.Background()
+	filter := bson.M{"name": "John"}
+
+	t.Run("page and total", func(t *testing.T) {
+		users := []User{{ID: "1", Name: "John", Email: "john@example.com"}}
+		mockRepo.On("ListWithTotal", ctx, filter).Return(users, int64(3), nil).Once()
+
+		page, total, err := service.ListWithTotal(ctx, filter, bson.D{}, 0, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, users, page)
+		assert.Equal(t, int64(3), total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("count error", func(t *testing.T) {
+		mockRepo.On("ListWithTotal", ctx, filter).Return([]User{}, int64(0), errors.New("count failed")).Once()
+
+		_, total, err := service.ListWithTotal(ctx, filter, bson.D{}, 0, 10)
+
+		assert.Error(t, err)
+		assert.Zero(t, total)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestBaseService_ListCursor(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	ctx := context.Background()
+
+	firstPage := []User{
+		{ID: "1", Name: "John", Email: "john@example.com"},
+		{ID: "2", Name: "Jane", Email: "jane@example.com"},
+	}
+	lastPage := []User{
+		{ID: "3", Name: "Jack", Email: "jack@example.com"},
+	}
+
+	t.Run("first page returns next cursor", func(t *testing.T) {
+		mockRepo.On("GetAllCursor", ctx, "", 2).Return(firstPage, "cursor-2", nil).Once()
+
+		result, next, err := service.ListCursor(ctx, bson.M{}, bson.D{}, "", 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, firstPage, result)
+		assert.Equal(t, "cursor-2", next)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("last page returns empty cursor", func(t *testing.T) {
+		mockRepo.On("GetAllCursor", ctx, "cursor-2", 2).Return(lastPage, "", nil).Once()
+
+		result, next, err := service.ListCursor(ctx, bson.M{}, bson.D{}, "cursor-2", 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, lastPage, result)
+		assert.Empty(t, next)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo.On("GetAllCursor", ctx, "bad", 2).Return([]User{}, "", errors.New("invalid cursor")).Once()
+
+		result, next, err := service.ListCursor(ctx, bson.M{}, bson.D{}, "bad", 2)
+
+		assert.Error(t, err)
+		assert.Empty(t, result)
+		assert.Empty(t, next)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestBaseService_FindById(t *testing.T) {
 	service, mockRepo, _ := setupTestService()
 	ctx := context.Background()
@@ -201,7 +339,7 @@ func TestBaseService_Create(t *testing.T) {
 	user := User{ID: "123", Name: "John", Email: "john@example.com"}
 
 	t.Run("successful create", func(t *testing.T) {
-		mockValidator.On("Validate", user).Return(nil).Once()
+		mockValidator.On("ValidateWithDetails", user).Return(nil, nil).Once()
 		mockRepo.On("Insert", ctx, user).Return("123", nil).Once()
 
 		err := service.Create(ctx, user)
@@ -213,7 +351,7 @@ func TestBaseService_Create(t *testing.T) {
 
 	t.Run("validation error", func(t *testing.T) {
 		validationErr := errors.New("validation failed")
-		mockValidator.On("Validate", user).Return(validationErr).Once()
+		mockValidator.On("ValidateWithDetails", user).Return(nil, validationErr).Once()
 
 		err := service.Create(ctx, user)
 
@@ -226,7 +364,7 @@ func TestBaseService_Create(t *testing.T) {
 
 	t.Run("repository error", func(t *testing.T) {
 		repoErr := errors.New("database error")
-		mockValidator.On("Validate", user).Return(nil).Once()
+		mockValidator.On("ValidateWithDetails", user).Return(nil, nil).Once()
 		mockRepo.On("Insert", ctx, user).Return(nil, repoErr).Once()
 
 		err := service.Create(ctx, user)
@@ -238,6 +376,22 @@ func TestBaseService_Create(t *testing.T) {
 	})
 }
 
+func TestBaseService_CreateReturnsFieldDetails(t *testing.T) {
+	baseService, mockRepo, mockValidator := setupTestService()
+	user := User{ID: "123", Name: "John", Email: "not-an-email"}
+	validationErr := errors.New("validation failed")
+	details := map[string]string{"email": "email must be a valid email address"}
+	mockValidator.On("ValidateWithDetails", user).Return(details, validationErr).Once()
+
+	err := baseService.Create(context.Background(), user)
+
+	var fieldErr *service.ValidationError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, details, fieldErr.Fields)
+	assert.ErrorIs(t, err, validationErr)
+	mockRepo.AssertNotCalled(t, "Insert")
+}
+
 func TestBaseService_Update(t *testing.T) {
 	service, mockRepo, mockValidator := setupTestService()
 	ctx := context.Background()
@@ -317,6 +471,22 @@ func TestBaseService_Delete(t *testing.T) {
 	})
 }
 
+func TestBaseService_SoftDelete(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	ctx := context.Background()
+	userID := "123"
+	deletedUser := User{ID: userID, Name: "John", Email: "john@example.com"}
+
+	mockRepo.On("SoftDelete", ctx, userID).Return(deletedUser, nil).Once()
+
+	result, err := service.SoftDelete(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, deletedUser, result)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "DeleteOne", ctx, userID)
+}
+
 func TestBaseService_Exists(t *testing.T) {
 	service, mockRepo, _ := setupTestService()
 	ctx := context.Background()
@@ -460,3 +630,213 @@ func TestBaseService_BulkInsert(t *testing.T) {
 		mockValidator.AssertNotCalled(t, "Validate")
 	})
 }
+
+func TestBaseService_Upsert(t *testing.T) {
+	service, mockRepo, mockValidator := setupTestService()
+	ctx := context.Background()
+	user := User{ID: "123", Name: "John", Email: "john@example.com"}
+	filter := bson.M{"email": user.Email}
+
+	t.Run("insert on miss", func(t *testing.T) {
+		mockValidator.On("ValidateWithDetails", user).Return(nil, nil).Once()
+		mockRepo.On("Upsert", ctx, user, filter).Return(&mongo.UpdateResult{UpsertedCount: 1}, nil).Once()
+
+		err := service.Upsert(ctx, user, filter)
+
+		assert.NoError(t, err)
+		mockValidator.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("update on hit", func(t *testing.T) {
+		mockValidator.On("ValidateWithDetails", user).Return(nil, nil).Once()
+		mockRepo.On("Upsert", ctx, user, filter).Return(&mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil).Once()
+
+		err := service.Upsert(ctx, user, filter)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		service, mockRepo, mockValidator := setupTestService()
+		validationErr := errors.New("validation failed")
+		mockValidator.On("ValidateWithDetails", user).Return(nil, validationErr).Once()
+
+		err := service.Upsert(ctx, user, filter)
+
+		assert.Equal(t, validationErr, err)
+		mockRepo.AssertNotCalled(t, "Upsert")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repoErr := errors.New("database error")
+		mockValidator.On("ValidateWithDetails", user).Return(nil, nil).Once()
+		mockRepo.On("Upsert", ctx, user, filter).Return(nil, repoErr).Once()
+
+		err := service.Upsert(ctx, user, filter)
+
+		assert.Equal(t, repoErr, err)
+	})
+}
+
+func TestBaseService_UpdateMany(t *testing.T) {
+	service, mockRepo, mockValidator := setupTestService()
+	ctx := context.Background()
+	filter := bson.M{"name": "John"}
+	update := map[string]interface{}{"email": "john@example.com"}
+
+	t.Run("updates every match", func(t *testing.T) {
+		mockValidator.On("ValidateUpdateRequest", update).Return(update, nil).Once()
+		mockRepo.On("UpdateMany", ctx, filter, update).Return(int64(3), nil).Once()
+
+		modified, err := service.UpdateMany(ctx, filter, update)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), modified)
+		mockValidator.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		mockValidator.On("ValidateUpdateRequest", update).Return(update, nil).Once()
+		mockRepo.On("UpdateMany", ctx, filter, update).Return(int64(0), nil).Once()
+
+		modified, err := service.UpdateMany(ctx, filter, update)
+
+		assert.NoError(t, err)
+		assert.Zero(t, modified)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		service, mockRepo, mockValidator := setupTestService()
+		validationErr := errors.New("validation failed")
+		mockValidator.On("ValidateUpdateRequest", update).Return(map[string]interface{}{}, validationErr).Once()
+
+		modified, err := service.UpdateMany(ctx, filter, update)
+
+		assert.Equal(t, validationErr, err)
+		assert.Zero(t, modified)
+		mockRepo.AssertNotCalled(t, "UpdateMany")
+	})
+}
+
+func TestBaseService_WithTransaction(t *testing.T) {
+	service, mockRepo, mockValidator := setupTestService()
+	ctx := context.Background()
+	user := User{ID: "123", Name: "John", Email: "john@example.com"}
+
+	t.Run("runs writes inside the transaction", func(t *testing.T) {
+		mockRepo.On("WithTransaction", ctx).Return(nil).Once()
+		mockValidator.On("ValidateWithDetails", user).Return(nil, nil).Once()
+		mockRepo.On("Insert", ctx, user).Return("123", nil).Once()
+
+		err := service.WithTransaction(ctx, func(sessCtx context.Context) error {
+			return service.Create(sessCtx, user)
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("propagates callback error", func(t *testing.T) {
+		txErr := errors.New("insert failed")
+		mockRepo.On("WithTransaction", ctx).Return(nil).Once()
+
+		err := service.WithTransaction(ctx, func(sessCtx context.Context) error {
+			return txErr
+		})
+
+		assert.Equal(t, txErr, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("session error skips callback", func(t *testing.T) {
+		sessionErr := errors.New("transactions not supported")
+		mockRepo.On("WithTransaction", ctx).Return(sessionErr).Once()
+
+		called := false
+		err := service.WithTransaction(ctx, func(sessCtx context.Context) error {
+			called = true
+			return nil
+		})
+
+		assert.Equal(t, sessionErr, err)
+		assert.False(t, called)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateIfMatch(t *testing.T) {
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+	readAt := time.UnixMilli(time.Now().UnixMilli())
+	update := map[string]interface{}{"email": "john@example.com"}
+	conditional := bson.M{"_id": id, "updated_at": readAt}
+
+	t.Run("matching etag updates", func(t *testing.T) {
+		svc, mockRepo, mockValidator := setupTestService()
+		updated := &User{Name: "John", Email: "john@example.com"}
+		mockValidator.On("ValidateUpdateRequest", update).Return(update, nil).Once()
+		mockRepo.On("UpdateMany", ctx, conditional, update).Return(int64(1), nil).Once()
+		mockRepo.On("Find", ctx, bson.M{"_id": id.Hex()}).Return(updated, nil).Once()
+
+		user, err := service.UpdateIfMatch(ctx, svc, update, id.Hex(), model.ETag(readAt))
+
+		require.NoError(t, err)
+		assert.Equal(t, *updated, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stale etag", func(t *testing.T) {
+		svc, mockRepo, mockValidator := setupTestService()
+		mockValidator.On("ValidateUpdateRequest", update).Return(update, nil).Once()
+		mockRepo.On("UpdateMany", ctx, conditional, update).Return(int64(0), nil).Once()
+		mockRepo.On("Find", ctx, bson.M{"_id": id.Hex()}).Return(&User{Name: "John"}, nil).Once()
+
+		_, err := service.UpdateIfMatch(ctx, svc, update, id.Hex(), model.ETag(readAt))
+
+		assert.ErrorIs(t, err, service.ErrStaleETag)
+	})
+
+	t.Run("malformed etag never matches", func(t *testing.T) {
+		svc, mockRepo, _ := setupTestService()
+		mockRepo.On("Find", ctx, bson.M{"_id": id.Hex()}).Return(&User{Name: "John"}, nil).Once()
+
+		_, err := service.UpdateIfMatch(ctx, svc, update, id.Hex(), "not-an-etag")
+
+		assert.ErrorIs(t, err, service.ErrStaleETag)
+		mockRepo.AssertNotCalled(t, "UpdateMany", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing document", func(t *testing.T) {
+		svc, mockRepo, mockValidator := setupTestService()
+		mockValidator.On("ValidateUpdateRequest", update).Return(update, nil).Once()
+		mockRepo.On("UpdateMany", ctx, conditional, update).Return(int64(0), nil).Once()
+		mockRepo.On("Find", ctx, bson.M{"_id": id.Hex()}).Return(nil, mongo.ErrNoDocuments).Once()
+
+		_, err := service.UpdateIfMatch(ctx, svc, update, id.Hex(), model.ETag(readAt))
+
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+}
+
+func TestETag_RoundTrip(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 10, 30, 0, 123456789, time.UTC)
+
+	etag := model.ETag(updatedAt)
+	assert.True(t, etag[0] == '"' && etag[len(etag)-1] == '"', "etag %s isn't quoted", etag)
+
+	parsed, err := model.ParseETag(etag)
+	require.NoError(t, err)
+	assert.True(t, parsed.Equal(updatedAt.Truncate(time.Millisecond)))
+
+	weak, err := model.ParseETag("W/" + etag)
+	require.NoError(t, err)
+	assert.True(t, weak.Equal(parsed))
+
+	assert.NotEqual(t, etag, model.ETag(updatedAt.Add(time.Millisecond)))
+	_, err = model.ParseETag("abc")
+	assert.ErrorIs(t, err, model.ErrInvalidETag)
+}
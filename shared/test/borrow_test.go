@@ -0,0 +1,219 @@
+package test
+
+import (
+	"context"
+	"shared/pkg/model"
+	"shared/pkg/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newBorrow(borrowDate time.Time, dueDate *time.Time) model.Borrow {
+	return model.Borrow{
+		Id:           primitive.NewObjectID(),
+		BookId:       primitive.NewObjectID(),
+		UserId:       primitive.NewObjectID(),
+		CollectionId: primitive.NewObjectID(),
+		BorrowDate:   borrowDate,
+		DueDate:      dueDate,
+		CreatedAt:    borrowDate,
+		UpdatedAt:    borrowDate,
+	}
+}
+
+func TestBorrowCreate_ValidatesDueDate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	due := now.AddDate(0, 0, 7)
+	overdue := now.AddDate(0, 0, -1)
+
+	t.Run("due date after borrow date", func(t *testing.T) {
+		repo := &MockRepository[model.Borrow]{}
+		borrow := newBorrow(now, &due)
+		repo.On("Insert", ctx, borrow).Return(borrow.Id, nil).Once()
+
+		err := service.NewBaseService[model.Borrow, model.BorrowUpdateRequest](repo).Create(ctx, borrow)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("due date before borrow date", func(t *testing.T) {
+		repo := &MockRepository[model.Borrow]{}
+
+		err := service.NewBaseService[model.Borrow, model.BorrowUpdateRequest](repo).Create(ctx, newBorrow(now, &overdue))
+
+		require.Error(t, err)
+		repo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing due date", func(t *testing.T) {
+		repo := &MockRepository[model.Borrow]{}
+
+		err := service.NewBaseService[model.Borrow, model.BorrowUpdateRequest](repo).Create(ctx, newBorrow(now, nil))
+
+		require.Error(t, err)
+		repo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	})
+}
+
+func TestToPbBorrow_NilDueDate(t *testing.T) {
+	borrow := newBorrow(time.Now().UTC(), nil)
+
+	pbBorrow := model.ToPbBorrow(&borrow)
+
+	require.NotNil(t, pbBorrow)
+	assert.Empty(t, pbBorrow.DueDate)
+	assert.Empty(t, pbBorrow.ReturnDate)
+}
+
+func TestBorrowConversion_RoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	due := now.AddDate(0, 0, 7)
+	returned := now.Add(48 * time.Hour)
+
+	active := newBorrow(now, &due)
+	active.RenewalCount = 1
+
+	completed := newBorrow(now, &due)
+	completed.ReturnDate = &returned
+	returnedAt := returned.Add(123456789 * time.Nanosecond)
+	completed.ReturnedAt = &returnedAt
+	completed.ReturnedBy = "librarian-7"
+
+	noDueDate := newBorrow(now, nil)
+
+	for name, borrow := range map[string]model.Borrow{
+		"without return date": active,
+		"with return date":    completed,
+		"without due date":    noDueDate,
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := model.FromPbBorrow(model.ToPbBorrow(&borrow))
+
+			require.NotNil(t, got)
+			// Status is computed on the way out, it is the only field the source lacks
+			borrow.Status = borrow.StatusAt(time.Now())
+			assert.Equal(t, borrow, *got)
+		})
+	}
+}
+
+func TestFromPbBorrow_EmptyDatesStayNil(t *testing.T) {
+	borrow := newBorrow(time.Now().UTC(), nil)
+	pbBorrow := model.ToPbBorrow(&borrow)
+
+	got := model.FromPbBorrow(pbBorrow)
+
+	require.NotNil(t, got)
+	assert.Nil(t, got.DueDate)
+	assert.Nil(t, got.ReturnDate)
+}
+
+func TestFromPbBorrow_InvalidReturnDate(t *testing.T) {
+	due := time.Now().UTC().AddDate(0, 0, 7)
+	borrow := newBorrow(time.Now().UTC(), &due)
+	pbBorrow := model.ToPbBorrow(&borrow)
+	pbBorrow.ReturnDate = "yesterday"
+
+	assert.Nil(t, model.FromPbBorrow(pbBorrow))
+}
+
+func TestBorrowIsOverdue(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	tomorrow := now.AddDate(0, 0, 1)
+
+	overdue := newBorrow(now.AddDate(0, 0, -8), &yesterday)
+	assert.True(t, overdue.IsOverdue(now))
+
+	notDue := newBorrow(now, &tomorrow)
+	assert.False(t, notDue.IsOverdue(now))
+
+	returned := newBorrow(now.AddDate(0, 0, -8), &yesterday)
+	returned.ReturnDate = &now
+	assert.False(t, returned.IsOverdue(now))
+
+	noDueDate := newBorrow(now, nil)
+	assert.False(t, noDueDate.IsOverdue(now))
+}
+
+func TestBorrowStatus(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	tomorrow := now.AddDate(0, 0, 1)
+
+	returned := newBorrow(now.AddDate(0, 0, -8), &yesterday)
+	returned.ReturnDate = &now
+	dueNow := newBorrow(now.AddDate(0, 0, -7), &now)
+
+	cases := map[string]struct {
+		borrow model.Borrow
+		want   string
+	}{
+		"active before due date":  {newBorrow(now, &tomorrow), model.BorrowStatusActive},
+		"active without due date": {newBorrow(now, nil), model.BorrowStatusActive},
+		"active when due now":     {dueNow, model.BorrowStatusActive},
+		"overdue after due date":  {newBorrow(now.AddDate(0, 0, -8), &yesterday), model.BorrowStatusOverdue},
+		"returned past due date":  {returned, model.BorrowStatusReturned},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.borrow.StatusAt(now))
+			assert.Equal(t, tc.want == model.BorrowStatusOverdue, tc.borrow.IsOverdue(now))
+		})
+	}
+}
+
+func TestToPbBorrow_Status(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	tomorrow := now.AddDate(0, 0, 1)
+
+	active := newBorrow(now, &tomorrow)
+	assert.Equal(t, model.BorrowStatusActive, model.ToPbBorrow(&active).Status)
+
+	overdue := newBorrow(now.AddDate(0, 0, -8), &yesterday)
+	assert.Equal(t, model.BorrowStatusOverdue, model.ToPbBorrow(&overdue).Status)
+
+	overdue.ReturnDate = &now
+	assert.Equal(t, model.BorrowStatusReturned, model.ToPbBorrow(&overdue).Status)
+}
+
+func TestBorrowDaysOverdue(t *testing.T) {
+	now := time.Now().UTC()
+	due := now.AddDate(0, 0, -2)
+
+	returnedAt := func(at time.Time) model.Borrow {
+		borrow := newBorrow(now.AddDate(0, 0, -9), &due)
+		borrow.ReturnDate = &at
+		return borrow
+	}
+	outstanding := newBorrow(now.AddDate(0, 0, -9), &due)
+	noDueDate := newBorrow(now, nil)
+
+	cases := map[string]struct {
+		borrow model.Borrow
+		want   int
+	}{
+		"returned before due":         {returnedAt(due.Add(-time.Minute)), 0},
+		"returned at due date":        {returnedAt(due), 0},
+		"returned a minute late":      {returnedAt(due.Add(time.Minute)), 1},
+		"returned exactly a day late": {returnedAt(due.AddDate(0, 0, 1)), 1},
+		"returned a day and a bit":    {returnedAt(due.Add(25 * time.Hour)), 2},
+		"outstanding counts to now":   {outstanding, 2},
+		"without due date":            {noDueDate, 0},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.borrow.DaysOverdue(now))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"context"
+	"net"
+	"shared/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns an address nothing is listening on
+func closedAddr(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+	return addr
+}
+
+func TestCacheGuard_FailsFastWhileDown(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: closedAddr(t), MaxRetries: -1})
+	defer client.Close()
+	guard := utils.GuardCache(client, time.Minute)
+	ctx := context.Background()
+
+	err := client.Get(ctx, "book:1").Err()
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, utils.ErrCacheUnavailable)
+	assert.False(t, guard.Available())
+	assert.Equal(t, int64(1), guard.Failures())
+
+	// Further commands skip Redis entirely
+	assert.ErrorIs(t, client.Get(ctx, "book:1").Err(), utils.ErrCacheUnavailable)
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "book:1", "{}", time.Hour)
+		return nil
+	})
+	assert.ErrorIs(t, err, utils.ErrCacheUnavailable)
+	assert.Equal(t, int64(1), guard.Failures())
+}
+
+func TestCacheGuard_RetriesAfterPause(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: closedAddr(t), MaxRetries: -1})
+	defer client.Close()
+	guard := utils.GuardCache(client, 50*time.Millisecond)
+	ctx := context.Background()
+
+	require.Error(t, client.Ping(ctx).Err())
+	require.False(t, guard.Available())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, guard.Available())
+
+	// The next command reaches for Redis again and fails with a connection error
+	err := client.Ping(ctx).Err()
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, utils.ErrCacheUnavailable)
+	assert.Equal(t, int64(2), guard.Failures())
+}
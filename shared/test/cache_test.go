@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"shared/pkg/model"
+	"shared/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMiniredis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+func TestSetCachedData_RoundTrips(t *testing.T) {
+	mr, client := newMiniredis(t)
+	ctx := context.Background()
+	collection := model.NewCollection()
+	collection.Name = "The Hobbit"
+
+	require.NoError(t, utils.SetCachedData(ctx, client, "collection:1", collection, time.Minute))
+
+	cached, found := utils.GetCachedData[model.Collection](ctx, client, "collection:1")
+	require.True(t, found)
+	assert.Equal(t, "The Hobbit", cached.Name)
+	assert.Equal(t, collection.Id, cached.Id)
+	assert.Equal(t, time.Minute, mr.TTL("collection:1"))
+}
+
+func TestSetCachedData_MarshalFailureLeavesCacheUntouched(t *testing.T) {
+	mr, client := newMiniredis(t)
+	ctx := context.Background()
+
+	err := utils.SetCachedData(ctx, client, "bad", func() {}, time.Minute)
+
+	assert.Error(t, err)
+	assert.False(t, mr.Exists("bad"))
+}
+
+func TestSetCachedData_RedisFailure(t *testing.T) {
+	mr, client := newMiniredis(t)
+	mr.Close()
+
+	err := utils.SetCachedData(context.Background(), client, "key", 1, time.Minute)
+
+	assert.Error(t, err)
+}
+
+func TestInvalidateCache(t *testing.T) {
+	mr, client := newMiniredis(t)
+	ctx := context.Background()
+	mr.Set("book:1", "{}")
+	mr.Set("book:2", "{}")
+	mr.Set("book:3", "{}")
+
+	require.NoError(t, utils.InvalidateCache(ctx, client, "book:1", "book:2", "book:missing"))
+	require.NoError(t, utils.InvalidateCache(ctx, client))
+
+	assert.False(t, mr.Exists("book:1"))
+	assert.False(t, mr.Exists("book:2"))
+	assert.True(t, mr.Exists("book:3"))
+}
+
+func TestListCacheKey(t *testing.T) {
+	_, client := newMiniredis(t)
+	ctx := context.Background()
+	query := map[string]interface{}{"author": "Herbert", "limit": 10}
+
+	first, err := utils.ListCacheKey(ctx, client, "books", query)
+	require.NoError(t, err)
+	again, err := utils.ListCacheKey(ctx, client, "books", map[string]interface{}{"limit": 10, "author": "Herbert"})
+	require.NoError(t, err)
+	other, err := utils.ListCacheKey(ctx, client, "books", map[string]interface{}{"author": "Herbert", "limit": 20})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, again)
+	assert.NotEqual(t, first, other)
+	assert.True(t, strings.HasPrefix(first, "books:list:0:"))
+
+	require.NoError(t, utils.InvalidateLists(ctx, client, "books"))
+	bumped, err := utils.ListCacheKey(ctx, client, "books", query)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, bumped)
+	assert.True(t, strings.HasPrefix(bumped, "books:list:1:"))
+}
@@ -0,0 +1,385 @@
+package test
+
+import (
+	"shared/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCacheTTLConfig_Defaults(t *testing.T) {
+	t.Setenv("BOOK_CACHE_TTL", "")
+	t.Setenv("COLLECTION_CACHE_TTL", "")
+	t.Setenv("AVAILABLE_BOOKS_CACHE_TTL", "")
+	t.Setenv("BORROW_CACHE_TTL", "")
+	t.Setenv("LIST_CACHE_TTL", "")
+	t.Setenv("CATEGORIES_CACHE_TTL", "")
+	t.Setenv("BOOK_COUNTS_CACHE_TTL", "")
+
+	cfg := config.LoadCacheTTLConfig()
+
+	assert.Equal(t, config.DefaultCacheTTL, cfg.BookTTL)
+	assert.Equal(t, config.DefaultCacheTTL, cfg.CollectionTTL)
+	assert.Equal(t, config.DefaultCacheTTL, cfg.AvailableBooksTTL)
+	assert.Equal(t, config.DefaultCacheTTL, cfg.BorrowTTL)
+	assert.Zero(t, cfg.ListTTL, "list caching is opt-in")
+	assert.Equal(t, config.DefaultCategoriesCacheTTL, cfg.CategoriesTTL)
+	assert.Equal(t, config.DefaultBookCountsCacheTTL, cfg.BookCountsTTL)
+}
+
+func TestLoadCacheTTLConfig_FromEnv(t *testing.T) {
+	t.Setenv("BOOK_CACHE_TTL", "30m")
+	t.Setenv("COLLECTION_CACHE_TTL", "2h")
+	t.Setenv("AVAILABLE_BOOKS_CACHE_TTL", "90s")
+	t.Setenv("BORROW_CACHE_TTL", "10m")
+	t.Setenv("LIST_CACHE_TTL", "15s")
+	t.Setenv("CATEGORIES_CACHE_TTL", "1m")
+	t.Setenv("BOOK_COUNTS_CACHE_TTL", "5s")
+
+	cfg := config.LoadCacheTTLConfig()
+
+	assert.Equal(t, 30*time.Minute, cfg.BookTTL)
+	assert.Equal(t, 2*time.Hour, cfg.CollectionTTL)
+	assert.Equal(t, 90*time.Second, cfg.AvailableBooksTTL)
+	assert.Equal(t, 10*time.Minute, cfg.BorrowTTL)
+	assert.Equal(t, 15*time.Second, cfg.ListTTL)
+	assert.Equal(t, time.Minute, cfg.CategoriesTTL)
+	assert.Equal(t, 5*time.Second, cfg.BookCountsTTL)
+}
+
+func TestLoadCacheTTLConfig_InvalidKeepsDefault(t *testing.T) {
+	t.Setenv("BOOK_CACHE_TTL", "soon")
+	t.Setenv("COLLECTION_CACHE_TTL", "-5m")
+	t.Setenv("AVAILABLE_BOOKS_CACHE_TTL", "")
+
+	cfg := config.LoadCacheTTLConfig()
+
+	assert.Equal(t, config.DefaultCacheTTL, cfg.BookTTL)
+	assert.Equal(t, config.DefaultCacheTTL, cfg.CollectionTTL)
+}
+
+func TestLoadBorrowConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("BORROW_LOAN_DAYS", "")
+		t.Setenv("BORROW_MAX_LOAN_DAYS", "")
+		t.Setenv("BORROW_MAX_ACTIVE", "")
+		t.Setenv("BORROW_RENEWAL_DAYS", "")
+		t.Setenv("BORROW_MAX_RENEWALS", "")
+		t.Setenv("BORROW_FINE_PER_DAY", "")
+		t.Setenv("BORROW_DUE_SOON_DAYS", "")
+		t.Setenv("BORROW_DUE_SOON_INTERVAL", "")
+		t.Setenv("BORROW_DUE_SOON_NOTIFY", "")
+
+		cfg := config.LoadBorrowConfig()
+
+		assert.Equal(t, config.DefaultLoanDays, cfg.LoanDays)
+		assert.Equal(t, config.DefaultMaxLoanDays, cfg.MaxLoanDays)
+		assert.Equal(t, config.DefaultMaxActive, cfg.MaxActive)
+		assert.Equal(t, config.DefaultRenewalDays, cfg.RenewalDays)
+		assert.Equal(t, config.DefaultMaxRenewals, cfg.MaxRenewals)
+		assert.Equal(t, int64(config.DefaultFinePerDay), cfg.FinePerDay)
+		assert.Equal(t, config.DefaultDueSoonDays, cfg.DueSoonDays)
+		assert.Equal(t, config.DefaultDueSoonInterval, cfg.DueSoonInterval)
+		assert.False(t, cfg.DueSoonNotify)
+	})
+
+	t.Run("due-soon webhook", func(t *testing.T) {
+		t.Setenv("BORROW_DUE_SOON_DAYS", "5")
+		t.Setenv("BORROW_DUE_SOON_INTERVAL", "1h")
+		t.Setenv("BORROW_DUE_SOON_NOTIFY", "true")
+		t.Setenv("BORROW_DUE_SOON_WEBHOOK_URL", "http://hooks.local/due-soon")
+
+		cfg := config.LoadBorrowConfig()
+
+		assert.Equal(t, 5, cfg.DueSoonDays)
+		assert.Equal(t, time.Hour, cfg.DueSoonInterval)
+		assert.True(t, cfg.DueSoonNotify)
+		assert.Equal(t, "http://hooks.local/due-soon", cfg.DueSoonWebhook)
+	})
+
+	t.Run("due-soon notify without webhook", func(t *testing.T) {
+		t.Setenv("BORROW_DUE_SOON_NOTIFY", "true")
+		t.Setenv("BORROW_DUE_SOON_WEBHOOK_URL", "")
+
+		assert.False(t, config.LoadBorrowConfig().DueSoonNotify)
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("BORROW_LOAN_DAYS", "14")
+		t.Setenv("BORROW_MAX_LOAN_DAYS", "30")
+		t.Setenv("BORROW_MAX_ACTIVE", "3")
+		t.Setenv("BORROW_RENEWAL_DAYS", "14")
+		t.Setenv("BORROW_MAX_RENEWALS", "1")
+		t.Setenv("BORROW_FINE_PER_DAY", "0")
+
+		cfg := config.LoadBorrowConfig()
+
+		assert.Equal(t, 14, cfg.LoanDays)
+		assert.Equal(t, 30, cfg.MaxLoanDays)
+		assert.Equal(t, 3, cfg.MaxActive)
+		assert.Equal(t, 14, cfg.RenewalDays)
+		assert.Equal(t, 1, cfg.MaxRenewals)
+		assert.Equal(t, int64(0), cfg.FinePerDay)
+	})
+
+	t.Run("invalid and over max", func(t *testing.T) {
+		t.Setenv("BORROW_LOAN_DAYS", "90")
+		t.Setenv("BORROW_MAX_LOAN_DAYS", "-1")
+		t.Setenv("BORROW_MAX_RENEWALS", "0")
+
+		cfg := config.LoadBorrowConfig()
+
+		assert.Equal(t, config.DefaultMaxLoanDays, cfg.MaxLoanDays)
+		assert.Equal(t, config.DefaultMaxLoanDays, cfg.LoanDays)
+		assert.Equal(t, config.DefaultMaxRenewals, cfg.MaxRenewals)
+	})
+}
+
+func TestLoadCollectionConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("COLLECTION_MAX_TOTAL_BOOKS", "")
+		t.Setenv("COLLECTION_SEED_BATCH_SIZE", "")
+
+		cfg := config.LoadCollectionConfig()
+
+		assert.Equal(t, config.DefaultMaxTotalBooks, cfg.MaxTotalBooks)
+		assert.Equal(t, config.DefaultSeedBatchSize, cfg.SeedBatchSize)
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("COLLECTION_MAX_TOTAL_BOOKS", "500")
+		t.Setenv("COLLECTION_SEED_BATCH_SIZE", "50")
+
+		cfg := config.LoadCollectionConfig()
+
+		assert.Equal(t, 500, cfg.MaxTotalBooks)
+		assert.Equal(t, 50, cfg.SeedBatchSize)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv("COLLECTION_MAX_TOTAL_BOOKS", "lots")
+		t.Setenv("COLLECTION_SEED_BATCH_SIZE", "0")
+
+		cfg := config.LoadCollectionConfig()
+
+		assert.Equal(t, config.DefaultMaxTotalBooks, cfg.MaxTotalBooks)
+		assert.Equal(t, config.DefaultSeedBatchSize, cfg.SeedBatchSize)
+	})
+}
+
+func TestLoadServerConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("BOOK_SERVICE_HOST", "")
+		t.Setenv("BOOK_SERVICE_PORT", "")
+		t.Setenv("BOOK_DRAIN_TIMEOUT", "")
+		t.Setenv("BOOK_MAX_CONNECTION_AGE", "")
+		t.Setenv("BOOK_MAX_CONNECTION_AGE_GRACE", "")
+		t.Setenv("BOOK_KEEPALIVE_MIN_TIME", "")
+
+		cfg := config.LoadServerConfig("book")
+
+		assert.Equal(t, ":50052", cfg.ListenAddress())
+		assert.Equal(t, "localhost:50052", cfg.DialAddress())
+		assert.Equal(t, config.DefaultDrainTimeout, cfg.DrainTimeout)
+		assert.Equal(t, config.DefaultMaxConnectionAge, cfg.MaxConnectionAge)
+		assert.Equal(t, config.DefaultMaxConnectionAgeGrace, cfg.MaxConnectionAgeGrace)
+		assert.Equal(t, config.DefaultKeepaliveMinTime, cfg.KeepaliveMinTime)
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("BORROW_SERVICE_HOST", "borrow.internal")
+		t.Setenv("BORROW_SERVICE_PORT", "6000")
+		t.Setenv("BORROW_DRAIN_TIMEOUT", "30s")
+		t.Setenv("BORROW_MAX_CONNECTION_AGE", "5m")
+		t.Setenv("BORROW_MAX_CONNECTION_AGE_GRACE", "10s")
+		t.Setenv("BORROW_KEEPALIVE_MIN_TIME", "20s")
+
+		cfg := config.LoadServerConfig("borrow")
+
+		assert.Equal(t, "borrow.internal:6000", cfg.ListenAddress())
+		assert.Equal(t, "borrow.internal:6000", cfg.DialAddress())
+		assert.Equal(t, 30*time.Second, cfg.DrainTimeout)
+		assert.Equal(t, 5*time.Minute, cfg.MaxConnectionAge)
+		assert.Equal(t, 10*time.Second, cfg.MaxConnectionAgeGrace)
+		assert.Equal(t, 20*time.Second, cfg.KeepaliveMinTime)
+	})
+
+	t.Run("wildcard host dials localhost", func(t *testing.T) {
+		t.Setenv("COLLECTION_SERVICE_HOST", "0.0.0.0")
+		t.Setenv("COLLECTION_SERVICE_PORT", "")
+
+		cfg := config.LoadServerConfig("collection")
+
+		assert.Equal(t, "0.0.0.0:50051", cfg.ListenAddress())
+		assert.Equal(t, "localhost:50051", cfg.DialAddress())
+	})
+}
+
+func TestLoadGatewayConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("GATEWAY_ADDR", "")
+		t.Setenv("GATEWAY_SHUTDOWN_TIMEOUT", "")
+		t.Setenv("GATEWAY_ADMIN_TOKEN", "")
+		t.Setenv("GATEWAY_MAX_BODY_BYTES", "")
+		t.Setenv("GATEWAY_MAX_BULK_BYTES", "")
+
+		cfg, err := config.LoadGatewayConfig()
+
+		assert.NoError(t, err)
+		assert.Equal(t, config.DefaultGatewayAddr, cfg.Addr)
+		assert.Equal(t, config.DefaultGatewayShutdownTimeout, cfg.ShutdownTimeout)
+		assert.Empty(t, cfg.AdminToken)
+		assert.Equal(t, config.DefaultGatewayMaxBodyBytes, cfg.MaxBodyBytes)
+		assert.Equal(t, config.DefaultGatewayMaxBulkBytes, cfg.MaxBulkBytes)
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("GATEWAY_ADDR", "0.0.0.0:9000")
+		t.Setenv("GATEWAY_SHUTDOWN_TIMEOUT", "30s")
+		t.Setenv("GATEWAY_ADMIN_TOKEN", "secret")
+		t.Setenv("GATEWAY_MAX_BODY_BYTES", "2048")
+		t.Setenv("GATEWAY_MAX_BULK_BYTES", "65536")
+
+		cfg, err := config.LoadGatewayConfig()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "0.0.0.0:9000", cfg.Addr)
+		assert.Equal(t, 30*time.Second, cfg.ShutdownTimeout)
+		assert.Equal(t, "secret", cfg.AdminToken)
+		assert.Equal(t, 2048, cfg.MaxBodyBytes)
+		assert.Equal(t, 65536, cfg.MaxBulkBytes)
+	})
+
+	t.Run("invalid timeout keeps default", func(t *testing.T) {
+		t.Setenv("GATEWAY_ADDR", "")
+		t.Setenv("GATEWAY_SHUTDOWN_TIMEOUT", "later")
+
+		cfg, err := config.LoadGatewayConfig()
+
+		assert.NoError(t, err)
+		assert.Equal(t, config.DefaultGatewayShutdownTimeout, cfg.ShutdownTimeout)
+	})
+
+	t.Run("malformed address", func(t *testing.T) {
+		for _, addr := range []string{"8080", "localhost", ":http-alt", "localhost:99999"} {
+			t.Setenv("GATEWAY_ADDR", addr)
+
+			_, err := config.LoadGatewayConfig()
+
+			assert.Error(t, err, addr)
+		}
+	})
+}
+
+func TestLoadMongoConfig(t *testing.T) {
+	keys := []string{
+		"MONGODB_URI", "MONGODB_DATABASE", "MONGODB_MAX_POOL_SIZE", "MONGODB_MIN_POOL_SIZE",
+		"MONGODB_MAX_CONN_IDLE_TIME", "MONGODB_CONNECT_TIMEOUT", "MONGODB_SERVER_SELECTION_TIMEOUT",
+		"MONGODB_PING_TIMEOUT", "MONGODB_CONNECT_ATTEMPTS", "MONGODB_CONNECT_BACKOFF",
+		"MONGODB_WRITE_CONCERN", "MONGODB_READ_PREFERENCE",
+		"BOOK_MONGODB_WRITE_CONCERN", "BOOK_MONGODB_READ_PREFERENCE",
+		"BORROW_MONGODB_WRITE_CONCERN", "BORROW_MONGODB_READ_PREFERENCE",
+	}
+	clear := func(t *testing.T) {
+		for _, key := range keys {
+			t.Setenv(key, "")
+		}
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		clear(t)
+
+		cfg := config.LoadMongoConfig("book")
+
+		assert.Equal(t, config.DefaultMongoConfig(), cfg)
+		assert.Equal(t, config.DefaultMongoDatabase, cfg.Database)
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		clear(t)
+		t.Setenv("MONGODB_URI", "mongodb://mongo:27017")
+		t.Setenv("MONGODB_DATABASE", "library_test")
+		t.Setenv("MONGODB_MAX_POOL_SIZE", "50")
+		t.Setenv("MONGODB_MIN_POOL_SIZE", "5")
+		t.Setenv("MONGODB_MAX_CONN_IDLE_TIME", "1m")
+		t.Setenv("MONGODB_CONNECT_TIMEOUT", "2s")
+		t.Setenv("MONGODB_SERVER_SELECTION_TIMEOUT", "3s")
+		t.Setenv("MONGODB_PING_TIMEOUT", "1s")
+		t.Setenv("MONGODB_CONNECT_ATTEMPTS", "10")
+		t.Setenv("MONGODB_CONNECT_BACKOFF", "500ms")
+
+		cfg := config.LoadMongoConfig("book")
+
+		assert.Equal(t, "mongodb://mongo:27017", cfg.URI)
+		assert.Equal(t, "library_test", cfg.Database)
+		assert.Equal(t, 50, cfg.MaxPoolSize)
+		assert.Equal(t, 5, cfg.MinPoolSize)
+		assert.Equal(t, time.Minute, cfg.MaxConnIdleTime)
+		assert.Equal(t, 2*time.Second, cfg.ConnectTimeout)
+		assert.Equal(t, 3*time.Second, cfg.ServerSelectionTimeout)
+		assert.Equal(t, time.Second, cfg.PingTimeout)
+		assert.Equal(t, 10, cfg.ConnectAttempts)
+		assert.Equal(t, 500*time.Millisecond, cfg.ConnectBackoff)
+	})
+
+	t.Run("per service write concern and read preference", func(t *testing.T) {
+		clear(t)
+
+		book := config.LoadMongoConfig("book")
+		borrow := config.LoadMongoConfig("borrow")
+
+		assert.Equal(t, config.DefaultMongoWriteConcern, book.WriteConcern)
+		assert.Equal(t, config.DefaultMongoReadPreference, book.ReadPreference)
+		assert.Equal(t, "majority", borrow.WriteConcern)
+		assert.Equal(t, config.DefaultMongoReadPreference, borrow.ReadPreference)
+	})
+
+	t.Run("service env overrides shared env", func(t *testing.T) {
+		clear(t)
+		t.Setenv("MONGODB_WRITE_CONCERN", "2")
+		t.Setenv("MONGODB_READ_PREFERENCE", "nearest")
+		t.Setenv("BOOK_MONGODB_READ_PREFERENCE", "SECONDARYPREFERRED")
+
+		book := config.LoadMongoConfig("book")
+		borrow := config.LoadMongoConfig("borrow")
+
+		assert.Equal(t, "2", book.WriteConcern)
+		assert.Equal(t, "secondaryPreferred", book.ReadPreference)
+		assert.Equal(t, "2", borrow.WriteConcern)
+		assert.Equal(t, "nearest", borrow.ReadPreference)
+	})
+
+	t.Run("invalid concern and preference keep defaults", func(t *testing.T) {
+		clear(t)
+		t.Setenv("BORROW_MONGODB_WRITE_CONCERN", "0")
+		t.Setenv("BORROW_MONGODB_READ_PREFERENCE", "fastest")
+
+		cfg := config.LoadMongoConfig("borrow")
+
+		assert.Equal(t, "majority", cfg.WriteConcern)
+		assert.Equal(t, config.DefaultMongoReadPreference, cfg.ReadPreference)
+	})
+
+	t.Run("min pool capped at max", func(t *testing.T) {
+		clear(t)
+		t.Setenv("MONGODB_MAX_POOL_SIZE", "10")
+		t.Setenv("MONGODB_MIN_POOL_SIZE", "20")
+
+		cfg := config.LoadMongoConfig("book")
+
+		assert.Equal(t, 10, cfg.MaxPoolSize)
+		assert.Equal(t, 10, cfg.MinPoolSize)
+	})
+
+	t.Run("invalid values keep defaults", func(t *testing.T) {
+		clear(t)
+		t.Setenv("MONGODB_MAX_POOL_SIZE", "lots")
+		t.Setenv("MONGODB_CONNECT_TIMEOUT", "-1s")
+
+		cfg := config.LoadMongoConfig("book")
+
+		assert.Equal(t, config.DefaultMongoConfig().MaxPoolSize, cfg.MaxPoolSize)
+		assert.Equal(t, config.DefaultMongoConfig().ConnectTimeout, cfg.ConnectTimeout)
+	})
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"shared/config"
+	"shared/pkg/mongotest"
+	"shared/pkg/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func TestClientOptions_FromConfig(t *testing.T) {
+	cfg := &config.MongoConfig{
+		URI:                    "mongodb://mongo:27017",
+		Database:               "library_test",
+		MaxPoolSize:            40,
+		MinPoolSize:            4,
+		MaxConnIdleTime:        time.Minute,
+		ConnectTimeout:         2 * time.Second,
+		ServerSelectionTimeout: 3 * time.Second,
+	}
+
+	opts := repository.ClientOptions(cfg)
+
+	assert.Equal(t, []string{"mongo:27017"}, opts.Hosts)
+	assert.Equal(t, uint64(40), *opts.MaxPoolSize)
+	assert.Equal(t, uint64(4), *opts.MinPoolSize)
+	assert.Equal(t, time.Minute, *opts.MaxConnIdleTime)
+	assert.Equal(t, 2*time.Second, *opts.ConnectTimeout)
+	assert.Equal(t, 3*time.Second, *opts.ServerSelectionTimeout)
+	require.NotNil(t, opts.WriteConcern)
+	assert.Equal(t, 1, opts.WriteConcern.W)
+}
+
+func TestClientOptions_WriteConcernAndReadPreference(t *testing.T) {
+	cfg := config.DefaultMongoConfig()
+	cfg.WriteConcern = "majority"
+	cfg.ReadPreference = "secondaryPreferred"
+
+	opts := repository.ClientOptions(cfg)
+
+	require.NotNil(t, opts.WriteConcern)
+	assert.Equal(t, "majority", opts.WriteConcern.W)
+	require.NotNil(t, opts.ReadPreference)
+	assert.Equal(t, readpref.SecondaryPreferredMode, opts.ReadPreference.Mode())
+
+	cfg.WriteConcern = "2"
+	opts = repository.ClientOptions(cfg)
+	assert.Equal(t, 2, opts.WriteConcern.W)
+}
+
+func TestClientOptions_DefaultsToW1AndPrimary(t *testing.T) {
+	opts := repository.ClientOptions(config.DefaultMongoConfig())
+
+	require.NotNil(t, opts.WriteConcern)
+	assert.Equal(t, 1, opts.WriteConcern.W)
+	require.NotNil(t, opts.ReadPreference)
+	assert.Equal(t, readpref.PrimaryMode, opts.ReadPreference.Mode())
+}
+
+func TestClientOptions_WriteConcernOverridesURI(t *testing.T) {
+	cfg := config.DefaultMongoConfig()
+	cfg.URI = "mongodb://mongo:27017/?w=majority"
+
+	opts := repository.ClientOptions(cfg)
+
+	require.NotNil(t, opts.WriteConcern)
+	assert.Equal(t, 1, opts.WriteConcern.W)
+}
+
+func TestConnect_GivesUpAfterAttempts(t *testing.T) {
+	cfg := config.DefaultMongoConfig()
+	cfg.URI = "mongodb://127.0.0.1:1/?directConnection=true"
+	cfg.ServerSelectionTimeout = 50 * time.Millisecond
+	cfg.ConnectAttempts = 2
+	cfg.ConnectBackoff = 10 * time.Millisecond
+
+	start := time.Now()
+	client, database, err := repository.Connect(cfg)
+
+	require.Error(t, err)
+	assert.Nil(t, client)
+	assert.Nil(t, database)
+	assert.Contains(t, err.Error(), "failed to connect to MongoDB")
+	assert.Contains(t, err.Error(), "gave up after 2 attempts")
+	assert.GreaterOrEqual(t, time.Since(start), cfg.ConnectBackoff)
+}
+
+func TestConnect_PingFailureIsReturned(t *testing.T) {
+	// Accepts the connection but hangs up before the handshake
+	uri := mongotest.Listen(t, func(conn net.Conn) { conn.Close() })
+
+	cfg := config.DefaultMongoConfig()
+	cfg.URI = uri
+	cfg.PingTimeout = 200 * time.Millisecond
+	cfg.ConnectAttempts = 1
+
+	client, database, err := repository.Connect(cfg)
+
+	require.Error(t, err)
+	assert.Nil(t, client)
+	assert.Nil(t, database)
+	assert.Contains(t, err.Error(), "MongoDB primary did not answer ping")
+}
+
+func TestConnect_PingBoundedByTimeout(t *testing.T) {
+	// Accepts the connection and never answers
+	uri := mongotest.Listen(t, func(conn net.Conn) {
+		t.Cleanup(func() { conn.Close() })
+	})
+
+	cfg := config.DefaultMongoConfig()
+	cfg.URI = uri
+	cfg.ConnectTimeout = time.Minute
+	cfg.ServerSelectionTimeout = time.Minute
+	cfg.PingTimeout = 100 * time.Millisecond
+	cfg.ConnectAttempts = 1
+
+	start := time.Now()
+	_, _, err := repository.Connect(cfg)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRetry_SucceedsOnSecondAttempt(t *testing.T) {
+	calls := 0
+	result, err := repository.Retry(context.Background(), 3, time.Millisecond, func(ctx context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("server selection timeout")
+		}
+		return "connected", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "connected", result)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_WrapsLastError(t *testing.T) {
+	lastErr := errors.New("connection refused")
+	calls := 0
+	_, err := repository.Retry(context.Background(), 3, time.Millisecond, func(ctx context.Context) (int, error) {
+		calls++
+		return 0, lastErr
+	})
+
+	assert.ErrorIs(t, err, lastErr)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := repository.Retry(ctx, 5, time.Hour, func(ctx context.Context) (int, error) {
+		calls++
+		cancel()
+		return 0, errors.New("connection refused")
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+// Every service connects through repository.Connect, none keeps its own copy
+func TestServicesHaveNoConnectOfTheirOwn(t *testing.T) {
+	for _, pattern := range []string{
+		"../../services/*/internal/db/connect.go",
+		"../../services/*/db/connect.go",
+	} {
+		matches, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	}
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"net"
+	"shared/config"
+	"shared/pkg/grpcutil"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Server settings with the default keepalive policy and the given connection age
+func keepaliveConfig(maxAge time.Duration) *config.ServerConfig {
+	return &config.ServerConfig{
+		MaxConnectionAge:      maxAge,
+		MaxConnectionAgeGrace: config.DefaultMaxConnectionAgeGrace,
+		KeepaliveMinTime:      config.DefaultKeepaliveMinTime,
+	}
+}
+
+// Dials an in-memory listener that has no server yet, the returned function
+// starts serving on it
+func dialPending(t *testing.T) (*grpc.ClientConn, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpcutil.KeepaliveServerOptions(keepaliveConfig(config.DefaultMaxConnectionAge))...)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpcutil.KeepaliveDialOption(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, func() { go server.Serve(lis) }
+}
+
+func TestWaitForReady_ReturnsOnceServerIsUp(t *testing.T) {
+	conn, serve := dialPending(t)
+	time.AfterFunc(50*time.Millisecond, serve)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, grpcutil.WaitForReady(ctx, conn))
+
+	states, ready := grpcutil.ConnectionStates(map[string]*grpc.ClientConn{"book": conn})
+	assert.True(t, ready)
+	assert.Equal(t, "READY", states["book"])
+}
+
+func TestWaitForConnections_ReportsBackendsThatStayDown(t *testing.T) {
+	up, serve := dialPending(t)
+	serve()
+	down, _ := dialPending(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	failed := grpcutil.WaitForConnections(ctx, map[string]*grpc.ClientConn{"book": up, "borrow": down})
+
+	assert.Len(t, failed, 1)
+	assert.ErrorIs(t, failed["borrow"], context.DeadlineExceeded)
+
+	_, ready := grpcutil.ConnectionStates(map[string]*grpc.ClientConn{"book": up, "borrow": down})
+	assert.False(t, ready)
+}
+
+func TestKeepaliveServerOptions_ClosesConnectionAfterMaxAge(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpcutil.KeepaliveServerOptions(keepaliveConfig(200 * time.Millisecond))...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	var dials atomic.Int32
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			dials.Add(1)
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpcutil.KeepaliveDialOption(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, grpcutil.WaitForReady(ctx, conn))
+
+	// The server sends GOAWAY once the connection is past its age, the client
+	// drops it and goes idle rather than failing
+	require.True(t, conn.WaitForStateChange(ctx, connectivity.Ready))
+	assert.NotEqual(t, connectivity.TransientFailure, conn.GetState())
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, dials.Load(), int32(2))
+}
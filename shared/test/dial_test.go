@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"net"
+	"shared/pkg/grpcutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestDialServices_AppliesSharedOptions(t *testing.T) {
+	t.Setenv("GRPC_INSECURE", "true")
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	connections, err := grpcutil.DialServices(
+		map[string]string{"book": "passthrough:///bufnet"},
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { grpcutil.CloseConnections(connections) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(connections["book"]).Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+}
+
+func TestDialServices_ReportsEveryUnreachablePeer(t *testing.T) {
+	t.Setenv("GRPC_INSECURE", "true")
+
+	connections, err := grpcutil.DialServices(map[string]string{
+		"book":       "localhost:50052",
+		"borrow":     "",
+		"collection": "collection-host",
+	})
+
+	require.Error(t, err)
+	assert.Nil(t, connections)
+	assert.Contains(t, err.Error(), "borrow service")
+	assert.Contains(t, err.Error(), "collection service")
+	assert.NotContains(t, err.Error(), "book service")
+}
+
+func TestDialServices_FailsWithoutCredentials(t *testing.T) {
+	t.Setenv("GRPC_INSECURE", "")
+	t.Setenv("GRPC_TLS_CA", "/nonexistent/ca.pem")
+
+	connections, err := grpcutil.DialServices(map[string]string{"book": "localhost:50052"})
+
+	require.Error(t, err)
+	assert.Nil(t, connections)
+}
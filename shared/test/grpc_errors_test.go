@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"shared/pkg/grpcutil"
+	pb "shared/proto/buffer"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPError_StatusError(t *testing.T) {
+	code, message := grpcutil.HTTPError(status.Error(codes.NotFound, "Collection not found"))
+
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "Collection not found", message)
+}
+
+func TestHTTPError_Deadline(t *testing.T) {
+	for _, err := range []error{
+		context.DeadlineExceeded,
+		fmt.Errorf("calling book service: %w", context.DeadlineExceeded),
+		status.Error(codes.DeadlineExceeded, "context deadline exceeded"),
+	} {
+		code, message := grpcutil.HTTPError(err)
+
+		assert.Equal(t, http.StatusGatewayTimeout, code)
+		assert.Equal(t, grpcutil.MessageTimeout, message)
+	}
+}
+
+func TestHTTPError_ConnectionError(t *testing.T) {
+	// A real refused dial, its text carries the backend address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	_, dialErr := net.Dial("tcp", addr)
+	require.Error(t, dialErr)
+
+	for _, err := range []error{
+		dialErr,
+		syscall.ECONNREFUSED,
+		status.Error(codes.Unavailable, "connection error: desc = transport: dial tcp "+addr+": connect: connection refused"),
+	} {
+		code, message := grpcutil.HTTPError(err)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, grpcutil.MessageUnavailable, message)
+		assert.NotContains(t, message, addr)
+	}
+}
+
+func TestHTTPError_UnknownErrorIsInternal(t *testing.T) {
+	code, message := grpcutil.HTTPError(errors.New("mongo: no reachable servers at 10.0.0.5"))
+
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, grpcutil.MessageInternal, message)
+}
+
+func TestBuildSort_AllowedKeys(t *testing.T) {
+	sort, err := grpcutil.BuildSort([]*pb.Sort{{Key: "name", Direction: 1}, {Key: "_id", Direction: -1}}, "_id", "name")
+
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "name", Value: int32(1)}, {Key: "_id", Value: int32(-1)}}, sort)
+}
+
+func TestBuildSort_RejectsUnknownKeys(t *testing.T) {
+	sort, err := grpcutil.BuildSort([]*pb.Sort{{Key: "name", Direction: 1}, {Key: "description", Direction: 1}}, "_id", "name")
+
+	assert.Nil(t, sort)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{
+		"description": "description can't be sorted on, use one of: _id name",
+	}, grpcutil.FieldViolations(err))
+}
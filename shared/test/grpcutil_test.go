@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"net"
+	"shared/pkg/grpcutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Starts an in-memory server using the request id interceptor and returns a
+// client for it. Every request id seen by a handler is sent on the channel.
+func newHealthClient(t *testing.T) (healthpb.HealthClient, <-chan string) {
+	lis := bufconn.Listen(1024 * 1024)
+	seen := make(chan string, 1)
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcutil.UnaryServerInterceptor(),
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			seen <- grpcutil.RequestIdFromContext(ctx)
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcutil.UnaryClientInterceptor()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn), seen
+}
+
+func TestRequestId_SurvivesRoundTrip(t *testing.T) {
+	client, seen := newHealthClient(t)
+
+	// A handler context of an upstream call that received an id
+	upstream := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcutil.RequestIdMetadata, "req-123"))
+
+	_, err := client.Check(upstream, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "req-123", <-seen)
+
+	// The downstream handler forwards the same id on its own calls
+	_, err = client.Check(grpcutil.WithRequestId(context.Background(), "req-123"), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "req-123", <-seen)
+}
+
+func TestRequestId_OutgoingMetadataWins(t *testing.T) {
+	client, seen := newHealthClient(t)
+
+	ctx := metadata.AppendToOutgoingContext(
+		grpcutil.WithRequestId(context.Background(), "from-context"),
+		grpcutil.RequestIdMetadata, "from-metadata",
+	)
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "from-metadata", <-seen)
+}
+
+func TestRequestId_GeneratedWhenMissing(t *testing.T) {
+	client, seen := newHealthClient(t)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, <-seen)
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"shared/pkg/grpcutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func servingStatus(t *testing.T, server *health.Server) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	return resp.GetStatus()
+}
+
+func TestHealth_NotServingUntilChecked(t *testing.T) {
+	server := grpcutil.NewHealthServer()
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server))
+}
+
+func TestHealth_ServingWhenPingsSucceed(t *testing.T) {
+	server := grpcutil.NewHealthServer()
+	ok := func(ctx context.Context) error { return nil }
+
+	status := grpcutil.CheckHealth(context.Background(), server, ok, ok)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, server))
+}
+
+func TestHealth_NotServingWhenDatabasePingFails(t *testing.T) {
+	server := grpcutil.NewHealthServer()
+	dbUp := true
+	dbPing := func(ctx context.Context) error {
+		if !dbUp {
+			return errors.New("server selection timeout")
+		}
+		return nil
+	}
+	redisPing := func(ctx context.Context) error { return nil }
+
+	grpcutil.CheckHealth(context.Background(), server, dbPing, redisPing)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, server))
+
+	dbUp = false
+	status := grpcutil.CheckHealth(context.Background(), server, dbPing, redisPing)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server))
+}
+
+func TestHealth_NotServingAfterShutdown(t *testing.T) {
+	server := grpcutil.NewHealthServer()
+	ok := func(ctx context.Context) error { return nil }
+	grpcutil.CheckHealth(context.Background(), server, ok)
+
+	server.Shutdown()
+	// A check racing with shutdown must not flip the status back
+	grpcutil.CheckHealth(context.Background(), server, ok)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server))
+}
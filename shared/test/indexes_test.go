@@ -0,0 +1,44 @@
+package test
+
+import (
+	"shared/pkg/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestValidateIndexModels_Valid(t *testing.T) {
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "name", Value: "text"}}, Options: options.Index().SetName("search")},
+		{Keys: bson.D{{Key: "a", Value: 1}, {Key: "b", Value: -1}}, Options: options.Index().SetName("a_b").SetUnique(true)},
+	}
+
+	assert.NoError(t, repository.ValidateIndexModels(models))
+}
+
+func TestValidateIndexModels_Invalid(t *testing.T) {
+	cases := map[string]mongo.IndexModel{
+		"unordered keys": {Keys: bson.M{"a": 1}, Options: options.Index().SetName("a")},
+		"empty keys":     {Keys: bson.D{}, Options: options.Index().SetName("a")},
+		"bad direction":  {Keys: bson.D{{Key: "a", Value: 2}}, Options: options.Index().SetName("a")},
+		"missing name":   {Keys: bson.D{{Key: "a", Value: 1}}},
+	}
+
+	for name, model := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, repository.ValidateIndexModels([]mongo.IndexModel{model}))
+		})
+	}
+}
+
+func TestValidateIndexModels_DuplicateName(t *testing.T) {
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "a", Value: 1}}, Options: options.Index().SetName("idx")},
+		{Keys: bson.D{{Key: "b", Value: 1}}, Options: options.Index().SetName("idx")},
+	}
+
+	assert.Error(t, repository.ValidateIndexModels(models))
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"shared/pkg/metrics"
+	"shared/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor_CountsRequests(t *testing.T) {
+	method := "/test.Service/Succeeds"
+	interceptor := metrics.UnaryServerInterceptor()
+	before := testutil.ToFloat64(metrics.GRPCRequests.WithLabelValues(method, codes.OK.String()))
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil },
+	)
+	require.NoError(t, err)
+
+	after := testutil.ToFloat64(metrics.GRPCRequests.WithLabelValues(method, codes.OK.String()))
+	assert.Equal(t, before+1, after)
+
+	// The scrape output carries the new series
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	assert.Contains(t, string(body), `grpc_server_requests_total{code="OK",method="/test.Service/Succeeds"}`)
+	assert.Contains(t, string(body), `grpc_server_request_duration_seconds_count{method="/test.Service/Succeeds"}`)
+}
+
+func TestUnaryServerInterceptor_RecordsErrorCode(t *testing.T) {
+	method := "/test.Service/Fails"
+	interceptor := metrics.UnaryServerInterceptor()
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "missing")
+		},
+	)
+	require.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.GRPCRequests.WithLabelValues(method, codes.NotFound.String())))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.GRPCRequests.WithLabelValues(method, codes.OK.String())))
+}
+
+func TestGetCachedData_CountsHitsAndMisses(t *testing.T) {
+	_, client := newMiniredis(t)
+	ctx := context.Background()
+	hits := metrics.CacheHits.WithLabelValues("metrics_test")
+	misses := metrics.CacheMisses.WithLabelValues("metrics_test")
+	hitsBefore, missesBefore := testutil.ToFloat64(hits), testutil.ToFloat64(misses)
+
+	utils.GetCachedData[int64](ctx, client, "metrics_test:1")
+	require.NoError(t, utils.SetCachedData(ctx, client, "metrics_test:1", int64(3), time.Minute))
+	utils.GetCachedData[int64](ctx, client, "metrics_test:1")
+
+	assert.Equal(t, hitsBefore+1, testutil.ToFloat64(hits))
+	assert.Equal(t, missesBefore+1, testutil.ToFloat64(misses))
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"context"
+	"shared/pkg/mongotest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestStore_RunsAggregations(t *testing.T) {
+	store := mongotest.Store{}
+	require.NoError(t, store.Insert("books",
+		bson.M{"collection_id": "c1", "is_borrowed": false},
+		bson.M{"collection_id": "c1", "is_borrowed": true},
+		bson.M{"collection_id": "c2", "is_borrowed": false, "deleted_at": "2026-10-01"},
+	))
+	repo := newFakeRepository(t, store.Reply)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$collection_id",
+			"available": bson.M{"$sum": bson.M{"$cond": bson.A{"$is_borrowed", 0, 1}}},
+		}}},
+	}
+	var results []bson.M
+	require.NoError(t, repo.Aggregate(context.Background(), pipeline, &results))
+
+	// The soft-deleted book is matched out by the repository
+	require.Len(t, results, 1)
+	assert.Equal(t, "c1", results[0]["_id"])
+	assert.EqualValues(t, 1, results[0]["available"])
+}
+
+func TestStore_UnsupportedStageFails(t *testing.T) {
+	repo := newFakeRepository(t, mongotest.Store{}.Reply)
+
+	var results []bson.M
+	err := repo.Aggregate(context.Background(), mongo.Pipeline{{{Key: "$sort", Value: bson.M{"name": 1}}}}, &results)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported stage $sort")
+}
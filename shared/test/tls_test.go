@@ -0,0 +1,173 @@
+package test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"shared/config"
+	"shared/pkg/grpcutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, filepath.Join(ca.dir, "ca.pem"), "CERTIFICATE", der)
+	return ca
+}
+
+// Issues a certificate for localhost signed by the CA and returns its cert and key paths
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(ca.dir, name+".pem")
+	keyPath := filepath.Join(ca.dir, name+"-key.pem")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDer)
+	return certPath, keyPath
+}
+
+func writePEM(t *testing.T, path string, kind string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600))
+}
+
+// Starts a health server with the given credentials on a local port
+func serveTLS(t *testing.T, cfg *config.TLSConfig) string {
+	creds, err := grpcutil.ServerCredentials(cfg)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(creds)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func checkHealth(t *testing.T, addr string, opt grpc.DialOption) error {
+	conn, err := grpc.NewClient(addr, opt)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestTLS_ServerRejectsInsecureClient(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	addr := serveTLS(t, &config.TLSConfig{CertFile: serverCert, KeyFile: serverKey, CAFile: filepath.Join(ca.dir, "ca.pem")})
+
+	err := checkHealth(t, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Error(t, err)
+}
+
+func TestTLS_MutualTLSClientAccepted(t *testing.T) {
+	ca := newTestCA(t)
+	caFile := filepath.Join(ca.dir, "ca.pem")
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	addr := serveTLS(t, &config.TLSConfig{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+
+	creds, err := grpcutil.ClientCredentials(&config.TLSConfig{
+		CertFile:   clientCert,
+		KeyFile:    clientKey,
+		CAFile:     caFile,
+		ServerName: "localhost",
+	})
+	require.NoError(t, err)
+	assert.NoError(t, checkHealth(t, addr, creds))
+}
+
+func TestTLS_ClientWithoutCertificateRejectedByMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	caFile := filepath.Join(ca.dir, "ca.pem")
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	addr := serveTLS(t, &config.TLSConfig{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+
+	creds, err := grpcutil.ClientCredentials(&config.TLSConfig{CAFile: caFile, ServerName: "localhost"})
+	require.NoError(t, err)
+	assert.Error(t, checkHealth(t, addr, creds))
+}
+
+func TestTLS_ServerRequiresCertificateUnlessInsecure(t *testing.T) {
+	_, err := grpcutil.ServerCredentials(&config.TLSConfig{})
+	assert.Error(t, err)
+
+	_, err = grpcutil.ServerCredentials(&config.TLSConfig{Insecure: true})
+	assert.NoError(t, err)
+}
+
+func TestLoadTLSConfig_FromEnv(t *testing.T) {
+	t.Setenv("GRPC_TLS_CERT", "/certs/tls.crt")
+	t.Setenv("GRPC_TLS_KEY", "/certs/tls.key")
+	t.Setenv("GRPC_TLS_CA", "/certs/ca.crt")
+	t.Setenv("GRPC_TLS_SERVER_NAME", "library.internal")
+	t.Setenv("GRPC_INSECURE", "true")
+
+	cfg := config.LoadTLSConfig()
+
+	assert.Equal(t, "/certs/tls.crt", cfg.CertFile)
+	assert.Equal(t, "/certs/tls.key", cfg.KeyFile)
+	assert.Equal(t, "/certs/ca.crt", cfg.CAFile)
+	assert.Equal(t, "library.internal", cfg.ServerName)
+	assert.True(t, cfg.Insecure)
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"context"
+	"shared/pkg/tracing"
+	"shared/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func useInMemoryTracer(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	provider := tracing.NewProvider("shared-test", sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+	return exporter
+}
+
+func TestCacheHelpers_RecordSpansUnderCaller(t *testing.T) {
+	exporter := useInMemoryTracer(t)
+	_, client := newMiniredis(t)
+
+	ctx, root := tracing.StartSpan(context.Background(), "caller", trace.SpanKindInternal)
+	require.NoError(t, utils.SetCachedData(ctx, client, "book:1", 1, time.Minute))
+	utils.GetCachedData[int](ctx, client, "book:1")
+	require.NoError(t, utils.InvalidateCache(ctx, client, "book:1"))
+	root.End()
+
+	names := []string{}
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "caller" {
+			continue
+		}
+		names = append(names, span.Name)
+		assert.Equal(t, root.SpanContext().SpanID(), span.Parent.SpanID(), span.Name)
+	}
+	assert.Equal(t, []string{"redis.set", "redis.get", "redis.del"}, names)
+}
+
+func TestTracing_DisabledWithoutEndpoint(t *testing.T) {
+	t.Setenv(tracing.EndpointEnv, "")
+
+	shutdown, err := tracing.Init(context.Background(), "shared-test")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"encoding/json"
+	"shared/pkg/model"
+	pb "shared/proto/buffer"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUser(t *testing.T) *model.User {
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	user := &model.User{
+		Id:        "u1",
+		Name:      "Ada Lovelace",
+		Username:  "ada",
+		Email:     "ada@example.com",
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+	}
+	require.NoError(t, user.SetPassword("correct horse"))
+	return user
+}
+
+func TestToUserResponse_KeepsPublicFields(t *testing.T) {
+	user := newUser(t)
+
+	response := model.ToUserResponse(user)
+
+	assert.Equal(t, &model.UserResponse{
+		Id:        "u1",
+		Name:      "Ada Lovelace",
+		Username:  "ada",
+		Email:     "ada@example.com",
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}, response)
+}
+
+func TestToUserResponse_JSONOmitsPassword(t *testing.T) {
+	user := newUser(t)
+
+	raw, err := json.Marshal(model.ToUserResponses([]*model.User{user, nil}))
+	require.NoError(t, err)
+
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Len(t, decoded, 1)
+	assert.NotContains(t, decoded[0], "password")
+	assert.ElementsMatch(t, []string{"id", "name", "username", "email", "created_at", "updated_at"}, keys(decoded[0]))
+}
+
+func TestUserPbRoundTrip_DropsPassword(t *testing.T) {
+	user := newUser(t)
+
+	response := model.FromPbUser(model.ToPbUser(user))
+
+	require.NotNil(t, response)
+	assert.Equal(t, model.ToUserResponse(user), response)
+}
+
+func TestFromPbUsers_SkipsInvalidUsers(t *testing.T) {
+	valid := model.ToPbUser(newUser(t))
+	invalid := model.ToPbUser(newUser(t))
+	invalid.CreatedAt = "yesterday"
+
+	users := model.FromPbUsers([]*pb.User{valid, invalid})
+
+	require.Len(t, users, 1)
+	assert.Equal(t, "u1", users[0].Id)
+}
+
+func keys(m map[string]interface{}) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
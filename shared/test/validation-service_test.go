@@ -0,0 +1,75 @@
+package test
+
+import (
+	"shared/pkg/model"
+	"shared/pkg/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Member struct {
+	Name      string   `json:"name" validate:"required"`
+	Email     string   `json:"email" validate:"required,email"`
+	Age       int      `json:"age" validate:"gte=0"`
+	Interests []string `json:"interests" validate:"min=1"`
+}
+
+func TestValidateWithDetails_MapsEachFailingField(t *testing.T) {
+	validator := service.NewValidationService[Member, Member]()
+
+	details, err := validator.ValidateWithDetails(Member{Email: "not-an-email", Age: -1})
+
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{
+		"name":      "name is required",
+		"email":     "email must be a valid email address",
+		"age":       "age must be greater than or equal to 0",
+		"interests": "interests must be at least 1 item",
+	}, details)
+}
+
+func TestValidateWithDetails_ValidEntity(t *testing.T) {
+	validator := service.NewValidationService[Member, Member]()
+
+	details, err := validator.ValidateWithDetails(Member{Name: "Ann", Email: "ann@example.com", Interests: []string{"poetry"}})
+
+	assert.NoError(t, err)
+	assert.Nil(t, details)
+}
+
+func TestValidationError_ListsMessages(t *testing.T) {
+	err := &service.ValidationError{Fields: map[string]string{
+		"name":  "name is required",
+		"email": "email must be a valid email address",
+	}}
+
+	assert.Equal(t, "validation failed: email must be a valid email address; name is required", err.Error())
+}
+
+func TestValidateBook_NotBorrowedIsValid(t *testing.T) {
+	validator := service.NewValidationService[model.Book, model.BookUpdateRequest]()
+
+	// false is the zero value of a bool, it must not read as missing
+	book := model.NewBook()
+	book.IsBorrowed = false
+	details, err := validator.ValidateWithDetails(book)
+	assert.NoError(t, err)
+	assert.Nil(t, details)
+
+	_, err = validator.ValidateUpdateRequest(map[string]interface{}{"is_borrowed": false})
+	assert.NoError(t, err)
+}
+
+func TestValidateBook_MissingCollectionId(t *testing.T) {
+	validator := service.NewValidationService[model.Book, model.BookUpdateRequest]()
+
+	book := model.NewBook()
+	book.CollectionId = primitive.NilObjectID
+	details, err := validator.ValidateWithDetails(book)
+
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{"collection_id": "collection_id is required"}, details)
+}
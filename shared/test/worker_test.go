@@ -0,0 +1,65 @@
+package test
+
+import (
+	"context"
+	"shared/pkg/worker"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_BoundsConcurrency(t *testing.T) {
+	pool := worker.NewPool(2)
+
+	var running, peak atomic.Int32
+	for range 6 {
+		require.NoError(t, pool.Go(func() {
+			now := running.Add(1)
+			for {
+				old := peak.Load()
+				if now <= old || peak.CompareAndSwap(old, now) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			running.Add(-1)
+		}))
+	}
+
+	require.NoError(t, pool.Shutdown(context.Background()))
+	assert.Equal(t, int32(2), peak.Load())
+	assert.Zero(t, running.Load())
+}
+
+func TestPool_ShutdownWaitsForQueuedTasks(t *testing.T) {
+	pool := worker.NewPool(1)
+
+	var done atomic.Int32
+	for range 3 {
+		require.NoError(t, pool.Go(func() {
+			time.Sleep(10 * time.Millisecond)
+			done.Add(1)
+		}))
+	}
+
+	require.NoError(t, pool.Shutdown(context.Background()))
+	assert.Equal(t, int32(3), done.Load())
+
+	// Nothing is accepted once shutdown started
+	assert.ErrorIs(t, pool.Go(func() { done.Add(1) }), worker.ErrPoolClosed)
+	assert.Equal(t, int32(3), done.Load())
+}
+
+func TestPool_ShutdownGivesUpAtDeadline(t *testing.T) {
+	pool := worker.NewPool(1)
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, pool.Go(func() { <-release }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Shutdown(ctx), context.DeadlineExceeded)
+}